@@ -0,0 +1,167 @@
+package gorsn
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DEFAULT_MAX_UNACKED is the number of events waiting for acknowledgement
+	// beyond which the oldest ones are abandoned, i.e. not sent again.
+	DEFAULT_MAX_UNACKED = 10000
+
+	// maxAckBackoff bounds the doubling of the delay between the successive
+	// deliveries of an unacknowledged event to 64 times the timeout.
+	maxAckBackoff = 6
+)
+
+// Stats holds the counters of the events delivery.
+type Stats struct {
+	// Delivered is the number of events sent to the queue.
+	Delivered int64
+	// Redelivered is the number of events sent again to the queue
+	// since not acknowledged in time.
+	Redelivered int64
+	// Unacked is the number of events waiting for acknowledgement.
+	Unacked int
+	// Abandoned is the number of unacknowledged events no longer sent
+	// again since over `DEFAULT_MAX_UNACKED` events were waiting.
+	Abandoned int64
+	// Dropped is the number of events lost since a queue was full or
+	// over the events rate limit under the `OVERFLOW_DROP` policy.
+	Dropped int64
+	// RateLimited is the number of events delayed or dropped since over
+	// the events rate limit, see `SetMaxEventsPerSecond`.
+	RateLimited int64
+}
+
+// Stats returns the counters of the events delivery.
+func (sn *snotifier) Stats() Stats {
+	return Stats{
+		Delivered:   sn.delivered.Load(),
+		Redelivered: sn.redelivered.Load(),
+		Unacked:     sn.acks.len(),
+		Abandoned:   sn.acks.abandoned.Load(),
+		Dropped:     sn.dropped.Load(),
+		RateLimited: sn.rateLimited.Load(),
+	}
+}
+
+// Ack acknowledges the processing of an event received in the at-least-once
+// delivery mode so it will not be sent again. It does nothing otherwise.
+func (ev Event) Ack() {
+	if ev.acks != nil {
+		ev.acks.ack(ev.seq)
+	}
+}
+
+// unacked is a delivered event waiting for acknowledgement
+// until its next delivery time.
+type unacked struct {
+	ev       Event
+	due      time.Time
+	attempts uint
+}
+
+// ackTracker keeps the delivered events until they are acknowledged.
+type ackTracker struct {
+	mu        sync.Mutex
+	seq       uint64
+	first     uint64 // lowest sequence number which could be pending.
+	pending   map[uint64]*unacked
+	abandoned atomic.Int64
+}
+
+// track assigns a sequence number to the event and keeps it until `due`.
+// The oldest pending event is abandoned once over the maximum.
+func (a *ackTracker) track(ev Event, due time.Time) Event {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.pending == nil {
+		a.pending = make(map[uint64]*unacked)
+	}
+	a.seq++
+	ev.seq, ev.acks = a.seq, a
+	a.pending[ev.seq] = &unacked{ev: ev, due: due}
+	for len(a.pending) > DEFAULT_MAX_UNACKED {
+		for ; a.first < a.seq; a.first++ {
+			if _, ok := a.pending[a.first]; ok {
+				delete(a.pending, a.first)
+				a.abandoned.Add(1)
+				break
+			}
+		}
+	}
+	return ev
+}
+
+func (a *ackTracker) ack(seq uint64) {
+	a.mu.Lock()
+	delete(a.pending, seq)
+	a.mu.Unlock()
+}
+
+func (a *ackTracker) len() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.pending)
+}
+
+// reset forgets all the events waiting for acknowledgement.
+func (a *ackTracker) reset() {
+	a.mu.Lock()
+	a.pending = nil
+	a.first = a.seq
+	a.mu.Unlock()
+}
+
+// expired returns at most `limit` events due for a new delivery in their
+// delivery order. The delay before the next delivery of each one doubles.
+func (a *ackTracker) expired(timeout time.Duration, now time.Time, limit int) []Event {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var due []*unacked
+	for _, u := range a.pending {
+		if !now.Before(u.due) {
+			due = append(due, u)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].ev.seq < due[j].ev.seq })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	evs := make([]Event, 0, len(due))
+	for _, u := range due {
+		if u.attempts < maxAckBackoff {
+			u.attempts++
+		}
+		u.due = now.Add(timeout << u.attempts)
+		evs = append(evs, u.ev)
+	}
+	return evs
+}
+
+// redeliver sends again, according to the overflow policy, the events not
+// acknowledged in time as long as there is room into the queue. Pending
+// events are forgotten once the mode is disabled.
+func (sn *snotifier) redeliver(timeout time.Duration) {
+	if timeout <= 0 {
+		sn.acks.reset()
+		return
+	}
+	room := cap(sn.queue) - len(sn.queue)
+	if room <= 0 {
+		return
+	}
+	for _, ev := range sn.acks.expired(timeout, sn.now(), room) {
+		sent, alive := sn.send(sn.queue, &sn.overflow, ev)
+		if !alive {
+			return
+		}
+		if sent {
+			sn.redelivered.Add(1)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package gorsn
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAckTrackerAbandonsOldest(t *testing.T) {
+	var a ackTracker
+	now := time.Now()
+	first := a.track(Event{Path: "first"}, now)
+	second := a.track(Event{Path: "second"}, now)
+	first.Ack()
+	for i := 0; i < DEFAULT_MAX_UNACKED; i++ {
+		a.track(Event{Path: "other"}, now)
+	}
+	if n := a.abandoned.Load(); n != 1 {
+		t.Fatalf("got %d abandoned events, want 1", n)
+	}
+	if n := a.len(); n != DEFAULT_MAX_UNACKED {
+		t.Errorf("got %d pending events, want %d", n, DEFAULT_MAX_UNACKED)
+	}
+	if _, ok := a.pending[second.seq]; ok {
+		t.Error("the oldest pending event was not abandoned")
+	}
+}
+
+func TestAckTrackerExpired(t *testing.T) {
+	var a ackTracker
+	now := time.Now()
+	timeout := time.Second
+	for _, p := range []string{"a", "b", "c"} {
+		a.track(Event{Path: p}, now.Add(timeout))
+	}
+	if evs := a.expired(timeout, now, 10); len(evs) != 0 {
+		t.Fatalf("got %d events expired before the timeout", len(evs))
+	}
+	now = now.Add(timeout)
+	evs := a.expired(timeout, now, 2)
+	if len(evs) != 2 || evs[0].Path != "a" || evs[1].Path != "b" {
+		t.Fatalf("got expired events %v, want the 2 oldest", evs)
+	}
+	// the delay of the sent ones doubled while `c` is still due.
+	if evs = a.expired(timeout, now.Add(timeout), 10); len(evs) != 1 || evs[0].Path != "c" {
+		t.Fatalf("got expired events %v, want only c", evs)
+	}
+	if evs = a.expired(timeout, now.Add(2*timeout), 10); len(evs) != 2 {
+		t.Fatalf("got %d expired events after twice the timeout, want 2", len(evs))
+	}
+}
+
+func TestRedeliverFullQueue(t *testing.T) {
+	opts := defaultOpts()
+	opts.Delivery().SetQueueSize(1)
+	sn, err := New(t.TempDir(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := sn.(*snotifier)
+	s.acks.track(Event{Path: "a"}, s.now())
+	s.acks.track(Event{Path: "b"}, s.now())
+	s.queue <- Event{Path: "unread"}
+
+	done := make(chan struct{})
+	go func() {
+		s.redeliver(time.Millisecond)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("redelivery blocked on the full queue")
+	}
+	<-s.queue
+	s.redeliver(time.Millisecond)
+	if ev := <-s.queue; ev.Path != "a" || s.redelivered.Load() != 1 {
+		t.Errorf("got %q redelivered with %d redeliveries, want a once", ev.Path, s.redelivered.Load())
+	}
+}
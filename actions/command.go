@@ -0,0 +1,184 @@
+package actions
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jeamon/gorsn"
+)
+
+// killWaitDelay is the time given to a killed program to release its output.
+const killWaitDelay = time.Second
+
+// Command runs an external program on the matching events, such as a build
+// each time a source file changes:
+//
+//	cmd := actions.NewCommand("go", "build", "./...").
+//		On(gorsn.CREATE, gorsn.MODIFY, gorsn.DELETE).
+//		Filter(gorsn.FilterPaths(regexp.MustCompile(`\.go$`))).
+//		SetTimeout(time.Minute)
+//	go cmd.Run(ctx, sn)
+//
+// The arguments are templates whose `{path}`, `{old_path}`, `{event}` and
+// `{type}` placeholders are replaced by the fields of the event. The program
+// is run without a shell so paths do not need to be escaped.
+type Command struct {
+	args []string
+
+	mu          sync.Mutex
+	names       []gorsn.EventName
+	filter      gorsn.Filter
+	concurrency int
+	timeout     time.Duration
+	report      func(Report)
+}
+
+// NewCommand returns a Command which runs the program named by the first
+// argument. By default, it runs on `CREATE`, `MODIFY`, `REPLACE`, `RENAME`
+// and `DELETE` events, one at a time and without timeout.
+func NewCommand(args ...string) *Command {
+	return &Command{
+		args:        args,
+		names:       []gorsn.EventName{gorsn.CREATE, gorsn.MODIFY, gorsn.REPLACE, gorsn.RENAME, gorsn.DELETE},
+		concurrency: 1,
+	}
+}
+
+// NewDevCommand applies the developer profile to `opts`, see
+// `Options.UseDevProfile`, and returns a Command which runs the program
+// named by the first argument once a changed file is fully written, i.e.
+// on its `STABLE` event, or once a path is renamed or deleted. This suits
+// build tools and live reloaders which must not see partially saved files.
+func NewDevCommand(opts *gorsn.Options, args ...string) *Command {
+	opts.UseDevProfile()
+	return NewCommand(args...).On(gorsn.STABLE, gorsn.RENAME, gorsn.DELETE)
+}
+
+// On defines the kinds of events which run the program.
+func (c *Command) On(names ...gorsn.EventName) *Command {
+	c.mu.Lock()
+	c.names = append([]gorsn.EventName(nil), names...)
+	c.mu.Unlock()
+	return c
+}
+
+// Filter defines which events among the selected kinds run the program.
+// A nil filter accepts all of them.
+func (c *Command) Filter(f gorsn.Filter) *Command {
+	c.mu.Lock()
+	c.filter = f
+	c.mu.Unlock()
+	return c
+}
+
+// SetConcurrency defines the maximum number of programs running at the same
+// time. Once reached, the events wait for a running program to complete. A
+// zero or negative value falls back to one.
+func (c *Command) SetConcurrency(n int) *Command {
+	if n <= 0 {
+		n = 1
+	}
+	c.mu.Lock()
+	c.concurrency = n
+	c.mu.Unlock()
+	return c
+}
+
+// SetTimeout defines the duration after which a running program is killed
+// and reported with `context.DeadlineExceeded`. Zero disables it.
+func (c *Command) SetTimeout(d time.Duration) *Command {
+	c.mu.Lock()
+	c.timeout = d
+	c.mu.Unlock()
+	return c
+}
+
+// OnReport registers a callback invoked with the outcome of each run.
+func (c *Command) OnReport(fn func(Report)) *Command {
+	c.mu.Lock()
+	c.report = fn
+	c.mu.Unlock()
+	return c
+}
+
+// Run executes the program for the matching events received from its own
+// subscription to `sn` until the context is cancelled, which kills the running
+// programs, or the scan notifier stopped. It waits for the running programs
+// before returning the context error if any. The main queue of `sn` must be
+// drained unless disabled with `SetSubscriptionsOnly`.
+func (c *Command) Run(ctx context.Context, sn gorsn.ScanNotifier) error {
+	c.mu.Lock()
+	queue := sn.QueueFor(c.names...)
+	sem := make(chan struct{}, c.concurrency)
+	c.mu.Unlock()
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case ev, ok := <-queue:
+			if !ok {
+				return nil
+			}
+			c.mu.Lock()
+			f := c.filter
+			c.mu.Unlock()
+			if f != nil && !f(ev) {
+				continue
+			}
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+				c.Exec(ctx, ev)
+			}()
+		}
+	}
+}
+
+// Exec runs the program for the event and returns its outcome.
+func (c *Command) Exec(ctx context.Context, ev gorsn.Event) Report {
+	r := Report{Event: ev, Action: EXEC}
+	if len(c.args) == 0 {
+		return r
+	}
+	c.mu.Lock()
+	timeout, fn := c.timeout, c.report
+	c.mu.Unlock()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	rep := strings.NewReplacer(
+		"{path}", ev.Path,
+		"{old_path}", ev.OldPath,
+		"{event}", string(ev.Name),
+		"{type}", string(ev.Type),
+	)
+	args := make([]string, len(c.args))
+	for i, a := range c.args {
+		args[i] = rep.Replace(a)
+	}
+	r.Target = args[0]
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	// do not wait for the children which keep the output open once killed.
+	cmd.WaitDelay = killWaitDelay
+	r.Output, r.Err = cmd.CombinedOutput()
+	if ctx.Err() != nil {
+		r.Err = ctx.Err()
+	}
+	if fn != nil {
+		fn(r)
+	}
+	return r
+}
@@ -0,0 +1,52 @@
+package actions
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jeamon/gorsn"
+)
+
+func TestNewDevCommand(t *testing.T) {
+	if _, err := exec.LookPath("echo"); err != nil {
+		t.Skip("no echo program:", err)
+	}
+	root := t.TempDir()
+	opts := &gorsn.Options{}
+	opts.Scan().SetInterval(10 * time.Millisecond)
+	reports := make(chan Report, 10)
+	cmd := NewDevCommand(opts, "echo", "{event}").OnReport(func(r Report) { reports <- r })
+	if opts.Delivery().GetDebounce() != gorsn.DEFAULT_DEV_DEBOUNCE || !opts.Filters().GetGitignore() {
+		t.Fatal("developer profile not applied")
+	}
+	sn, err := gorsn.New(root, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for range sn.Queue() {
+		}
+	}()
+	if err := sn.StartAsync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Stop()
+	go cmd.Run(context.Background(), sn)
+
+	time.Sleep(50 * time.Millisecond)
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case r := <-reports:
+		if r.Event.Name != gorsn.STABLE || r.Err != nil {
+			t.Errorf("got run for %s event with error %v, want a successful run once stable", r.Event.Name, r.Err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the program to run")
+	}
+}
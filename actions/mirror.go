@@ -0,0 +1,362 @@
+// Package actions provides components which act on the events of a gorsn
+// scan notifier. `Mirror` turns a scan notifier into a one-way folder sync
+// which replicates the changes of the monitored directory into a target
+// directory. `Command` runs an external program on the matching events.
+//
+//	sn, _ := gorsn.New("/data/src", &opts)
+//	m := actions.NewMirror("/data/src", "/backup/src")
+//	m.OnReport(func(r actions.Report) {
+//		if r.Err != nil {
+//			log.Printf("%s %q: %v", r.Action, r.Target, r.Err)
+//		}
+//	})
+//	go m.Run(ctx, sn)
+//	sn.Run(ctx)
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jeamon/gorsn"
+)
+
+// ErrConflict is reported when a target item was modified by someone else
+// since the mirror last wrote it. The change of the source is not applied.
+var ErrConflict = errors.New("actions: target changed since last sync")
+
+// Action is the operation applied to the target directory.
+type Action string
+
+const (
+	COPY   Action = "COPY"
+	REMOVE Action = "REMOVE"
+	MKDIR  Action = "MKDIR"
+	MOVE   Action = "MOVE"
+	CHMOD  Action = "CHMOD"
+	EXEC   Action = "EXEC"
+)
+
+// Report describes the outcome of an action run for an event. Target is the
+// path written by a `Mirror` or the program run by a `Command`, whose combined
+// standard output and error is kept into Output.
+type Report struct {
+	Event  gorsn.Event
+	Action Action
+	Target string
+	Output []byte
+	Err    error
+}
+
+// state is what the mirror knows about a target file it wrote.
+type state struct {
+	modTime time.Time
+	size    int64
+}
+
+// Mirror replicates the changes of a source directory into a target
+// directory: files are copied on `CREATE`, `MODIFY` and `REPLACE`, moved on
+// `RENAME`, their permissions updated on `PERM` and removed on `DELETE`.
+// Target files changed by someone else since the mirror wrote them are left
+// untouched and reported with `ErrConflict`, so are the directories holding
+// such files.
+type Mirror struct {
+	source string
+	target string
+
+	mu     sync.Mutex
+	synced map[string]state // target files written, keyed by relative path.
+	report func(Report)
+}
+
+// NewMirror returns a Mirror of the `source` directory, which must be the
+// root of the scan notifier, into the `target` directory. The scan notifier
+// must emit the full paths, i.e. without `SetRelativePaths`.
+func NewMirror(source, target string) *Mirror {
+	return &Mirror{source: filepath.Clean(source), target: filepath.Clean(target), synced: make(map[string]state)}
+}
+
+// OnReport registers a callback invoked with the outcome of each applied
+// event, including the failures and the conflicts.
+func (m *Mirror) OnReport(fn func(Report)) *Mirror {
+	m.mu.Lock()
+	m.report = fn
+	m.mu.Unlock()
+	return m
+}
+
+// Run copies the whole source directory into the target one, then applies
+// the events received from its own subscription to `sn` until the context is
+// cancelled or the scan notifier stopped. It returns the context error if any
+// or the error of the initial copy. The main queue of `sn` must be drained
+// unless disabled with `SetSubscriptionsOnly`.
+func (m *Mirror) Run(ctx context.Context, sn gorsn.ScanNotifier) error {
+	queue := sn.QueueFor(gorsn.CREATE, gorsn.MODIFY, gorsn.REPLACE, gorsn.RENAME, gorsn.PERM, gorsn.DELETE)
+	if err := m.Sync(); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case ev, ok := <-queue:
+			if !ok {
+				return nil
+			}
+			m.Apply(ev)
+		}
+	}
+}
+
+// Sync copies the items of the source directory which are missing or
+// different into the target directory.
+func (m *Mirror) Sync() error {
+	return filepath.WalkDir(m.source, func(p string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) && p != m.source {
+			// removed during the walk, its event follows.
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		typ := gorsn.FILE
+		if d.IsDir() {
+			typ = gorsn.DIR
+		} else if !d.Type().IsRegular() {
+			return nil
+		}
+		m.Apply(gorsn.Event{Path: p, Type: typ, Name: gorsn.CREATE})
+		return nil
+	})
+}
+
+// Apply replicates the change described by the event into the target
+// directory and returns its outcome. Events of other kinds of items than
+// files and directories are ignored.
+func (m *Mirror) Apply(ev gorsn.Event) Report {
+	r := Report{Event: ev}
+	rel, ok := m.rel(ev.Path)
+	if !ok || (ev.Type != gorsn.FILE && ev.Type != gorsn.DIR) {
+		return r
+	}
+	r.Target = filepath.Join(m.target, rel)
+	switch ev.Name {
+	case gorsn.CREATE, gorsn.MODIFY, gorsn.REPLACE:
+		if ev.Type == gorsn.DIR && ev.Name != gorsn.CREATE {
+			// the content of a directory is replicated by its own events.
+			return r
+		}
+		if ev.Type == gorsn.DIR {
+			r.Action, r.Err = MKDIR, m.mkdir(ev.Path, r.Target)
+		} else {
+			r.Action, r.Err = COPY, m.copy(rel, ev.Path, r.Target)
+		}
+	case gorsn.RENAME:
+		r.Action, r.Err = MOVE, m.move(ev, rel, r.Target)
+	case gorsn.PERM:
+		r.Action, r.Err = CHMOD, os.Chmod(r.Target, ev.Mode.Perm())
+	case gorsn.DELETE:
+		r.Action, r.Err = REMOVE, m.remove(rel, r.Target, ev.Type)
+	default:
+		return r
+	}
+	m.mu.Lock()
+	fn := m.report
+	m.mu.Unlock()
+	if fn != nil {
+		fn(r)
+	}
+	return r
+}
+
+// rel returns the path relative to the source directory. It reports false
+// for the source directory itself and the paths outside of it.
+func (m *Mirror) rel(p string) (string, bool) {
+	rel, err := filepath.Rel(m.source, p)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return rel, true
+}
+
+// conflict reports whether the target file changed since it was written.
+// Target files not written by the mirror are owned by it and never conflict.
+func (m *Mirror) conflict(rel, target string) bool {
+	m.mu.Lock()
+	st, ok := m.synced[rel]
+	m.mu.Unlock()
+	if !ok {
+		return false
+	}
+	fi, err := os.Stat(target)
+	if err != nil {
+		return !errors.Is(err, fs.ErrNotExist)
+	}
+	return fi.Size() != st.size || !fi.ModTime().Equal(st.modTime)
+}
+
+func (m *Mirror) mkdir(src, target string) error {
+	fi, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	return os.MkdirAll(target, fi.Mode().Perm())
+}
+
+// copy writes the content of the source file into a temporary file next to
+// the target which then replaces it, so the target is never half written.
+// The modification time of the source is kept so unchanged files are not
+// copied again by `Sync`.
+func (m *Mirror) copy(rel, src, target string) error {
+	if m.conflict(rel, target) {
+		return ErrConflict
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	fi, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	if cur, err := os.Stat(target); err == nil && cur.Size() == fi.Size() && cur.ModTime().Equal(fi.ModTime()) {
+		m.record(rel, cur)
+		return nil
+	}
+	if err = os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".gorsn-mirror-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = io.Copy(tmp, in)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if err = os.Chmod(tmp.Name(), fi.Mode().Perm()); err != nil {
+		return err
+	}
+	if err = os.Chtimes(tmp.Name(), fi.ModTime(), fi.ModTime()); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp.Name(), target); err != nil {
+		return err
+	}
+	cur, err := os.Stat(target)
+	if err != nil {
+		return err
+	}
+	m.record(rel, cur)
+	return nil
+}
+
+// move renames the target of the previous path, or copies the new path if
+// that target does not exist.
+func (m *Mirror) move(ev gorsn.Event, rel, target string) error {
+	oldRel, ok := m.rel(ev.OldPath)
+	if !ok {
+		return m.copy(rel, ev.Path, target)
+	}
+	old := filepath.Join(m.target, oldRel)
+	if m.conflict(oldRel, old) || m.conflict(rel, target) {
+		return ErrConflict
+	}
+	if _, err := os.Lstat(old); errors.Is(err, fs.ErrNotExist) {
+		if ev.Type == gorsn.DIR {
+			return m.mkdir(ev.Path, target)
+		}
+		return m.copy(rel, ev.Path, target)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	if err := os.Rename(old, target); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	prefix := oldRel + string(filepath.Separator)
+	for p, st := range m.synced {
+		if p == oldRel {
+			delete(m.synced, p)
+			m.synced[rel] = st
+		} else if strings.HasPrefix(p, prefix) {
+			delete(m.synced, p)
+			m.synced[filepath.Join(rel, strings.TrimPrefix(p, prefix))] = st
+		}
+	}
+	return nil
+}
+
+// remove deletes the target of the path. A directory holding files changed
+// by someone else is left untouched and these files are reported.
+func (m *Mirror) remove(rel, target string, typ gorsn.PathType) error {
+	var err error
+	if typ == gorsn.DIR {
+		if conflicts := m.conflicts(rel); len(conflicts) > 0 {
+			return fmt.Errorf("%w: %s", ErrConflict, strings.Join(conflicts, ", "))
+		}
+		err = os.RemoveAll(target)
+	} else {
+		if m.conflict(rel, target) {
+			return ErrConflict
+		}
+		err = os.Remove(target)
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		err = nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	prefix := rel + string(filepath.Separator)
+	for p := range m.synced {
+		if p == rel || strings.HasPrefix(p, prefix) {
+			delete(m.synced, p)
+		}
+	}
+	return err
+}
+
+// conflicts returns the sorted paths of the synced files under the directory
+// `rel` which changed since they were written.
+func (m *Mirror) conflicts(rel string) []string {
+	prefix := rel + string(filepath.Separator)
+	m.mu.Lock()
+	var synced []string
+	for p := range m.synced {
+		if strings.HasPrefix(p, prefix) {
+			synced = append(synced, p)
+		}
+	}
+	m.mu.Unlock()
+	var conflicts []string
+	for _, p := range synced {
+		if m.conflict(p, filepath.Join(m.target, p)) {
+			conflicts = append(conflicts, p)
+		}
+	}
+	sort.Strings(conflicts)
+	return conflicts
+}
+
+// record keeps the state of a target file written by the mirror.
+func (m *Mirror) record(rel string, fi fs.FileInfo) {
+	m.mu.Lock()
+	m.synced[rel] = state{modTime: fi.ModTime(), size: fi.Size()}
+	m.mu.Unlock()
+}
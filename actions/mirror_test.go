@@ -0,0 +1,243 @@
+package actions
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jeamon/gorsn"
+)
+
+// write creates the file `name` under `dir` with `content`.
+func write(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	p := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// content returns the content of the file `name` under `dir`, "<none>"
+// if it does not exist.
+func content(t *testing.T, dir, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return "<none>"
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// tamper changes the target file like someone else would.
+func tamper(t *testing.T, dir, name string) {
+	t.Helper()
+	p := write(t, dir, name, "changed by someone else")
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(p, later, later); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newTestMirror(t *testing.T) (m *Mirror, src, dst string) {
+	src, dst = t.TempDir(), t.TempDir()
+	return NewMirror(src, dst), src, dst
+}
+
+func TestMirrorSync(t *testing.T) {
+	m, src, dst := newTestMirror(t)
+	write(t, src, "a", "1")
+	write(t, src, filepath.Join("d", "e", "b"), "22")
+	if err := os.Mkdir(filepath.Join(src, "empty"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	var actions []string
+	m.OnReport(func(r Report) {
+		if r.Err != nil {
+			t.Errorf("got error %v for %s", r.Err, r.Target)
+		}
+		actions = append(actions, string(r.Action))
+	})
+	if err := m.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if a, b := content(t, dst, "a"), content(t, dst, filepath.Join("d", "e", "b")); a != "1" || b != "22" {
+		t.Errorf("got contents %q and %q, want the source ones", a, b)
+	}
+	fi, err := os.Stat(filepath.Join(dst, "empty"))
+	if err != nil || !fi.IsDir() || fi.Mode().Perm() != 0o700 {
+		t.Errorf("got empty directory %v with error %v, want it created with its permissions", fi, err)
+	}
+	sfi, _ := os.Stat(filepath.Join(src, "a"))
+	if tfi, _ := os.Stat(filepath.Join(dst, "a")); !tfi.ModTime().Equal(sfi.ModTime()) {
+		t.Errorf("got modification time %v, want the source one %v", tfi.ModTime(), sfi.ModTime())
+	}
+	if got := strings.Join(actions, ","); strings.Count(got, string(COPY)) != 2 || strings.Count(got, string(MKDIR)) != 3 {
+		t.Errorf("got actions %s, want 2 copies and 3 directories", got)
+	}
+
+	// a second sync only reports the target changed by someone else.
+	tamper(t, dst, "a")
+	var failed []Report
+	m.OnReport(func(r Report) {
+		if r.Err != nil {
+			failed = append(failed, r)
+		}
+	})
+	if err := m.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if len(failed) != 1 || !errors.Is(failed[0].Err, ErrConflict) || failed[0].Target != filepath.Join(dst, "a") {
+		t.Errorf("got failures %+v, want a single conflict of a", failed)
+	}
+	if got := content(t, dst, "a"); got != "changed by someone else" {
+		t.Errorf("got content %q, want the changed target kept", got)
+	}
+
+	if err := NewMirror(filepath.Join(src, "missing"), dst).Sync(); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("got error %v for a missing source, want os.ErrNotExist", err)
+	}
+}
+
+func TestMirrorCopy(t *testing.T) {
+	m, src, dst := newTestMirror(t)
+	p := write(t, src, filepath.Join("d", "a"), "1")
+	r := m.Apply(gorsn.Event{Path: p, Type: gorsn.FILE, Name: gorsn.CREATE})
+	if r.Action != COPY || r.Err != nil || r.Target != filepath.Join(dst, "d", "a") {
+		t.Fatalf("got report %+v, want a copy to the target", r)
+	}
+	write(t, src, filepath.Join("d", "a"), "22")
+	if r := m.Apply(gorsn.Event{Path: p, Type: gorsn.FILE, Name: gorsn.MODIFY}); r.Err != nil {
+		t.Fatal(r.Err)
+	}
+	if got := content(t, dst, filepath.Join("d", "a")); got != "22" {
+		t.Errorf("got content %q, want the modified one", got)
+	}
+	if err := os.Chmod(p, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if r := m.Apply(gorsn.Event{Path: p, Type: gorsn.FILE, Name: gorsn.PERM, Mode: 0o600}); r.Action != CHMOD || r.Err != nil {
+		t.Fatalf("got report %+v, want a change of permissions", r)
+	}
+	if fi, err := os.Stat(r.Target); err != nil || fi.Mode().Perm() != 0o600 {
+		t.Errorf("got target %v with error %v, want permissions 0600", fi, err)
+	}
+	// the events of other items and of the paths out of the source.
+	for _, ev := range []gorsn.Event{
+		{Path: src, Type: gorsn.DIR, Name: gorsn.CREATE},
+		{Path: filepath.Join(filepath.Dir(src), "other"), Type: gorsn.FILE, Name: gorsn.CREATE},
+		{Path: filepath.Join(src, "link"), Type: gorsn.SYMLINK, Name: gorsn.CREATE},
+		{Path: filepath.Join(src, "d"), Type: gorsn.DIR, Name: gorsn.MODIFY},
+	} {
+		if r := m.Apply(ev); r.Action != "" {
+			t.Errorf("got action %s for %s of %s, want it ignored", r.Action, ev.Name, ev.Path)
+		}
+	}
+}
+
+func TestMirrorRename(t *testing.T) {
+	m, src, dst := newTestMirror(t)
+	write(t, src, filepath.Join("d", "a"), "1")
+	write(t, src, filepath.Join("d", "e", "b"), "22")
+	if err := m.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(filepath.Join(src, "d"), filepath.Join(src, "n")); err != nil {
+		t.Fatal(err)
+	}
+	r := m.Apply(gorsn.Event{Path: filepath.Join(src, "n"), OldPath: filepath.Join(src, "d"), Type: gorsn.DIR, Name: gorsn.RENAME})
+	if r.Action != MOVE || r.Err != nil {
+		t.Fatalf("got report %+v, want a move", r)
+	}
+	if got := content(t, dst, filepath.Join("n", "e", "b")); got != "22" {
+		t.Errorf("got content %q of the moved child, want %q", got, "22")
+	}
+	if _, err := os.Stat(filepath.Join(dst, "d")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("got error %v for the old directory, want it moved", err)
+	}
+
+	// the children are tracked under their new path.
+	tamper(t, dst, filepath.Join("n", "e", "b"))
+	write(t, src, filepath.Join("n", "e", "b"), "333")
+	r = m.Apply(gorsn.Event{Path: filepath.Join(src, "n", "e", "b"), Type: gorsn.FILE, Name: gorsn.MODIFY})
+	if !errors.Is(r.Err, ErrConflict) {
+		t.Errorf("got error %v for a moved child changed by someone else, want ErrConflict", r.Err)
+	}
+
+	// a rename whose old target is missing is a copy.
+	p := write(t, src, "c", "4")
+	r = m.Apply(gorsn.Event{Path: p, OldPath: filepath.Join(src, "unknown"), Type: gorsn.FILE, Name: gorsn.RENAME})
+	if r.Err != nil || content(t, dst, "c") != "4" {
+		t.Errorf("got report %+v, want the renamed file copied", r)
+	}
+}
+
+func TestMirrorDelete(t *testing.T) {
+	m, src, dst := newTestMirror(t)
+	write(t, src, "a", "1")
+	write(t, src, filepath.Join("d", "e", "b"), "22")
+	if err := m.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	// a target file not written by the mirror is owned by it.
+	write(t, dst, filepath.Join("d", "own"), "3")
+	if err := os.RemoveAll(filepath.Join(src, "d")); err != nil {
+		t.Fatal(err)
+	}
+	r := m.Apply(gorsn.Event{Path: filepath.Join(src, "d"), Type: gorsn.DIR, Name: gorsn.DELETE})
+	if r.Action != REMOVE || r.Err != nil {
+		t.Fatalf("got report %+v, want the directory removed", r)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "d")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("got error %v for the removed directory", err)
+	}
+	if r := m.Apply(gorsn.Event{Path: filepath.Join(src, "a"), Type: gorsn.FILE, Name: gorsn.DELETE}); r.Err != nil {
+		t.Fatal(r.Err)
+	}
+	if got := content(t, dst, "a"); got != "<none>" {
+		t.Errorf("got content %q of the removed file", got)
+	}
+	// removing a missing target is not an error.
+	if r := m.Apply(gorsn.Event{Path: filepath.Join(src, "a"), Type: gorsn.FILE, Name: gorsn.DELETE}); r.Err != nil {
+		t.Errorf("got error %v removing a missing target", r.Err)
+	}
+}
+
+func TestMirrorConflict(t *testing.T) {
+	m, src, dst := newTestMirror(t)
+	a := write(t, src, "a", "1")
+	write(t, src, filepath.Join("d", "b"), "22")
+	write(t, src, filepath.Join("d", "e", "c"), "333")
+	if err := m.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	tamper(t, dst, "a")
+	tamper(t, dst, filepath.Join("d", "e", "c"))
+
+	write(t, src, "a", "4444")
+	for _, name := range []gorsn.EventName{gorsn.MODIFY, gorsn.DELETE} {
+		if r := m.Apply(gorsn.Event{Path: a, Type: gorsn.FILE, Name: name}); !errors.Is(r.Err, ErrConflict) {
+			t.Errorf("got error %v for %s of a changed target, want ErrConflict", r.Err, name)
+		}
+	}
+	if got := content(t, dst, "a"); got != "changed by someone else" {
+		t.Errorf("got content %q, want the changed target kept", got)
+	}
+
+	r := m.Apply(gorsn.Event{Path: filepath.Join(src, "d"), Type: gorsn.DIR, Name: gorsn.DELETE})
+	if !errors.Is(r.Err, ErrConflict) || !strings.Contains(r.Err.Error(), filepath.Join("d", "e", "c")) {
+		t.Fatalf("got error %v for a directory holding a changed file, want ErrConflict reporting it", r.Err)
+	}
+	if b, c := content(t, dst, filepath.Join("d", "b")), content(t, dst, filepath.Join("d", "e", "c")); b != "22" || c != "changed by someone else" {
+		t.Errorf("got contents %q and %q, want the directory untouched", b, c)
+	}
+}
@@ -0,0 +1,78 @@
+package gorsn
+
+import "fmt"
+
+// rateBaseline keeps the number of events emitted during
+// the latest scan cycles in order to compute their mean.
+type rateBaseline struct {
+	counts []int64
+	next   int
+	full   bool
+}
+
+func (b *rateBaseline) reset(window int) {
+	b.counts = make([]int64, window)
+	b.next = 0
+	b.full = false
+}
+
+func (b *rateBaseline) add(n int64) {
+	b.counts[b.next] = n
+	b.next++
+	if b.next == len(b.counts) {
+		b.next = 0
+		b.full = true
+	}
+}
+
+// busy reports whether events were emitted during each cycle.
+func (b *rateBaseline) busy() bool {
+	for _, n := range b.counts {
+		if n == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *rateBaseline) mean() float64 {
+	var sum int64
+	for _, n := range b.counts {
+		sum += n
+	}
+	return float64(sum) / float64(len(b.counts))
+}
+
+// detectAnomaly compares the number of events emitted during the latest
+// cycle against the rolling baseline and emits an `ANOMALY` event when it
+// is `sensitivity` times above the mean or when a tree which emitted events
+// during each cycle of the baseline went silent.
+func (sn *snotifier) detectAnomaly(emitted int64) {
+	window := int(sn.opts.events.anomalyWindow.Load())
+	if window == 0 {
+		return
+	}
+	b := &sn.baseline
+	if len(b.counts) != window {
+		b.reset(window)
+	}
+	if b.full {
+		sensitivity, _ := sn.opts.events.anomalySensitivity.Load().(float64)
+		avg := b.mean()
+		ref := avg
+		if ref < 1 {
+			ref = 1
+		}
+		var err error
+		switch {
+		case float64(emitted) > ref*sensitivity:
+			err = fmt.Errorf("%w: spike of %d events against a baseline of %.2f", ErrAnomalyDetected, emitted, avg)
+		case emitted == 0 && b.busy():
+			err = fmt.Errorf("%w: no events against a baseline of %.2f", ErrAnomalyDetected, avg)
+		}
+		if err != nil {
+			sn.queueEvent(Event{Path: sn.root, Type: sn.rootType(), Name: ANOMALY, Error: err})
+		}
+	}
+	b.add(emitted)
+}
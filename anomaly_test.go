@@ -0,0 +1,143 @@
+package gorsn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDetectAnomaly(t *testing.T) {
+	tests := []struct {
+		name   string
+		counts []int64
+		want   []string // the anomalies of the cycles after the baseline.
+	}{
+		{"steady", []int64{2, 2, 2, 3, 2}, []string{"", ""}},
+		{"spike", []int64{2, 2, 2, 7, 2}, []string{"spike", ""}},
+		{"spike over a quiet tree", []int64{0, 0, 0, 4, 0}, []string{"spike", ""}},
+		{"silence of a busy tree", []int64{2, 1, 2, 0, 0}, []string{"no events", ""}},
+		{"silence of a busy tree under the sensitivity", []int64{1, 1, 1, 0}, []string{"no events"}},
+		{"silence of a bursty tree", []int64{5, 0, 5, 0}, []string{""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := defaultOpts()
+			opts.Events().SetAnomalyDetection(3, 3)
+			sn, err := New(t.TempDir(), opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := sn.(*snotifier)
+			s.running.Store(true)
+			var got []string
+			for i, n := range tt.counts {
+				s.detectAnomaly(n)
+				if i < 3 {
+					continue
+				}
+				var anomaly string
+				select {
+				case ev := <-sn.Queue():
+					if ev.Name != ANOMALY || !errors.Is(ev.Error, ErrAnomalyDetected) {
+						t.Fatalf("got %s event with error %v, want an anomaly", ev.Name, ev.Error)
+					}
+					anomaly = "no events"
+					if strings.Contains(ev.Error.Error(), "spike") {
+						anomaly = "spike"
+					}
+				default:
+				}
+				got = append(got, anomaly)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got anomalies %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectAnomalySkipsAbandonedCycle(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, "1", "a")
+	if err := os.Mkdir(filepath.Join(dir, "zz"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, filepath.Join(dir, "zz"), "1", "x")
+	backend := &gatedBackend{
+		Backend: osBackend{},
+		dir:     filepath.Join(dir, "zz"),
+		file:    filepath.Join(dir, "a"),
+		read:    newGate(),
+		info:    newGate(),
+	}
+	var (
+		mu     sync.Mutex
+		cycles []int
+	)
+	paused := make(chan struct{})
+	opts := defaultOpts()
+	opts.Scan().SetInterval(5 * time.Millisecond).SetMaxWorkers(1).SetBackend(backend).
+		SetBeforeScan(func(cycle int) {
+			switch cycle {
+			case 1, 2:
+				writeFiles(t, dir, "1", fmt.Sprintf("c%d", cycle))
+			case 3:
+				backend.read.armed.Store(true)
+				backend.info.armed.Store(true)
+			}
+		}).
+		SetAfterScan(func(cycle int, _ ScanSummary) {
+			mu.Lock()
+			cycles = append(cycles, cycle)
+			mu.Unlock()
+		})
+	opts.Events().SetAnomalyDetection(2, 3)
+	opts.Delivery().SetQueueSize(64).OnPause(func() { close(paused) })
+	sn, err := New(dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sn.StartAsync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Stop()
+
+	wait(t, backend.info.entered, "worker")
+	wait(t, backend.read.entered, "walker")
+	if err := sn.PauseImmediately(); err != nil {
+		t.Fatal(err)
+	}
+	close(backend.read.release)
+	for !sn.(*snotifier).aborted.Load() {
+		time.Sleep(time.Millisecond)
+	}
+	close(backend.info.release)
+	wait(t, paused, "OnPause")
+
+	for {
+		select {
+		case ev := <-sn.Queue():
+			if ev.Name == ANOMALY {
+				t.Errorf("got anomaly %v from the abandoned cycle", ev.Error)
+			}
+			continue
+		default:
+		}
+		break
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !reflect.DeepEqual(cycles, []int{1, 2}) {
+		t.Errorf("got post-scan hook of cycles %v, want only the completed cycles 1 and 2", cycles)
+	}
+	if b := &sn.(*snotifier).baseline; !b.full || b.next != 0 {
+		t.Errorf("got baseline %+v, want only the samples of the completed cycles", *b)
+	}
+}
@@ -0,0 +1,56 @@
+package gorsn
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// SetTrackAppends defines the glob patterns, relative to the root directory and
+// using the `.gitignore` syntax, of the regular files whose `MODIFY`, `REPLACE`
+// and `ROTATE` events carry their previous and current sizes into the `OldSize`
+// and `NewSize` fields, such as "*.log". Log shippers could then read only the
+// bytes appended between both sizes. No patterns disable it which is the default.
+// It returns an error which wraps `ErrInvalidOptions` if a glob is malformed,
+// the tracked files are then unchanged.
+func (eo *EventOptions) SetTrackAppends(globs ...string) error {
+	var patterns []*regexp.Regexp
+	for _, g := range globs {
+		g = strings.Trim(g, "/")
+		if g == "" {
+			continue
+		}
+		re, err := regexp.Compile(globToRegexp(g))
+		if err != nil {
+			return fmt.Errorf("%w: tracked appends glob %q: %v", ErrInvalidOptions, g, err)
+		}
+		patterns = append(patterns, re)
+	}
+	eo.appends.Store(patterns)
+	return nil
+}
+
+// appendPatterns returns the patterns of the files whose sizes are tracked.
+func (eo *EventOptions) appendPatterns() []*regexp.Regexp {
+	patterns, _ := eo.appends.Load().([]*regexp.Regexp)
+	return patterns
+}
+
+// withSizes sets the previous and current sizes of the event of a regular
+// file if its path matches the patterns of the tracked appends.
+func (sn *snotifier) withSizes(ev *Event, oldSize, newSize int64) {
+	if ev.Type != FILE {
+		return
+	}
+	patterns := sn.opts.events.appendPatterns()
+	if len(patterns) == 0 {
+		return
+	}
+	rel := sn.rel(ev.Path)
+	for _, re := range patterns {
+		if re.MatchString(rel) {
+			ev.OldSize, ev.NewSize = oldSize, newSize
+			return
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package gorsn
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetTrackAppends(t *testing.T) {
+	tests := []struct {
+		glob  string
+		fails bool
+	}{
+		{"*.log", false},
+		{"logs/**/*.[0-9]", false},
+		{"[]", true},
+		{"[!]", true},
+		{"[]]", true},
+		{"[z-a]", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.glob, func(t *testing.T) {
+			opts := defaultOpts()
+			if err := opts.Events().SetTrackAppends("*.txt"); err != nil {
+				t.Fatal(err)
+			}
+			err := opts.Events().SetTrackAppends("*.out", tt.glob)
+			if tt.fails != (err != nil) {
+				t.Fatalf("got error %v, want a failure %t", err, tt.fails)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidOptions) {
+				t.Fatalf("got error %v, want ErrInvalidOptions", err)
+			}
+			root := t.TempDir()
+			sn, err := New(root, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ev := Event{Path: filepath.Join(root, "notes.txt"), Type: FILE}
+			sn.(*snotifier).withSizes(&ev, 1, 2)
+			if tracked := ev.NewSize == 2; tracked != tt.fails {
+				t.Errorf("got previous patterns kept %t, want %t", tracked, tt.fails)
+			}
+		})
+	}
+}
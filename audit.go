@@ -0,0 +1,125 @@
+package gorsn
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"sync"
+	"time"
+)
+
+// auditRecord is the JSON representation of an event into the audit log.
+type auditRecord struct {
+	Schema        int          `json:"schema"`
+	Time          time.Time    `json:"time"`
+	Root          string       `json:"root,omitempty"`
+	RootLabel     string       `json:"root_label,omitempty"`
+	Checksum      string       `json:"checksum,omitempty"`
+	ChecksumAlgo  string       `json:"checksum_algorithm,omitempty"`
+	ContentType   string       `json:"content_type,omitempty"`
+	LinkTarget    string       `json:"link_target,omitempty"`
+	RealPath      string       `json:"real_path,omitempty"`
+	Suppressed    int          `json:"suppressed,omitempty"`
+	OldSize       int64        `json:"old_size,omitempty"`
+	NewSize       int64        `json:"new_size,omitempty"`
+	Regions       []Region     `json:"regions,omitempty"`
+	Path          string       `json:"path"`
+	OldPath       string       `json:"old_path,omitempty"`
+	ChildrenCount int          `json:"children_count,omitempty"`
+	Dropped       int          `json:"dropped,omitempty"`
+	Prefixes      []string     `json:"prefixes,omitempty"`
+	Type          PathType     `json:"type"`
+	Name          EventName    `json:"event"`
+	Error         string       `json:"error,omitempty"`
+	Severity      string       `json:"severity"`
+	Tags          []string     `json:"tags,omitempty"`
+	Cycle         int          `json:"cycle,omitempty"`
+	Summary       *ScanSummary `json:"summary,omitempty"`
+	Mode          fs.FileMode  `json:"mode,omitempty"`
+	OldMode       fs.FileMode  `json:"old_mode,omitempty"`
+	DetectedAt    *time.Time   `json:"detected_at,omitempty"`
+	ModTime       *time.Time   `json:"mod_time,omitempty"`
+}
+
+type auditLogSink struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewAuditLogSink returns a Sink which appends each event as a JSON line
+// stamped with the time it was recorded into `w`. Closing `w` is left to
+// the caller once the scan notifier has stopped.
+func NewAuditLogSink(w io.Writer) Sink {
+	return &auditLogSink{enc: json.NewEncoder(w)}
+}
+
+func (s *auditLogSink) Write(ev Event) error {
+	rec := newAuditRecord(ev)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.enc.Encode(rec)
+}
+
+// newAuditRecord builds the record of an event stamped with the current time.
+func newAuditRecord(ev Event) auditRecord {
+	rec := auditRecord{
+		Schema:        SchemaVersion,
+		Time:          time.Now(),
+		Root:          ev.Root,
+		RootLabel:     ev.RootLabel,
+		Checksum:      ev.Checksum,
+		ChecksumAlgo:  ev.ChecksumAlgorithm,
+		ContentType:   ev.ContentType,
+		LinkTarget:    ev.LinkTarget,
+		RealPath:      ev.RealPath,
+		Suppressed:    ev.Suppressed,
+		OldSize:       ev.OldSize,
+		NewSize:       ev.NewSize,
+		Regions:       ev.Regions,
+		Path:          ev.Path,
+		OldPath:       ev.OldPath,
+		ChildrenCount: ev.ChildrenCount,
+		Dropped:       ev.Dropped,
+		Prefixes:      ev.Prefixes,
+		Type:          ev.Type,
+		Name:          ev.Name,
+		Mode:          ev.Mode,
+		OldMode:       ev.OldMode,
+		Severity:      ev.Severity.String(),
+		Tags:          ev.Tags,
+		Cycle:         ev.Cycle,
+		Summary:       ev.Summary,
+	}
+	if ev.Error != nil {
+		rec.Error = ev.Error.Error()
+	}
+	if !ev.Time.IsZero() {
+		rec.DetectedAt = &ev.Time
+	}
+	if !ev.ModTime.IsZero() {
+		rec.ModTime = &ev.ModTime
+	}
+	return rec
+}
+
+// event rebuilds the event described by the record.
+func (rec auditRecord) event() Event {
+	ev := Event{Root: rec.Root, RootLabel: rec.RootLabel, Checksum: rec.Checksum, ChecksumAlgorithm: rec.ChecksumAlgo, ContentType: rec.ContentType, LinkTarget: rec.LinkTarget, RealPath: rec.RealPath, Suppressed: rec.Suppressed, OldSize: rec.OldSize, NewSize: rec.NewSize, Regions: rec.Regions, Path: rec.Path, OldPath: rec.OldPath, Type: rec.Type, Name: rec.Name, ChildrenCount: rec.ChildrenCount, Dropped: rec.Dropped, Prefixes: rec.Prefixes, Mode: rec.Mode, OldMode: rec.OldMode, Tags: rec.Tags, Cycle: rec.Cycle, Summary: rec.Summary}
+	if rec.Error != "" {
+		ev.Error = errors.New(rec.Error)
+	}
+	switch rec.Severity {
+	case "WARNING":
+		ev.Severity = SEVERITY_WARNING
+	case "ERROR":
+		ev.Severity = SEVERITY_ERROR
+	}
+	if rec.DetectedAt != nil {
+		ev.Time = *rec.DetectedAt
+	}
+	if rec.ModTime != nil {
+		ev.ModTime = *rec.ModTime
+	}
+	return ev
+}
@@ -0,0 +1,66 @@
+package gorsn
+
+import "time"
+
+// autoscale holds the bounds of the workers pool when it is scaled
+// from the backlog of the paths to check and the scan durations.
+type autoscale struct {
+	min, max uint32
+}
+
+// autoscale returns the bounds of the workers pool if autoscaling is enabled.
+func (so *ScanOptions) autoscale() *autoscale {
+	as, _ := so.scale.Load().(*autoscale)
+	return as
+}
+
+// poolSize returns the size of the workers pool for a scan cycle.
+func (sn *snotifier) poolSize() uint32 {
+	as := sn.opts.scan.autoscale()
+	if as == nil {
+		return sn.opts.scan.maxworkers.Load()
+	}
+	if sn.target < as.min {
+		sn.target = as.min
+	}
+	if sn.target > as.max {
+		sn.target = as.max
+	}
+	return sn.target
+}
+
+// grow starts one more worker if the paths to check are piling up
+// into the internal queue and the workers pool did not reach its limit.
+func (sn *snotifier) grow() {
+	backlog := len(sn.iqueue)
+	if backlog > sn.backlog {
+		sn.backlog = backlog
+	}
+	as := sn.opts.scan.autoscale()
+	if as == nil || sn.size >= as.max || backlog <= cap(sn.iqueue)/2 {
+		return
+	}
+	sn.spawn()
+}
+
+// scale computes the size of the workers pool for the next scan cycle. It keeps
+// the workers added during the completed cycle or doubles the pool if the cycle
+// lasted longer than the scan interval. It removes one worker if the internal
+// queue never got over a quarter of its capacity.
+func (sn *snotifier) scale(elapsed time.Duration) {
+	as := sn.opts.scan.autoscale()
+	if as == nil {
+		return
+	}
+	switch {
+	case sn.size > sn.target:
+		sn.target = sn.size
+	case elapsed > sn.opts.scan.interval.Load().(time.Duration):
+		sn.target *= 2
+	case sn.backlog <= cap(sn.iqueue)/4 && sn.target > as.min:
+		sn.target--
+	}
+	if sn.target > as.max {
+		sn.target = as.max
+	}
+}
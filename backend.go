@@ -0,0 +1,119 @@
+package gorsn
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// Backend abstracts the file system operations needed to scan the root
+// directory. It allows to monitor any file system such as in-memory ones
+// for unit testing or composed ones. The default backend is the local
+// operating system file system.
+type Backend interface {
+	// Stat returns the infos of the named file and follows symlinks.
+	Stat(name string) (fs.FileInfo, error)
+	// Lstat returns the infos of the named file without following symlinks.
+	Lstat(name string) (fs.FileInfo, error)
+	// ReadDir returns the entries of the named directory sorted by filename.
+	ReadDir(name string) ([]fs.DirEntry, error)
+	// Join joins any number of path elements into a single path.
+	Join(elem ...string) string
+}
+
+// osBackend is the Backend of the local file system. Long paths are
+// accessed through their extended-length form on Windows.
+type osBackend struct{}
+
+func (osBackend) Stat(name string) (fs.FileInfo, error)      { return os.Stat(longPath(name)) }
+func (osBackend) Lstat(name string) (fs.FileInfo, error)     { return os.Lstat(longPath(name)) }
+func (osBackend) ReadDir(name string) ([]fs.DirEntry, error) { return os.ReadDir(longPath(name)) }
+func (osBackend) Join(elem ...string) string                 { return filepath.Join(elem...) }
+func (osBackend) Open(name string) (io.ReadCloser, error)    { return os.Open(longPath(name)) }
+func (osBackend) Readlink(name string) (string, error)       { return os.Readlink(longPath(name)) }
+func (osBackend) EvalSymlinks(name string) (string, error)   { return filepath.EvalSymlinks(name) }
+
+// fsBackend is the Backend of an `fs.FS` file system.
+type fsBackend struct {
+	fsys fs.FS
+}
+
+// FSBackend returns a Backend which scans the `fsys` file system. Paths are
+// slash-separated and unrooted like `fs.FS` ones, so "." is its root directory.
+// Any afero file system could be monitored through `afero.NewIOFS` adapter
+// without making afero a dependency of this package.
+func FSBackend(fsys fs.FS) Backend {
+	return &fsBackend{fsys}
+}
+
+func (b *fsBackend) Stat(name string) (fs.FileInfo, error) {
+	return fs.Stat(b.fsys, name)
+}
+
+// Lstat falls back to Stat since `fs.FS` does not expose symlinks.
+func (b *fsBackend) Lstat(name string) (fs.FileInfo, error) {
+	return fs.Stat(b.fsys, name)
+}
+
+func (b *fsBackend) ReadDir(name string) ([]fs.DirEntry, error) {
+	return fs.ReadDir(b.fsys, name)
+}
+
+func (b *fsBackend) Join(elem ...string) string {
+	return path.Join(elem...)
+}
+
+func (b *fsBackend) Open(name string) (io.ReadCloser, error) {
+	return b.fsys.Open(name)
+}
+
+// walk walks the file tree rooted at root by using the backend `b` and
+// calls `fn` for each file or directory in the tree, including root. It
+// follows the same rules as `filepath.WalkDir`.
+func walk(b Backend, root string, fn fs.WalkDirFunc) error {
+	info, err := b.Lstat(root)
+	if err != nil {
+		err = fn(root, nil, err)
+	} else {
+		err = walkDir(b, root, fs.FileInfoToDirEntry(info), fn)
+	}
+	if errors.Is(err, fs.SkipDir) || errors.Is(err, fs.SkipAll) {
+		return nil
+	}
+	return err
+}
+
+func walkDir(b Backend, name string, d fs.DirEntry, fn fs.WalkDirFunc) error {
+	if err := fn(name, d, nil); err != nil || !d.IsDir() {
+		if errors.Is(err, fs.SkipDir) && d.IsDir() {
+			// successfully skipped directory.
+			err = nil
+		}
+		return err
+	}
+
+	dirs, err := b.ReadDir(name)
+	if err != nil {
+		// second call to report the ReadDir error.
+		err = fn(name, d, err)
+		if err != nil {
+			if errors.Is(err, fs.SkipDir) && d.IsDir() {
+				err = nil
+			}
+			return err
+		}
+	}
+
+	for _, d1 := range dirs {
+		if err := walkDir(b, b.Join(name, d1.Name()), d1, fn); err != nil {
+			if errors.Is(err, fs.SkipDir) {
+				break
+			}
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package gorsn
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// cycleDirs and cycleFiles shape the tree scanned by `startCycles`.
+const cycleDirs, cycleFiles = 20, 100
+
+// startCycles starts a scan notifier over a tree of `cycleDirs` folders of
+// `cycleFiles` files which scans once per call of `cycle`. The changes are
+// drained and `stop` stops the scan notifier.
+func startCycles(tb testing.TB) (cycle, stop func()) {
+	root := tb.TempDir()
+	for i := 0; i < cycleDirs; i++ {
+		dir := filepath.Join(root, fmt.Sprintf("d%02d", i))
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			tb.Fatal(err)
+		}
+		for j := 0; j < cycleFiles; j++ {
+			if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("f%03d", j)), nil, 0o644); err != nil {
+				tb.Fatal(err)
+			}
+		}
+	}
+	next, done, quit := make(chan struct{}), make(chan struct{}), make(chan struct{})
+	opts := defaultOpts()
+	opts.Scan().SetInterval(0).
+		SetBeforeScan(func(int) {
+			select {
+			case <-next:
+			case <-quit:
+			}
+		}).
+		SetAfterScan(func(int, ScanSummary) {
+			select {
+			case done <- struct{}{}:
+			case <-quit:
+			}
+		})
+	sn, err := New(root, opts)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	drained := drain(sn)
+	if err := sn.StartAsync(context.Background()); err != nil {
+		tb.Fatal(err)
+	}
+	cycle = func() {
+		next <- struct{}{}
+		<-done
+	}
+	stop = func() {
+		close(quit)
+		sn.Stop()
+		<-drained
+	}
+	return cycle, stop
+}
+
+// BenchmarkScanCycle measures a scan cycle without changes over a tree of
+// 20 folders of 100 files. Each op is a whole cycle, including the walk and
+// the directory reads. Recycling the entries sent to the workers reduced it
+// from 8 to 7 allocations per visited path on linux/amd64:
+//
+//	before: 1105039 B/op  16422 allocs/op
+//	after:  1008298 B/op  14404 allocs/op
+func BenchmarkScanCycle(b *testing.B) {
+	cycle, stop := startCycles(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cycle()
+	}
+	b.StopTimer()
+	stop()
+}
+
+// TestScanCycleAllocs guards the allocations of a scan cycle without changes
+// measured by `BenchmarkScanCycle`, which are mostly the lstat calls and the
+// directory reads.
+func TestScanCycleAllocs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("scans 2000 files")
+	}
+	cycle, stop := startCycles(t)
+	defer stop()
+	cycle()
+	paths := float64(cycleDirs * (cycleFiles + 1))
+	if perPath := testing.AllocsPerRun(5, cycle) / paths; perPath > 8 {
+		t.Errorf("got %.2f allocations per path in a scan cycle, want at most 8", perPath)
+	}
+}
+
+// TestEmitAllocs guards the emission path against allocations. Events are
+// not pooled: they are values copied to the consumers, which keep them as
+// long as they want, and emitting one does not allocate by default, so a
+// pool would only add a release call to the API without saving anything.
+func TestEmitAllocs(t *testing.T) {
+	sn, err := newSnotifier(t.TempDir(), defaultOpts(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sn.running.Store(true)
+	ev := Event{Path: filepath.Join(sn.root, "a"), Type: FILE, Name: MODIFY}
+	allocs := testing.AllocsPerRun(100, func() {
+		sn.queueEvent(ev)
+		<-sn.queue
+	})
+	if allocs != 0 {
+		t.Errorf("got %.1f allocations per emitted event, want none", allocs)
+	}
+}
@@ -0,0 +1,174 @@
+// Package boltstore provides a gorsn.StateStore which keeps the states of the
+// items into a bbolt database, within a bucket per root keyed by their path.
+// Each save is a single transaction so the stored states are never partially
+// written. It implements gorsn.StateLookup so a scan notifier could only keep
+// a part of the items into memory and look up the others into the database:
+//
+//	store, err := boltstore.Open("states.db")
+//	if err != nil {
+//		return err
+//	}
+//	defer store.Close()
+//	opts.Persistence().SetStateStore(store).SetMaxCachedPaths(100000)
+//
+// The package lives into its own directory so the programs which do not
+// import it do not build bbolt.
+package boltstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jeamon/gorsn"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	rootsBucket = []byte("roots")
+	pathsBucket = []byte("paths")
+	schemaKey   = []byte("schema")
+)
+
+// Store is a gorsn.StateStore backed by a bbolt database. It is safe for
+// concurrent use and could hold the states of many roots.
+type Store struct {
+	db *bolt.DB
+}
+
+var _ gorsn.StateLookup = (*Store)(nil)
+
+// Open opens the database `name`, created if needed. It fails if the file is
+// locked by another process for more than a second.
+func Open(name string) (*Store, error) {
+	db, err := bolt.Open(name, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the database. The store must not be used afterwards.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Load returns all the stored states of the root, nil if there is none.
+func (s *Store) Load(root string) (*gorsn.State, error) {
+	var st *gorsn.State
+	err := s.db.View(func(tx *bolt.Tx) error {
+		rb := rootBucket(tx, root)
+		if rb == nil {
+			return nil
+		}
+		schema, err := strconv.Atoi(string(rb.Get(schemaKey)))
+		if err != nil {
+			return fmt.Errorf("schema of root %q: %v", root, err)
+		}
+		st = &gorsn.State{Schema: schema, Root: root, Paths: make(map[string]gorsn.PathState)}
+		return rb.Bucket(pathsBucket).ForEach(func(k, v []byte) error {
+			var ps gorsn.PathState
+			if err := json.Unmarshal(v, &ps); err != nil {
+				return fmt.Errorf("state of %q: %v", k, err)
+			}
+			st.Paths[string(k)] = ps
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// Save records the changed states and drops the removed ones within a single
+// transaction.
+func (s *Store) Save(root string, changed map[string]gorsn.PathState, removed []string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		roots, err := tx.CreateBucketIfNotExists(rootsBucket)
+		if err != nil {
+			return err
+		}
+		rb, err := roots.CreateBucketIfNotExists([]byte(root))
+		if err != nil {
+			return err
+		}
+		if rb.Get(schemaKey) == nil {
+			if err := rb.Put(schemaKey, []byte(strconv.Itoa(gorsn.SchemaVersion))); err != nil {
+				return err
+			}
+		}
+		pb, err := rb.CreateBucketIfNotExists(pathsBucket)
+		if err != nil {
+			return err
+		}
+		for path, ps := range changed {
+			v, err := json.Marshal(ps)
+			if err != nil {
+				return err
+			}
+			if err := pb.Put([]byte(path), v); err != nil {
+				return err
+			}
+		}
+		for _, path := range removed {
+			if err := pb.Delete([]byte(path)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Lookup returns the stored state of the item `path` of the root and whether
+// there is one.
+func (s *Store) Lookup(root, path string) (gorsn.PathState, bool, error) {
+	var ps gorsn.PathState
+	var found bool
+	err := s.db.View(func(tx *bolt.Tx) error {
+		rb := rootBucket(tx, root)
+		if rb == nil {
+			return nil
+		}
+		v := rb.Bucket(pathsBucket).Get([]byte(path))
+		if v == nil {
+			return nil
+		}
+		found = true
+		return json.Unmarshal(v, &ps)
+	})
+	return ps, found && err == nil, err
+}
+
+// Range calls `fn` for each stored item of the root, sorted by path, until it
+// returns false. It runs within a read transaction so the store must not be
+// saved into from `fn`.
+func (s *Store) Range(root string, fn func(path string, ps gorsn.PathState) bool) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		rb := rootBucket(tx, root)
+		if rb == nil {
+			return nil
+		}
+		c := rb.Bucket(pathsBucket).Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			var ps gorsn.PathState
+			if err := json.Unmarshal(v, &ps); err != nil {
+				return fmt.Errorf("state of %q: %v", k, err)
+			}
+			if !fn(string(k), ps) {
+				return nil
+			}
+		}
+		return nil
+	})
+}
+
+// rootBucket returns the bucket of the root, nil if nothing was stored yet.
+func rootBucket(tx *bolt.Tx, root string) *bolt.Bucket {
+	roots := tx.Bucket(rootsBucket)
+	if roots == nil {
+		return nil
+	}
+	return roots.Bucket([]byte(root))
+}
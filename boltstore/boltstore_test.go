@@ -0,0 +1,172 @@
+package boltstore
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jeamon/gorsn"
+)
+
+func openStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := Open(filepath.Join(t.TempDir(), "states.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func ps(size int64) gorsn.PathState {
+	return gorsn.PathState{ModTime: time.Unix(size, 0).UTC(), Mode: 0o644, Size: size, Checksum: "c"}
+}
+
+func TestStore(t *testing.T) {
+	s := openStore(t)
+	if st, err := s.Load("/root"); st != nil || err != nil {
+		t.Fatalf("got state %v and error %v when empty, want none", st, err)
+	}
+	if err := s.Save("/root", map[string]gorsn.PathState{"/root/a": ps(1), "/root/b": ps(2), "/root/c": ps(3)}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Save("/root", map[string]gorsn.PathState{"/root/a": ps(4)}, []string{"/root/b"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Save("/other", map[string]gorsn.PathState{"/other/x": ps(5)}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	want := &gorsn.State{Schema: gorsn.SchemaVersion, Root: "/root", Paths: map[string]gorsn.PathState{"/root/a": ps(4), "/root/c": ps(3)}}
+	if st, err := s.Load("/root"); err != nil || !reflect.DeepEqual(st, want) {
+		t.Errorf("got state %+v and error %v, want %+v", st, err, want)
+	}
+	if got, ok, err := s.Lookup("/root", "/root/a"); err != nil || !ok || !reflect.DeepEqual(got, ps(4)) {
+		t.Errorf("got state %+v, %t and error %v for a, want %+v", got, ok, err, ps(4))
+	}
+	for _, path := range []string{"/root/b", "/other/x"} {
+		if _, ok, err := s.Lookup("/root", path); ok || err != nil {
+			t.Errorf("got %s found with error %v, want it unknown", path, err)
+		}
+	}
+	if _, ok, err := s.Lookup("/none", "/none/a"); ok || err != nil {
+		t.Errorf("got an item of an unknown root with error %v, want none", err)
+	}
+
+	var paths []string
+	err := s.Range("/root", func(path string, _ gorsn.PathState) bool {
+		paths = append(paths, path)
+		return true
+	})
+	if err != nil || !reflect.DeepEqual(paths, []string{"/root/a", "/root/c"}) {
+		t.Errorf("got paths %q and error %v, want the sorted ones of the root", paths, err)
+	}
+	paths = nil
+	s.Range("/root", func(path string, _ gorsn.PathState) bool {
+		paths = append(paths, path)
+		return false
+	})
+	if len(paths) != 1 {
+		t.Errorf("got paths %q, want the range stopped after the first one", paths)
+	}
+}
+
+func TestStoreReopen(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "states.db")
+	s, err := Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Save("/root", map[string]gorsn.PathState{"/root/a": ps(1)}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(name); err == nil {
+		t.Error("got the database opened twice, want it locked")
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	s, err = Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if got, ok, err := s.Lookup("/root", "/root/a"); err != nil || !ok || !reflect.DeepEqual(got, ps(1)) {
+		t.Errorf("got state %+v, %t and error %v once reopened, want %+v", got, ok, err, ps(1))
+	}
+}
+
+// TestStoreNotifier runs scan notifiers which cache a part of the items only,
+// so the others are looked up into the store.
+func TestStoreNotifier(t *testing.T) {
+	dir := t.TempDir()
+	write := func(content string, names ...string) {
+		t.Helper()
+		for _, name := range names {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	write("1", "a", "b", "c", "d", "e", "f")
+	s := openStore(t)
+
+	// run scans the root once and returns the events keyed by base name.
+	run := func() map[string][]gorsn.EventName {
+		t.Helper()
+		scanned := make(chan struct{})
+		var once sync.Once
+		opts := &gorsn.Options{}
+		opts.Events().SetIgnoreNoChange(true)
+		opts.Scan().SetInterval(time.Hour).SetAfterScan(func(int, gorsn.ScanSummary) { once.Do(func() { close(scanned) }) })
+		opts.Delivery().SetQueueSize(16)
+		opts.Persistence().SetStateStore(s).SetMaxCachedPaths(2)
+		sn, err := gorsn.New(dir, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := sn.StartAsync(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case <-scanned:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for the first cycle")
+		}
+		sn.Stop()
+		evs := make(map[string][]gorsn.EventName)
+		for ev := range sn.Queue() {
+			evs[filepath.Base(ev.Path)] = append(evs[filepath.Base(ev.Path)], ev.Name)
+		}
+		if err := sn.Wait(); err != nil {
+			t.Fatal(err)
+		}
+		return evs
+	}
+	if evs := run(); len(evs) != 0 {
+		t.Errorf("got events %v on the first run, want none", evs)
+	}
+
+	write("22", "a", "g")
+	if err := os.Remove(filepath.Join(dir, "b")); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]gorsn.EventName{"a": {gorsn.MODIFY}, "b": {gorsn.DELETE}, "g": {gorsn.CREATE}}
+	if evs := run(); !reflect.DeepEqual(evs, want) {
+		t.Errorf("got events %v after a restart, want %v", evs, want)
+	}
+	if evs := run(); len(evs) != 0 {
+		t.Errorf("got events %v after a restart without changes, want none", evs)
+	}
+	st, err := s.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(st.Paths) != 6 || st.Paths[filepath.Join(dir, "a")].Size != 2 {
+		t.Errorf("got stored states %+v, want the 6 files with a updated", st.Paths)
+	}
+}
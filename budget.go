@@ -0,0 +1,98 @@
+package gorsn
+
+import (
+	"io/fs"
+	"strings"
+	"unsafe"
+)
+
+// pathCost is the estimated memory used by a tracked path apart from its
+// name: the cached infos, a content digest and the cache entry itself.
+const pathCost = int64(unsafe.Sizeof(pathInfos{})) + 64 + 64
+
+// overBudget reports whether the estimated footprint of the paths
+// cache exceeds the memory budget.
+func (sn *snotifier) overBudget() bool {
+	max := sn.opts.scan.memBudget.Load()
+	return max > 0 && sn.footprint.Load() > max
+}
+
+// budgetExceeded emits a `BUDGET_EXCEEDED` event once until the
+// footprint falls back under the budget.
+func (sn *snotifier) budgetExceeded(path string, pt PathType) {
+	if sn.running.Load() && !sn.exceeded.Swap(true) {
+		sn.queueEvent(Event{Path: path, Type: pt, Name: BUDGET_EXCEEDED, Error: ErrMemoryBudget})
+	}
+}
+
+// checkBudget degrades the monitoring at the end of a scan cycle while
+// the footprint exceeds the memory budget: the content of the deepest
+// directories is forgotten and no longer scanned, one level per cycle.
+// The event is re-armed once the footprint is back under 90% of the
+// budget. Collapsed subtrees stay so until the notifier is restarted.
+func (sn *snotifier) checkBudget() {
+	max := sn.opts.scan.memBudget.Load()
+	if max <= 0 {
+		return
+	}
+	if sn.footprint.Load() <= max*9/10 {
+		sn.exceeded.Store(false)
+	}
+	if !sn.overBudget() {
+		return
+	}
+	sn.budgetExceeded(sn.root, sn.rootType())
+	depth := sn.maxDepth.Load()
+	if depth == 0 {
+		sn.paths.Range(func(key, _ any) bool {
+			if d := sn.depth(key.(string)); d > depth {
+				depth = d
+			}
+			return true
+		})
+	}
+	if depth <= 1 {
+		// only the top level items are left.
+		return
+	}
+	depth--
+	sn.maxDepth.Store(depth)
+	sn.paths.Range(func(key, value any) bool {
+		path := key.(string)
+		if sn.depth(path) > depth {
+			sn.linkRemove(path, value.(*pathInfos).sys)
+			sn.untrack(path)
+		}
+		return true
+	})
+}
+
+// depth returns the number of elements of the path relative to the root.
+func (sn *snotifier) depth(path string) int32 {
+	rel := sn.rel(path)
+	if rel == "" || rel == "." {
+		return 0
+	}
+	return int32(strings.Count(rel, "/")) + 1
+}
+
+// collapsed reports whether the path is located into a subtree collapsed
+// to fit the memory budget. For a directory at the depth limit, it returns
+// `fs.SkipDir` so its content is not scanned.
+func (sn *snotifier) collapsed(s string, d fs.DirEntry) (bool, error) {
+	max := sn.maxDepth.Load()
+	if max <= 0 {
+		return false, nil
+	}
+	depth := sn.depth(s)
+	if depth > max {
+		if d.IsDir() {
+			return true, fs.SkipDir
+		}
+		return true, nil
+	}
+	if depth == max && d.IsDir() {
+		return false, fs.SkipDir
+	}
+	return false, nil
+}
@@ -0,0 +1,52 @@
+package gorsn
+
+// Capabilities describes the changes detection features which are
+// guaranteed by a platform and backend combination. Portable consumers
+// could rely on it to adapt their expectations about the emitted events.
+type Capabilities struct {
+	// Ownership reports whether owner user and group changes are detected.
+	Ownership bool
+	// InodeIdentity reports whether files identity is known, which is
+	// needed to track renames and hard links.
+	InodeIdentity bool
+	// SubSecondMtime reports whether modification times have a precision
+	// finer than the second, so quick successive writes are detected.
+	SubSecondMtime bool
+	// AttributeEvents reports whether windows file attributes are monitored.
+	AttributeEvents bool
+	// ExtendedAttrs reports whether extended attributes changes are detected.
+	ExtendedAttrs bool
+	// Checksum reports whether files content could be read to be hashed.
+	Checksum bool
+}
+
+// CapabilitiesReporter is optionally implemented by backends
+// in order to describe the features they support.
+type CapabilitiesReporter interface {
+	Capabilities() Capabilities
+}
+
+// PlatformCapabilities returns the capabilities of the local file system
+// on the current platform, which is the default backend.
+func PlatformCapabilities() Capabilities {
+	return platformCapabilities
+}
+
+func (osBackend) Capabilities() Capabilities {
+	return platformCapabilities
+}
+
+// Capabilities of an `fs.FS` backend are limited to the content reading
+// since the infos exposed by the underlying file system are unknown.
+func (b *fsBackend) Capabilities() Capabilities {
+	return Capabilities{Checksum: true}
+}
+
+// Capabilities returns the capabilities of the scan notifier backend.
+// Backends which do not implement `CapabilitiesReporter` report none.
+func (sn *snotifier) Capabilities() Capabilities {
+	if cr, ok := sn.opts.scan.backend.(CapabilitiesReporter); ok {
+		return cr.Capabilities()
+	}
+	return Capabilities{}
+}
@@ -0,0 +1,173 @@
+package gorsn
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// ChatFormat defines the payload expected by an incoming webhook.
+type ChatFormat uint32
+
+const (
+	// CHAT_SLACK posts the message as the `text` of a Slack payload.
+	CHAT_SLACK ChatFormat = iota
+	// CHAT_DISCORD posts the message as the `content` of a Discord payload.
+	CHAT_DISCORD
+)
+
+const (
+	// DEFAULT_CHAT_TEMPLATE is the message posted for an event by the chat sink.
+	DEFAULT_CHAT_TEMPLATE = "{{.Name}} {{.Type}} `{{.Path}}`{{if .Error}}: {{.Error}}{{end}}"
+
+	// DEFAULT_CHAT_BACKLOG is the number of messages waiting to be posted
+	// beyond which the chat sink drops the new ones.
+	DEFAULT_CHAT_BACKLOG = 100
+
+	// DEFAULT_CHAT_TIMEOUT is the timeout of a webhook request.
+	DEFAULT_CHAT_TIMEOUT = 10 * time.Second
+)
+
+// ChatRoute defines a channel of a chat service and the events it receives.
+type ChatRoute struct {
+	URL    string // incoming webhook of the channel.
+	Format ChatFormat
+	// Filter selects the events posted to the channel, such as `FilterNames`
+	// or `FilterPaths`. A nil filter selects all of them.
+	Filter Filter
+	// Template builds the message from the event. A nil template falls back
+	// to `DEFAULT_CHAT_TEMPLATE`.
+	Template *template.Template
+}
+
+// ChatSink is a Sink which posts the events to chat channels webhooks.
+type ChatSink struct {
+	routes []ChatRoute
+	client *http.Client
+	posts  chan chatPost
+	done   chan struct{}
+	once   sync.Once
+
+	mu  sync.Mutex
+	err error // failure of the latest post, reported by the next write.
+}
+
+type chatPost struct {
+	url     string
+	payload []byte
+}
+
+var defaultChatTemplate = template.Must(template.New("chat").Parse(DEFAULT_CHAT_TEMPLATE))
+
+// NewChatSink returns a Sink which posts a message for each event to the
+// webhook of every route whose filter selects it, such as a Slack or Discord
+// channel. Messages are posted in the background, up to `DEFAULT_CHAT_BACKLOG`
+// are kept while waiting. The failures are returned by the next write, so they
+// are reported by an `ERROR` event. Call `Close` once the scan notifier
+// stopped to post the pending messages.
+func NewChatSink(routes ...ChatRoute) *ChatSink {
+	s := &ChatSink{
+		routes: routes,
+		client: &http.Client{Timeout: DEFAULT_CHAT_TIMEOUT},
+		posts:  make(chan chatPost, DEFAULT_CHAT_BACKLOG),
+		done:   make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+func (s *ChatSink) Write(ev Event) error {
+	s.mu.Lock()
+	err := s.err
+	s.err = nil
+	s.mu.Unlock()
+	for _, r := range s.routes {
+		if r.Filter != nil && !r.Filter(ev) {
+			continue
+		}
+		payload, perr := r.payload(ev)
+		if perr != nil {
+			err = errors.Join(err, perr)
+			continue
+		}
+		select {
+		case s.posts <- chatPost{url: r.URL, payload: payload}:
+		default:
+			err = errors.Join(err, fmt.Errorf("chat backlog full, message to %s dropped", redact(r.URL)))
+		}
+	}
+	return err
+}
+
+// Close posts the pending messages then stops the sink. Writes after Close
+// must not happen.
+func (s *ChatSink) Close() error {
+	s.once.Do(func() { close(s.posts) })
+	<-s.done
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// run posts the messages until the sink is closed.
+func (s *ChatSink) run() {
+	defer close(s.done)
+	for p := range s.posts {
+		if err := s.post(p); err != nil {
+			s.mu.Lock()
+			s.err = err
+			s.mu.Unlock()
+		}
+	}
+}
+
+func (s *ChatSink) post(p chatPost) error {
+	resp, err := s.client.Post(p.url, "application/json", bytes.NewReader(p.payload))
+	if err != nil {
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			// drop the URL which holds the secret token.
+			err = uerr.Err
+		}
+		return fmt.Errorf("chat webhook %s: %w", redact(p.url), err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("chat webhook %s: %s", redact(p.url), resp.Status)
+	}
+	return nil
+}
+
+// payload builds the JSON body of the message of the event.
+func (r ChatRoute) payload(ev Event) ([]byte, error) {
+	tmpl := r.Template
+	if tmpl == nil {
+		tmpl = defaultChatTemplate
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, ev); err != nil {
+		return nil, err
+	}
+	key := "text"
+	if r.Format == CHAT_DISCORD {
+		key = "content"
+	}
+	return json.Marshal(map[string]string{key: b.String()})
+}
+
+// redact strips the path of a webhook URL since it holds its secret token.
+func redact(u string) string {
+	if i := strings.Index(u, "://"); i >= 0 {
+		if j := strings.IndexByte(u[i+3:], '/'); j >= 0 {
+			return u[:i+3+j] + "/..."
+		}
+	}
+	return u
+}
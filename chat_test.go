@@ -0,0 +1,110 @@
+package gorsn
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"text/template"
+)
+
+// webhook is a fake chat service which records the posted bodies by path.
+type webhook struct {
+	mu     sync.Mutex
+	bodies map[string][]string
+	status int
+}
+
+func newWebhook(t *testing.T, status int) (*webhook, *httptest.Server) {
+	w := &webhook{bodies: map[string][]string{}, status: status}
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("got %s request with content type %q, want a JSON post", r.Method, r.Header.Get("Content-Type"))
+		}
+		w.mu.Lock()
+		w.bodies[r.URL.Path] = append(w.bodies[r.URL.Path], string(body))
+		w.mu.Unlock()
+		rw.WriteHeader(w.status)
+	}))
+	t.Cleanup(srv.Close)
+	return w, srv
+}
+
+func TestChatSinkPayload(t *testing.T) {
+	w, srv := newWebhook(t, http.StatusNoContent)
+	sink := NewChatSink(
+		ChatRoute{URL: srv.URL + "/slack/T0KEN", Format: CHAT_SLACK},
+		ChatRoute{
+			URL:      srv.URL + "/discord/T0KEN",
+			Format:   CHAT_DISCORD,
+			Filter:   FilterNames(DELETE),
+			Template: template.Must(template.New("t").Parse("**{{.Name}}** {{.Path}}")),
+		},
+	)
+	events := []Event{
+		{Path: "in/a.csv", Type: FILE, Name: CREATE},
+		{Path: "in/b.csv", Type: FILE, Name: DELETE},
+		{Path: "in", Type: DIR, Name: ERROR, Error: errors.New("permission denied")},
+	}
+	for _, ev := range events {
+		if err := sink.Write(ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]string{
+		"/slack/T0KEN": {
+			`{"text":"CREATE FILE ` + "`in/a.csv`" + `"}`,
+			`{"text":"DELETE FILE ` + "`in/b.csv`" + `"}`,
+			`{"text":"ERROR DIRECTORY ` + "`in`" + `: permission denied"}`,
+		},
+		"/discord/T0KEN": {`{"content":"**DELETE** in/b.csv"}`},
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !reflect.DeepEqual(w.bodies, want) {
+		t.Errorf("got bodies %q, want %q", w.bodies, want)
+	}
+}
+
+func TestChatSinkFailure(t *testing.T) {
+	w, srv := newWebhook(t, http.StatusForbidden)
+	sink := NewChatSink(ChatRoute{URL: srv.URL + "/hooks/S3CRET"})
+	if err := sink.Write(Event{Path: "a", Type: FILE, Name: CREATE}); err != nil {
+		t.Fatal(err)
+	}
+	err := sink.Close()
+	if err == nil || !strings.Contains(err.Error(), "403 Forbidden") {
+		t.Fatalf("got error %v, want the status of the webhook", err)
+	}
+	if strings.Contains(err.Error(), "S3CRET") {
+		t.Errorf("error %q leaks the token of the webhook", err)
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.bodies["/hooks/S3CRET"]) != 1 {
+		t.Errorf("got %d posts, want 1", len(w.bodies["/hooks/S3CRET"]))
+	}
+}
+
+func TestChatSinkUnreachable(t *testing.T) {
+	_, srv := newWebhook(t, http.StatusOK)
+	url := srv.URL + "/hooks/S3CRET"
+	srv.Close()
+	sink := NewChatSink(ChatRoute{URL: url})
+	if err := sink.Write(Event{Path: "a", Type: FILE, Name: CREATE}); err != nil {
+		t.Fatal(err)
+	}
+	err := sink.Close()
+	if err == nil || strings.Contains(err.Error(), "S3CRET") {
+		t.Errorf("got error %v, want a failure without the token of the webhook", err)
+	}
+}
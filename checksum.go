@@ -0,0 +1,125 @@
+package gorsn
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+)
+
+// opener is implemented by backends which could read files content.
+type opener interface {
+	Open(name string) (io.ReadCloser, error)
+}
+
+// checksum returns the hex-encoded digest of the named file content and the
+// digests of its blocks if the changed regions are enabled.
+func (sn *snotifier) checksum(name string) (string, *blockSums, error) {
+	op, ok := sn.opts.scan.backend.(opener)
+	if !ok {
+		return "", nil, ErrChecksumNotSupported
+	}
+	f, err := op.Open(name)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %w", ErrChecksumFailure, err)
+	}
+	defer f.Close()
+	h := sn.hasher()
+	var blocks *blockSums
+	if size := sn.opts.events.blockSize.Load(); size > 0 {
+		if blocks, err = sn.hashBlocks(f, h, size); err != nil {
+			return "", nil, err
+		}
+	} else if _, err := io.Copy(h, f); err != nil {
+		return "", nil, fmt.Errorf("%w: %w", ErrChecksumFailure, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), blocks, nil
+}
+
+// hasher returns a new instance of the configured hash function.
+func (sn *snotifier) hasher() hash.Hash {
+	if fn, ok := sn.opts.scan.hasher.Load().(func() hash.Hash); ok && fn != nil {
+		return fn()
+	}
+	return sha256.New()
+}
+
+// hashName returns the name of the configured hash function.
+func (sn *snotifier) hashName() string {
+	if name, _ := sn.opts.scan.hashName.Load().(string); name != "" {
+		return name
+	}
+	if fn, ok := sn.opts.scan.hasher.Load().(func() hash.Hash); ok && fn != nil {
+		return ""
+	}
+	return "sha256"
+}
+
+// withChecksum sets the digest of the event if enabled.
+func (sn *snotifier) withChecksum(ev *Event, sum string) {
+	if sum != "" && sn.opts.events.includeChecksum.Load() {
+		ev.Checksum, ev.ChecksumAlgorithm = sum, sn.hashName()
+	}
+}
+
+// hash computes the digest of a regular file into the hashing pool. If
+// `compare` is true and the content changed, it emits a `MODIFY` event.
+// If `touched` is true and the content did not change, it emits a `TOUCH`
+// event. The `held` event if any is emitted along with the digest once
+// known. It blocks while all hashing workers are busy.
+func (sn *snotifier) hash(pt PathType, fse *fsEntry, fi fs.FileInfo, pi *pathInfos, compare, touched bool, held *Event) {
+	sn.hsem <- struct{}{}
+	sn.hwg.Add(1)
+	go func(sys sysInfo, fse fsEntry) {
+		defer func() {
+			<-sn.hsem
+			sn.hwg.Done()
+		}()
+		sum, blocks, err := sn.digest(fse.path, fi, sys)
+		if err != nil {
+			if held != nil {
+				sn.queueEvent(*held)
+			}
+			if sn.lockedFailure(fse.path, pt, err) {
+				return
+			}
+			if !sn.opts.events.ignoreErrors.Load() {
+				sn.queueEvent(Event{Path: fse.path, Type: pt, Name: ERROR, Error: err})
+			}
+			return
+		}
+		sn.unlocked(fse.path)
+		changed := compare && pi.sum != "" && sum != pi.sum
+		if sum != pi.sum {
+			sn.changed(fse.path)
+		}
+		pi.mu.Lock()
+		pi.sum = sum
+		prev := pi.blocks
+		pi.blocks = blocks
+		pi.mu.Unlock()
+		if held != nil {
+			sn.withChecksum(held, sum)
+			withRegions(held, prev, blocks)
+			sn.queueEvent(*held)
+		}
+		if !changed {
+			if touched {
+				ev := Event{Path: fse.path, Type: pt, Name: TOUCH, Error: fse.err}
+				sn.withChecksum(&ev, sum)
+				sn.queueEvent(ev)
+			}
+			return
+		}
+		sn.markChanging(pi, pt)
+		if !sn.opts.events.ignoreModify.Load() {
+			ev := Event{Path: fse.path, Type: pt, Name: MODIFY, Error: fse.err}
+			sn.withSizes(&ev, fi.Size(), fi.Size())
+			sn.withChecksum(&ev, sum)
+			withRegions(&ev, prev, blocks)
+			sn.queueEvent(ev)
+		}
+	}(pi.sys, *fse)
+}
@@ -0,0 +1,26 @@
+package gorsn
+
+import "time"
+
+// Clock is the source of time of a scan notifier.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// Sleep pauses the calling goroutine for at least the duration `d`.
+	Sleep(d time.Duration)
+	// After waits for the duration `d` to elapse and then sends
+	// the current time on the returned channel.
+	After(d time.Duration) <-chan time.Time
+}
+
+// realClock is the Clock based on the system time.
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) Sleep(d time.Duration)                  { time.Sleep(d) }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// now returns the current time of the scan notifier clock.
+func (sn *snotifier) now() time.Time {
+	return sn.opts.scan.clock.Now()
+}
@@ -0,0 +1,68 @@
+package gorsn
+
+import (
+	"sync"
+	"time"
+)
+
+// fakeClock is a Clock whose time only moves with advance.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeTimer
+}
+
+type fakeTimer struct {
+	at time.Time
+	c  chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.waiters = append(c.waiters, fakeTimer{at: c.now.Add(d), c: ch})
+	return ch
+}
+
+func (c *fakeClock) Sleep(d time.Duration) {
+	<-c.After(d)
+}
+
+// advance moves the time forward and fires the timers which expired.
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	n := 0
+	for _, w := range c.waiters {
+		if w.at.After(c.now) {
+			c.waiters[n] = w
+			n++
+			continue
+		}
+		w.c <- c.now
+	}
+	c.waiters = c.waiters[:n]
+}
+
+// pending returns the number of timers not yet fired.
+func (c *fakeClock) pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
@@ -0,0 +1,122 @@
+package gorsn
+
+import (
+	"regexp"
+	"sync/atomic"
+)
+
+// Clone returns an independent copy of the options, so a template of settings
+// could be reused by many scan notifiers without the runtime changes of one
+// affecting the others. The values provided by the caller such as the backend,
+// the clock, the callbacks, the sinks, the observers, the trace writer and the
+// initial state and store are shared, not copied. The compiled patterns are
+// shared as well since they are safe for concurrent use.
+func (o *Options) Clone() *Options {
+	if o == nil {
+		return nil
+	}
+	c := &Options{}
+
+	so, cso := &o.scan, &c.scan
+	copyValue(&cso.interval, &so.interval)
+	cso.maxworkers.Store(so.maxworkers.Load())
+	cso.backend = so.backend
+	cso.clock = so.clock
+	cso.checksum.Store(so.checksum.Load())
+	copyValue(&cso.hasher, &so.hasher)
+	copyValue(&cso.hashName, &so.hashName)
+	cso.hashWorkers.Store(so.hashWorkers.Load())
+	copyValue(&cso.beforeScan, &so.beforeScan)
+	copyValue(&cso.afterScan, &so.afterScan)
+	copyValue(&cso.trace, &so.trace)
+	if hp, _ := so.hot.Load().(*hotPaths); hp != nil {
+		cso.hot.Store(&hotPaths{append([]*regexp.Regexp(nil), hp.patterns...), hp.interval})
+	}
+	copyValue(&cso.scale, &so.scale)
+	copyValue(&cso.progress, &so.progress)
+	cso.maxTracked.Store(so.maxTracked.Load())
+	cso.limitPolicy.Store(so.limitPolicy.Load())
+	copyValue(&cso.compare, &so.compare)
+	cso.throttle.Store(so.throttle.Load())
+	cso.maxLoad.Store(so.maxLoad.Load())
+	cso.memBudget.Store(so.memBudget.Load())
+	cso.configFile = so.configFile
+
+	fo, cfo := &o.filters, &c.filters
+	copyValue(&cfo.excludePaths, &fo.excludePaths)
+	copyValue(&cfo.includePaths, &fo.includePaths)
+	cfo.ignoreFile.Store(fo.ignoreFile.Load())
+	cfo.ignoreFolder.Store(fo.ignoreFolder.Load())
+	cfo.ignoreSymlink.Store(fo.ignoreSymlink.Load())
+	cfo.ignoreFifo.Store(fo.ignoreFifo.Load())
+	cfo.ignoreSocket.Store(fo.ignoreSocket.Load())
+	cfo.ignoreDevice.Store(fo.ignoreDevice.Load())
+	cfo.ignoreFolderContent.Store(fo.ignoreFolderContent.Load())
+	cfo.gitignore.Store(fo.gitignore.Load())
+	cfo.topLevelOnly.Store(fo.topLevelOnly.Load())
+	cfo.ignoreEditorTemp.Store(fo.ignoreEditorTemp.Load())
+	cfo.ignoreArtifacts.Store(fo.ignoreArtifacts.Load())
+	copyValue(&cfo.ctypes, &fo.ctypes)
+	copyValue(&cfo.uids, &fo.uids)
+	copyValue(&cfo.gids, &fo.gids)
+
+	eo, ceo := &o.events, &c.events
+	ceo.ignoreErrors.Store(eo.ignoreErrors.Load())
+	ceo.ignoreNoChange.Store(eo.ignoreNoChange.Load())
+	ceo.ignoreDelete.Store(eo.ignoreDelete.Load())
+	ceo.ignoreCreate.Store(eo.ignoreCreate.Load())
+	ceo.ignoreModify.Store(eo.ignoreModify.Load())
+	ceo.ignorePerm.Store(eo.ignorePerm.Load())
+	ceo.ignoreAttrib.Store(eo.ignoreAttrib.Load())
+	ceo.trackOwner.Store(eo.trackOwner.Load())
+	ceo.trackRenames.Store(eo.trackRenames.Load())
+	ceo.trackLinks.Store(eo.trackLinks.Load())
+	ceo.settleCycles.Store(eo.settleCycles.Load())
+	ceo.deleteGrace.Store(eo.deleteGrace.Load())
+	ceo.vanished.Store(eo.vanished.Load())
+	ceo.anomalyWindow.Store(eo.anomalyWindow.Load())
+	copyValue(&ceo.anomalySensitivity, &eo.anomalySensitivity)
+	copyValue(&ceo.sizeLimits, &eo.sizeLimits)
+	ceo.stopOnRootLost.Store(eo.stopOnRootLost.Load())
+	ceo.collapseDeletes.Store(eo.collapseDeletes.Load())
+	ceo.detectTouch.Store(eo.detectTouch.Load())
+	ceo.atomicSaves.Store(eo.atomicSaves.Load())
+	copyValue(&ceo.classify, &eo.classify)
+	copyValue(&ceo.tags, &eo.tags)
+	ceo.scanSummary.Store(eo.scanSummary.Load())
+	copyValue(&ceo.filter, &eo.filter)
+	ceo.detectLocked.Store(eo.detectLocked.Load())
+	ceo.pathForm.Store(eo.pathForm.Load())
+	copyValue(&ceo.normalizer, &eo.normalizer)
+	ceo.relativePaths.Store(eo.relativePaths.Load())
+	copyValue(&ceo.label, &eo.label)
+	ceo.includeChecksum.Store(eo.includeChecksum.Load())
+	ceo.detectContentType.Store(eo.detectContentType.Load())
+	copyValue(&ceo.dedupWindow, &eo.dedupWindow)
+	ceo.detectRotation.Store(eo.detectRotation.Load())
+	copyValue(&ceo.appends, &eo.appends)
+	ceo.blockSize.Store(eo.blockSize.Load())
+
+	do, cdo := &o.delivery, &c.delivery
+	cdo.queueSize.Store(do.queueSize.Load())
+	copyValue(&cdo.debounce, &do.debounce)
+	cdo.history.Store(do.history.Load())
+	copyValue(&cdo.ackTimeout, &do.ackTimeout)
+	cdo.ordered.Store(do.ordered.Load())
+	cdo.maxRate.Store(do.maxRate.Load())
+	cdo.overflow.Store(do.overflow.Load())
+	cdo.subsOnly.Store(do.subsOnly.Load())
+	copyValue(&cdo.lobservers, &do.lobservers)
+	copyValue(&cdo.esinks, &do.esinks)
+
+	c.persist = o.persist
+	return c
+}
+
+// copyValue stores the value of `src` into `dst` if any. The values held by
+// the options are replaced as a whole on each change so they could be shared.
+func copyValue(dst, src *atomic.Value) {
+	if v := src.Load(); v != nil {
+		dst.Store(v)
+	}
+}
@@ -0,0 +1,75 @@
+package gorsn
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestClone(t *testing.T) {
+	opts := defaultOpts()
+	opts.Scan().SetInterval(time.Minute).SetMaxWorkers(2)
+	if err := opts.Scan().SetHotPaths([]string{"logs"}, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	opts.Filters().SetExcludeRegex(regexp.MustCompile(`\.tmp$`)).SetContentTypes("text/plain")
+	opts.Events().SetIgnoreCreate(true).SetSizeThreshold("big", 1<<20)
+	opts.Delivery().SetQueueSize(8).SetDebounce(time.Second)
+
+	c := opts.Clone()
+	if !reflect.DeepEqual(c.Events().GetSizeThresholds(), opts.Events().GetSizeThresholds()) ||
+		c.Scan().GetInterval() != time.Minute || c.Delivery().GetQueueSize() != 8 {
+		t.Fatal("got a clone different from the original")
+	}
+	if c.scan.hot.Load() == opts.scan.hot.Load() {
+		t.Error("got hot paths shared by the clone")
+	}
+
+	c.Scan().SetInterval(time.Hour).SetMaxWorkers(4)
+	if err := c.Scan().SetHotPaths([]string{"tmp", "cache"}, 2*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	c.Filters().SetExcludeRegex(nil).SetContentTypes("image/png")
+	c.Events().SetIgnoreCreate(false).SetSizeThreshold("big", 0).SetSizeThreshold("huge", 1<<30)
+	c.Delivery().SetQueueSize(16).SetDebounce(0)
+
+	so := &opts.scan
+	if v := so.GetInterval(); v != time.Minute {
+		t.Errorf("got interval %v, want %v", v, time.Minute)
+	}
+	if v := so.GetMaxWorkers(); v != 2 {
+		t.Errorf("got max workers %d, want 2", v)
+	}
+	if hp := so.hotPaths(time.Minute); hp == nil || len(hp.patterns) != 1 || hp.interval != time.Second {
+		t.Errorf("got hot paths %+v, want logs every second", hp)
+	}
+	fo := &opts.filters
+	if re := fo.GetExcludeRegex(); re == nil || re.String() != `\.tmp$` {
+		t.Errorf("got exclude pattern %v, want %q", re, `\.tmp$`)
+	}
+	if v := fo.GetContentTypes(); !reflect.DeepEqual(v, []string{"text/plain"}) {
+		t.Errorf("got content types %q, want text/plain", v)
+	}
+	eo := &opts.events
+	if !eo.GetIgnoreCreate() {
+		t.Error("got create events enabled, want them ignored")
+	}
+	if v := eo.GetSizeThresholds(); !reflect.DeepEqual(v, map[string]int64{"big": 1 << 20}) {
+		t.Errorf("got size thresholds %v, want only big", v)
+	}
+	do := &opts.delivery
+	if v := do.GetQueueSize(); v != 8 {
+		t.Errorf("got queue size %d, want 8", v)
+	}
+	if v := do.GetDebounce(); v != time.Second {
+		t.Errorf("got debounce %v, want %v", v, time.Second)
+	}
+}
+
+func TestCloneNil(t *testing.T) {
+	var o *Options
+	if c := o.Clone(); c != nil {
+		t.Errorf("got clone %+v of nil options", c)
+	}
+}
@@ -0,0 +1,465 @@
+package gorsn
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"time"
+)
+
+// maxProtoEventSize is the size in bytes of the largest `Event` message read
+// by the EventDecoder of `NewProtoDecoder`, so a corrupted size prefix does
+// not trigger a huge allocation.
+const maxProtoEventSize = 16 << 20
+
+// EventEncoder writes events to a stream.
+type EventEncoder interface {
+	Encode(Event) error
+}
+
+// EventDecoder reads the events written by the matching EventEncoder. It
+// returns `io.EOF` at the end of the stream.
+type EventDecoder interface {
+	Decode(*Event) error
+}
+
+// eventRecord is the serializable form of an event.
+type eventRecord struct {
+	Schema        int
+	Path          string
+	OldPath       string
+	Type          PathType
+	Name          EventName
+	Error         string
+	Mode          fs.FileMode
+	OldMode       fs.FileMode
+	ChildrenCount int
+	Dropped       int
+	Prefixes      []string
+	Time          time.Time
+	ModTime       time.Time
+	Severity      Severity
+	Tags          []string
+	Cycle         int
+	Summary       *ScanSummary
+	Root          string
+	RootLabel     string
+	Checksum      string
+	ChecksumAlgo  string
+	ContentType   string
+	LinkTarget    string
+	RealPath      string
+	Suppressed    int
+	OldSize       int64
+	NewSize       int64
+	Regions       []Region
+	RegionsKnown  bool // distinguishes the unchanged content from unknown regions.
+}
+
+func newEventRecord(ev Event) eventRecord {
+	rec := eventRecord{
+		Schema: SchemaVersion, Path: ev.Path, OldPath: ev.OldPath, Type: ev.Type, Name: ev.Name,
+		Mode: ev.Mode, OldMode: ev.OldMode, ChildrenCount: ev.ChildrenCount, Dropped: ev.Dropped,
+		Prefixes: ev.Prefixes, Time: ev.Time, ModTime: ev.ModTime, Severity: ev.Severity,
+		Tags: ev.Tags, Cycle: ev.Cycle, Summary: ev.Summary, Root: ev.Root,
+		RootLabel: ev.RootLabel, Checksum: ev.Checksum, ChecksumAlgo: ev.ChecksumAlgorithm,
+		ContentType: ev.ContentType, LinkTarget: ev.LinkTarget, RealPath: ev.RealPath,
+		Suppressed: ev.Suppressed, OldSize: ev.OldSize, NewSize: ev.NewSize, Regions: ev.Regions,
+		RegionsKnown: ev.Regions != nil,
+	}
+	if ev.Error != nil {
+		rec.Error = ev.Error.Error()
+	}
+	return rec
+}
+
+// event rebuilds the event described by the record.
+func (rec eventRecord) event() Event {
+	ev := Event{
+		Path: rec.Path, OldPath: rec.OldPath, Type: rec.Type, Name: rec.Name,
+		Mode: rec.Mode, OldMode: rec.OldMode, ChildrenCount: rec.ChildrenCount, Dropped: rec.Dropped,
+		Prefixes: rec.Prefixes, Time: rec.Time, ModTime: rec.ModTime, Severity: rec.Severity,
+		Tags: rec.Tags, Cycle: rec.Cycle, Summary: rec.Summary, Root: rec.Root,
+		RootLabel: rec.RootLabel, Checksum: rec.Checksum, ChecksumAlgorithm: rec.ChecksumAlgo,
+		ContentType: rec.ContentType, LinkTarget: rec.LinkTarget, RealPath: rec.RealPath,
+		Suppressed: rec.Suppressed, OldSize: rec.OldSize, NewSize: rec.NewSize, Regions: rec.Regions,
+	}
+	if rec.Error != "" {
+		ev.Error = errors.New(rec.Error)
+	}
+	if rec.RegionsKnown && ev.Regions == nil {
+		ev.Regions = []Region{}
+	}
+	return ev
+}
+
+type gobEncoder struct{ enc *gob.Encoder }
+
+type gobDecoder struct{ dec *gob.Decoder }
+
+// NewGobEncoder returns an EventEncoder which writes the events with
+// `encoding/gob`. Errors are written as their message.
+func NewGobEncoder(w io.Writer) EventEncoder {
+	return gobEncoder{enc: gob.NewEncoder(w)}
+}
+
+// NewGobDecoder returns an EventDecoder of the events written by the
+// EventEncoder of `NewGobEncoder`.
+func NewGobDecoder(r io.Reader) EventDecoder {
+	return gobDecoder{dec: gob.NewDecoder(r)}
+}
+
+func (e gobEncoder) Encode(ev Event) error {
+	return e.enc.Encode(newEventRecord(ev))
+}
+
+func (d gobDecoder) Decode(ev *Event) error {
+	var rec eventRecord
+	if err := d.dec.Decode(&rec); err != nil {
+		return err
+	}
+	*ev = rec.event()
+	return nil
+}
+
+type protoEncoder struct {
+	w   io.Writer
+	buf []byte
+}
+
+type protoDecoder struct {
+	r   *bufio.Reader
+	buf []byte
+}
+
+// NewProtoEncoder returns an EventEncoder which writes the events as the
+// `Event` messages of the `gorsn.proto` schema, each prefixed by its size as
+// a varint, so they could be read from any language.
+func NewProtoEncoder(w io.Writer) EventEncoder {
+	return &protoEncoder{w: w}
+}
+
+// NewProtoDecoder returns an EventDecoder of the size-prefixed `Event`
+// messages of the `gorsn.proto` schema. Messages larger than 16 MiB are
+// rejected.
+func NewProtoDecoder(r io.Reader) EventDecoder {
+	return &protoDecoder{r: bufio.NewReader(r)}
+}
+
+func (e *protoEncoder) Encode(ev Event) error {
+	msg := appendProtoEvent(nil, newEventRecord(ev))
+	e.buf = binary.AppendUvarint(e.buf[:0], uint64(len(msg)))
+	e.buf = append(e.buf, msg...)
+	_, err := e.w.Write(e.buf)
+	return err
+}
+
+func (d *protoDecoder) Decode(ev *Event) error {
+	size, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		return err
+	}
+	if size > maxProtoEventSize {
+		return fmt.Errorf("proto: message of %d bytes too large", size)
+	}
+	if uint64(cap(d.buf)) < size {
+		d.buf = make([]byte, size)
+	}
+	d.buf = d.buf[:size]
+	if _, err := io.ReadFull(d.r, d.buf); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	rec, err := parseProtoEvent(d.buf)
+	if err != nil {
+		return err
+	}
+	*ev = rec.event()
+	return nil
+}
+
+// protobuf wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+func appendProtoTag(b []byte, field int, wire int) []byte {
+	return binary.AppendUvarint(b, uint64(field)<<3|uint64(wire))
+}
+
+func appendProtoInt(b []byte, field int, v int64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = appendProtoTag(b, field, wireVarint)
+	return binary.AppendUvarint(b, uint64(v))
+}
+
+func appendProtoString(b []byte, field int, s string) []byte {
+	if s == "" {
+		return b
+	}
+	b = appendProtoTag(b, field, wireBytes)
+	b = binary.AppendUvarint(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+func appendProtoBytes(b []byte, field int, msg []byte) []byte {
+	b = appendProtoTag(b, field, wireBytes)
+	b = binary.AppendUvarint(b, uint64(len(msg)))
+	return append(b, msg...)
+}
+
+// unixNano returns the nanoseconds of the time, zero for the zero time.
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func fromUnixNano(n int64) time.Time {
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n)
+}
+
+func appendProtoEvent(b []byte, rec eventRecord) []byte {
+	b = appendProtoInt(b, 1, int64(rec.Schema))
+	b = appendProtoString(b, 2, rec.Path)
+	b = appendProtoString(b, 3, rec.OldPath)
+	b = appendProtoString(b, 4, string(rec.Type))
+	b = appendProtoString(b, 5, string(rec.Name))
+	b = appendProtoString(b, 6, rec.Error)
+	b = appendProtoInt(b, 7, int64(uint32(rec.Mode)))
+	b = appendProtoInt(b, 8, int64(uint32(rec.OldMode)))
+	b = appendProtoInt(b, 9, int64(rec.ChildrenCount))
+	b = appendProtoInt(b, 10, int64(rec.Dropped))
+	for _, p := range rec.Prefixes {
+		b = appendProtoBytes(b, 11, []byte(p))
+	}
+	b = appendProtoInt(b, 12, unixNano(rec.Time))
+	b = appendProtoInt(b, 13, unixNano(rec.ModTime))
+	b = appendProtoInt(b, 14, int64(rec.Severity))
+	for _, t := range rec.Tags {
+		b = appendProtoBytes(b, 15, []byte(t))
+	}
+	b = appendProtoInt(b, 16, int64(rec.Cycle))
+	if s := rec.Summary; s != nil {
+		var m []byte
+		m = appendProtoInt(m, 1, int64(s.Cycle))
+		m = appendProtoInt(m, 2, s.Visited)
+		m = appendProtoInt(m, 3, s.Emitted)
+		m = appendProtoInt(m, 4, int64(s.Duration))
+		m = appendProtoInt(m, 5, int64(s.Workers))
+		for name, n := range s.Counts {
+			var e []byte
+			e = appendProtoString(e, 1, string(name))
+			e = appendProtoInt(e, 2, n)
+			m = appendProtoBytes(m, 6, e)
+		}
+		m = appendProtoInt(m, 7, s.Errors)
+		b = appendProtoBytes(b, 17, m)
+	}
+	b = appendProtoString(b, 18, rec.Root)
+	b = appendProtoString(b, 19, rec.RootLabel)
+	b = appendProtoString(b, 20, rec.Checksum)
+	b = appendProtoString(b, 21, rec.ChecksumAlgo)
+	b = appendProtoString(b, 22, rec.ContentType)
+	b = appendProtoString(b, 23, rec.LinkTarget)
+	b = appendProtoString(b, 24, rec.RealPath)
+	b = appendProtoInt(b, 25, int64(rec.Suppressed))
+	b = appendProtoInt(b, 26, rec.OldSize)
+	b = appendProtoInt(b, 27, rec.NewSize)
+	for _, r := range rec.Regions {
+		var m []byte
+		m = appendProtoInt(m, 1, r.Offset)
+		m = appendProtoInt(m, 2, r.Length)
+		b = appendProtoBytes(b, 28, m)
+	}
+	if rec.RegionsKnown {
+		b = appendProtoInt(b, 29, 1)
+	}
+	return b
+}
+
+// protoFields calls `fn` for each field of the message with its number and
+// its value: the varint or the content of the length-delimited ones. Fixed
+// size fields are skipped since the schema does not use them.
+func protoFields(b []byte, fn func(field int, v uint64, data []byte) error) error {
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 {
+			return errors.New("proto: invalid field tag")
+		}
+		b = b[n:]
+		field, wire := int(tag>>3), int(tag&7)
+		var v uint64
+		var data []byte
+		switch wire {
+		case wireVarint:
+			if v, n = binary.Uvarint(b); n <= 0 {
+				return errors.New("proto: invalid varint")
+			}
+			b = b[n:]
+		case wireBytes:
+			size, n := binary.Uvarint(b)
+			if n <= 0 || size > uint64(len(b)-n) {
+				return errors.New("proto: invalid length")
+			}
+			data, b = b[n:n+int(size)], b[n+int(size):]
+		case wireFixed64:
+			if len(b) < 8 {
+				return io.ErrUnexpectedEOF
+			}
+			b = b[8:]
+			continue
+		case wireFixed32:
+			if len(b) < 4 {
+				return io.ErrUnexpectedEOF
+			}
+			b = b[4:]
+			continue
+		default:
+			return fmt.Errorf("proto: unsupported wire type %d", wire)
+		}
+		if err := fn(field, v, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseProtoEvent(b []byte) (eventRecord, error) {
+	var rec eventRecord
+	err := protoFields(b, func(field int, v uint64, data []byte) error {
+		switch field {
+		case 1:
+			rec.Schema = int(v)
+		case 2:
+			rec.Path = string(data)
+		case 3:
+			rec.OldPath = string(data)
+		case 4:
+			rec.Type = PathType(data)
+		case 5:
+			rec.Name = EventName(data)
+		case 6:
+			rec.Error = string(data)
+		case 7:
+			rec.Mode = fs.FileMode(v)
+		case 8:
+			rec.OldMode = fs.FileMode(v)
+		case 9:
+			rec.ChildrenCount = int(v)
+		case 10:
+			rec.Dropped = int(v)
+		case 11:
+			rec.Prefixes = append(rec.Prefixes, string(data))
+		case 12:
+			rec.Time = fromUnixNano(int64(v))
+		case 13:
+			rec.ModTime = fromUnixNano(int64(v))
+		case 14:
+			rec.Severity = Severity(v)
+		case 15:
+			rec.Tags = append(rec.Tags, string(data))
+		case 16:
+			rec.Cycle = int(v)
+		case 17:
+			s, err := parseProtoSummary(data)
+			if err != nil {
+				return err
+			}
+			rec.Summary = s
+		case 18:
+			rec.Root = string(data)
+		case 19:
+			rec.RootLabel = string(data)
+		case 20:
+			rec.Checksum = string(data)
+		case 21:
+			rec.ChecksumAlgo = string(data)
+		case 22:
+			rec.ContentType = string(data)
+		case 23:
+			rec.LinkTarget = string(data)
+		case 24:
+			rec.RealPath = string(data)
+		case 25:
+			rec.Suppressed = int(v)
+		case 26:
+			rec.OldSize = int64(v)
+		case 27:
+			rec.NewSize = int64(v)
+		case 28:
+			var r Region
+			err := protoFields(data, func(field int, v uint64, data []byte) error {
+				if field == 1 {
+					r.Offset = int64(v)
+				} else if field == 2 {
+					r.Length = int64(v)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			rec.Regions = append(rec.Regions, r)
+		case 29:
+			rec.RegionsKnown = v != 0
+		}
+		return nil
+	})
+	return rec, err
+}
+
+func parseProtoSummary(b []byte) (*ScanSummary, error) {
+	s := &ScanSummary{}
+	err := protoFields(b, func(field int, v uint64, data []byte) error {
+		switch field {
+		case 1:
+			s.Cycle = int(v)
+		case 2:
+			s.Visited = int64(v)
+		case 3:
+			s.Emitted = int64(v)
+		case 4:
+			s.Duration = time.Duration(v)
+		case 5:
+			s.Workers = int(v)
+		case 6:
+			var name EventName
+			var n int64
+			err := protoFields(data, func(field int, v uint64, data []byte) error {
+				if field == 1 {
+					name = EventName(data)
+				} else if field == 2 {
+					n = int64(v)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if s.Counts == nil {
+				s.Counts = make(map[EventName]int64)
+			}
+			s.Counts[name] = n
+		case 7:
+			s.Errors = int64(v)
+		}
+		return nil
+	})
+	return s, err
+}
@@ -0,0 +1,66 @@
+package gorsn
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCodecs(t *testing.T) {
+	now := time.Unix(1700000000, 42)
+	events := []Event{
+		{Path: "a", Type: FILE, Name: MODIFY, Time: now, Tags: []string{"x", "y"}},
+		{Path: "b", Type: FILE, Name: MODIFY, Time: now, Regions: []Region{}},
+		{Path: "c", Type: FILE, Name: MODIFY, Time: now, Regions: []Region{{Offset: 4, Length: 2}}},
+		{Path: "d", OldPath: "e", Type: DIR, Name: RENAME, Time: now, Error: errors.New("failed")},
+	}
+	codecs := []struct {
+		name string
+		enc  func(io.Writer) EventEncoder
+		dec  func(io.Reader) EventDecoder
+	}{
+		{"gob", NewGobEncoder, NewGobDecoder},
+		{"proto", NewProtoEncoder, NewProtoDecoder},
+	}
+	for _, c := range codecs {
+		var buf bytes.Buffer
+		enc := c.enc(&buf)
+		for _, ev := range events {
+			if err := enc.Encode(ev); err != nil {
+				t.Fatal(err)
+			}
+		}
+		dec := c.dec(&buf)
+		for _, want := range events {
+			var got Event
+			if err := dec.Decode(&got); err != nil {
+				t.Fatalf("%s: %v", c.name, err)
+			}
+			// empty regions of unchanged content differ from unknown ones.
+			if !reflect.DeepEqual(got.Regions, want.Regions) {
+				t.Errorf("%s: got regions %#v of %s, want %#v", c.name, got.Regions, want.Path, want.Regions)
+			}
+			if got.Path != want.Path || got.OldPath != want.OldPath || !got.Time.Equal(want.Time) ||
+				(got.Error == nil) != (want.Error == nil) || !reflect.DeepEqual(got.Tags, want.Tags) {
+				t.Errorf("%s: got event %+v, want %+v", c.name, got, want)
+			}
+		}
+		if err := dec.Decode(&Event{}); err != io.EOF {
+			t.Errorf("%s: got error %v at the end of the stream, want io.EOF", c.name, err)
+		}
+	}
+}
+
+func TestProtoDecoderMessageSize(t *testing.T) {
+	header := binary.AppendUvarint(nil, math.MaxInt32)
+	err := NewProtoDecoder(bytes.NewReader(header)).Decode(&Event{})
+	if err == nil || !strings.Contains(err.Error(), "too large") {
+		t.Errorf("got error %v, want a too large message", err)
+	}
+}
@@ -0,0 +1,121 @@
+package gorsn
+
+import (
+	"hash/maphash"
+	"sort"
+)
+
+// coldInfos returns the stored infos of a path not cached into memory and
+// whether there are some. It always reports false unless the cache is limited.
+func (sn *snotifier) coldInfos(path string) (*pathInfos, bool, error) {
+	if sn.lookup == nil {
+		return nil, false, nil
+	}
+	ps, ok, err := sn.lookup.Lookup(sn.root, path)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	return statePathInfos(ps, SchemaVersion), true, nil
+}
+
+// resumable reports whether the store already holds items of the root, in
+// which case they are compared by the first scan instead of being recorded
+// by the initialization.
+func (sn *snotifier) resumable() (bool, error) {
+	stored := false
+	err := sn.lookup.Range(sn.root, func(string, PathState) bool {
+		stored = true
+		return false
+	})
+	return stored, err
+}
+
+// visit records that the stored path not cached was found by the scan.
+func (sn *snotifier) visit(path string) {
+	h := maphash.String(sn.vseed, path)
+	sn.vmu.Lock()
+	sn.visits = append(sn.visits, h)
+	sn.vmu.Unlock()
+}
+
+// unvisitCold forgets the stored paths found by the scan and whether their
+// lookup failed.
+func (sn *snotifier) unvisitCold() {
+	sn.vmu.Lock()
+	sn.visits = sn.visits[:0]
+	sn.vmu.Unlock()
+	sn.cfailed.Store(false)
+}
+
+// warmMissing caches the stored paths which were not found by the scan, so
+// `missingPaths` checks them like the cached ones. If `under` is not nil,
+// only the paths under these ones are considered. Paths no longer matching
+// the options are removed from the store without event.
+func (sn *snotifier) warmMissing(under []string) {
+	if sn.lookup == nil {
+		return
+	}
+	sn.vmu.Lock()
+	defer sn.vmu.Unlock()
+	visits := sn.visits
+	sort.Slice(visits, func(i, j int) bool { return visits[i] < visits[j] })
+	visited := func(path string) bool {
+		h := maphash.String(sn.vseed, path)
+		i := sort.Search(len(visits), func(i int) bool { return visits[i] >= h })
+		return i < len(visits) && visits[i] == h
+	}
+	err := sn.lookup.Range(sn.root, func(path string, ps PathState) bool {
+		if !sn.running.Load() {
+			return false
+		}
+		if under != nil && !isUnder(path, under) {
+			return true
+		}
+		if _, cached := sn.paths.Load(path); cached || visited(path) {
+			return true
+		}
+		if ignore, _ := sn.check(path, getPathType(ps.Mode), nil); ignore {
+			sn.changed(path)
+			return true
+		}
+		sn.track(path, statePathInfos(ps, SchemaVersion))
+		return true
+	})
+	if err != nil {
+		sn.storeFailed(err)
+	}
+}
+
+// cool evicts the least recently seen paths from the cache once saved, so
+// that it holds at most the number set by `SetMaxCachedPaths`. The paths not
+// saved yet, settling or in their delete grace period are kept.
+func (sn *snotifier) cool() {
+	if sn.lookup == nil {
+		return
+	}
+	sn.unvisitCold()
+	excess := sn.tracked.Load() - sn.opts.persist.maxCached
+	if excess <= 0 {
+		return
+	}
+	type entry struct {
+		path string
+		seen int64
+	}
+	var entries []entry
+	sn.paths.Range(func(key, value any) bool {
+		pi := value.(*pathInfos)
+		if _, dirty := sn.dirty.Load(key); dirty || pi.settling || pi.missing > 0 {
+			return true
+		}
+		entries = append(entries, entry{key.(string), pi.seen.Load()})
+		return true
+	})
+	sort.Slice(entries, func(i, j int) bool { return entries[i].seen < entries[j].seen })
+	if int64(len(entries)) > excess {
+		entries = entries[:excess]
+	}
+	for _, e := range entries {
+		sn.forget(e.path)
+	}
+}
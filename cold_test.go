@@ -0,0 +1,224 @@
+package gorsn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// mapStore is a StateLookup which keeps the states into memory. Its lookups
+// fail with `fail` if set.
+type mapStore struct {
+	mu    sync.Mutex
+	paths map[string]PathState
+	fail  error
+}
+
+func newMapStore() *mapStore { return &mapStore{paths: make(map[string]PathState)} }
+
+func (s *mapStore) Load(string) (*State, error) {
+	panic("states loaded while looked up")
+}
+
+func (s *mapStore) Save(_ string, changed map[string]PathState, removed []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for path, ps := range changed {
+		s.paths[path] = ps
+	}
+	for _, path := range removed {
+		delete(s.paths, path)
+	}
+	return nil
+}
+
+func (s *mapStore) Lookup(_, path string) (PathState, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ps, ok := s.paths[path]
+	return ps, ok, s.fail
+}
+
+func (s *mapStore) Range(_ string, fn func(string, PathState) bool) error {
+	s.mu.Lock()
+	paths := make(map[string]PathState, len(s.paths))
+	for path, ps := range s.paths {
+		paths[path] = ps
+	}
+	s.mu.Unlock()
+	for path, ps := range paths {
+		if !fn(path, ps) {
+			break
+		}
+	}
+	return nil
+}
+
+// names returns the base names of the stored paths.
+func (s *mapStore) names() map[string]bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make(map[string]bool, len(s.paths))
+	for path := range s.paths {
+		names[filepath.Base(path)] = true
+	}
+	return names
+}
+
+// startCold starts a scan notifier over `dir` which caches 3 items and looks
+// up the others into `store`. It scans once per call of `cycle`, which returns
+// the events of the cycle.
+func startCold(t *testing.T, dir string, store *mapStore) (sn *snotifier, cycle func() map[string][]EventName) {
+	t.Helper()
+	next, done, quit := make(chan struct{}), make(chan struct{}), make(chan struct{})
+	opts := defaultOpts()
+	opts.Scan().SetInterval(0).
+		SetBeforeScan(func(int) {
+			select {
+			case <-next:
+			case <-quit:
+			}
+		}).
+		SetAfterScan(func(int, ScanSummary) {
+			select {
+			case done <- struct{}{}:
+			case <-quit:
+			}
+		})
+	opts.Delivery().SetQueueSize(64)
+	opts.Persistence().SetStateStore(store).SetMaxCachedPaths(3)
+	sn, err := newSnotifier(dir, opts, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sn.StartAsync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		close(quit)
+		sn.Stop()
+		sn.Wait()
+	})
+	return sn, func() map[string][]EventName {
+		t.Helper()
+		next <- struct{}{}
+		<-done
+		return received(sn)
+	}
+}
+
+func TestColdCache(t *testing.T) {
+	dir := t.TempDir()
+	var names []string
+	for i := 0; i < 10; i++ {
+		names = append(names, fmt.Sprintf("f%d", i))
+	}
+	writeFiles(t, dir, "1", names...)
+	store := newMapStore()
+	sn, cycle := startCold(t, dir, store)
+
+	if evs := cycle(); len(evs) != 0 {
+		t.Errorf("got events %v on the first cycle, want none", evs)
+	}
+	if n := sn.tracked.Load(); n > 3 {
+		t.Errorf("got %d cached items, want at most 3", n)
+	}
+	if n := len(store.names()); n != 10 {
+		t.Errorf("got %d stored items, want the 10 files", n)
+	}
+
+	writeFiles(t, dir, "22", "f1")
+	writeFiles(t, dir, "1", "new")
+	if err := os.Remove(filepath.Join(dir, "f2")); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]EventName{"f1": {MODIFY}, "f2": {DELETE}, "new": {CREATE}}
+	if evs := cycle(); !reflect.DeepEqual(evs, want) {
+		t.Errorf("got events %v, want %v", evs, want)
+	}
+	if evs := cycle(); len(evs) != 0 {
+		t.Errorf("got events %v without changes, want none", evs)
+	}
+	if n := sn.tracked.Load(); n > 3 {
+		t.Errorf("got %d cached items, want at most 3", n)
+	}
+	stored := store.names()
+	if !stored["new"] || stored["f2"] {
+		t.Errorf("got stored items %v, want new stored and f2 removed", stored)
+	}
+	if n := len(sn.Export().Paths); n != 10 {
+		t.Errorf("got %d exported items, want the cached and the stored ones", n)
+	}
+}
+
+func TestColdCacheResume(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, "1", "a", "b", "c", "d", "e")
+	store := newMapStore()
+	_, cycle := startCold(t, dir, store)
+	cycle()
+
+	writeFiles(t, dir, "22", "a")
+	writeFiles(t, dir, "1", "f")
+	if err := os.Remove(filepath.Join(dir, "b")); err != nil {
+		t.Fatal(err)
+	}
+	// the changes made while stopped are reported by the first cycle.
+	_, cycle = startCold(t, dir, store)
+	want := map[string][]EventName{"a": {MODIFY}, "b": {DELETE}, "f": {CREATE}}
+	if evs := cycle(); !reflect.DeepEqual(evs, want) {
+		t.Errorf("got events %v, want %v", evs, want)
+	}
+}
+
+func TestColdCacheLookupError(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, "1", "a", "b", "c", "d", "e")
+	store := newMapStore()
+	sn, cycle := startCold(t, dir, store)
+	cycle()
+
+	store.mu.Lock()
+	store.fail = errors.New("disk failure")
+	store.mu.Unlock()
+	// the items which could not be looked up are neither created nor deleted.
+	errs := 0
+	for _, names := range cycle() {
+		for _, name := range names {
+			if name != ERROR {
+				t.Errorf("got %s event while the lookups fail, want ERROR ones", name)
+			}
+			errs++
+		}
+	}
+	if errs != 1 {
+		t.Errorf("got %d ERROR events while the lookups fail, want a single one", errs)
+	}
+	store.mu.Lock()
+	store.fail = nil
+	store.mu.Unlock()
+	if evs := cycle(); len(evs) != 0 {
+		t.Errorf("got events %v once the lookups work again, want none", evs)
+	}
+	if n := len(sn.Export().Paths); n != 5 {
+		t.Errorf("got %d exported items, want the 5 files", n)
+	}
+}
+
+func TestColdCacheValidate(t *testing.T) {
+	opts := defaultOpts()
+	opts.Persistence().SetStateStore(NewFileStateStore(filepath.Join(t.TempDir(), "state"))).SetMaxCachedPaths(3)
+	if err := opts.Validate(); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("got error %v with a store without lookups, want ErrInvalidOptions", err)
+	}
+	opts.Persistence().SetStateStore(newMapStore())
+	opts.Scan().SetMaxTrackedPaths(10, LIMIT_EVICT)
+	if err := opts.Validate(); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("got error %v along with the tracked paths limit, want ErrInvalidOptions", err)
+	}
+}
@@ -0,0 +1,53 @@
+package gorsn
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// collapseDeletes keeps a single `DELETE` event for each deleted directory,
+// counting its deleted sub-items into `ChildrenCount`. Other events are left
+// untouched. Kept events are sorted by path.
+func collapseDeletes(events []Event) []Event {
+	dirs := make(map[string]int)
+	for i, ev := range events {
+		if ev.Name == DELETE && ev.Type == DIR {
+			dirs[ev.Path] = i
+		}
+	}
+	drop := make([]bool, len(events))
+	for i, ev := range events {
+		if ev.Name != DELETE {
+			continue
+		}
+		if top, ok := topDeleted(ev.Path, dirs); ok {
+			events[dirs[top]].ChildrenCount++
+			drop[i] = true
+		}
+	}
+	kept := events[:0]
+	for i, ev := range events {
+		if !drop[i] {
+			kept = append(kept, ev)
+		}
+	}
+	sort.SliceStable(kept, func(i, j int) bool { return kept[i].Path < kept[j].Path })
+	return kept
+}
+
+// topDeleted returns the highest deleted directory containing the path.
+func topDeleted(path string, dirs map[string]int) (string, bool) {
+	var top string
+	found := false
+	for {
+		i := strings.LastIndexAny(path, "/"+string(filepath.Separator))
+		if i <= 0 {
+			return top, found
+		}
+		path = path[:i]
+		if _, ok := dirs[path]; ok {
+			top, found = path, true
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package gorsn
+
+import "time"
+
+// Comparator tells whether the content of a known item changed between its
+// previous and its current state. It is called by the workers for each item
+// so implementations must be safe for concurrent use and return quickly.
+type Comparator interface {
+	Changed(prev, cur PathState) bool
+}
+
+var (
+	// COMPARE_MTIME reports a change if the modification time changed.
+	COMPARE_MTIME Comparator = modTimeComparator{}
+	// COMPARE_MTIME_SIZE reports a change if the modification time or the
+	// size of a regular file changed. This is the default comparator.
+	COMPARE_MTIME_SIZE Comparator = modTimeSizeComparator{}
+	// COMPARE_CTIME reports a change if the inode change time changed, so
+	// a modification time restored by the writer is still detected. It falls
+	// back to `COMPARE_MTIME_SIZE` where the change time is not available.
+	COMPARE_CTIME Comparator = changeTimeComparator{}
+	// COMPARE_CHECKSUM reports a change only if the content digest of a
+	// regular file changed. Other kinds of items, such as the directories
+	// and the symlinks, are compared like with `COMPARE_MTIME_SIZE`.
+	// Selecting it enables the checksum option.
+	COMPARE_CHECKSUM Comparator = checksumComparator{}
+)
+
+type modTimeComparator struct{}
+
+func (modTimeComparator) Changed(prev, cur PathState) bool {
+	return !prev.ModTime.Equal(cur.ModTime)
+}
+
+type modTimeSizeComparator struct{}
+
+func (modTimeSizeComparator) Changed(prev, cur PathState) bool {
+	return !prev.ModTime.Equal(cur.ModTime) || (cur.Mode.IsRegular() && prev.Size != cur.Size)
+}
+
+type changeTimeComparator struct{}
+
+func (changeTimeComparator) Changed(prev, cur PathState) bool {
+	if prev.ChangeTime.IsZero() || cur.ChangeTime.IsZero() || prev.Mode != cur.Mode {
+		// a permissions change also updates the change time.
+		return COMPARE_MTIME_SIZE.Changed(prev, cur)
+	}
+	return !prev.ChangeTime.Equal(cur.ChangeTime)
+}
+
+// checksumComparator leaves the detection of the regular files changes to
+// the hashing workers which compare the content digests.
+type checksumComparator struct{}
+
+func (checksumComparator) Changed(prev, cur PathState) bool {
+	if cur.Mode.IsRegular() {
+		return false
+	}
+	return COMPARE_MTIME_SIZE.Changed(prev, cur)
+}
+
+// comparator returns the configured comparator.
+func (so *ScanOptions) comparator() Comparator {
+	if c, ok := so.compare.Load().(Comparator); ok && c != nil {
+		return c
+	}
+	return COMPARE_MTIME_SIZE
+}
+
+// state returns the exported state of the path infos.
+func (pi *pathInfos) state() PathState {
+	pi.mu.RLock()
+	defer pi.mu.RUnlock()
+	return PathState{ModTime: pi.modTime, Mode: pi.mode, Size: pi.size, Checksum: pi.sum, ChangeTime: nanoTime(pi.sys.ctime)}
+}
+
+// nanoTime converts a time in nanoseconds, zero meaning unknown.
+func nanoTime(ns int64) time.Time {
+	if ns == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ns)
+}
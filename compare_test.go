@@ -0,0 +1,72 @@
+package gorsn
+
+import (
+	"io/fs"
+	"testing"
+	"time"
+)
+
+func TestComparators(t *testing.T) {
+	t0 := time.Unix(1000, 0)
+	t1 := t0.Add(time.Second)
+	tests := []struct {
+		name       string
+		prev, cur  PathState
+		mtime      bool
+		mtimeSize  bool
+		changeTime bool
+		checksum   bool
+	}{
+		{
+			"unchanged file",
+			PathState{ModTime: t0, Size: 1, ChangeTime: t0}, PathState{ModTime: t0, Size: 1, ChangeTime: t0},
+			false, false, false, false,
+		},
+		{
+			"file modified",
+			PathState{ModTime: t0, Size: 1, ChangeTime: t0}, PathState{ModTime: t1, Size: 2, ChangeTime: t1},
+			true, true, true, false,
+		},
+		{
+			"file resized with restored mtime",
+			PathState{ModTime: t0, Size: 1, ChangeTime: t0}, PathState{ModTime: t0, Size: 2, ChangeTime: t1},
+			false, true, true, false,
+		},
+		{
+			"file without change time",
+			PathState{ModTime: t0, Size: 1}, PathState{ModTime: t0, Size: 2},
+			false, true, true, false,
+		},
+		{
+			"directory modified",
+			PathState{ModTime: t0, Mode: fs.ModeDir, Size: 64}, PathState{ModTime: t1, Mode: fs.ModeDir, Size: 96},
+			true, true, true, true,
+		},
+		{
+			"directory resized only",
+			PathState{ModTime: t0, Mode: fs.ModeDir, Size: 64}, PathState{ModTime: t0, Mode: fs.ModeDir, Size: 96},
+			false, false, false, false,
+		},
+		{
+			"symlink retargeted",
+			PathState{ModTime: t0, Mode: fs.ModeSymlink, Size: 3}, PathState{ModTime: t1, Mode: fs.ModeSymlink, Size: 3},
+			true, true, true, true,
+		},
+	}
+	for _, tt := range tests {
+		for _, c := range []struct {
+			name string
+			cmp  Comparator
+			want bool
+		}{
+			{"mtime", COMPARE_MTIME, tt.mtime},
+			{"mtime_size", COMPARE_MTIME_SIZE, tt.mtimeSize},
+			{"ctime", COMPARE_CTIME, tt.changeTime},
+			{"checksum", COMPARE_CHECKSUM, tt.checksum},
+		} {
+			if got := c.cmp.Changed(tt.prev, tt.cur); got != c.want {
+				t.Errorf("%s with %s: got changed %t, want %t", tt.name, c.name, got, c.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,538 @@
+package gorsn
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// config is the documented schema of the settings which could be loaded
+// from a file. Missing keys keep their default value. Durations are strings
+// such as "500ms" or "2s". Example in YAML:
+//
+//	scan:
+//	  interval: 2s
+//	  max_workers: 4
+//	  checksum: true
+//	  hash_workers: 2
+//	  autoscale_min: 2
+//	  autoscale_max: 16
+//	  max_tracked_paths: 100000
+//	  limit_policy: error # or skip, evict.
+//	  comparator: mtime_size # or mtime, ctime, checksum.
+//	  throttle: 500 # file system operations per second.
+//	  load_threshold: 4.5
+//	  memory_budget: 268435456 # bytes.
+//	filters:
+//	  exclude: '\.log$'
+//	  include: ''
+//	  ignore_files: false
+//	  ignore_folders: false
+//	  ignore_symlinks: true
+//	  ignore_fifos: true
+//	  ignore_sockets: true
+//	  ignore_devices: true
+//	  ignore_folder_content: false
+//	  gitignore: true
+//	  ignore_editor_temp: true
+//	  ignore_artifacts: true
+//	  content_types: 'image/, application/pdf'
+//	  owners: '1000, 1001' # user IDs.
+//	  groups: '' # group IDs.
+//	  recursive: true
+//	events:
+//	  ignore_errors: false
+//	  ignore_delete: false
+//	  ignore_create: false
+//	  ignore_modify: false
+//	  ignore_perm: false
+//	  ignore_attrib: false
+//	  track_ownership: false
+//	  track_renames: true
+//	  track_links: false
+//	  settle_cycles: 2
+//	  delete_grace: 1
+//	  vanished: error # or suppress, create_delete, transient.
+//	  stop_on_root_lost: false
+//	  collapse_deletes: false
+//	  detect_touch: false
+//	  detect_rotation: false
+//	  changed_regions: 1048576 # bytes of the compared blocks.
+//	  detect_atomic_saves: false
+//	  scan_summary: false
+//	  detect_locked: false
+//	  path_form: native # or dos, extended.
+//	  relative_paths: false
+//	  root_label: ''
+//	  include_checksum: false
+//	  detect_content_type: false
+//	  dedup_window: 0s
+//	  filter_expr: 'path.endsWith(".go") && size < 1048576'
+//	delivery:
+//	  queue_size: 100
+//	  debounce: 100ms
+//	  ack_timeout: 0s
+//	  history_size: 50
+//	  deterministic_order: false
+//	  overflow_policy: block # or drop.
+//	  max_events_per_second: 0
+//	  subscriptions_only: false
+//
+// The same keys are expected as nested objects into JSON.
+type config struct {
+	Scan struct {
+		Interval     *duration `json:"interval"`
+		MaxWorkers   *int      `json:"max_workers"`
+		Checksum     *bool     `json:"checksum"`
+		HashWorkers  *int      `json:"hash_workers"`
+		AutoscaleMin *int      `json:"autoscale_min"`
+		AutoscaleMax *int      `json:"autoscale_max"`
+		MaxTracked   *int      `json:"max_tracked_paths"`
+		LimitPolicy  *string   `json:"limit_policy"`
+		Comparator   *string   `json:"comparator"`
+		Throttle     *int      `json:"throttle"`
+		MaxLoad      *float64  `json:"load_threshold"`
+		MemBudget    *int64    `json:"memory_budget"`
+	} `json:"scan"`
+	Filters struct {
+		Exclude             *string `json:"exclude"`
+		Include             *string `json:"include"`
+		IgnoreFiles         *bool   `json:"ignore_files"`
+		IgnoreFolders       *bool   `json:"ignore_folders"`
+		IgnoreSymlinks      *bool   `json:"ignore_symlinks"`
+		IgnoreFifos         *bool   `json:"ignore_fifos"`
+		IgnoreSockets       *bool   `json:"ignore_sockets"`
+		IgnoreDevices       *bool   `json:"ignore_devices"`
+		IgnoreFolderContent *bool   `json:"ignore_folder_content"`
+		Gitignore           *bool   `json:"gitignore"`
+		IgnoreEditorTemp    *bool   `json:"ignore_editor_temp"`
+		IgnoreArtifacts     *bool   `json:"ignore_artifacts"`
+		ContentTypes        *string `json:"content_types"`
+		Owners              *string `json:"owners"`
+		Groups              *string `json:"groups"`
+		Recursive           *bool   `json:"recursive"`
+	} `json:"filters"`
+	Events struct {
+		IgnoreErrors    *bool     `json:"ignore_errors"`
+		IgnoreDelete    *bool     `json:"ignore_delete"`
+		IgnoreCreate    *bool     `json:"ignore_create"`
+		IgnoreModify    *bool     `json:"ignore_modify"`
+		IgnorePerm      *bool     `json:"ignore_perm"`
+		IgnoreAttrib    *bool     `json:"ignore_attrib"`
+		TrackOwnership  *bool     `json:"track_ownership"`
+		TrackRenames    *bool     `json:"track_renames"`
+		TrackLinks      *bool     `json:"track_links"`
+		SettleCycles    *int      `json:"settle_cycles"`
+		DeleteGrace     *int      `json:"delete_grace"`
+		Vanished        *string   `json:"vanished"`
+		StopOnRootLost  *bool     `json:"stop_on_root_lost"`
+		CollapseDeletes *bool     `json:"collapse_deletes"`
+		DetectTouch     *bool     `json:"detect_touch"`
+		DetectRotation  *bool     `json:"detect_rotation"`
+		ChangedRegions  *int      `json:"changed_regions"`
+		AtomicSaves     *bool     `json:"detect_atomic_saves"`
+		ScanSummary     *bool     `json:"scan_summary"`
+		DetectLocked    *bool     `json:"detect_locked"`
+		PathForm        *string   `json:"path_form"`
+		RelativePaths   *bool     `json:"relative_paths"`
+		RootLabel       *string   `json:"root_label"`
+		IncludeChecksum *bool     `json:"include_checksum"`
+		ContentType     *bool     `json:"detect_content_type"`
+		DedupWindow     *duration `json:"dedup_window"`
+		FilterExpr      *string   `json:"filter_expr"`
+	} `json:"events"`
+	Delivery struct {
+		QueueSize          *int      `json:"queue_size"`
+		Debounce           *duration `json:"debounce"`
+		AckTimeout         *duration `json:"ack_timeout"`
+		HistorySize        *int      `json:"history_size"`
+		DeterministicOrder *bool     `json:"deterministic_order"`
+		OverflowPolicy     *string   `json:"overflow_policy"`
+		MaxEventsPerSecond *int      `json:"max_events_per_second"`
+		SubscriptionsOnly  *bool     `json:"subscriptions_only"`
+	} `json:"delivery"`
+}
+
+// duration is a time.Duration decoded from a string such as "1s".
+type duration time.Duration
+
+func (d *duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string: %s", b)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = duration(v)
+	return nil
+}
+
+var limitPolicies = map[string]LimitPolicy{
+	"error": LIMIT_ERROR,
+	"skip":  LIMIT_SKIP,
+	"evict": LIMIT_EVICT,
+}
+
+var overflowPolicies = map[string]OverflowPolicy{
+	"block": OVERFLOW_BLOCK,
+	"drop":  OVERFLOW_DROP,
+}
+
+var comparators = map[string]Comparator{
+	"mtime":      COMPARE_MTIME,
+	"mtime_size": COMPARE_MTIME_SIZE,
+	"ctime":      COMPARE_CTIME,
+	"checksum":   COMPARE_CHECKSUM,
+}
+
+var pathForms = map[string]PathForm{
+	"native":   PATH_FORM_NATIVE,
+	"dos":      PATH_FORM_DOS,
+	"extended": PATH_FORM_EXTENDED,
+}
+
+var vanishedModes = map[string]VanishedMode{
+	"suppress":      VANISHED_SUPPRESS,
+	"error":         VANISHED_ERROR,
+	"create_delete": VANISHED_CREATE_DELETE,
+	"transient":     VANISHED_TRANSIENT,
+}
+
+// OptionsFromFile loads the settings from a YAML or a JSON file.
+// See `OptionsFromReader` for the expected content.
+func OptionsFromFile(path string) (*Options, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	defer f.Close()
+	return OptionsFromReader(f)
+}
+
+// OptionsFromReader loads the settings from a JSON object or a YAML document
+// limited to nested mappings of scalar values. It returns an error which
+// wraps `ErrInvalidConfig` if the content is malformed, contains unknown
+// keys or invalid values. The supported keys are listed into the README.
+func OptionsFromReader(r io.Reader) (*Options, error) {
+	data, err := readConfig(r)
+	if err != nil {
+		return nil, err
+	}
+	return optionsFromJSON(data)
+}
+
+// readConfig reads a JSON or a YAML config and returns it as JSON.
+func readConfig(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) == 0 || trimmed[0] != '{' {
+		if data, err = yamlToJSON(data); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+		}
+	}
+	return data, nil
+}
+
+// optionsFromJSON builds the options from a JSON document of the config schema.
+func optionsFromJSON(data []byte) (*Options, error) {
+	cfg, err := decodeConfig(data)
+	if err != nil {
+		return nil, err
+	}
+	o := defaultOpts()
+	if err := cfg.apply(o); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	return o, nil
+}
+
+// decodeConfig decodes a JSON document of the config schema.
+func decodeConfig(data []byte) (*config, error) {
+	var cfg config
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	return &cfg, nil
+}
+
+// apply sets the defined settings on the options.
+func (c *config) apply(o *Options) error {
+	sc, fc, ec, dc := &c.Scan, &c.Filters, &c.Events, &c.Delivery
+	if sc.Interval != nil {
+		o.Scan().SetInterval(time.Duration(*sc.Interval))
+	}
+	setInt(sc.MaxWorkers, func(v int) { o.Scan().SetMaxWorkers(v) })
+	setBool(sc.Checksum, func(v bool) { o.Scan().SetChecksum(v) })
+	setInt(sc.HashWorkers, func(v int) { o.Scan().SetHashWorkers(v) })
+	setInt(sc.Throttle, func(v int) { o.Scan().SetScanThrottle(v) })
+	if sc.MemBudget != nil {
+		o.Scan().SetMemoryBudget(*sc.MemBudget)
+	}
+	if sc.MaxLoad != nil {
+		o.Scan().SetLoadThreshold(*sc.MaxLoad)
+	}
+	if sc.Comparator != nil {
+		c, ok := comparators[*sc.Comparator]
+		if !ok {
+			return fmt.Errorf("unknown comparator %q", *sc.Comparator)
+		}
+		o.Scan().SetComparator(c)
+	}
+	if sc.AutoscaleMax != nil {
+		min := 0
+		if sc.AutoscaleMin != nil {
+			min = *sc.AutoscaleMin
+		}
+		o.Scan().SetAutoscale(min, *sc.AutoscaleMax)
+	}
+	if sc.MaxTracked != nil {
+		policy := LIMIT_ERROR
+		if sc.LimitPolicy != nil {
+			p, ok := limitPolicies[*sc.LimitPolicy]
+			if !ok {
+				return fmt.Errorf("unknown limit policy %q", *sc.LimitPolicy)
+			}
+			policy = p
+		}
+		o.Scan().SetMaxTrackedPaths(*sc.MaxTracked, policy)
+	}
+
+	for _, p := range []struct {
+		expr *string
+		set  func(*regexp.Regexp) *FilterOptions
+	}{
+		{fc.Exclude, o.Filters().SetExcludeRegex},
+		{fc.Include, o.Filters().SetIncludeRegex},
+	} {
+		if p.expr == nil {
+			continue
+		}
+		re, err := regexp.Compile(*p.expr)
+		if err != nil {
+			return err
+		}
+		p.set(re)
+	}
+	setBool(fc.IgnoreFiles, func(v bool) { o.Filters().SetIgnoreFiles(v) })
+	setBool(fc.IgnoreFolders, func(v bool) { o.Filters().SetIgnoreFolders(v) })
+	setBool(fc.IgnoreSymlinks, func(v bool) { o.Filters().SetIgnoreSymlinks(v) })
+	setBool(fc.IgnoreFifos, func(v bool) { o.Filters().SetIgnoreFifos(v) })
+	setBool(fc.IgnoreSockets, func(v bool) { o.Filters().SetIgnoreSockets(v) })
+	setBool(fc.IgnoreDevices, func(v bool) { o.Filters().SetIgnoreDevices(v) })
+	setBool(fc.IgnoreFolderContent, func(v bool) { o.Filters().SetIgnoreFolderContent(v) })
+	setBool(fc.Gitignore, func(v bool) { o.Filters().SetGitignore(v) })
+	setBool(fc.IgnoreEditorTemp, func(v bool) { o.Filters().SetIgnoreEditorTemp(v) })
+	setBool(fc.IgnoreArtifacts, func(v bool) { o.Filters().SetIgnoreArtifacts(v) })
+	if fc.ContentTypes != nil {
+		var types []string
+		for _, t := range strings.Split(*fc.ContentTypes, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				types = append(types, t)
+			}
+		}
+		o.Filters().SetContentTypes(types...)
+	}
+	for _, p := range []struct {
+		kind string
+		ids  *string
+		set  func([]int) *FilterOptions
+	}{
+		{"user", fc.Owners, o.Filters().SetOwnerFilter},
+		{"group", fc.Groups, o.Filters().SetGroupFilter},
+	} {
+		if p.ids == nil {
+			continue
+		}
+		var ids []int
+		for _, v := range strings.Split(*p.ids, ",") {
+			if v = strings.TrimSpace(v); v == "" {
+				continue
+			}
+			id, err := strconv.ParseUint(v, 10, 32)
+			if err != nil {
+				return fmt.Errorf("invalid %s id %q", p.kind, v)
+			}
+			ids = append(ids, int(id))
+		}
+		p.set(ids)
+	}
+	setBool(fc.Recursive, func(v bool) { o.Filters().SetRecursive(v) })
+
+	setBool(ec.IgnoreErrors, func(v bool) { o.Events().SetIgnoreErrors(v) })
+	setBool(ec.IgnoreDelete, func(v bool) { o.Events().SetIgnoreDelete(v) })
+	setBool(ec.IgnoreCreate, func(v bool) { o.Events().SetIgnoreCreate(v) })
+	setBool(ec.IgnoreModify, func(v bool) { o.Events().SetIgnoreModify(v) })
+	setBool(ec.IgnorePerm, func(v bool) { o.Events().SetIgnorePerm(v) })
+	setBool(ec.IgnoreAttrib, func(v bool) { o.Events().SetIgnoreAttrib(v) })
+	setBool(ec.TrackOwnership, func(v bool) { o.Events().SetTrackOwnership(v) })
+	setBool(ec.TrackRenames, func(v bool) { o.Events().SetTrackRenames(v) })
+	setBool(ec.TrackLinks, func(v bool) { o.Events().SetTrackLinks(v) })
+	setInt(ec.SettleCycles, func(v int) { o.Events().SetSettleCycles(v) })
+	setInt(ec.DeleteGrace, func(v int) { o.Events().SetDeleteGrace(v) })
+	setBool(ec.StopOnRootLost, func(v bool) { o.Events().SetStopOnRootLost(v) })
+	setBool(ec.CollapseDeletes, func(v bool) { o.Events().SetCollapseDeletes(v) })
+	setBool(ec.DetectTouch, func(v bool) { o.Events().SetDetectTouch(v) })
+	setBool(ec.DetectRotation, func(v bool) { o.Events().SetDetectRotation(v) })
+	setInt(ec.ChangedRegions, func(v int) { o.Events().SetChangedRegions(v) })
+	setBool(ec.AtomicSaves, func(v bool) { o.Events().SetDetectAtomicSaves(v) })
+	setBool(ec.ScanSummary, func(v bool) { o.Events().SetScanSummary(v) })
+	setBool(ec.DetectLocked, func(v bool) { o.Events().SetDetectLocked(v) })
+	setBool(ec.RelativePaths, func(v bool) { o.Events().SetRelativePaths(v) })
+	setBool(ec.IncludeChecksum, func(v bool) { o.Events().SetIncludeChecksum(v) })
+	setBool(ec.ContentType, func(v bool) { o.Events().SetDetectContentType(v) })
+	if ec.DedupWindow != nil {
+		o.Events().SetDedupWindow(time.Duration(*ec.DedupWindow))
+	}
+	if ec.RootLabel != nil {
+		o.Events().SetRootLabel(*ec.RootLabel)
+	}
+	if ec.FilterExpr != nil {
+		if err := o.Events().SetEventFilterExpr(*ec.FilterExpr); err != nil {
+			return err
+		}
+	}
+	if ec.Vanished != nil {
+		m, ok := vanishedModes[*ec.Vanished]
+		if !ok {
+			return fmt.Errorf("unknown vanished mode %q", *ec.Vanished)
+		}
+		o.Events().SetVanished(m)
+	}
+	if ec.PathForm != nil {
+		f, ok := pathForms[*ec.PathForm]
+		if !ok {
+			return fmt.Errorf("unknown path form %q", *ec.PathForm)
+		}
+		o.Events().SetPathForm(f)
+	}
+
+	setInt(dc.QueueSize, func(v int) { o.Delivery().SetQueueSize(v) })
+	if dc.Debounce != nil {
+		o.Delivery().SetDebounce(time.Duration(*dc.Debounce))
+	}
+	if dc.AckTimeout != nil {
+		o.Delivery().SetAckTimeout(time.Duration(*dc.AckTimeout))
+	}
+	setInt(dc.HistorySize, func(v int) { o.Delivery().SetHistorySize(v) })
+	setBool(dc.DeterministicOrder, func(v bool) { o.Delivery().SetDeterministicOrder(v) })
+	if dc.OverflowPolicy != nil {
+		p, ok := overflowPolicies[*dc.OverflowPolicy]
+		if !ok {
+			return fmt.Errorf("unknown overflow policy %q", *dc.OverflowPolicy)
+		}
+		o.Delivery().SetOverflowPolicy(p)
+	}
+	setInt(dc.MaxEventsPerSecond, func(v int) { o.Delivery().SetMaxEventsPerSecond(v) })
+	setBool(dc.SubscriptionsOnly, func(v bool) { o.Delivery().SetSubscriptionsOnly(v) })
+	return nil
+}
+
+func setBool(v *bool, set func(bool)) {
+	if v != nil {
+		set(*v)
+	}
+}
+
+func setInt(v *int, set func(int)) {
+	if v != nil {
+		set(*v)
+	}
+}
+
+// yamlToJSON converts a YAML document made of nested mappings of scalar
+// values into its JSON equivalent. Sequences, anchors and multi-line
+// values are not supported.
+func yamlToJSON(data []byte) ([]byte, error) {
+	type level struct {
+		indent int
+		m      map[string]any
+	}
+	root := map[string]any{}
+	stack := []level{{-1, root}}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimRight(stripYAMLComment(sc.Text()), " \t")
+		content := strings.TrimLeft(line, " ")
+		if content == "" || content == "---" {
+			continue
+		}
+		if strings.HasPrefix(content, "\t") || strings.HasPrefix(content, "- ") {
+			return nil, fmt.Errorf("line %d: unsupported yaml syntax", n)
+		}
+		indent := len(line) - len(content)
+		key, value, ok := strings.Cut(content, ":")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expecting a key", n)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		for indent <= stack[len(stack)-1].indent {
+			stack = stack[:len(stack)-1]
+		}
+		parent := stack[len(stack)-1].m
+		if value == "" {
+			child := map[string]any{}
+			parent[key] = child
+			stack = append(stack, level{indent, child})
+			continue
+		}
+		v, err := yamlScalar(value)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", n, err)
+		}
+		parent[key] = v
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return json.Marshal(root)
+}
+
+// stripYAMLComment removes a trailing comment outside of quotes.
+func stripYAMLComment(line string) string {
+	var quote rune
+	for i, c := range line {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// yamlScalar decodes a quoted string, a boolean, a number or a plain string.
+func yamlScalar(s string) (any, error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		return strconv.Unquote(s)
+	case strings.HasPrefix(s, "'") && strings.HasSuffix(s, "'") && len(s) > 1:
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	case s == "true" || s == "false":
+		return s == "true", nil
+	}
+	if i, err := strconv.Atoi(s); err == nil {
+		return i, nil
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f, nil
+	}
+	return s, nil
+}
@@ -0,0 +1,131 @@
+package gorsn
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestYAMLToJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{"empty", "", `{}`},
+		{"nested", "scan:\n  interval: 2s\n  max_workers: 4\nevents:\n  track_renames: true\n", `{"events":{"track_renames":true},"scan":{"interval":"2s","max_workers":4}}`},
+		{"deeper indentation", "scan:\n    interval: 2s\nfilters:\n      gitignore: false\n", `{"filters":{"gitignore":false},"scan":{"interval":"2s"}}`},
+		{"dedent to an outer level", "a:\n  b:\n    c: 1\n  d: 2\ne: 3\n", `{"a":{"b":{"c":1},"d":2},"e":3}`},
+		{"comments and document start", "---\n# settings\nscan: # group\n  interval: 2s # every 2 seconds\n\n", `{"scan":{"interval":"2s"}}`},
+		{"hash into quotes", "filters:\n  exclude: '#tmp$' # comment\n  include: \"a#b\"\n", `{"filters":{"exclude":"#tmp$","include":"a#b"}}`},
+		{"hash into a word", "root_label: a#b\n", `{"root_label":"a#b"}`},
+		{"scalars", "a: 'it''s'\nb: \"tab\\t\"\nc: false\nd: -3\ne: 4.5\nf: 1e3\ng: plain text\n", `{"a":"it's","b":"tab\t","c":false,"d":-3,"e":4.5,"f":1000,"g":"plain text"}`},
+		{"comma separated list", "filters:\n  owners: '1000, 1001'\n", `{"filters":{"owners":"1000, 1001"}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := yamlToJSON([]byte(tt.yaml))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestYAMLToJSONErrors(t *testing.T) {
+	for name, yaml := range map[string]string{
+		"sequence":        "filters:\n  content_types:\n    - image/\n",
+		"inline sequence": "- scan\n",
+		"tab indentation": "scan:\n\tinterval: 2s\n",
+		"missing colon":   "scan:\n  interval 2s\n",
+		"bad quoting":     "root_label: \"abc\n",
+	} {
+		if _, err := yamlToJSON([]byte(yaml)); err == nil {
+			t.Errorf("%s: got no error", name)
+		}
+	}
+}
+
+func TestOptionsFromReader(t *testing.T) {
+	const yaml = `
+scan:
+  interval: 250ms
+  max_workers: 3
+  comparator: ctime
+  max_tracked_paths: 10
+  limit_policy: evict
+filters:
+  exclude: '\.log$'
+  content_types: 'image/, application/pdf'
+  owners: '1000, 1001'
+  recursive: false
+events:
+  vanished: transient
+  path_form: dos
+  dedup_window: 1s
+delivery:
+  overflow_policy: drop
+  subscriptions_only: true
+`
+	const json = `{
+	"scan": {"interval": "250ms", "max_workers": 3, "comparator": "ctime", "max_tracked_paths": 10, "limit_policy": "evict"},
+	"filters": {"exclude": "\\.log$", "content_types": "image/, application/pdf", "owners": "1000, 1001", "recursive": false},
+	"events": {"vanished": "transient", "path_form": "dos", "dedup_window": "1s"},
+	"delivery": {"overflow_policy": "drop", "subscriptions_only": true}
+}`
+	for name, doc := range map[string]string{"yaml": yaml, "json": json} {
+		o, err := OptionsFromReader(strings.NewReader(doc))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		so, fo, eo, do := o.Scan(), o.Filters(), o.Events(), o.Delivery()
+		max, policy := so.GetMaxTrackedPaths()
+		switch {
+		case so.GetInterval() != 250*time.Millisecond, so.GetMaxWorkers() != 3,
+			so.GetComparator() != COMPARE_CTIME, max != 10, policy != LIMIT_EVICT:
+			t.Errorf("%s: scan options not loaded", name)
+		case fo.GetExcludeRegex().String() != `\.log$`, fo.GetRecursive(),
+			!reflect.DeepEqual(fo.GetContentTypes(), []string{"image/", "application/pdf"}),
+			!reflect.DeepEqual(fo.GetOwnerFilter(), []int{1000, 1001}):
+			t.Errorf("%s: filters options not loaded", name)
+		case eo.GetVanished() != VANISHED_TRANSIENT, eo.GetPathForm() != PATH_FORM_DOS, eo.GetDedupWindow() != time.Second:
+			t.Errorf("%s: events options not loaded", name)
+		case do.GetOverflowPolicy() != OVERFLOW_DROP, !do.GetSubscriptionsOnly():
+			t.Errorf("%s: delivery options not loaded", name)
+		}
+	}
+}
+
+func TestOptionsFromReaderErrors(t *testing.T) {
+	tests := []struct {
+		name, doc string
+	}{
+		{"unknown group", "watch:\n  interval: 2s\n"},
+		{"unknown key", "scan:\n  every: 2s\n"},
+		{"invalid duration", "scan:\n  interval: soon\n"},
+		{"duration as a number", "scan:\n  interval: 2\n"},
+		{"string as an integer", "scan:\n  max_workers: many\n"},
+		{"integer as a boolean", "scan:\n  checksum: 1\n"},
+		{"unknown comparator", "scan:\n  comparator: size\n"},
+		{"unknown limit policy", "scan:\n  max_tracked_paths: 10\n  limit_policy: drop\n"},
+		{"invalid exclude pattern", "filters:\n  exclude: '('\n"},
+		{"invalid owner id", "filters:\n  owners: '1000, root'\n"},
+		{"negative group id", "filters:\n  groups: '-1'\n"},
+		{"unknown vanished mode", "events:\n  vanished: ignore\n"},
+		{"unknown path form", "events:\n  path_form: unc\n"},
+		{"invalid filter expression", "events:\n  filter_expr: 'size >'\n"},
+		{"unknown overflow policy", "delivery:\n  overflow_policy: wait\n"},
+		{"malformed json", `{"scan": {"interval": "2s"}`},
+		{"malformed yaml", "scan:\n\tinterval: 2s\n"},
+	}
+	for _, tt := range tests {
+		if _, err := OptionsFromReader(strings.NewReader(tt.doc)); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("%s: got error %v, want ErrInvalidConfig", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package gorsn
+
+import (
+	"io"
+	"net/http"
+	"strings"
+)
+
+// sniffLen is the number of bytes considered to detect the content type.
+const sniffLen = 512
+
+// sniff sets the content type of the `CREATE` and `MODIFY` events of the
+// regular files if the detection is enabled or the content types filtered.
+func (sn *snotifier) sniff(ev *Event) {
+	if ev.Type != FILE || (ev.Name != CREATE && ev.Name != MODIFY) || ev.ContentType != "" {
+		return
+	}
+	if !sn.opts.events.detectContentType.Load() && len(sn.opts.filters.contentTypes()) == 0 {
+		return
+	}
+	op, ok := sn.opts.scan.backend.(opener)
+	if !ok {
+		return
+	}
+	f, err := op.Open(ev.Path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	buf := make([]byte, sniffLen)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return
+	}
+	ev.ContentType = http.DetectContentType(buf[:n])
+}
+
+// typeFiltered reports whether the event of a regular file should be dropped
+// since its content type does not match the allowed ones.
+func (sn *snotifier) typeFiltered(ev Event) bool {
+	if ev.Type != FILE || (ev.Name != CREATE && ev.Name != MODIFY) {
+		return false
+	}
+	types := sn.opts.filters.contentTypes()
+	if len(types) == 0 {
+		return false
+	}
+	media, _, _ := strings.Cut(ev.ContentType, ";")
+	media = strings.TrimSpace(media)
+	if media == "" {
+		return true
+	}
+	for _, t := range types {
+		if strings.HasSuffix(t, "/") && strings.HasPrefix(media, t) || strings.EqualFold(media, t) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,10 @@
+//go:build aix || dragonfly || illumos || linux || openbsd || solaris
+
+package gorsn
+
+import "syscall"
+
+// statCtime returns the inode change time in nanoseconds.
+func statCtime(st *syscall.Stat_t) int64 {
+	return int64(st.Ctim.Sec)*1e9 + int64(st.Ctim.Nsec)
+}
@@ -0,0 +1,10 @@
+//go:build darwin || freebsd || netbsd
+
+package gorsn
+
+import "syscall"
+
+// statCtime returns the inode change time in nanoseconds.
+func statCtime(st *syscall.Stat_t) int64 {
+	return int64(st.Ctimespec.Sec)*1e9 + int64(st.Ctimespec.Nsec)
+}
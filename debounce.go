@@ -0,0 +1,108 @@
+package gorsn
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// debounceIdleTick is the polling period of the debouncer when disabled.
+	debounceIdleTick = 100 * time.Millisecond
+	// debounceMinTick is the minimum polling period of the debouncer.
+	debounceMinTick = 10 * time.Millisecond
+)
+
+// debounced is an event waiting for its path to be quiet.
+type debounced struct {
+	ev   Event
+	last time.Time
+}
+
+// debouncer coalesces the successive events of each path
+// and releases them once the path stayed quiet long enough.
+type debouncer struct {
+	mu      sync.Mutex
+	pending map[string]*debounced
+}
+
+// debounceable reports whether the event describes a path change.
+func debounceable(name EventName) bool {
+	switch name {
+	case CREATE, MODIFY, DELETE, PERM, OWNER, RENAME:
+		return true
+	default:
+		return false
+	}
+}
+
+// add merges the event with the pending one of the same path. A path
+// created then modified stays created, a path created then deleted is
+// dropped and a path deleted then created is reported as modified.
+func (d *debouncer) add(ev Event, now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.pending == nil {
+		d.pending = make(map[string]*debounced)
+	}
+	p, ok := d.pending[ev.Path]
+	if !ok {
+		d.pending[ev.Path] = &debounced{ev, now}
+		return
+	}
+	p.last = now
+	switch {
+	case p.ev.Name == CREATE && ev.Name == DELETE:
+		delete(d.pending, ev.Path)
+	case p.ev.Name == CREATE:
+		// keep reporting the creation.
+	case p.ev.Name == DELETE && ev.Name == CREATE:
+		ev.Name = MODIFY
+		p.ev = ev
+	default:
+		p.ev = ev
+	}
+}
+
+// ready removes and returns the events of paths quiet for at least `wait`.
+func (d *debouncer) ready(wait time.Duration, now time.Time) []Event {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var evs []Event
+	for path, p := range d.pending {
+		if now.Sub(p.last) >= wait {
+			evs = append(evs, p.ev)
+			delete(d.pending, path)
+		}
+	}
+	return evs
+}
+
+// debounceLoop periodically releases the quiet events and sends again
+// the unacknowledged ones until the notifier is stopped. Events still
+// pending at that time are dropped.
+func (sn *snotifier) debounceLoop() {
+	defer close(sn.ddone)
+	for {
+		wait := sn.opts.delivery.debounce.Load().(time.Duration)
+		timeout := sn.opts.delivery.ackTimeout.Load().(time.Duration)
+		tick := debounceIdleTick
+		if wait > 0 {
+			tick = wait / 2
+		}
+		if timeout > 0 && timeout/2 < tick {
+			tick = timeout / 2
+		}
+		if tick < debounceMinTick {
+			tick = debounceMinTick
+		}
+		select {
+		case <-sn.stop:
+			return
+		case <-sn.opts.scan.clock.After(tick):
+		}
+		for _, ev := range sn.debounce.ready(wait, sn.now()) {
+			sn.deliver(ev)
+		}
+		sn.redeliver(timeout)
+	}
+}
@@ -0,0 +1,76 @@
+package gorsn
+
+import (
+	"sync"
+	"time"
+)
+
+// deduped is the latest `MODIFY` event emitted for a path along with the
+// number of the identical ones suppressed since.
+type deduped struct {
+	at         time.Time
+	suppressed int
+}
+
+// dedupper suppresses the identical `MODIFY` events of each path emitted
+// within the dedup window, e.g. of a file whose modification time ticks
+// each scan cycle because of an external process.
+type dedupper struct {
+	mu     sync.Mutex
+	last   map[string]*deduped
+	pruned time.Time
+}
+
+// check reports whether the event should be suppressed. Otherwise it sets
+// into the event the number of events suppressed for its path since the
+// previous emitted one.
+func (d *dedupper) check(ev *Event, window time.Duration, now time.Time) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.last == nil {
+		d.last = make(map[string]*deduped)
+	}
+	d.prune(window, now)
+	p, ok := d.last[ev.Path]
+	if ev.Name != MODIFY {
+		if ok {
+			ev.Suppressed = p.suppressed
+			delete(d.last, ev.Path)
+		}
+		return false
+	}
+	if !ok {
+		d.last[ev.Path] = &deduped{at: now}
+		return false
+	}
+	if now.Sub(p.at) < window {
+		p.suppressed++
+		return true
+	}
+	ev.Suppressed = p.suppressed
+	p.at, p.suppressed = now, 0
+	return false
+}
+
+// prune forgets the paths without suppressed events once the window
+// elapsed, at most once per window.
+func (d *dedupper) prune(window time.Duration, now time.Time) {
+	if now.Sub(d.pruned) < window {
+		return
+	}
+	d.pruned = now
+	for path, p := range d.last {
+		if p.suppressed == 0 && now.Sub(p.at) >= window {
+			delete(d.last, path)
+		}
+	}
+}
+
+// duplicated reports whether the event is a duplicate to suppress.
+func (sn *snotifier) duplicated(ev *Event) bool {
+	window := sn.opts.events.dedupWindow.Load().(time.Duration)
+	if window <= 0 {
+		return false
+	}
+	return sn.dedup.check(ev, window, sn.now())
+}
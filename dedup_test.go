@@ -0,0 +1,73 @@
+package gorsn
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDedupWindow(t *testing.T) {
+	clock := newFakeClock()
+	opts := defaultOpts()
+	opts.Scan().SetClock(clock)
+	opts.Events().SetDedupWindow(time.Second)
+	opts.Delivery().SetQueueSize(16)
+	sn, err := newSnotifier(t.TempDir(), opts, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sn.running.Store(true)
+
+	type step struct {
+		after      time.Duration // clock advance before the event.
+		path       string
+		name       EventName
+		emitted    bool
+		suppressed int
+	}
+	steps := []step{
+		{0, "a", MODIFY, true, 0},
+		{0, "b", MODIFY, true, 0},
+		{500 * time.Millisecond, "a", MODIFY, false, 0},
+		{499 * time.Millisecond, "a", MODIFY, false, 0},
+		// the window starts from the latest emitted event.
+		{time.Millisecond, "a", MODIFY, true, 2},
+		{0, "b", MODIFY, true, 0},
+		{time.Millisecond, "a", MODIFY, false, 0},
+		// other kinds of events are never suppressed and carry the count.
+		{0, "a", ATTRIB, true, 1},
+		{0, "a", MODIFY, true, 0},
+		{0, "a", DELETE, true, 0},
+	}
+	for i, s := range steps {
+		clock.advance(s.after)
+		sn.queueEvent(Event{Path: s.path, Type: FILE, Name: s.name})
+		evs := pending(sn)
+		if !s.emitted {
+			if len(evs) != 0 {
+				t.Errorf("step %d: got events %+v, want the %s of %s suppressed", i, evs, s.name, s.path)
+			}
+			continue
+		}
+		if len(evs) != 1 || evs[0].Name != s.name || evs[0].Suppressed != s.suppressed {
+			t.Errorf("step %d: got events %+v, want the %s of %s with %d suppressed", i, evs, s.name, s.path, s.suppressed)
+		}
+	}
+}
+
+func TestDedupPrune(t *testing.T) {
+	var d dedupper
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, p := range []string{"a", "b"} {
+		d.check(&Event{Path: p, Name: MODIFY}, time.Second, now)
+	}
+	d.check(&Event{Path: "b", Name: MODIFY}, time.Second, now.Add(time.Millisecond))
+
+	// only the paths without suppressed events are forgotten.
+	d.check(&Event{Path: "c", Name: MODIFY}, time.Second, now.Add(time.Second))
+	if _, ok := d.last["a"]; ok {
+		t.Error("got a still tracked once its window elapsed")
+	}
+	if p, ok := d.last["b"]; !ok || p.suppressed != 1 {
+		t.Errorf("got b tracked %v, want it kept with its suppressed event", ok)
+	}
+}
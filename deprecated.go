@@ -0,0 +1,159 @@
+package gorsn
+
+import "time"
+
+// Deprecated: use EventName instead.
+type eventName = EventName
+
+// Deprecated: use PathType instead.
+type pathType = PathType
+
+// Deprecated: use Options.Delivery().SetQueueSize instead.
+func (o *Options) SetQueueSize(v int) *Options {
+	o.delivery.SetQueueSize(v)
+	return o
+}
+
+// Deprecated: use Options.Scan().SetMaxWorkers instead.
+func (o *Options) SetMaxWorkers(v int) *Options {
+	o.scan.SetMaxWorkers(v)
+	return o
+}
+
+// Deprecated: use Options.Scan().SetInterval instead.
+func (o *Options) SetScanInterval(v time.Duration) *Options {
+	o.scan.SetInterval(v)
+	return o
+}
+
+// Deprecated: use Options.Events().SetIgnoreErrors instead.
+func (o *Options) SetIgnoreErrors(v bool) *Options {
+	o.events.SetIgnoreErrors(v)
+	return o
+}
+
+// Deprecated: use Options.Events().SetIgnoreNoChange instead.
+func (o *Options) SetIgnoreNoChangeEvent(v bool) *Options {
+	o.events.SetIgnoreNoChange(v)
+	return o
+}
+
+// Deprecated: use Options.Events().SetIgnoreDelete instead.
+func (o *Options) SetIgnoreDeleteEvent(v bool) *Options {
+	o.events.SetIgnoreDelete(v)
+	return o
+}
+
+// Deprecated: use Options.Events().SetIgnoreCreate instead.
+func (o *Options) SetIgnoreCreateEvent(v bool) *Options {
+	o.events.SetIgnoreCreate(v)
+	return o
+}
+
+// Deprecated: use Options.Events().SetIgnoreModify instead.
+func (o *Options) SetIgnoreModifyEvent(v bool) *Options {
+	o.events.SetIgnoreModify(v)
+	return o
+}
+
+// Deprecated: use Options.Events().SetIgnorePerm instead.
+func (o *Options) SetIgnorePermEvent(v bool) *Options {
+	o.events.SetIgnorePerm(v)
+	return o
+}
+
+// Deprecated: use Options.Filters().SetIgnoreFiles instead.
+func (o *Options) SetIgnoreFileEvent(v bool) *Options {
+	o.filters.SetIgnoreFiles(v)
+	return o
+}
+
+// Deprecated: use Options.Filters().SetIgnoreFolders instead.
+func (o *Options) SetIgnoreFolderEvent(v bool) *Options {
+	o.filters.SetIgnoreFolders(v)
+	return o
+}
+
+// Deprecated: use Options.Filters().SetIgnoreSymlinks instead.
+func (o *Options) SetIgnoreSymlink(v bool) *Options {
+	o.filters.SetIgnoreSymlinks(v)
+	return o
+}
+
+// Deprecated: use Options.Filters().SetIgnoreFolderContent instead.
+func (o *Options) SetIgnoreFolderContentEvent(v bool) *Options {
+	o.filters.SetIgnoreFolderContent(v)
+	return o
+}
+
+// Deprecated: use Options.Scan().SetBackend instead.
+func (o *Options) SetBackend(b Backend) *Options {
+	o.scan.SetBackend(b)
+	return o
+}
+
+// Deprecated: use Options.Scan().SetChecksum instead.
+func (o *Options) SetChecksum(v bool) *Options {
+	o.scan.SetChecksum(v)
+	return o
+}
+
+// Deprecated: use Options.Scan().SetBeforeScan instead.
+func (o *Options) SetBeforeScan(fn func(cycle int)) *Options {
+	o.scan.SetBeforeScan(fn)
+	return o
+}
+
+// Deprecated: use Options.Scan().SetAfterScan instead.
+func (o *Options) SetAfterScan(fn func(cycle int, summary ScanSummary)) *Options {
+	o.scan.SetAfterScan(fn)
+	return o
+}
+
+// Deprecated: use Options.Filters().SetGitignore instead.
+func (o *Options) SetGitignore(v bool) *Options {
+	o.filters.SetGitignore(v)
+	return o
+}
+
+// Deprecated: use Options.Filters().SetIgnoreEditorTemp instead.
+func (o *Options) SetIgnoreEditorTemp(v bool) *Options {
+	o.filters.SetIgnoreEditorTemp(v)
+	return o
+}
+
+// Deprecated: use Options.Events().SetTrackOwnership instead.
+func (o *Options) SetTrackOwnership(v bool) *Options {
+	o.events.SetTrackOwnership(v)
+	return o
+}
+
+// Deprecated: use Options.Events().SetTrackRenames instead.
+func (o *Options) SetTrackRenames(v bool) *Options {
+	o.events.SetTrackRenames(v)
+	return o
+}
+
+// Deprecated: use Options.Events().SetAnomalyDetection instead.
+func (o *Options) SetAnomalyDetection(window int, sensitivity float64) *Options {
+	o.events.SetAnomalyDetection(window, sensitivity)
+	return o
+}
+
+// Deprecated: use Options.Delivery().SetDebounce instead.
+func (o *Options) SetDebounce(v time.Duration) *Options {
+	o.delivery.SetDebounce(v)
+	return o
+}
+
+// Deprecated: use Options.Delivery().AddSink instead.
+func (o *Options) AddSink(s Sink) *Options {
+	o.delivery.AddSink(s)
+	return o
+}
+
+// Deprecated: use Options.Delivery().AddObserver instead.
+func (o *Options) AddObserver(ob LifecycleObserver) *Options {
+	o.delivery.AddObserver(ob)
+	return o
+}
@@ -0,0 +1,62 @@
+package gorsn
+
+import (
+	"path/filepath"
+	"regexp"
+)
+
+// editorTempPatterns matches the base name of temporary files created
+// by common editors while saving: vim swap and backup files, vim write
+// probe, emacs lock and auto-save files, JetBrains safe-write files,
+// kate swap files and generic tilde backups.
+var editorTempPatterns = regexp.MustCompile(
+	`^(\..+\.sw[a-p]|.+~|4913|\.#.+|#.+#|.+___jb_(tmp|old)___|\..+\.kate-swp)$`,
+)
+
+// atomicSavePatterns matches the base name of temporary files written then
+// renamed over the real file by atomic saves: GNOME output streams and the
+// `.tmp` or `.temp` files with an optional random suffix of many libraries.
+var atomicSavePatterns = regexp.MustCompile(
+	`^(\.goutputstream-.+|.+\.te?mp([.-]?\d+|[.-]\w+)?)$`,
+)
+
+// artifactPatterns matches the base name of the files left by operating systems,
+// office suites and downloads in progress: macOS `.DS_Store` and AppleDouble
+// files, Windows thumbnails caches and folder settings, LibreOffice and MS Office
+// lock files and the partial files of browsers, download managers and transfer
+// clients.
+var artifactPatterns = regexp.MustCompile(
+	`^(\.DS_Store|\._.+|[Tt]humbs\.db|ehthumbs\.db|[Dd]esktop\.ini|\.~lock\..+#|~\$.+|` +
+		`.+\.(crdownload|part|partial|download|opdownload|filepart|!qB|!ut))$`,
+)
+
+// artifactDirs holds the base name of the directories maintained by
+// operating systems for their indexing, trash and recycle bin.
+var artifactDirs = map[string]bool{
+	".Spotlight-V100":           true,
+	".Trashes":                  true,
+	".fseventsd":                true,
+	".TemporaryItems":           true,
+	"$RECYCLE.BIN":              true,
+	"System Volume Information": true,
+}
+
+// isEditorTemp reports whether the path is an editor temporary file.
+func isEditorTemp(s string) bool {
+	return editorTempPatterns.MatchString(filepath.Base(s))
+}
+
+// isAtomicSaveTemp reports whether the path is a temporary file of an atomic save.
+func isAtomicSaveTemp(s string) bool {
+	return isEditorTemp(s) || atomicSavePatterns.MatchString(filepath.Base(s))
+}
+
+// isArtifact reports whether the path is a system, temporary or partial file,
+// or the directory of such files if `dir`.
+func isArtifact(s string, dir bool) bool {
+	name := filepath.Base(s)
+	if dir {
+		return artifactDirs[name]
+	}
+	return artifactPatterns.MatchString(name) || atomicSavePatterns.MatchString(name)
+}
@@ -0,0 +1,132 @@
+package gorsn
+
+import (
+	"fmt"
+	"net/smtp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DEFAULT_EMAIL_WINDOW is the default aggregation period of the email sink.
+	DEFAULT_EMAIL_WINDOW = time.Minute
+
+	// DEFAULT_EMAIL_MAX_EVENTS is the default number of events listed into
+	// a digest, the others are only counted.
+	DEFAULT_EMAIL_MAX_EVENTS = 100
+)
+
+// EmailConfig defines the SMTP server and the content of the digests sent by
+// the email sink.
+type EmailConfig struct {
+	Addr    string    // address of the SMTP server such as `mail.example.com:587`.
+	Auth    smtp.Auth // nil if the server does not require authentication.
+	From    string
+	To      []string
+	Subject string // default to `gorsn: <count> changes`.
+	// Filter selects the events to report, such as `FilterPaths` on `^/etc/`.
+	// A nil filter selects all of them.
+	Filter Filter
+	// Window is the period during which the selected events are aggregated
+	// before being sent. Zero falls back to `DEFAULT_EMAIL_WINDOW`.
+	Window time.Duration
+	// MaxEvents is the number of events listed into a digest. Zero falls
+	// back to `DEFAULT_EMAIL_MAX_EVENTS`.
+	MaxEvents int
+}
+
+// EmailSink is a Sink which sends a digest of the selected events by email.
+type EmailSink struct {
+	cfg  EmailConfig
+	send func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+
+	mu     sync.Mutex
+	events []Event
+	count  int
+	timer  *time.Timer
+	err    error // failure of the latest digest, reported by the next write.
+}
+
+// NewEmailSink returns a Sink which aggregates the events selected by the
+// filter during a window started by the first one, then sends their digest
+// through the SMTP server. The sending failures are returned by the next
+// write, so they are reported by an `ERROR` event. Call `Flush` once the scan
+// notifier stopped to send the pending events.
+func NewEmailSink(cfg EmailConfig) *EmailSink {
+	if cfg.Window <= 0 {
+		cfg.Window = DEFAULT_EMAIL_WINDOW
+	}
+	if cfg.MaxEvents <= 0 {
+		cfg.MaxEvents = DEFAULT_EMAIL_MAX_EVENTS
+	}
+	return &EmailSink{cfg: cfg, send: smtp.SendMail}
+}
+
+func (s *EmailSink) Write(ev Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.err
+	s.err = nil
+	if s.cfg.Filter != nil && !s.cfg.Filter(ev) {
+		return err
+	}
+	s.count++
+	if len(s.events) < s.cfg.MaxEvents {
+		s.events = append(s.events, ev)
+	}
+	if s.timer == nil {
+		s.timer = time.AfterFunc(s.cfg.Window, func() {
+			if err := s.Flush(); err != nil {
+				s.mu.Lock()
+				s.err = err
+				s.mu.Unlock()
+			}
+		})
+	}
+	return err
+}
+
+// Flush sends the digest of the pending events, if any, without waiting for
+// the end of the window.
+func (s *EmailSink) Flush() error {
+	s.mu.Lock()
+	events, count := s.events, s.count
+	s.events, s.count = nil, 0
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	s.mu.Unlock()
+	if count == 0 {
+		return nil
+	}
+	return s.send(s.cfg.Addr, s.cfg.Auth, s.cfg.From, s.cfg.To, s.digest(events, count))
+}
+
+// digest builds the message which lists the events.
+func (s *EmailSink) digest(events []Event, count int) []byte {
+	subject := s.cfg.Subject
+	if subject == "" {
+		subject = fmt.Sprintf("gorsn: %d changes", count)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", s.cfg.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(s.cfg.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", subject)
+	b.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n")
+	for _, ev := range events {
+		fmt.Fprintf(&b, "%s %s %s %s", ev.Time.Format(time.RFC3339), ev.Name, ev.Type, ev.Path)
+		if ev.OldPath != "" {
+			fmt.Fprintf(&b, " (from %s)", ev.OldPath)
+		}
+		if ev.Error != nil {
+			fmt.Fprintf(&b, ": %v", ev.Error)
+		}
+		b.WriteString("\r\n")
+	}
+	if count > len(events) {
+		fmt.Fprintf(&b, "... and %d more events.\r\n", count-len(events))
+	}
+	return []byte(b.String())
+}
@@ -0,0 +1,128 @@
+package gorsn
+
+import (
+	"errors"
+	"net/smtp"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// mail is a message sent through the fake SMTP function.
+type mail struct {
+	addr string
+	from string
+	to   []string
+	msg  string
+}
+
+// fakeMailer records the sent messages on `sent` and fails with `err` if set.
+type fakeMailer struct {
+	mu   sync.Mutex
+	sent chan mail
+	err  error
+}
+
+func (m *fakeMailer) send(addr string, _ smtp.Auth, from string, to []string, msg []byte) error {
+	m.sent <- mail{addr, from, to, string(msg)}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.err
+}
+
+func newTestEmailSink(cfg EmailConfig) (*EmailSink, *fakeMailer) {
+	m := &fakeMailer{sent: make(chan mail, 10)}
+	s := NewEmailSink(cfg)
+	s.send = m.send
+	return s, m
+}
+
+func TestEmailSinkDigest(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	s, m := newTestEmailSink(EmailConfig{
+		Addr: "mail.example.com:587", From: "gorsn@example.com", To: []string{"a@example.com", "b@example.com"},
+		Window: 20 * time.Millisecond, MaxEvents: 2,
+	})
+	events := []Event{
+		{Path: "/etc/b", OldPath: "/etc/a", Type: FILE, Name: RENAME, Time: at},
+		{Path: "/etc", Type: DIR, Name: ERROR, Error: errors.New("denied"), Time: at},
+		{Path: "/etc/c", Type: FILE, Name: CREATE, Time: at},
+	}
+	for _, ev := range events {
+		if err := s.Write(ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var got mail
+	select {
+	case got = <-m.sent:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the digest")
+	}
+	want := mail{
+		addr: "mail.example.com:587",
+		from: "gorsn@example.com",
+		to:   []string{"a@example.com", "b@example.com"},
+		msg: "From: gorsn@example.com\r\n" +
+			"To: a@example.com, b@example.com\r\n" +
+			"Subject: gorsn: 3 changes\r\n" +
+			"MIME-Version: 1.0\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n" +
+			"2024-01-02T03:04:05Z RENAME FILE /etc/b (from /etc/a)\r\n" +
+			"2024-01-02T03:04:05Z ERROR DIRECTORY /etc: denied\r\n" +
+			"... and 1 more events.\r\n",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got digest %+v, want %+v", got, want)
+	}
+	select {
+	case extra := <-m.sent:
+		t.Errorf("got a second digest %q for a single window", extra.msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestEmailSinkFlush(t *testing.T) {
+	s, m := newTestEmailSink(EmailConfig{Subject: "drops", Window: time.Hour, Filter: func(ev Event) bool { return ev.Name != DELETE }})
+	if err := s.Flush(); err != nil || len(m.sent) != 0 {
+		t.Fatalf("got error %v and %d digests without events, want none", err, len(m.sent))
+	}
+	for _, name := range []EventName{DELETE, CREATE} {
+		if err := s.Write(Event{Path: "/in/a", Type: FILE, Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.sent) != 1 {
+		t.Fatalf("got %d digests, want 1", len(m.sent))
+	}
+	msg := (<-m.sent).msg
+	if !strings.Contains(msg, "Subject: drops\r\n") || !strings.Contains(msg, " CREATE FILE /in/a\r\n") || strings.Contains(msg, "DELETE") {
+		t.Errorf("got digest %q, want the CREATE event with the custom subject", msg)
+	}
+}
+
+func TestEmailSinkFailure(t *testing.T) {
+	s, m := newTestEmailSink(EmailConfig{Window: time.Millisecond})
+	m.err = errors.New("relay denied")
+	if err := s.Write(Event{Path: "/in/a", Type: FILE, Name: CREATE}); err != nil {
+		t.Fatal(err)
+	}
+	<-m.sent
+	// the failure is reported by the next write.
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) {
+		if err := s.Write(Event{Path: "/in/b", Type: FILE, Name: CREATE}); err != nil {
+			if !errors.Is(err, m.err) {
+				t.Errorf("got error %v, want the sending failure", err)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the failure of the digest")
+		}
+		<-m.sent
+	}
+}
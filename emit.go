@@ -0,0 +1,82 @@
+package gorsn
+
+import "fmt"
+
+// Emit re-sends the current state of `path` as a `CREATE` event. The path
+// is expected as provided into the events, otherwise relative to the root.
+// It returns an error which wraps `ErrPathNotFound` if the path is unknown.
+// The event is delivered regardless of the events filtering options.
+func (sn *snotifier) Emit(path string) error {
+	sn.emu.RLock()
+	defer sn.emu.RUnlock()
+	if sn.isStopping() {
+		return ErrScanIsStopping
+	}
+	if !sn.IsRunning() {
+		return ErrScanIsNotRunning
+	}
+	val, ok := sn.paths.Load(path)
+	if !ok {
+		path = sn.opts.scan.backend.Join(sn.root, path)
+		val, ok = sn.paths.Load(path)
+	}
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrPathNotFound, path)
+	}
+	ps := val.(*pathInfos).state()
+	sn.deliver(Event{Path: path, Type: getPathType(ps.Mode), Name: CREATE, ModTime: ps.ModTime})
+	return nil
+}
+
+// Inject sends `ev` as if it was detected by the scan notifier. The path and
+// the kind of event are checked against the filtering options, a filtered out
+// event is silently dropped. It blocks like the detected events if the queue
+// is full, unless the overflow policy drops them.
+func (sn *snotifier) Inject(ev Event) error {
+	sn.emu.RLock()
+	defer sn.emu.RUnlock()
+	if sn.isStopping() {
+		return ErrScanIsStopping
+	}
+	if !sn.IsRunning() {
+		return ErrScanIsNotRunning
+	}
+	if ev.Path != sn.root {
+		if ignore, _ := sn.check(ev.Path, ev.Type, nil); ignore {
+			return nil
+		}
+	}
+	if sn.muted(ev.Name) {
+		return nil
+	}
+	if !sn.queueEvent(ev) {
+		return ErrScanIsStopping
+	}
+	return nil
+}
+
+// muted reports whether the kind of event is disabled by the options.
+func (sn *snotifier) muted(name EventName) bool {
+	eo := &sn.opts.events
+	switch name {
+	case ERROR:
+		return eo.ignoreErrors.Load()
+	case NOCHANGE:
+		return eo.ignoreNoChange.Load()
+	case CREATE:
+		return eo.ignoreCreate.Load()
+	case MODIFY, REPLACE, ROTATE:
+		return eo.ignoreModify.Load()
+	case DELETE:
+		return eo.ignoreDelete.Load()
+	case PERM:
+		return eo.ignorePerm.Load()
+	case ATTRIB:
+		return eo.ignoreAttrib.Load()
+	case OWNER:
+		return !eo.trackOwner.Load()
+	case SCAN_SUMMARY:
+		return !eo.scanSummary.Load()
+	}
+	return false
+}
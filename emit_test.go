@@ -0,0 +1,89 @@
+package gorsn
+
+import (
+	"errors"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+func TestInject(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(*Options)
+		ev    Event
+		sent  bool
+	}{
+		{"created file", func(*Options) {}, Event{Path: "a.txt", Type: FILE, Name: CREATE}, true},
+		{"excluded path", func(o *Options) {
+			o.Filters().SetExcludeRegex(regexp.MustCompile(`\.tmp$`))
+		}, Event{Path: "a.tmp", Type: FILE, Name: CREATE}, false},
+		{"path not included", func(o *Options) {
+			o.Filters().SetIncludeRegex(regexp.MustCompile(`\.go$`))
+		}, Event{Path: "a.txt", Type: FILE, Name: MODIFY}, false},
+		{"ignored kind of path", func(o *Options) {
+			o.Filters().SetIgnoreFolders(true)
+		}, Event{Path: "sub", Type: DIR, Name: CREATE}, false},
+		{"ignored event", func(o *Options) {
+			o.Events().SetIgnoreDelete(true)
+		}, Event{Path: "a.txt", Type: FILE, Name: DELETE}, false},
+		{"ignored replace with modify", func(o *Options) {
+			o.Events().SetIgnoreModify(true)
+		}, Event{Path: "a.txt", Type: FILE, Name: REPLACE}, false},
+		{"untracked owner", func(*Options) {}, Event{Path: "a.txt", Type: FILE, Name: OWNER}, false},
+		{"tracked owner", func(o *Options) {
+			o.Events().SetTrackOwnership(true)
+		}, Event{Path: "a.txt", Type: FILE, Name: OWNER}, true},
+		{"root error", func(o *Options) {
+			o.Filters().SetIncludeRegex(regexp.MustCompile(`\.go$`))
+		}, Event{Type: DIR, Name: ERROR, Error: errors.New("lost")}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := defaultOpts()
+			tt.setup(opts)
+			sn, err := newSnotifier(t.TempDir(), opts, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sn.running.Store(true)
+			ev := tt.ev
+			ev.Path = filepath.Join(sn.root, ev.Path)
+			if err := sn.Inject(ev); err != nil {
+				t.Fatal(err)
+			}
+			got := pending(sn)
+			if !tt.sent {
+				if len(got) != 0 {
+					t.Errorf("got events %v, want the injected event dropped", got)
+				}
+				return
+			}
+			if len(got) != 1 || got[0].Path != ev.Path || got[0].Name != ev.Name || got[0].Error != ev.Error {
+				t.Fatalf("got events %v, want the injected %s of %s", got, ev.Name, ev.Path)
+			}
+			if got[0].Time.IsZero() {
+				t.Error("got an injected event without detection time")
+			}
+		})
+	}
+}
+
+func TestInjectNotRunning(t *testing.T) {
+	sn, err := newSnotifier(t.TempDir(), defaultOpts(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ev := Event{Path: filepath.Join(sn.root, "a"), Type: FILE, Name: CREATE}
+	if err := sn.Inject(ev); !errors.Is(err, ErrScanIsNotRunning) {
+		t.Errorf("got error %v before starting, want ErrScanIsNotRunning", err)
+	}
+	sn.running.Store(true)
+	sn.stopping.Store(true)
+	if err := sn.Inject(ev); !errors.Is(err, ErrScanIsStopping) {
+		t.Errorf("got error %v while stopping, want ErrScanIsStopping", err)
+	}
+	if got := pending(sn); len(got) != 0 {
+		t.Errorf("got events %v, want none", got)
+	}
+}
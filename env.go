@@ -0,0 +1,132 @@
+package gorsn
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type envKind int
+
+const (
+	envString envKind = iota
+	envBool
+	envInt
+	envFloat
+)
+
+// envVars maps the environment variables names (without prefix)
+// to their group and key into the configuration schema.
+var envVars = []struct {
+	name  string
+	group string
+	key   string
+	kind  envKind
+}{
+	{"SCAN_INTERVAL", "scan", "interval", envString},
+	{"MAX_WORKERS", "scan", "max_workers", envInt},
+	{"CHECKSUM", "scan", "checksum", envBool},
+	{"HASH_WORKERS", "scan", "hash_workers", envInt},
+	{"AUTOSCALE_MIN", "scan", "autoscale_min", envInt},
+	{"AUTOSCALE_MAX", "scan", "autoscale_max", envInt},
+	{"MAX_TRACKED_PATHS", "scan", "max_tracked_paths", envInt},
+	{"LIMIT_POLICY", "scan", "limit_policy", envString},
+	{"COMPARATOR", "scan", "comparator", envString},
+	{"SCAN_THROTTLE", "scan", "throttle", envInt},
+	{"LOAD_THRESHOLD", "scan", "load_threshold", envFloat},
+	{"MEMORY_BUDGET", "scan", "memory_budget", envInt},
+	{"EXCLUDE_REGEX", "filters", "exclude", envString},
+	{"INCLUDE_REGEX", "filters", "include", envString},
+	{"IGNORE_FILES", "filters", "ignore_files", envBool},
+	{"IGNORE_FOLDERS", "filters", "ignore_folders", envBool},
+	{"IGNORE_SYMLINKS", "filters", "ignore_symlinks", envBool},
+	{"IGNORE_FIFOS", "filters", "ignore_fifos", envBool},
+	{"IGNORE_SOCKETS", "filters", "ignore_sockets", envBool},
+	{"IGNORE_DEVICES", "filters", "ignore_devices", envBool},
+	{"IGNORE_FOLDER_CONTENT", "filters", "ignore_folder_content", envBool},
+	{"GITIGNORE", "filters", "gitignore", envBool},
+	{"IGNORE_EDITOR_TEMP", "filters", "ignore_editor_temp", envBool},
+	{"IGNORE_ARTIFACTS", "filters", "ignore_artifacts", envBool},
+	{"CONTENT_TYPES", "filters", "content_types", envString},
+	{"OWNERS", "filters", "owners", envString},
+	{"GROUPS", "filters", "groups", envString},
+	{"RECURSIVE", "filters", "recursive", envBool},
+	{"IGNORE_ERRORS", "events", "ignore_errors", envBool},
+	{"IGNORE_DELETE", "events", "ignore_delete", envBool},
+	{"IGNORE_CREATE", "events", "ignore_create", envBool},
+	{"IGNORE_MODIFY", "events", "ignore_modify", envBool},
+	{"IGNORE_PERM", "events", "ignore_perm", envBool},
+	{"IGNORE_ATTRIB", "events", "ignore_attrib", envBool},
+	{"TRACK_OWNERSHIP", "events", "track_ownership", envBool},
+	{"TRACK_RENAMES", "events", "track_renames", envBool},
+	{"TRACK_LINKS", "events", "track_links", envBool},
+	{"SETTLE_CYCLES", "events", "settle_cycles", envInt},
+	{"DELETE_GRACE", "events", "delete_grace", envInt},
+	{"VANISHED", "events", "vanished", envString},
+	{"STOP_ON_ROOT_LOST", "events", "stop_on_root_lost", envBool},
+	{"COLLAPSE_DELETES", "events", "collapse_deletes", envBool},
+	{"DETECT_TOUCH", "events", "detect_touch", envBool},
+	{"DETECT_ROTATION", "events", "detect_rotation", envBool},
+	{"CHANGED_REGIONS", "events", "changed_regions", envInt},
+	{"DETECT_ATOMIC_SAVES", "events", "detect_atomic_saves", envBool},
+	{"SCAN_SUMMARY", "events", "scan_summary", envBool},
+	{"DETECT_LOCKED", "events", "detect_locked", envBool},
+	{"PATH_FORM", "events", "path_form", envString},
+	{"RELATIVE_PATHS", "events", "relative_paths", envBool},
+	{"ROOT_LABEL", "events", "root_label", envString},
+	{"INCLUDE_CHECKSUM", "events", "include_checksum", envBool},
+	{"DETECT_CONTENT_TYPE", "events", "detect_content_type", envBool},
+	{"DEDUP_WINDOW", "events", "dedup_window", envString},
+	{"FILTER_EXPR", "events", "filter_expr", envString},
+	{"QUEUE_SIZE", "delivery", "queue_size", envInt},
+	{"DEBOUNCE", "delivery", "debounce", envString},
+	{"ACK_TIMEOUT", "delivery", "ack_timeout", envString},
+	{"HISTORY_SIZE", "delivery", "history_size", envInt},
+	{"DETERMINISTIC_ORDER", "delivery", "deterministic_order", envBool},
+	{"OVERFLOW_POLICY", "delivery", "overflow_policy", envString},
+	{"MAX_EVENTS_PER_SECOND", "delivery", "max_events_per_second", envInt},
+	{"SUBSCRIPTIONS_ONLY", "delivery", "subscriptions_only", envBool},
+}
+
+// OptionsFromEnv loads the settings from the environment variables named
+// after the configuration file keys with `prefix` such as `GORSN`, e.g.
+// `GORSN_SCAN_INTERVAL=2s`, `GORSN_MAX_WORKERS=4`, `GORSN_EXCLUDE_REGEX=\.log$`
+// or `GORSN_TRACK_RENAMES=true`. Unset variables keep their default value. It
+// returns an error which wraps `ErrInvalidConfig` for an invalid value.
+func OptionsFromEnv(prefix string) (*Options, error) {
+	if prefix != "" && !strings.HasSuffix(prefix, "_") {
+		prefix += "_"
+	}
+	doc := map[string]map[string]any{}
+	for _, ev := range envVars {
+		name := prefix + ev.name
+		raw, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		var v any = raw
+		var err error
+		switch ev.kind {
+		case envBool:
+			v, err = strconv.ParseBool(raw)
+		case envInt:
+			v, err = strconv.Atoi(raw)
+		case envFloat:
+			v, err = strconv.ParseFloat(raw, 64)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidConfig, name, err)
+		}
+		if doc[ev.group] == nil {
+			doc[ev.group] = map[string]any{}
+		}
+		doc[ev.group][ev.key] = v
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	return optionsFromJSON(data)
+}
@@ -0,0 +1,75 @@
+package gorsn
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestOptionsFromEnv(t *testing.T) {
+	for _, prefix := range []string{"TEST_GORSN", "TEST_GORSN_"} {
+		t.Run(prefix, func(t *testing.T) {
+			t.Setenv("TEST_GORSN_SCAN_INTERVAL", "3s")
+			t.Setenv("TEST_GORSN_MAX_WORKERS", "6")
+			t.Setenv("TEST_GORSN_LOAD_THRESHOLD", "2.5")
+			t.Setenv("TEST_GORSN_EXCLUDE_REGEX", `\.log$`)
+			t.Setenv("TEST_GORSN_TRACK_RENAMES", "true")
+			t.Setenv("TEST_GORSN_OVERFLOW_POLICY", "drop")
+			// without the prefix, it is ignored.
+			t.Setenv("QUEUE_SIZE", "7")
+			o, err := OptionsFromEnv(prefix)
+			if err != nil {
+				t.Fatal(err)
+			}
+			switch {
+			case o.Scan().GetInterval() != 3*time.Second:
+				t.Errorf("got interval %s, want 3s", o.Scan().GetInterval())
+			case o.Scan().GetMaxWorkers() != 6:
+				t.Errorf("got %d workers, want 6", o.Scan().GetMaxWorkers())
+			case o.Scan().GetLoadThreshold() != 2.5:
+				t.Errorf("got load threshold %v, want 2.5", o.Scan().GetLoadThreshold())
+			case o.Filters().GetExcludeRegex().String() != `\.log$`:
+				t.Errorf("got exclude pattern %v", o.Filters().GetExcludeRegex())
+			case !o.Events().GetTrackRenames():
+				t.Error("renames tracking not enabled")
+			case o.Delivery().GetOverflowPolicy() != OVERFLOW_DROP:
+				t.Error("dropping overflow policy not set")
+			case o.Delivery().GetQueueSize() != DEFAULT_QUEUE_SIZE:
+				t.Errorf("got queue size %d, want the default", o.Delivery().GetQueueSize())
+			}
+		})
+	}
+}
+
+func TestOptionsFromEnvErrors(t *testing.T) {
+	tests := []struct {
+		name, value string
+	}{
+		{"CHECKSUM", "yes please"},
+		{"MAX_WORKERS", "four"},
+		{"MAX_WORKERS", "4.5"},
+		{"LOAD_THRESHOLD", "high"},
+		{"SCAN_INTERVAL", "2"},
+		{"COMPARATOR", "size"},
+		{"INCLUDE_REGEX", "("},
+		{"OWNERS", "root"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+"="+tt.value, func(t *testing.T) {
+			t.Setenv("TEST_GORSN_"+tt.name, tt.value)
+			if _, err := OptionsFromEnv("TEST_GORSN"); !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("got error %v, want ErrInvalidConfig", err)
+			}
+		})
+	}
+}
+
+func TestEnvVarsKeys(t *testing.T) {
+	for _, ev := range envVars {
+		doc := fmt.Sprintf(`{%q: {%q: null}}`, ev.group, ev.key)
+		if _, err := decodeConfig([]byte(doc)); err != nil {
+			t.Errorf("%s: %v", ev.name, err)
+		}
+	}
+}
@@ -17,6 +17,28 @@ const (
 	ErrScanIsStopping     ErrorCode = "scan notifier is stopping"
 	ErrScanIsNotReady     ErrorCode = "scan notifier is not (re)initialized"
 	ErrScanIsNotPaused    ErrorCode = "scan notifier is not paused"
+	ErrInvalidState       ErrorCode = "invalid state to import"
+	ErrPathNotFound       ErrorCode = "path is not under monitoring"
+	ErrInvalidConfig      ErrorCode = "invalid configuration"
+	ErrInvalidOptions     ErrorCode = "invalid options"
+	ErrWatchExists        ErrorCode = "watch label already registered"
+	ErrWatchNotFound      ErrorCode = "watch label not registered"
+	ErrUnknownEventName   ErrorCode = "unknown event name"
+	ErrUnknownPathType    ErrorCode = "unknown path type"
+
+	// Events errors
+	ErrAnomalyDetected      ErrorCode = "abnormal rate of changes detected"
+	ErrChecksumNotSupported ErrorCode = "checksum not supported by backend"
+	ErrChecksumFailure      ErrorCode = "error computing checksum"
+	ErrSinkFailure          ErrorCode = "error writing event to sink"
+	ErrJournalFailure       ErrorCode = "error accessing events journal"
+	ErrStateStore           ErrorCode = "error accessing items states"
+	ErrTooManyPaths         ErrorCode = "too many paths under monitoring"
+	ErrSizeThreshold        ErrorCode = "directory size over threshold"
+	ErrRootLost             ErrorCode = "root path is no longer available"
+	ErrMemoryBudget         ErrorCode = "paths cache over memory budget"
+	ErrQueueOverflow        ErrorCode = "events dropped since queue was full"
+	ErrFileLocked           ErrorCode = "file locked by another process"
 )
 
 // Error returns the real error message.
@@ -1,41 +1,244 @@
 package gorsn
 
-type eventName string
+import (
+	"fmt"
+	"io/fs"
+	"strings"
+	"time"
+)
+
+// EventName is the name of a kind of event such as `CREATE` or `DELETE`.
+type EventName string
 
 const (
-	CREATE   eventName = "CREATE"
-	MODIFY   eventName = "MODIFY"
-	DELETE   eventName = "DELETE"
-	PERM     eventName = "PERM"
-	ERROR    eventName = "ERROR"
-	NOCHANGE eventName = "NOCHANGE"
+	CREATE    EventName = "CREATE"
+	MODIFY    EventName = "MODIFY"
+	DELETE    EventName = "DELETE"
+	PERM      EventName = "PERM"
+	ERROR     EventName = "ERROR"
+	NOCHANGE  EventName = "NOCHANGE"
+	ANOMALY   EventName = "ANOMALY"
+	OWNER     EventName = "OWNER"
+	RENAME    EventName = "RENAME"
+	ATTRIB    EventName = "ATTRIB"
+	LINK      EventName = "LINK"
+	UNLINK    EventName = "UNLINK"
+	STABLE    EventName = "STABLE"
+	REPLACE   EventName = "REPLACE"
+	TRANSIENT EventName = "TRANSIENT"
+
+	SIZE_THRESHOLD EventName = "SIZE_THRESHOLD"
+	ROOT_LOST      EventName = "ROOT_LOST"
+	ROOT_RESTORED  EventName = "ROOT_RESTORED"
+	TOUCH          EventName = "TOUCH"
+
+	BUDGET_EXCEEDED EventName = "BUDGET_EXCEEDED"
+	OVERFLOW        EventName = "OVERFLOW"
+	SCAN_SUMMARY    EventName = "SCAN_SUMMARY"
+	LOCKED          EventName = "LOCKED"
+	CONFIG_RELOADED EventName = "CONFIG_RELOADED"
+	ROTATE          EventName = "ROTATE"
 )
 
-type pathType string
+// PathType is the kind of item an event is about such as `FILE` or `DIR`.
+type PathType string
 
 const (
-	FILE        pathType = "FILE"
-	DIR         pathType = "DIRECTORY"
-	SYMLINK     pathType = "SYMLINK"
-	UNSUPPORTED pathType = "UNSUPPORTED"
+	FILE        PathType = "FILE"
+	DIR         PathType = "DIRECTORY"
+	SYMLINK     PathType = "SYMLINK"
+	FIFO        PathType = "FIFO"
+	SOCKET      PathType = "SOCKET"
+	DEVICE      PathType = "DEVICE"
+	UNSUPPORTED PathType = "UNSUPPORTED"
 )
 
+// pathTypes lists the known kinds of items.
+var pathTypes = []PathType{FILE, DIR, SYMLINK, FIFO, SOCKET, DEVICE, UNSUPPORTED}
+
+func (n EventName) String() string { return string(n) }
+
+func (t PathType) String() string { return string(t) }
+
+// ParseEventName returns the event name matching `s` regardless of its case.
+// It returns an error which wraps `ErrUnknownEventName` if there is none.
+func ParseEventName(s string) (EventName, error) {
+	n := EventName(strings.ToUpper(strings.TrimSpace(s)))
+	if _, ok := eventRank[n]; !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownEventName, s)
+	}
+	return n, nil
+}
+
+// ParsePathType returns the path type matching `s` regardless of its case.
+// `DIR` is accepted as well as `DIRECTORY`. It returns an error which wraps
+// `ErrUnknownPathType` if there is none.
+func ParsePathType(s string) (PathType, error) {
+	t := PathType(strings.ToUpper(strings.TrimSpace(s)))
+	if t == "DIR" {
+		return DIR, nil
+	}
+	for _, pt := range pathTypes {
+		if t == pt {
+			return pt, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnknownPathType, s)
+}
+
 type Event struct {
-	Path  string
-	Type  pathType
-	Name  eventName
-	Error error
+	Path    string
+	Type    PathType
+	Name    EventName
+	Error   error
+	OldPath string // previous path of a `RENAME` event, another path of the file of a `LINK` or `UNLINK` one.
+	// Mode and OldMode are the current and previous modes of a `PERM` event,
+	// including the setuid, setgid and sticky bits. `Mode ^ OldMode` gives
+	// the changed bits.
+	Mode    fs.FileMode
+	OldMode fs.FileMode
+	// ChildrenCount is the number of deleted sub-items of a directory
+	// reported by a single `DELETE` event when deletes are collapsed.
+	ChildrenCount int
+	// Dropped is the number of events lost by an `OVERFLOW` event and
+	// Prefixes the sorted directories of their paths.
+	Dropped  int
+	Prefixes []string
+	// Time is when the change was detected. ModTime is the latest known
+	// modification time of the item, zero if unknown.
+	Time    time.Time
+	ModTime time.Time
+	// Severity is the classification of the event, see `SetSeverityFunc`.
+	Severity Severity
+	// Tags are the labels of the rules matching the path, see `AddTagRule`.
+	Tags []string
+	// Cycle is the sequence number of the scan cycle which detected the
+	// change, or of the latest one for the changes detected in between.
+	Cycle int
+	// Summary holds the statistics of the cycle of a `SCAN_SUMMARY` event.
+	Summary *ScanSummary
+	// Root is the monitored root path. Paths are relative to it when
+	// enabled by `SetRelativePaths`, so `filepath.Join(Root, Path)` gives
+	// the full path.
+	Root string
+	// RootLabel is the label of the root defined by `SetRootLabel` or the
+	// label of the watch of a `Manager`, so events could be routed without
+	// parsing the paths.
+	RootLabel string
+	// Checksum is the hex-encoded content digest of a regular file computed
+	// by the hash function named ChecksumAlgorithm, see `SetIncludeChecksum`.
+	Checksum          string
+	ChecksumAlgorithm string
+	// ContentType is the MIME type of a regular file sniffed from its first
+	// 512 bytes, see `SetDetectContentType`.
+	ContentType string
+	// LinkTarget is the destination of a symlink as stored and RealPath its
+	// path once all the symlinks are evaluated, empty if it could not be
+	// resolved such as for a dangling link. Set for `SYMLINK` type events.
+	LinkTarget string
+	RealPath   string
+	// Suppressed is the number of identical `MODIFY` events of the path
+	// suppressed before this event, see `SetDedupWindow`.
+	Suppressed int
+	// OldSize and NewSize are the previous and current sizes in bytes of a
+	// regular file tracked by `SetTrackAppends`. For a `MODIFY` event of a
+	// file only written at its end, the bytes from OldSize to NewSize were
+	// appended.
+	OldSize int64
+	NewSize int64
+	// Regions are the ranges of bytes of a regular file which changed with
+	// a `MODIFY` event, see `SetChangedRegions`. It is empty if the content
+	// is unchanged and nil if the regions are unknown.
+	Regions []Region
+
+	seq  uint64
+	acks *ackTracker
 }
 
 // queueEvent emits an to the queue after constructing the event.
+// Path changes events are held by the debouncer when enabled.
 func (sn *snotifier) queueEvent(ev Event) bool {
 	if !sn.running.Load() {
 		return false
 	}
-	select {
-	case sn.queue <- ev:
+	sn.stamp(&ev)
+	sn.sniff(&ev)
+	sn.resolve(&ev)
+	if sn.exprFiltered(ev) || sn.typeFiltered(ev) || sn.duplicated(&ev) {
+		return true
+	}
+	if sn.opts.delivery.debounce.Load().(time.Duration) > 0 && debounceable(ev.Name) {
+		sn.trace(traceRecord{Kind: traceEvent, Path: ev.Path, Type: ev.Type, Event: ev.Name, Detail: "debounced"})
+		sn.debounce.add(ev, sn.now())
+		return true
+	}
+	sn.trace(traceRecord{Kind: traceEvent, Path: ev.Path, Type: ev.Type, Event: ev.Name})
+	if sn.ordering.Load() {
+		sn.hold(ev)
 		return true
-	case <-sn.stop:
 	}
-	return false
+	return sn.deliver(ev)
+}
+
+// stamp sets the detection time and the latest known modification time of
+// the event. It runs on the goroutine which detected the change so that time
+// does not include the debouncing or ordering delays.
+func (sn *snotifier) stamp(ev *Event) {
+	if ev.Time.IsZero() {
+		ev.Time = sn.now()
+	}
+	if ev.Cycle == 0 {
+		ev.Cycle = int(sn.epoch.Load())
+	}
+	if v, ok := sn.paths.Load(ev.Path); ok && ev.ModTime.IsZero() {
+		ev.ModTime = v.(*pathInfos).state().ModTime
+	}
+}
+
+// deliver writes the event to the sinks and sends it to the queue, followed
+// by the `ERROR` events of the sinks which failed to write it.
+func (sn *snotifier) deliver(ev Event) bool {
+	if ev.Time.IsZero() {
+		ev.Time = sn.now()
+	}
+	// the tags are matched against the paths before their conversion.
+	ev = sn.classify(sn.formPaths(sn.tag(ev)))
+	if allowed, alive := sn.limit(ev); !allowed {
+		return alive
+	}
+	failures := sn.sink(ev)
+	if !sn.dispatch(ev) {
+		return false
+	}
+	for _, failure := range failures {
+		if !sn.dispatch(failure) {
+			return false
+		}
+	}
+	return true
+}
+
+// dispatch sends the event to the queue and the subscriptions and records it.
+func (sn *snotifier) dispatch(ev Event) bool {
+	if timeout := sn.opts.delivery.ackTimeout.Load().(time.Duration); timeout > 0 {
+		ev = sn.acks.track(ev, sn.now().Add(timeout))
+	}
+	subs := sn.subscriptions()
+	sent := true
+	if !sn.opts.delivery.subsOnly.Load() {
+		var alive bool
+		if sent, alive = sn.send(sn.queue, &sn.overflow, ev); !alive {
+			return false
+		}
+	}
+	if !sn.publish(ev, subs) {
+		return false
+	}
+	sn.emitted.Add(1)
+	sn.counts.add(ev)
+	if sent {
+		sn.delivered.Add(1)
+	}
+	sn.history.add(ev, int(sn.opts.delivery.history.Load()), sn.now())
+	return true
 }
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/jeamon/gorsn"
+)
+
+// run monitors the folder given by `args` with the settings of the file named
+// by the `-config` flag, or else of the `GORSN_` environment variables, and
+// prints each event to `out` as a line of text or JSON like a command line
+// watcher. It closes `ready` once the initial content is known and returns
+// once `stop` is closed. With the `-version` flag, it prints the version and
+// the build informations of the library then returns at once.
+func run(args []string, out io.Writer, ready chan<- struct{}, stop <-chan struct{}) error {
+	// step 2. parse the command line.
+	flags := flag.NewFlagSet("gorsn", flag.ContinueOnError)
+	config := flags.String("config", "", "path of a JSON or YAML configuration file")
+	format := flags.String("format", "text", "output format of the events: text or json")
+	version := flags.Bool("version", false, "print the version and the build informations then exit")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *version {
+		b := gorsn.BuildInfo()
+		fmt.Fprintf(out, "gorsn %s\nmodule: %s\nsum: %s\ngo: %s\nschema: %d\n",
+			gorsn.Version(), b.Module, b.Sum, b.GoVersion, b.SchemaVersion)
+		return nil
+	}
+	if flags.NArg() != 1 {
+		return errors.New("usage: gorsn [-version] [-config file] [-format text|json] <folder>")
+	}
+
+	// step 3. load the options from the configuration file or the environment.
+	var opts *gorsn.Options
+	var err error
+	if *config != "" {
+		opts, err = gorsn.OptionsFromFile(*config)
+	} else {
+		opts, err = gorsn.OptionsFromEnv("GORSN")
+	}
+	if err != nil {
+		return err
+	}
+	switch *format {
+	case "text":
+	case "json":
+		// the audit log sink writes each event as a JSON line.
+		opts.Delivery().AddSink(gorsn.NewAuditLogSink(out))
+	default:
+		return fmt.Errorf("unknown output format %q", *format)
+	}
+	var once sync.Once
+	opts.Scan().SetAfterScan(func(int, gorsn.ScanSummary) { once.Do(func() { close(ready) }) })
+
+	// step 4. get an instance.
+	sn, err := gorsn.New(flags.Arg(0), opts)
+	if err != nil {
+		return err
+	}
+
+	// step 5. print the events received from the queue.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for ev := range sn.Queue() {
+			if *format == "text" {
+				fmt.Fprintf(out, "%s %s %s\n", ev.Name, ev.Type, ev.Path)
+			}
+		}
+	}()
+
+	go func() {
+		<-stop
+		sn.Stop()
+	}()
+
+	// step 6. start the scan notifier. it blocks until stopped.
+	err = sn.Start(context.Background())
+	<-done
+	return err
+}
+
+func main() {
+	// step 1. stop the scan notifier on CTRL+C.
+	stop := make(chan struct{})
+	go func() {
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGQUIT,
+			syscall.SIGTERM, syscall.SIGHUP, os.Interrupt)
+
+		<-sigChan
+		signal.Stop(sigChan)
+		close(stop)
+	}()
+
+	if err := run(os.Args[1:], os.Stdout, make(chan struct{}), stop); err != nil {
+		log.Fatal(err)
+	}
+}
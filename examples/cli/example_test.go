@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jeamon/gorsn"
+)
+
+// output is a buffer safe for concurrent use.
+type output struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (o *output) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.buf.Write(p)
+}
+
+func (o *output) String() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.buf.String()
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"text", "CREATE FILE %s\n"},
+		{"json", `"path":%q,"type":"FILE","event":"CREATE"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			root := t.TempDir()
+			config := filepath.Join(t.TempDir(), "gorsn.yaml")
+			if err := os.WriteFile(config, []byte("scan:\n  interval: 10ms\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			out := &output{}
+			ready, stop := make(chan struct{}), make(chan struct{})
+			errc := make(chan error, 1)
+			go func() { errc <- run([]string{"-config", config, "-format", tt.format, root}, out, ready, stop) }()
+			select {
+			case <-ready:
+			case err := <-errc:
+				t.Fatal(err)
+			}
+
+			name := filepath.Join(root, "notes.txt")
+			if err := os.WriteFile(name, nil, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			want := fmt.Sprintf(tt.want, name)
+			deadline := time.Now().Add(5 * time.Second)
+			for !strings.Contains(out.String(), want) && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+			close(stop)
+			if err := <-errc; err != nil {
+				t.Fatal(err)
+			}
+			if got := out.String(); !strings.Contains(got, want) {
+				t.Errorf("got output %q, want the line %q", got, want)
+			}
+		})
+	}
+}
+
+func TestRunVersion(t *testing.T) {
+	out := &output{}
+	if err := run([]string{"-version"}, out, make(chan struct{}), nil); err != nil {
+		t.Fatal(err)
+	}
+	want := fmt.Sprintf("gorsn %s\nmodule: %s\n", gorsn.Version(), gorsn.BuildInfo().Module)
+	if got := out.String(); !strings.HasPrefix(got, want) || !strings.Contains(got, fmt.Sprintf("schema: %d\n", gorsn.SchemaVersion)) {
+		t.Errorf("got output %q, want the version and the build informations", got)
+	}
+}
+
+func TestRunUsage(t *testing.T) {
+	for _, args := range [][]string{nil, {"-format", "xml", t.TempDir()}, {"-unknown"}} {
+		if err := run(args, &output{}, make(chan struct{}), nil); err == nil {
+			t.Errorf("got no error for the arguments %q", args)
+		}
+	}
+}
@@ -11,53 +11,71 @@ import (
 	"github.com/jeamon/gorsn"
 )
 
-func main() {
-	// step 1. define a path to a valid folder.
-	root, err := os.Getwd() // lets use this package folder
-	if err != nil {
-		log.Fatal(err)
-	}
-
+// run monitors `root` with custom options until `stop` is closed and
+// returns the received events.
+func run(root string, stop <-chan struct{}) ([]gorsn.Event, error) {
 	// step 2. define own custom options.
 	excludeRule := regexp.MustCompile(`.*(\.git).*`)
 	// exclude `.git` folder. No include rule.
-	opts := gorsn.RegexOpts(excludeRule, nil).
-		// 5 events can wait into the queue.
-		SetQueueSize(5).
+	opts := gorsn.RegexOpts(excludeRule, nil)
+	// 5 events can wait into the queue.
+	opts.Delivery().SetQueueSize(5)
+	opts.Scan().
 		// 2 goroutines to build & emit events.
 		SetMaxWorkers(2).
 		// 0 sec - immediate, so no delay to scan.
-		SetScanInterval(0)
-		// others options keep their default values.
+		SetInterval(0)
+	// others options keep their default values.
 
 	// step 3. get an instance based on above settings.
 	sn, err := gorsn.New(root, opts)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
-	// [optional] stop the scan notifier on CTRL+C.
 	go func() {
-		sigChan := make(chan os.Signal, 1)
-		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGQUIT,
-			syscall.SIGTERM, syscall.SIGHUP, os.Interrupt)
-
-		<-sigChan
-		signal.Stop(sigChan)
+		<-stop
 		sn.Stop()
 	}()
 
 	// step 4. asynchronously receive events from the queue.
+	var events []gorsn.Event
+	done := make(chan struct{})
 	go func() {
+		defer close(done)
 		for event := range sn.Queue() {
 			log.Printf("received %q %s %s %v\n", event.Path, event.Type, event.Name, event.Error)
+			events = append(events, event)
 		}
 	}()
 
 	// step 5. start the scan notifier on the defined path.
 	// this line blocks unless it fails or explicitly stopped.
 	err = sn.Start(context.Background())
+	<-done
+	return events, err
+}
+
+func main() {
+	// step 1. define a path to a valid folder.
+	root, err := os.Getwd() // lets use this package folder
 	if err != nil {
 		log.Fatal(err)
 	}
+
+	// [optional] stop the scan notifier on CTRL+C.
+	stop := make(chan struct{})
+	go func() {
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGQUIT,
+			syscall.SIGTERM, syscall.SIGHUP, os.Interrupt)
+
+		<-sigChan
+		signal.Stop(sigChan)
+		close(stop)
+	}()
+
+	if _, err = run(root, stop); err != nil {
+		log.Fatal(err)
+	}
 }
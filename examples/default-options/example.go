@@ -11,54 +11,69 @@ import (
 	"github.com/jeamon/gorsn"
 )
 
-func main() {
-	// step 1. define a path to a valid folder.
-	root, err := os.Getwd() // lets use this package folder
-	if err != nil {
-		log.Fatal(err)
-	}
-
+// run monitors `root` with the default options until `stop` is closed and
+// returns the received events.
+func run(root string, stop <-chan struct{}) ([]gorsn.Event, error) {
 	// step 2. use default options.
 	var opts gorsn.Options
 
 	// step 3. get an instance.
 	sn, err := gorsn.New(root, &opts)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
-	// [optional] stop the scan notifier on CTRL+C.
-	go func() {
-		sigChan := make(chan os.Signal, 1)
-		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGQUIT,
-			syscall.SIGTERM, syscall.SIGHUP, os.Interrupt)
-
-		<-sigChan
-		signal.Stop(sigChan)
-		sn.Stop()
-	}()
-
 	// step 4. asynchronously receive events from the queue.
+	var events []gorsn.Event
+	done := make(chan struct{})
 	go func() {
+		defer close(done)
 		for event := range sn.Queue() {
 			log.Printf("received %q %s %s %v\n", event.Path, event.Type, event.Name, event.Error)
+			events = append(events, event)
 		}
 	}()
 
-	done := make(chan struct{})
-	// step 5. start the scan notifier on the defined path.
-	// lets run it as goroutine to bypass the blocking behavior.
-	go func() {
-		err = sn.Start(context.Background())
-		if err != nil {
-			log.Fatal(err)
-		}
-		done <- struct{}{}
-	}()
+	// step 5. start the scan notifier on the defined path
+	// into the background.
+	if err = sn.StartAsync(context.Background()); err != nil {
+		return nil, err
+	}
 
 	// lets change the settings on the fly.
-	opts.SetMaxWorkers(10).SetScanInterval(500 * time.Millisecond)
+	opts.Scan().SetMaxWorkers(10).SetInterval(500 * time.Millisecond)
+
+	go func() {
+		<-stop
+		sn.Stop()
+	}()
 
 	// wait the runner to exit
+	err = sn.Wait()
 	<-done
+	return events, err
+}
+
+func main() {
+	// step 1. define a path to a valid folder.
+	root, err := os.Getwd() // lets use this package folder
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// [optional] stop the scan notifier on CTRL+C.
+	stop := make(chan struct{})
+	go func() {
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGQUIT,
+			syscall.SIGTERM, syscall.SIGHUP, os.Interrupt)
+
+		<-sigChan
+		signal.Stop(sigChan)
+		close(stop)
+	}()
+
+	if _, err = run(root, stop); err != nil {
+		log.Fatal(err)
+	}
 }
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jeamon/gorsn"
+)
+
+func TestRun(t *testing.T) {
+	root := t.TempDir()
+	stop := make(chan struct{})
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		os.WriteFile(filepath.Join(root, "a.txt"), []byte("a"), 0o644)
+		time.Sleep(1500 * time.Millisecond)
+		close(stop)
+	}()
+	events, err := run(root, stop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Name != gorsn.CREATE || events[0].Path != filepath.Join(root, "a.txt") {
+		t.Errorf("got events %v, want the creation of a.txt", events)
+	}
+}
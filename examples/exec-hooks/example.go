@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/jeamon/gorsn"
+	"github.com/jeamon/gorsn/actions"
+)
+
+// run executes the program `args` for each change of a text file of `root`
+// and returns the outcome of each run.
+func run(root string, args ...string) ([]actions.Report, error) {
+	// step 2. get an instance which signals the end of its first scan.
+	ready := make(chan struct{})
+	var once sync.Once
+	opts := &gorsn.Options{}
+	opts.Scan().
+		SetInterval(100 * time.Millisecond).
+		SetAfterScan(func(int, gorsn.ScanSummary) { once.Do(func() { close(ready) }) })
+	sn, err := gorsn.New(root, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// step 3. the queue must still be consumed.
+	go func() {
+		for range sn.Queue() {
+		}
+	}()
+
+	// step 4. run the program for the text files changes,
+	// killing it if it takes longer than 5 seconds.
+	ran := make(chan actions.Report, 10)
+	cmd := actions.NewCommand(args...).
+		On(gorsn.CREATE, gorsn.MODIFY, gorsn.DELETE).
+		Filter(gorsn.FilterPaths(regexp.MustCompile(`\.txt$`))).
+		SetTimeout(5 * time.Second).
+		OnReport(func(r actions.Report) {
+			log.Printf("ran %s for %s %q: %s %v\n", r.Target, r.Event.Name, r.Event.Path, r.Output, r.Err)
+			ran <- r
+		})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		cmd.Run(context.Background(), sn)
+	}()
+
+	// step 5. once the initial content is known, make some changes and
+	// wait for the program to run for each of them, then stop.
+	var reports []actions.Report
+	var failure error
+	go func() {
+		defer sn.Stop()
+		<-ready
+		name := filepath.Join(root, "notes.txt")
+		if failure = writeFile(name, "v1"); failure != nil {
+			return
+		}
+		if failure = writeFile(filepath.Join(root, "image.png"), "v1"); failure != nil {
+			return
+		}
+		if failure = await(ran, &reports); failure != nil {
+			return
+		}
+		if failure = os.Remove(name); failure != nil {
+			return
+		}
+		failure = await(ran, &reports)
+	}()
+
+	// step 6. start the scan notifier. it blocks until stopped.
+	if err = sn.Start(context.Background()); err != nil {
+		return nil, err
+	}
+	// wait for the running programs.
+	<-done
+	return reports, failure
+}
+
+// writeFile writes the content to a temporary file renamed into place, so
+// a scan never sees the file partially written.
+func writeFile(name, content string) error {
+	tmp := name + ".tmp"
+	if err := os.WriteFile(tmp, []byte(content), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, name)
+}
+
+// await appends the next report of the program to `reports`.
+func await(ran <-chan actions.Report, reports *[]actions.Report) error {
+	select {
+	case r := <-ran:
+		*reports = append(*reports, r)
+		return nil
+	case <-time.After(10 * time.Second):
+		return errors.New("timed out waiting for the program to run")
+	}
+}
+
+func main() {
+	// step 1. lets use a temporary folder as playground.
+	root, err := os.MkdirTemp("", "gorsn-hooks-")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	if _, err = run(root, "echo", "{event}", "{path}"); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	if _, err := exec.LookPath("echo"); err != nil {
+		t.Skip("no echo program:", err)
+	}
+	root := t.TempDir()
+	reports, err := run(root, "echo", "{event}", "{path}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range reports {
+		if r.Err != nil {
+			t.Errorf("run for %s failed: %v", r.Event.Path, r.Err)
+		}
+		got = append(got, strings.TrimSpace(string(r.Output)))
+	}
+	name := filepath.Join(root, "notes.txt")
+	if want := []string{"CREATE " + name, "DELETE " + name}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got outputs %q, want %q", got, want)
+	}
+}
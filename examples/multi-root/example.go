@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jeamon/gorsn"
+)
+
+// run watches the `roots` keyed by their label for a second, makes some
+// changes into each one and returns the merged events received.
+func run(roots map[string]string) ([]gorsn.LabeledEvent, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// step 2. register one watch per root into a manager.
+	manager := gorsn.NewManager(10)
+	for name, dir := range roots {
+		opts := &gorsn.Options{}
+		opts.Scan().SetInterval(100 * time.Millisecond)
+		if _, err := manager.Add(name, dir, opts); err != nil {
+			return nil, err
+		}
+	}
+
+	// step 3. start all the watches. stops on context cancellation.
+	go manager.Start(ctx)
+
+	// step 4. make some changes into each root.
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		for name, dir := range roots {
+			os.WriteFile(filepath.Join(dir, name+".txt"), []byte(name), 0o644)
+		}
+	}()
+
+	// step 5. consume the merged stream.
+	var events []gorsn.LabeledEvent
+	for le := range manager.Queue() {
+		log.Printf("[%s] received %q %s %s\n", le.Label, le.Path, le.Type, le.Name)
+		events = append(events, le)
+	}
+	return events, nil
+}
+
+func main() {
+	// step 1. lets use two temporary folders as playground.
+	roots := map[string]string{}
+	for _, name := range []string{"tenant-a", "tenant-b"} {
+		dir, err := os.MkdirTemp("", "gorsn-"+name+"-")
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		roots[name] = dir
+	}
+
+	if _, err := run(roots); err != nil {
+		log.Fatal(err)
+	}
+}
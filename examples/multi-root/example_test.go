@@ -0,0 +1,27 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jeamon/gorsn"
+)
+
+func TestRun(t *testing.T) {
+	roots := map[string]string{"tenant-a": t.TempDir(), "tenant-b": t.TempDir()}
+	events, err := run(roots)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for _, le := range events {
+		if le.Name == gorsn.CREATE {
+			got[le.Label] = le.Path
+		}
+	}
+	for name, root := range roots {
+		if want := filepath.Join(root, name+".txt"); got[name] != want {
+			t.Errorf("got creation of %q for %s, want %q", got[name], name, want)
+		}
+	}
+}
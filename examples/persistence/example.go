@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/jeamon/gorsn"
+)
+
+// watch starts the scan notifier, logs its events and stops it after `d`.
+// It returns the events received and the state exported right before the stop.
+func watch(name string, sn gorsn.ScanNotifier, d time.Duration) ([]gorsn.Event, *gorsn.State, error) {
+	var events []gorsn.Event
+	var state *gorsn.State
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for event := range sn.Queue() {
+			log.Printf("[%s] received %q %s %s\n", name, event.Path, event.Type, event.Name)
+			events = append(events, event)
+		}
+	}()
+	go func() {
+		time.Sleep(d)
+		state = sn.Export()
+		sn.Stop()
+	}()
+	err := sn.Start(context.Background())
+	<-done
+	return events, state, err
+}
+
+// run hands off the monitoring of `root` from an instance to another one
+// and returns the events received by the second instance.
+func run(root string) ([]gorsn.Event, error) {
+	os.WriteFile(filepath.Join(root, "kept.txt"), []byte("v1"), 0o644)
+	os.WriteFile(filepath.Join(root, "removed.txt"), []byte("v1"), 0o644)
+
+	// step 2. run a first instance for a while and
+	// export its state right before it is stopped.
+	opts := &gorsn.Options{}
+	opts.Scan().SetInterval(100 * time.Millisecond)
+	first, err := gorsn.New(root, opts)
+	if err != nil {
+		return nil, err
+	}
+	_, state, err := watch("first", first, 300*time.Millisecond)
+	if err != nil {
+		return nil, err
+	}
+
+	// step 3. changes happening while no instance is running.
+	time.Sleep(10 * time.Millisecond)
+	os.WriteFile(filepath.Join(root, "kept.txt"), []byte("v2"), 0o644)
+	os.Remove(filepath.Join(root, "removed.txt"))
+	os.WriteFile(filepath.Join(root, "new.log"), []byte("v1"), 0o644)
+
+	// step 4. hand off to a new instance with different filters.
+	// the changes made in between are notified on its first scan.
+	opts = gorsn.RegexOpts(regexp.MustCompile(`.*\.log$`), nil)
+	opts.Scan().SetInterval(100 * time.Millisecond)
+	opts.Persistence().SetState(state)
+	second, err := gorsn.New(root, opts)
+	if err != nil {
+		return nil, err
+	}
+	events, _, err := watch("second", second, 300*time.Millisecond)
+	return events, err
+}
+
+func main() {
+	// step 1. lets use a temporary folder as playground.
+	root, err := os.MkdirTemp("", "gorsn-handoff-")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	if _, err = run(root); err != nil {
+		log.Fatal(err)
+	}
+}
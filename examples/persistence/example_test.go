@@ -0,0 +1,26 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jeamon/gorsn"
+)
+
+func TestRun(t *testing.T) {
+	root := t.TempDir()
+	events, err := run(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]gorsn.EventName{}
+	for _, ev := range events {
+		if ev.Type == gorsn.FILE {
+			got[filepath.Base(ev.Path)] = ev.Name
+		}
+	}
+	want := map[string]gorsn.EventName{"kept.txt": gorsn.MODIFY, "removed.txt": gorsn.DELETE}
+	if len(got) != len(want) || got["kept.txt"] != want["kept.txt"] || got["removed.txt"] != want["removed.txt"] {
+		t.Errorf("got events %v of the second instance, want %v", got, want)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jeamon/gorsn"
+)
+
+// run records the events of some changes into `root` into a journal kept
+// under `journal` then returns them replayed from the journal files.
+func run(root, journal string) ([]gorsn.Event, error) {
+	// step 2. record each event into journal files of 1 KiB at most.
+	j, err := gorsn.NewJournal(journal, 1024, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer j.Close()
+	opts := &gorsn.Options{}
+	opts.Scan().SetInterval(100 * time.Millisecond)
+	opts.Delivery().AddSink(j)
+
+	// step 3. get an instance.
+	sn, err := gorsn.New(root, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// step 4. the consumer crashes so the events are lost.
+	go func() {
+		for range sn.Queue() {
+		}
+	}()
+
+	// step 5. make some changes then stop.
+	start := time.Now()
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		// files are created empty so a scan could not see them half written.
+		for _, name := range []string{"a.txt", "b.txt"} {
+			os.WriteFile(filepath.Join(root, name), nil, 0o644)
+		}
+		time.Sleep(300 * time.Millisecond)
+		os.Remove(filepath.Join(root, "a.txt"))
+		time.Sleep(300 * time.Millisecond)
+		sn.Stop()
+	}()
+	if err = sn.Start(context.Background()); err != nil {
+		return nil, err
+	}
+
+	// step 6. a restarted consumer replays what it missed since `start`.
+	events, err := gorsn.ReplayJournal(journal, start, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range events {
+		log.Printf("replayed %q %s %s at %s\n", event.Path, event.Type, event.Name, event.Time.Format(time.RFC3339Nano))
+	}
+	return events, nil
+}
+
+func main() {
+	// step 1. lets use temporary folders as playground and journal.
+	root, err := os.MkdirTemp("", "gorsn-replay-")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	journal, err := os.MkdirTemp("", "gorsn-journal-")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(journal)
+
+	if _, err = run(root, journal); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jeamon/gorsn"
+)
+
+func TestRun(t *testing.T) {
+	events, err := run(t.TempDir(), t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, ev := range events {
+		if ev.Type == gorsn.FILE {
+			got = append(got, string(ev.Name)+" "+filepath.Base(ev.Path))
+		}
+	}
+	want := []string{"CREATE a.txt", "CREATE b.txt", "DELETE a.txt"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got replayed events %q, want %q", got, want)
+	}
+}
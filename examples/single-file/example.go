@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jeamon/gorsn"
+)
+
+// run monitors a config file created into `dir`, updates it then returns
+// the successive contents reloaded.
+func run(dir string) ([]string, error) {
+	config := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(config, []byte(`{"level":"info"}`), 0o644); err != nil {
+		return nil, err
+	}
+
+	// step 2. monitor only that file.
+	opts := &gorsn.Options{}
+	opts.Scan().SetInterval(100 * time.Millisecond)
+	sn, err := gorsn.New(config, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// step 3. reload the config on each change.
+	var reloaded []string
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for event := range sn.Queue() {
+			if event.Name == gorsn.MODIFY || event.Name == gorsn.CREATE {
+				data, _ := os.ReadFile(event.Path)
+				log.Printf("reloaded config: %s\n", data)
+				reloaded = append(reloaded, string(data))
+				continue
+			}
+			log.Printf("received %q %s %s\n", event.Path, event.Type, event.Name)
+		}
+	}()
+
+	// step 4. update the config then stop.
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		os.WriteFile(config, []byte(`{"level":"debug"}`), 0o644)
+		time.Sleep(300 * time.Millisecond)
+		sn.Stop()
+	}()
+
+	// step 5. start the scan notifier. it blocks until stopped.
+	err = sn.Start(context.Background())
+	<-done
+	return reloaded, err
+}
+
+func main() {
+	// step 1. lets use a temporary folder for the config file.
+	dir, err := os.MkdirTemp("", "gorsn-config-")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if _, err = run(dir); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,16 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	reloaded, err := run(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{`{"level":"debug"}`}; !reflect.DeepEqual(reloaded, want) {
+		t.Errorf("got reloaded configs %q, want %q", reloaded, want)
+	}
+}
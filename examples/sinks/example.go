@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/jeamon/gorsn"
+)
+
+// counter is a custom sink which counts the events per name.
+type counter struct {
+	creates atomic.Int64
+	deletes atomic.Int64
+}
+
+func (c *counter) Write(ev gorsn.Event) error {
+	switch ev.Name {
+	case gorsn.CREATE:
+		c.creates.Add(1)
+	case gorsn.DELETE:
+		c.deletes.Add(1)
+	}
+	return nil
+}
+
+// run makes some changes into `root` and returns the counter sink
+// which received the events.
+func run(root string) (*counter, error) {
+	// step 2. write each event as JSON line on stdout
+	// and into our own counter sink.
+	c := &counter{}
+	opts := &gorsn.Options{}
+	opts.Scan().SetInterval(100 * time.Millisecond)
+	opts.Delivery().
+		AddSink(gorsn.NewAuditLogSink(os.Stdout)).
+		AddSink(c)
+
+	// step 3. get an instance.
+	sn, err := gorsn.New(root, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// step 4. the queue must still be consumed.
+	go func() {
+		for range sn.Queue() {
+		}
+	}()
+
+	// step 5. make some changes then stop.
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+			os.WriteFile(filepath.Join(root, name), []byte(name), 0o644)
+		}
+		time.Sleep(300 * time.Millisecond)
+		os.Remove(filepath.Join(root, "b.txt"))
+		time.Sleep(300 * time.Millisecond)
+		sn.Stop()
+	}()
+
+	// step 6. start the scan notifier. it blocks until stopped.
+	if err = sn.Start(context.Background()); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func main() {
+	// step 1. lets use a temporary folder as playground.
+	root, err := os.MkdirTemp("", "gorsn-sinks-")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	c, err := run(root)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("created: %d - deleted: %d\n", c.creates.Load(), c.deletes.Load())
+}
@@ -0,0 +1,13 @@
+package main
+
+import "testing"
+
+func TestRun(t *testing.T) {
+	c, err := run(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creates, deletes := c.creates.Load(), c.deletes.Load(); creates != 3 || deletes != 1 {
+		t.Errorf("got %d creates and %d deletes, want 3 and 1", creates, deletes)
+	}
+}
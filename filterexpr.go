@@ -0,0 +1,475 @@
+package gorsn
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// filterExpr is a compiled event filtering expression.
+type filterExpr struct {
+	src  string
+	eval func(*exprEnv) any
+}
+
+// exprEnv holds the fields an expression is evaluated against.
+type exprEnv struct {
+	ev   Event
+	size int64
+}
+
+// exprFields are the fields an expression could refer to.
+var exprFields = map[string]func(*exprEnv) any{
+	"path":  func(e *exprEnv) any { return e.ev.Path },
+	"type":  func(e *exprEnv) any { return string(e.ev.Type) },
+	"name":  func(e *exprEnv) any { return string(e.ev.Name) },
+	"size":  func(e *exprEnv) any { return e.size },
+	"mtime": func(e *exprEnv) any { return e.ev.ModTime },
+}
+
+// SetEventFilterExpr defines an expression which the events must satisfy to be
+// emitted, evaluated after the other filtering options. It refers to the fields
+// `path` (slash-separated and relative to the root directory, empty for the
+// root itself), `type`, `name`, `size` (latest known size in bytes) and `mtime`
+// with the operators `==`, `!=`, `<`, `<=`, `>`, `>=`, `&&`, `||`, `!` and `in`,
+// the string methods `startsWith`, `endsWith`, `contains` and `matches` (regular
+// expression) and the string, integer, boolean and list literals. Strings are
+// double-quoted with Go escapes or single-quoted without escapes. The `mtime`
+// is compared with RFC 3339 strings. For example:
+//
+//	name in ["CREATE", "MODIFY"] && path.endsWith(".go") && size < 1048576
+//	!(type == "DIRECTORY") || mtime > "2024-01-01T00:00:00Z"
+//	path.matches('^src/.*\.(c|h)$')
+//
+// An empty expression removes the filter. It returns an error which wraps
+// `ErrInvalidOptions` if the expression could not be compiled.
+func (eo *EventOptions) SetEventFilterExpr(expr string) error {
+	if strings.TrimSpace(expr) == "" {
+		eo.filter.Store((*filterExpr)(nil))
+		return nil
+	}
+	f, err := compileFilterExpr(expr)
+	if err != nil {
+		return fmt.Errorf("%w: filter expression: %v", ErrInvalidOptions, err)
+	}
+	eo.filter.Store(f)
+	return nil
+}
+
+// filterExpr returns the compiled filtering expression if any.
+func (eo *EventOptions) filterExpr() *filterExpr {
+	f, _ := eo.filter.Load().(*filterExpr)
+	return f
+}
+
+// accepts reports whether the event of an item of `size` bytes satisfies the
+// expression. An expression which does not evaluate to a boolean, like a
+// comparison of values of different types, rejects the event.
+func (f *filterExpr) accepts(ev Event, size int64) bool {
+	ok, _ := f.eval(&exprEnv{ev: ev, size: size}).(bool)
+	return ok
+}
+
+// exprFiltered reports whether the event is rejected by the filtering
+// expression. It must be called by the goroutine which detected the change.
+func (sn *snotifier) exprFiltered(ev Event) bool {
+	f := sn.opts.events.filterExpr()
+	if f == nil {
+		return false
+	}
+	var size int64
+	if val, ok := sn.paths.Load(ev.Path); ok {
+		size = val.(*pathInfos).size
+	}
+	ev.Path = sn.rel(ev.Path)
+	if normalize := sn.opts.events.pathNormalizer(); normalize != nil {
+		ev.Path = normalize(ev.Path)
+	}
+	return !f.accepts(ev, size)
+}
+
+func compileFilterExpr(src string) (*filterExpr, error) {
+	toks, err := lexExpr(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{toks: toks}
+	eval, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at offset %d", p.peek().text, p.peek().pos)
+	}
+	return &filterExpr{src: src, eval: eval}, nil
+}
+
+type tokKind int
+
+const (
+	tokEOF tokKind = iota
+	tokIdent
+	tokString
+	tokInt
+	tokOp
+)
+
+type exprToken struct {
+	kind tokKind
+	text string
+	pos  int
+}
+
+// lexExpr splits the expression into tokens.
+func lexExpr(src string) ([]exprToken, error) {
+	var toks []exprToken
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			j := i + 1
+			for j < len(src) && (src[j] == '_' || src[j] >= 'a' && src[j] <= 'z' || src[j] >= 'A' && src[j] <= 'Z' || src[j] >= '0' && src[j] <= '9') {
+				j++
+			}
+			toks = append(toks, exprToken{tokIdent, src[i:j], i})
+			i = j
+		case c >= '0' && c <= '9':
+			j := i + 1
+			for j < len(src) && src[j] >= '0' && src[j] <= '9' {
+				j++
+			}
+			toks = append(toks, exprToken{tokInt, src[i:j], i})
+			i = j
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(src) && src[j] != c {
+				if src[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("unterminated string at offset %d", i)
+			}
+			// single-quoted strings are raw, handy for the patterns.
+			s := strings.ReplaceAll(src[i+1:j], `\'`, `'`)
+			if c == '"' {
+				var err error
+				if s, err = strconv.Unquote(src[i : j+1]); err != nil {
+					return nil, fmt.Errorf("invalid string at offset %d", i)
+				}
+			}
+			toks = append(toks, exprToken{tokString, s, i})
+			i = j + 1
+		default:
+			op := ""
+			for _, o := range []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "!", "(", ")", "[", "]", ",", "."} {
+				if strings.HasPrefix(src[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected %q at offset %d", c, i)
+			}
+			toks = append(toks, exprToken{tokOp, op, i})
+			i += len(op)
+		}
+	}
+	return append(toks, exprToken{tokEOF, "end of expression", len(src)}), nil
+}
+
+type exprParser struct {
+	toks []exprToken
+	i    int
+}
+
+func (p *exprParser) peek() exprToken { return p.toks[p.i] }
+
+func (p *exprParser) next() exprToken {
+	t := p.toks[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+// accept consumes the operator `op` if it is the next token.
+func (p *exprParser) accept(op string) bool {
+	if t := p.peek(); t.kind == tokOp && t.text == op {
+		p.i++
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) expect(op string) error {
+	if !p.accept(op) {
+		t := p.peek()
+		return fmt.Errorf("expected %q instead of %q at offset %d", op, t.text, t.pos)
+	}
+	return nil
+}
+
+func (p *exprParser) or() (func(*exprEnv) any, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(e *exprEnv) any {
+			if b, _ := l(e).(bool); b {
+				return true
+			}
+			b, _ := right(e).(bool)
+			return b
+		}
+	}
+	return left, nil
+}
+
+func (p *exprParser) and() (func(*exprEnv) any, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(e *exprEnv) any {
+			if b, _ := l(e).(bool); !b {
+				return false
+			}
+			b, _ := right(e).(bool)
+			return b
+		}
+	}
+	return left, nil
+}
+
+func (p *exprParser) unary() (func(*exprEnv) any, error) {
+	if p.accept("!") {
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return func(e *exprEnv) any {
+			b, ok := operand(e).(bool)
+			return ok && !b
+		}, nil
+	}
+	return p.comparison()
+}
+
+func (p *exprParser) comparison() (func(*exprEnv) any, error) {
+	left, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind == tokIdent && t.text == "in" {
+		p.next()
+		list, err := p.primary()
+		if err != nil {
+			return nil, err
+		}
+		return func(e *exprEnv) any {
+			items, _ := list(e).([]any)
+			v := left(e)
+			for _, item := range items {
+				if c, ok := compareValues(v, item); ok && c == 0 {
+					return true
+				}
+			}
+			return false
+		}, nil
+	}
+	t := p.peek()
+	if t.kind != tokOp {
+		return left, nil
+	}
+	var test func(int) bool
+	switch t.text {
+	case "==":
+		test = func(c int) bool { return c == 0 }
+	case "!=":
+		test = func(c int) bool { return c != 0 }
+	case "<":
+		test = func(c int) bool { return c < 0 }
+	case "<=":
+		test = func(c int) bool { return c <= 0 }
+	case ">":
+		test = func(c int) bool { return c > 0 }
+	case ">=":
+		test = func(c int) bool { return c >= 0 }
+	default:
+		return left, nil
+	}
+	p.next()
+	right, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	return func(e *exprEnv) any {
+		c, ok := compareValues(left(e), right(e))
+		return ok && test(c)
+	}, nil
+}
+
+func (p *exprParser) primary() (func(*exprEnv) any, error) {
+	t := p.next()
+	var value func(*exprEnv) any
+	switch {
+	case t.kind == tokOp && t.text == "(":
+		inner, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		value = inner
+	case t.kind == tokOp && t.text == "[":
+		var items []func(*exprEnv) any
+		for !p.accept("]") {
+			if len(items) > 0 {
+				if err := p.expect(","); err != nil {
+					return nil, err
+				}
+			}
+			item, err := p.primary()
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		value = func(e *exprEnv) any {
+			list := make([]any, len(items))
+			for i, item := range items {
+				list[i] = item(e)
+			}
+			return list
+		}
+	case t.kind == tokString:
+		s := t.text
+		value = func(*exprEnv) any { return s }
+	case t.kind == tokInt:
+		n, err := strconv.ParseInt(t.text, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer %q at offset %d", t.text, t.pos)
+		}
+		value = func(*exprEnv) any { return n }
+	case t.kind == tokIdent && (t.text == "true" || t.text == "false"):
+		b := t.text == "true"
+		value = func(*exprEnv) any { return b }
+	case t.kind == tokIdent:
+		field, ok := exprFields[t.text]
+		if !ok {
+			return nil, fmt.Errorf("unknown field %q at offset %d", t.text, t.pos)
+		}
+		value = field
+	default:
+		return nil, fmt.Errorf("unexpected %q at offset %d", t.text, t.pos)
+	}
+	for p.accept(".") {
+		m, err := p.method(value)
+		if err != nil {
+			return nil, err
+		}
+		value = m
+	}
+	return value, nil
+}
+
+// method parses the call of a string method on `recv`.
+func (p *exprParser) method(recv func(*exprEnv) any) (func(*exprEnv) any, error) {
+	name := p.next()
+	if name.kind != tokIdent {
+		return nil, fmt.Errorf("expected a method name at offset %d", name.pos)
+	}
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	arg := p.next()
+	if arg.kind != tokString {
+		return nil, fmt.Errorf("expected a string argument at offset %d", arg.pos)
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	var test func(string) bool
+	switch name.text {
+	case "startsWith":
+		test = func(s string) bool { return strings.HasPrefix(s, arg.text) }
+	case "endsWith":
+		test = func(s string) bool { return strings.HasSuffix(s, arg.text) }
+	case "contains":
+		test = func(s string) bool { return strings.Contains(s, arg.text) }
+	case "matches":
+		re, err := regexp.Compile(arg.text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern at offset %d: %v", arg.pos, err)
+		}
+		test = re.MatchString
+	default:
+		return nil, fmt.Errorf("unknown method %q at offset %d", name.text, name.pos)
+	}
+	return func(e *exprEnv) any {
+		s, ok := recv(e).(string)
+		return ok && test(s)
+	}, nil
+}
+
+// compareValues compares two values of the same type. A time is compared
+// with a string holding an RFC 3339 time. It reports false if they are not
+// comparable.
+func compareValues(a, b any) (int, bool) {
+	if t, ok := a.(time.Time); ok {
+		if s, ok := b.(string); ok {
+			var err error
+			if b, err = time.Parse(time.RFC3339, s); err != nil {
+				return 0, false
+			}
+		}
+		u, ok := b.(time.Time)
+		return t.Compare(u), ok
+	}
+	if _, ok := b.(time.Time); ok {
+		c, ok := compareValues(b, a)
+		return -c, ok
+	}
+	switch x := a.(type) {
+	case string:
+		y, ok := b.(string)
+		return strings.Compare(x, y), ok
+	case int64:
+		y, ok := b.(int64)
+		switch {
+		case !ok:
+			return 0, false
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		}
+		return 0, true
+	case bool:
+		y, ok := b.(bool)
+		if !ok || x != y {
+			return 1, ok
+		}
+		return 0, true
+	}
+	return 0, false
+}
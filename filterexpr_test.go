@@ -0,0 +1,101 @@
+package gorsn
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFilterExpr(t *testing.T) {
+	mtime := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	ev := Event{Path: "src/main.c", Type: FILE, Name: MODIFY, ModTime: mtime}
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`name == "MODIFY"`, true},
+		{`name != "MODIFY"`, false},
+		{`size < 2048 && size >= 1024`, true},
+		{`size > 1024`, false},
+		// && binds tighter than ||.
+		{`true || false && false`, true},
+		{`(true || false) && false`, false},
+		{`false && false || true`, true},
+		// ! applies to the next operand only.
+		{`!false && false`, false},
+		{`!(false && false)`, true},
+		{`!!true`, true},
+		{`!size`, false},
+		{`name in ["CREATE", "MODIFY"]`, true},
+		{`name in ["CREATE"]`, false},
+		{`size in [1, 1024]`, true},
+		{`name in []`, false},
+		{`!(type in ["DIRECTORY", "SYMLINK"])`, true},
+		{`path.matches('^src/.*\.(c|h)$')`, true},
+		{`path.matches("^src/.*\\.(c|h)$")`, true},
+		{`path == 'src/main.c'`, true},
+		{`path.endsWith(".c") && path.startsWith("src/") && path.contains("main")`, true},
+		{`"it's" == 'it\'s'`, true},
+		{`"tab\there" == 'tab\there'`, false},
+		{`mtime > "2024-01-01T00:00:00Z"`, true},
+		{`mtime <= "2024-06-01T00:00:00Z"`, true},
+		{`"2024-07-01T00:00:00Z" > mtime`, true},
+		{`mtime > "yesterday"`, false},
+		// values of different types do not compare.
+		{`size == "1024"`, false},
+		{`size`, false},
+	}
+	for _, tt := range tests {
+		f, err := compileFilterExpr(tt.expr)
+		if err != nil {
+			t.Errorf("%s: %v", tt.expr, err)
+			continue
+		}
+		if got := f.accepts(ev, 1024); got != tt.want {
+			t.Errorf("%s: got %t, want %t", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestFilterExprErrors(t *testing.T) {
+	for _, expr := range []string{
+		`name ==`,
+		`name == "CREATE`,
+		`path == 'src`,
+		`"\q" == path`,
+		`owner == "root"`,
+		`(size > 1`,
+		`size > 1)`,
+		`name in ["CREATE" "MODIFY"]`,
+		`path.matches('[')`,
+		`path.lower()`,
+		`path.startsWith(1)`,
+		`path.`,
+		`size > 99999999999999999999`,
+		`size # 1`,
+		`&& true`,
+	} {
+		if _, err := compileFilterExpr(expr); err == nil {
+			t.Errorf("%s: got no compilation error", expr)
+		}
+	}
+}
+
+func TestExprFilteredRelativePath(t *testing.T) {
+	opts := defaultOpts()
+	if err := opts.Events().SetEventFilterExpr(`path.matches('^src/.*\.(c|h)$')`); err != nil {
+		t.Fatal(err)
+	}
+	root := t.TempDir()
+	sn, err := New(root, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := sn.(*snotifier)
+	for name, filtered := range map[string]bool{"src/main.c": false, "src/lib/util.h": false, "main.c": true, "src/main.go": true} {
+		ev := Event{Path: filepath.Join(root, filepath.FromSlash(name)), Type: FILE, Name: CREATE}
+		if got := s.exprFiltered(ev); got != filtered {
+			t.Errorf("%s: got filtered %t, want %t", name, got, filtered)
+		}
+	}
+}
@@ -0,0 +1,366 @@
+package gorsn
+
+import (
+	"regexp"
+	"time"
+)
+
+// GetInterval returns the delay between two scan cycles.
+func (so *ScanOptions) GetInterval() time.Duration {
+	if v, ok := so.interval.Load().(time.Duration); ok {
+		return v
+	}
+	return DEFAULT_SCAN_INTERVAL
+}
+
+// GetMaxWorkers returns the number of goroutines which build the events.
+func (so *ScanOptions) GetMaxWorkers() int {
+	if v := so.maxworkers.Load(); v > 0 {
+		return int(v)
+	}
+	return DEFAULT_MAX_WORKERS
+}
+
+// GetAutoscale returns the bounds of the number of workers, zeros if the
+// autoscaling is disabled.
+func (so *ScanOptions) GetAutoscale() (min, max int) {
+	if as := so.autoscale(); as != nil {
+		return int(as.min), int(as.max)
+	}
+	return 0, 0
+}
+
+// GetMaxTrackedPaths returns the limit of tracked paths, zero if unlimited,
+// and the policy applied once reached.
+func (so *ScanOptions) GetMaxTrackedPaths() (int, LimitPolicy) {
+	return int(so.maxTracked.Load()), LimitPolicy(so.limitPolicy.Load())
+}
+
+// GetScanThrottle returns the file system operations per second of the scans,
+// zero if unlimited.
+func (so *ScanOptions) GetScanThrottle() int {
+	return int(so.throttle.Load())
+}
+
+// GetLoadThreshold returns the system load which pauses the scans, zero if disabled.
+func (so *ScanOptions) GetLoadThreshold() float64 {
+	return so.loadThreshold()
+}
+
+// GetMemoryBudget returns the bytes of the paths cache, zero if unlimited.
+func (so *ScanOptions) GetMemoryBudget() int64 {
+	return so.memBudget.Load()
+}
+
+// GetConfigFile returns the config file reloaded on changes, empty if none.
+func (so *ScanOptions) GetConfigFile() string {
+	return so.configFile
+}
+
+// GetChecksum returns whether the content digests of regular files are compared.
+func (so *ScanOptions) GetChecksum() bool {
+	return so.checksum.Load()
+}
+
+// GetComparator returns the strategy which tells whether the content changed.
+func (so *ScanOptions) GetComparator() Comparator {
+	return so.comparator()
+}
+
+// GetHashName returns the name of the hash function reported into the events.
+func (so *ScanOptions) GetHashName() string {
+	name, _ := so.hashName.Load().(string)
+	return name
+}
+
+// GetHashWorkers returns the number of goroutines which compute the digests.
+func (so *ScanOptions) GetHashWorkers() int {
+	if v := so.hashWorkers.Load(); v > 0 {
+		return int(v)
+	}
+	return DEFAULT_HASH_WORKERS
+}
+
+// GetExcludeRegex returns the pattern of the paths to skip, nil if none.
+func (fo *FilterOptions) GetExcludeRegex() *regexp.Regexp {
+	return fo.excludeRegex()
+}
+
+// GetIncludeRegex returns the pattern of the only paths to monitor, nil if none.
+func (fo *FilterOptions) GetIncludeRegex() *regexp.Regexp {
+	return fo.includeRegex()
+}
+
+// GetIgnoreFiles returns whether regular files are skipped.
+func (fo *FilterOptions) GetIgnoreFiles() bool {
+	return fo.ignoreFile.Load()
+}
+
+// GetIgnoreFolders returns whether directories are skipped.
+func (fo *FilterOptions) GetIgnoreFolders() bool {
+	return fo.ignoreFolder.Load()
+}
+
+// GetIgnoreSymlinks returns whether symbolic links are skipped.
+func (fo *FilterOptions) GetIgnoreSymlinks() bool {
+	return fo.ignoreSymlink.Load()
+}
+
+// GetIgnoreFifos returns whether named pipes are skipped.
+func (fo *FilterOptions) GetIgnoreFifos() bool {
+	return fo.ignoreFifo.Load()
+}
+
+// GetIgnoreSockets returns whether unix domain sockets are skipped.
+func (fo *FilterOptions) GetIgnoreSockets() bool {
+	return fo.ignoreSocket.Load()
+}
+
+// GetIgnoreDevices returns whether block and character devices are skipped.
+func (fo *FilterOptions) GetIgnoreDevices() bool {
+	return fo.ignoreDevice.Load()
+}
+
+// GetIgnoreFolderContent returns whether the sub-directories and all their
+// content are skipped.
+func (fo *FilterOptions) GetIgnoreFolderContent() bool {
+	return fo.ignoreFolderContent.Load()
+}
+
+// GetRecursive returns whether the content of the sub-directories is monitored.
+func (fo *FilterOptions) GetRecursive() bool {
+	return !fo.topLevelOnly.Load()
+}
+
+// GetGitignore returns whether the paths ignored by git are skipped.
+func (fo *FilterOptions) GetGitignore() bool {
+	return fo.gitignore.Load()
+}
+
+// GetIgnoreEditorTemp returns whether temporary files of editors are skipped.
+func (fo *FilterOptions) GetIgnoreEditorTemp() bool {
+	return fo.ignoreEditorTemp.Load()
+}
+
+// GetIgnoreArtifacts returns whether system, temporary and partial files are skipped.
+func (fo *FilterOptions) GetIgnoreArtifacts() bool {
+	return fo.ignoreArtifacts.Load()
+}
+
+// GetContentTypes returns a copy of the allowed MIME types, none if not filtered.
+func (fo *FilterOptions) GetContentTypes() []string {
+	return append([]string(nil), fo.contentTypes()...)
+}
+
+// GetOwnerFilter returns a copy of the allowed user IDs, none if not filtered.
+func (fo *FilterOptions) GetOwnerFilter() []int {
+	return append([]int(nil), fo.ownerFilter()...)
+}
+
+// GetGroupFilter returns a copy of the allowed group IDs, none if not filtered.
+func (fo *FilterOptions) GetGroupFilter() []int {
+	return append([]int(nil), fo.groupFilter()...)
+}
+
+// GetIgnoreErrors returns whether `ERROR` events are not emitted.
+func (eo *EventOptions) GetIgnoreErrors() bool {
+	return eo.ignoreErrors.Load()
+}
+
+// GetIgnoreNoChange returns whether `NOCHANGE` events are not emitted.
+func (eo *EventOptions) GetIgnoreNoChange() bool {
+	return eo.ignoreNoChange.Load()
+}
+
+// GetIgnoreDelete returns whether `DELETE` events are not emitted.
+func (eo *EventOptions) GetIgnoreDelete() bool {
+	return eo.ignoreDelete.Load()
+}
+
+// GetIgnoreCreate returns whether `CREATE` events are not emitted.
+func (eo *EventOptions) GetIgnoreCreate() bool {
+	return eo.ignoreCreate.Load()
+}
+
+// GetIgnoreModify returns whether `MODIFY` events are not emitted.
+func (eo *EventOptions) GetIgnoreModify() bool {
+	return eo.ignoreModify.Load()
+}
+
+// GetIgnorePerm returns whether `PERM` events are not emitted.
+func (eo *EventOptions) GetIgnorePerm() bool {
+	return eo.ignorePerm.Load()
+}
+
+// GetIgnoreAttrib returns whether `ATTRIB` events are not emitted.
+func (eo *EventOptions) GetIgnoreAttrib() bool {
+	return eo.ignoreAttrib.Load()
+}
+
+// GetTrackOwnership returns whether `OWNER` events are emitted.
+func (eo *EventOptions) GetTrackOwnership() bool {
+	return eo.trackOwner.Load()
+}
+
+// GetTrackRenames returns whether `RENAME` events are emitted.
+func (eo *EventOptions) GetTrackRenames() bool {
+	return eo.trackRenames.Load()
+}
+
+// GetTrackLinks returns whether `LINK` and `UNLINK` events are emitted.
+func (eo *EventOptions) GetTrackLinks() bool {
+	return eo.trackLinks.Load()
+}
+
+// GetSettleCycles returns the number of scans before a `STABLE` event, zero if disabled.
+func (eo *EventOptions) GetSettleCycles() int {
+	return int(eo.settleCycles.Load())
+}
+
+// GetDeleteGrace returns the number of scans a path must be missing before its
+// `DELETE` event.
+func (eo *EventOptions) GetDeleteGrace() int {
+	return int(eo.deleteGrace.Load())
+}
+
+// GetVanished returns how a path which disappeared while scanned is reported.
+func (eo *EventOptions) GetVanished() VanishedMode {
+	return VanishedMode(eo.vanished.Load())
+}
+
+// GetSizeThresholds returns a copy of the byte limits of the supervised
+// directories keyed by their path relative to the root directory.
+func (eo *EventOptions) GetSizeThresholds() map[string]int64 {
+	limits := make(map[string]int64)
+	for d, n := range eo.sizeThresholds() {
+		limits[d] = n
+	}
+	return limits
+}
+
+// GetDetectAtomicSaves returns whether atomic saves are reported as `MODIFY` events.
+func (eo *EventOptions) GetDetectAtomicSaves() bool {
+	return eo.atomicSaves.Load()
+}
+
+// GetScanSummary returns whether `SCAN_SUMMARY` events are emitted.
+func (eo *EventOptions) GetScanSummary() bool {
+	return eo.scanSummary.Load()
+}
+
+// GetDetectLocked returns whether locked files are reported by `LOCKED` events.
+func (eo *EventOptions) GetDetectLocked() bool {
+	return eo.detectLocked.Load()
+}
+
+// GetPathForm returns the form of the paths emitted on Windows.
+func (eo *EventOptions) GetPathForm() PathForm {
+	return PathForm(eo.pathForm.Load())
+}
+
+// GetRelativePaths returns whether the paths are relative to the root path.
+func (eo *EventOptions) GetRelativePaths() bool {
+	return eo.relativePaths.Load()
+}
+
+// GetIncludeChecksum returns whether the events carry the content digest.
+func (eo *EventOptions) GetIncludeChecksum() bool {
+	return eo.includeChecksum.Load()
+}
+
+// GetDetectContentType returns whether the events carry the MIME type.
+func (eo *EventOptions) GetDetectContentType() bool {
+	return eo.detectContentType.Load()
+}
+
+// GetDedupWindow returns the period of the suppressed `MODIFY` events, zero if disabled.
+func (eo *EventOptions) GetDedupWindow() time.Duration {
+	d, _ := eo.dedupWindow.Load().(time.Duration)
+	return d
+}
+
+// GetRootLabel returns the label of the root path, empty if none.
+func (eo *EventOptions) GetRootLabel() string {
+	return eo.rootLabel()
+}
+
+// GetDetectRotation returns whether `ROTATE` events are emitted.
+func (eo *EventOptions) GetDetectRotation() bool {
+	return eo.detectRotation.Load()
+}
+
+// GetChangedRegions returns the bytes of the compared blocks, zero if disabled.
+func (eo *EventOptions) GetChangedRegions() int {
+	return int(eo.blockSize.Load())
+}
+
+// GetDetectTouch returns whether `TOUCH` events are emitted.
+func (eo *EventOptions) GetDetectTouch() bool {
+	return eo.detectTouch.Load()
+}
+
+// GetCollapseDeletes returns whether a deleted directory is reported without its content.
+func (eo *EventOptions) GetCollapseDeletes() bool {
+	return eo.collapseDeletes.Load()
+}
+
+// GetStopOnRootLost returns whether the scan notifier stops once the root path is lost.
+func (eo *EventOptions) GetStopOnRootLost() bool {
+	return eo.stopOnRootLost.Load()
+}
+
+// GetAnomalyDetection returns the number of cycles of the mean, zero if the
+// detection is disabled, and the sensitivity.
+func (eo *EventOptions) GetAnomalyDetection() (window int, sensitivity float64) {
+	sensitivity, ok := eo.anomalySensitivity.Load().(float64)
+	if !ok {
+		sensitivity = DEFAULT_ANOMALY_SENSITIVITY
+	}
+	return int(eo.anomalyWindow.Load()), sensitivity
+}
+
+// GetQueueSize returns the capacity of the events queue.
+func (do *DeliveryOptions) GetQueueSize() int {
+	if v := do.queueSize.Load(); v > 0 {
+		return int(v)
+	}
+	return DEFAULT_QUEUE_SIZE
+}
+
+// GetDebounce returns the delay of the path changes events, zero if disabled.
+func (do *DeliveryOptions) GetDebounce() time.Duration {
+	d, _ := do.debounce.Load().(time.Duration)
+	return d
+}
+
+// GetDeterministicOrder returns whether the events of a scan cycle are sorted.
+func (do *DeliveryOptions) GetDeterministicOrder() bool {
+	return do.ordered.Load()
+}
+
+// GetAckTimeout returns the delay before an unacknowledged event is sent
+// again, zero if the at-least-once delivery mode is disabled.
+func (do *DeliveryOptions) GetAckTimeout() time.Duration {
+	d, _ := do.ackTimeout.Load().(time.Duration)
+	return d
+}
+
+// GetOverflowPolicy returns how the events are delivered once a queue is full.
+func (do *DeliveryOptions) GetOverflowPolicy() OverflowPolicy {
+	return OverflowPolicy(do.overflow.Load())
+}
+
+// GetMaxEventsPerSecond returns the events delivered per second, zero if unlimited.
+func (do *DeliveryOptions) GetMaxEventsPerSecond() int {
+	return int(do.maxRate.Load())
+}
+
+// GetSubscriptionsOnly returns whether the main queue receives no events.
+func (do *DeliveryOptions) GetSubscriptionsOnly() bool {
+	return do.subsOnly.Load()
+}
+
+// GetHistorySize returns the number of latest events kept, zero if disabled.
+func (do *DeliveryOptions) GetHistorySize() int {
+	return int(do.history.Load())
+}
@@ -0,0 +1,40 @@
+package gorsn
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestGetQueueSizeWhileSetting(t *testing.T) {
+	opts := defaultOpts()
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		for i := 1; i <= 100; i++ {
+			opts.Delivery().SetQueueSize(i)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			if v := opts.Delivery().GetQueueSize(); v <= 0 {
+				t.Errorf("got queue size %d", v)
+				return
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			opts.Clone()
+		}
+	}()
+	wg.Wait()
+	if v := opts.Delivery().GetQueueSize(); v != 100 {
+		t.Errorf("got queue size %d, want 100", v)
+	}
+	if v := opts.Delivery().SetQueueSize(0).GetQueueSize(); v != DEFAULT_QUEUE_SIZE {
+		t.Errorf("got queue size %d, want the default %d", v, DEFAULT_QUEUE_SIZE)
+	}
+}
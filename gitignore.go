@@ -0,0 +1,161 @@
+package gorsn
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// gitignoreRule is a parsed pattern line of a `.gitignore` file.
+type gitignoreRule struct {
+	re       *regexp.Regexp
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// gitignore holds the ordered rules of a `.gitignore` file.
+type gitignore struct {
+	rules []gitignoreRule
+}
+
+// parseGitignore reads the patterns of a `.gitignore` file. Blank lines
+// and comments are skipped. Invalid patterns are silently discarded.
+func parseGitignore(r io.Reader) *gitignore {
+	g := &gitignore{}
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rule := gitignoreRule{}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		}
+		line = strings.TrimPrefix(line, `\`)
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			rule.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		re, err := regexp.Compile(globToRegexp(line))
+		if err != nil {
+			continue
+		}
+		rule.re = re
+		g.rules = append(g.rules, rule)
+	}
+	return g
+}
+
+// globToRegexp converts a gitignore glob into an anchored regular expression.
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "/**") && i+3 == len(glob):
+			b.WriteString("(?:/.*)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i:], ']')
+			if end < 0 {
+				b.WriteString(regexp.QuoteMeta(string(c)))
+				continue
+			}
+			class := glob[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+// match reports whether the slash-separated `rel` path relative to the
+// root directory is ignored. A path is also ignored when one of its parent
+// directories is ignored.
+func (g *gitignore) match(rel string, isDir bool) bool {
+	if g == nil || rel == "" {
+		return false
+	}
+	parts := strings.Split(rel, "/")
+	for i := 1; i <= len(parts); i++ {
+		dir := i < len(parts) || isDir
+		if g.matchOne(strings.Join(parts[:i], "/"), parts[i-1], dir) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *gitignore) matchOne(rel, base string, isDir bool) bool {
+	ignored := false
+	for _, r := range g.rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		target := base
+		if r.anchored {
+			target = rel
+		}
+		if r.re.MatchString(target) {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
+
+// loadGitignore (re)loads the root `.gitignore` file if the option is enabled.
+func (sn *snotifier) loadGitignore() {
+	if sn.single || !sn.opts.filters.gitignore.Load() {
+		return
+	}
+	g := &gitignore{}
+	if op, ok := sn.opts.scan.backend.(opener); ok {
+		if f, err := op.Open(sn.opts.scan.backend.Join(sn.root, ".gitignore")); err == nil {
+			g = parseGitignore(f)
+			f.Close()
+		}
+	}
+	sn.gitignore.Store(g)
+}
+
+// gitignored reports whether the path is ignored by the `.gitignore` rules
+// or belongs to the `.git` directory when the option is enabled.
+func (sn *snotifier) gitignored(s string, t PathType) bool {
+	if !sn.opts.filters.gitignore.Load() {
+		return false
+	}
+	rel := sn.rel(s)
+	if rel == ".git" || strings.HasPrefix(rel, ".git/") {
+		return true
+	}
+	g, _ := sn.gitignore.Load().(*gitignore)
+	return g.match(rel, t == DIR)
+}
@@ -1,3 +1,7 @@
 module github.com/jeamon/gorsn
 
 go 1.20
+
+require go.etcd.io/bbolt v1.3.9
+
+require golang.org/x/sys v0.4.0 // indirect
@@ -0,0 +1,87 @@
+package gorsntest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jeamon/gorsn"
+)
+
+var _ gorsn.Clock = (*Clock)(nil)
+
+// Clock is a fake gorsn.Clock whose time only moves with Advance, so the
+// scan cycles, the debouncing or the timed pauses of a real scan notifier
+// could be driven deterministically once set with `ScanOptions.SetClock`.
+type Clock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []waiter
+}
+
+// waiter is a pending call to After or Sleep.
+type waiter struct {
+	at time.Time
+	c  chan time.Time
+}
+
+// NewClock returns a fake clock set at `t`.
+func NewClock(t time.Time) *Clock {
+	return &Clock{now: t}
+}
+
+// Now returns the current time of the clock.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After returns a channel which receives the current time once the clock
+// was advanced by at least `d`. It fires at once if `d` is not positive.
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.waiters = append(c.waiters, waiter{at: c.now.Add(d), c: ch})
+	return ch
+}
+
+// Sleep blocks until the clock was advanced by at least `d`.
+func (c *Clock) Sleep(d time.Duration) {
+	<-c.After(d)
+}
+
+// Advance moves the clock forward by `d` and wakes up the callers of
+// After and Sleep whose delay elapsed, the earliest first.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	sort.SliceStable(c.waiters, func(i, j int) bool {
+		return c.waiters[i].at.Before(c.waiters[j].at)
+	})
+	n := 0
+	for _, w := range c.waiters {
+		if w.at.After(c.now) {
+			c.waiters[n] = w
+			n++
+			continue
+		}
+		w.c <- c.now
+	}
+	c.waiters = c.waiters[:n]
+}
+
+// Waiters returns the number of calls to After and Sleep still waiting,
+// so a test could wait for a goroutine to block on the clock before
+// advancing it.
+func (c *Clock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
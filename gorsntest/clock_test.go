@@ -0,0 +1,80 @@
+package gorsntest
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/jeamon/gorsn"
+)
+
+func TestClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewClock(start)
+	select {
+	case now := <-c.After(0):
+		if !now.Equal(start) {
+			t.Errorf("got time %v, want %v", now, start)
+		}
+	default:
+		t.Error("zero delay did not fire at once")
+	}
+
+	late, early := c.After(2*time.Second), c.After(time.Second)
+	slept := make(chan struct{})
+	go func() {
+		c.Sleep(3 * time.Second)
+		close(slept)
+	}()
+	for c.Waiters() != 3 {
+		time.Sleep(time.Millisecond)
+	}
+
+	c.Advance(time.Second)
+	if now := <-early; !now.Equal(start.Add(time.Second)) {
+		t.Errorf("got time %v, want %v", now, start.Add(time.Second))
+	}
+	select {
+	case <-late:
+		t.Error("fired before its delay")
+	default:
+	}
+	c.Advance(2 * time.Second)
+	<-late
+	<-slept
+	if c.Waiters() != 0 {
+		t.Errorf("got %d waiters, want none", c.Waiters())
+	}
+	if now := c.Now(); !now.Equal(start.Add(3 * time.Second)) {
+		t.Errorf("got time %v, want %v", now, start.Add(3*time.Second))
+	}
+}
+
+// TestClockDrivesNotifier drives the scan cycles of a real notifier over an
+// in-memory file system.
+func TestClockDrivesNotifier(t *testing.T) {
+	fsys := fstest.MapFS{"a.txt": {Data: []byte("a")}}
+	clock := NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	opts := &gorsn.Options{}
+	opts.Scan().SetBackend(gorsn.FSBackend(fsys)).SetClock(clock).SetInterval(time.Minute)
+	sn, err := gorsn.New(".", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sn.StartAsync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Stop()
+
+	// the scanner and the debouncer wait for the clock between the cycles.
+	for clock.Waiters() != 2 {
+		time.Sleep(time.Millisecond)
+	}
+	fsys["b.txt"] = &fstest.MapFile{Data: []byte("b"), ModTime: clock.Now()}
+	clock.Advance(time.Minute)
+	ev := EventuallyReceives(t, sn.Queue(), Like(gorsn.Event{Name: gorsn.CREATE}))
+	if ev.Path != "b.txt" || !ev.Time.Equal(clock.Now()) {
+		t.Errorf("got event %s of %q at %v, want the CREATE of b.txt at %v", ev.Name, ev.Path, ev.Time, clock.Now())
+	}
+}
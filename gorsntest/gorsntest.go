@@ -0,0 +1,139 @@
+// Package gorsntest provides utilities to unit-test the consumers of a
+// gorsn.ScanNotifier without touching the real file system: a fake notifier
+// fed by the test, a sink which records the events, helpers to synthesize
+// events and assertions on a queue of events. A real scan notifier could be
+// driven with a fake clock over an in-memory file system as well, such as
+// `gorsn.FSBackend(fstest.MapFS{...})`.
+//
+//	fake := gorsntest.NewNotifier(10)
+//	go consumer(fake)
+//	fake.Send(gorsntest.Create("/data/a.txt"))
+//	gorsntest.EventuallyReceives(t, out, gorsntest.Like(gorsn.Event{Name: gorsn.MODIFY}))
+package gorsntest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jeamon/gorsn"
+)
+
+// DefaultTimeout is the delay after which the assertions give up.
+var DefaultTimeout = 2 * time.Second
+
+// TB is the subset of testing.TB used by the assertions.
+type TB interface {
+	Helper()
+	Fatalf(format string, args ...any)
+}
+
+// Create returns a `CREATE` event of a regular file.
+func Create(path string) gorsn.Event {
+	return gorsn.Event{Path: path, Type: gorsn.FILE, Name: gorsn.CREATE}
+}
+
+// Modify returns a `MODIFY` event of a regular file.
+func Modify(path string) gorsn.Event {
+	return gorsn.Event{Path: path, Type: gorsn.FILE, Name: gorsn.MODIFY}
+}
+
+// Delete returns a `DELETE` event of a regular file.
+func Delete(path string) gorsn.Event {
+	return gorsn.Event{Path: path, Type: gorsn.FILE, Name: gorsn.DELETE}
+}
+
+// Rename returns a `RENAME` event of a regular file moved from `oldPath`.
+func Rename(oldPath, path string) gorsn.Event {
+	return gorsn.Event{Path: path, OldPath: oldPath, Type: gorsn.FILE, Name: gorsn.RENAME}
+}
+
+// Error returns an `ERROR` event of a regular file.
+func Error(path string, err error) gorsn.Event {
+	return gorsn.Event{Path: path, Type: gorsn.FILE, Name: gorsn.ERROR, Error: err}
+}
+
+// Matcher reports whether an event is the expected one.
+type Matcher func(gorsn.Event) bool
+
+// Like returns a Matcher of the events which have the same values
+// than the non-zero fields of `want`.
+func Like(want gorsn.Event) Matcher {
+	return func(ev gorsn.Event) bool {
+		return (want.Path == "" || ev.Path == want.Path) &&
+			(want.OldPath == "" || ev.OldPath == want.OldPath) &&
+			(want.Type == "" || ev.Type == want.Type) &&
+			(want.Name == "" || ev.Name == want.Name)
+	}
+}
+
+// EventuallyReceives reads the queue until an event accepted by `m` is
+// received and returns it. The test fails if none is received before
+// `DefaultTimeout` or if the queue is closed.
+func EventuallyReceives(t TB, queue <-chan gorsn.Event, m Matcher) gorsn.Event {
+	t.Helper()
+	timeout := time.After(DefaultTimeout)
+	for {
+		select {
+		case ev, ok := <-queue:
+			if !ok {
+				t.Fatalf("gorsntest: queue closed before receiving the expected event")
+				return gorsn.Event{}
+			}
+			if m(ev) {
+				return ev
+			}
+		case <-timeout:
+			t.Fatalf("gorsntest: expected event not received within %v", DefaultTimeout)
+			return gorsn.Event{}
+		}
+	}
+}
+
+// NeverReceives reads the queue during `d` and fails the test if
+// an event accepted by `m` is received.
+func NeverReceives(t TB, queue <-chan gorsn.Event, m Matcher, d time.Duration) {
+	t.Helper()
+	timeout := time.After(d)
+	for {
+		select {
+		case ev, ok := <-queue:
+			if !ok {
+				return
+			}
+			if m(ev) {
+				t.Fatalf("gorsntest: unexpected event %s %s %q", ev.Name, ev.Type, ev.Path)
+				return
+			}
+		case <-timeout:
+			return
+		}
+	}
+}
+
+// Recorder is a gorsn.Sink which keeps all the events written to it.
+type Recorder struct {
+	mu     sync.Mutex
+	events []gorsn.Event
+}
+
+// Write records the event.
+func (r *Recorder) Write(ev gorsn.Event) error {
+	r.mu.Lock()
+	r.events = append(r.events, ev)
+	r.mu.Unlock()
+	return nil
+}
+
+// Events returns a copy of the recorded events.
+func (r *Recorder) Events() []gorsn.Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]gorsn.Event{}, r.events...)
+}
+
+// Reset forgets the recorded events.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	r.events = nil
+	r.mu.Unlock()
+}
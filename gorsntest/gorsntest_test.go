@@ -0,0 +1,135 @@
+package gorsntest
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jeamon/gorsn"
+)
+
+// fakeT records the failures of the assertions.
+type fakeT struct {
+	failures []string
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Fatalf(format string, args ...any) {
+	t.failures = append(t.failures, fmt.Sprintf(format, args...))
+}
+
+func TestEvents(t *testing.T) {
+	errBoom := errors.New("boom")
+	tests := []struct {
+		got  gorsn.Event
+		want gorsn.Event
+	}{
+		{Create("a"), gorsn.Event{Path: "a", Type: gorsn.FILE, Name: gorsn.CREATE}},
+		{Modify("a"), gorsn.Event{Path: "a", Type: gorsn.FILE, Name: gorsn.MODIFY}},
+		{Delete("a"), gorsn.Event{Path: "a", Type: gorsn.FILE, Name: gorsn.DELETE}},
+		{Rename("a", "b"), gorsn.Event{Path: "b", OldPath: "a", Type: gorsn.FILE, Name: gorsn.RENAME}},
+		{Error("a", errBoom), gorsn.Event{Path: "a", Type: gorsn.FILE, Name: gorsn.ERROR, Error: errBoom}},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.got, tt.want) {
+			t.Errorf("got event %+v, want %+v", tt.got, tt.want)
+		}
+	}
+}
+
+func TestLike(t *testing.T) {
+	ev := Rename("a", "b")
+	tests := []struct {
+		want gorsn.Event
+		ok   bool
+	}{
+		{gorsn.Event{}, true},
+		{gorsn.Event{Name: gorsn.RENAME}, true},
+		{gorsn.Event{Path: "b", OldPath: "a", Type: gorsn.FILE, Name: gorsn.RENAME}, true},
+		{gorsn.Event{Path: "a"}, false},
+		{gorsn.Event{OldPath: "b"}, false},
+		{gorsn.Event{Type: gorsn.DIR}, false},
+		{gorsn.Event{Name: gorsn.CREATE}, false},
+	}
+	for _, tt := range tests {
+		if got := Like(tt.want)(ev); got != tt.ok {
+			t.Errorf("Like(%+v) got %v, want %v", tt.want, got, tt.ok)
+		}
+	}
+}
+
+func TestEventuallyReceives(t *testing.T) {
+	defer func(d time.Duration) { DefaultTimeout = d }(DefaultTimeout)
+	DefaultTimeout = 20 * time.Millisecond
+
+	queue := make(chan gorsn.Event, 3)
+	queue <- Create("a")
+	queue <- Modify("a")
+	ft := &fakeT{}
+	if ev := EventuallyReceives(ft, queue, Like(Modify("a"))); !reflect.DeepEqual(ev, Modify("a")) || len(ft.failures) != 0 {
+		t.Errorf("got event %+v and failures %q, want the MODIFY event", ev, ft.failures)
+	}
+	if len(queue) != 0 {
+		t.Errorf("got %d events left, want the skipped events consumed", len(queue))
+	}
+
+	EventuallyReceives(ft, queue, Like(Delete("a")))
+	if len(ft.failures) != 1 {
+		t.Errorf("got failures %q, want a timeout", ft.failures)
+	}
+
+	close(queue)
+	ft = &fakeT{}
+	EventuallyReceives(ft, queue, Like(Delete("a")))
+	if len(ft.failures) != 1 {
+		t.Errorf("got failures %q, want a closed queue", ft.failures)
+	}
+}
+
+func TestNeverReceives(t *testing.T) {
+	queue := make(chan gorsn.Event, 2)
+	queue <- Create("a")
+	ft := &fakeT{}
+	NeverReceives(ft, queue, Like(Delete("a")), 10*time.Millisecond)
+	if len(ft.failures) != 0 {
+		t.Errorf("got failures %q, want none", ft.failures)
+	}
+
+	queue <- Delete("a")
+	NeverReceives(ft, queue, Like(Delete("a")), time.Second)
+	if len(ft.failures) != 1 {
+		t.Errorf("got failures %q, want the DELETE event reported", ft.failures)
+	}
+
+	close(queue)
+	ft = &fakeT{}
+	NeverReceives(ft, queue, Like(Delete("a")), time.Second)
+	if len(ft.failures) != 0 {
+		t.Errorf("got failures %q, want none once the queue is closed", ft.failures)
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	r := &Recorder{}
+	var _ gorsn.Sink = r
+	for _, ev := range []gorsn.Event{Create("a"), Delete("a")} {
+		if err := r.Write(ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+	events := r.Events()
+	if want := []gorsn.Event{Create("a"), Delete("a")}; !reflect.DeepEqual(events, want) {
+		t.Errorf("got events %+v, want %+v", events, want)
+	}
+	events[0] = Modify("b")
+	if got := r.Events()[0]; !reflect.DeepEqual(got, Create("a")) {
+		t.Errorf("got event %+v, want the recorded events left unchanged", got)
+	}
+	r.Reset()
+	if got := r.Events(); len(got) != 0 {
+		t.Errorf("got events %+v after reset, want none", got)
+	}
+}
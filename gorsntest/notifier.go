@@ -0,0 +1,370 @@
+package gorsntest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jeamon/gorsn"
+)
+
+var _ gorsn.ScanNotifier = (*Notifier)(nil)
+
+// Notifier is a fake gorsn.ScanNotifier which delivers the events sent by
+// the test. It follows the same lifecycle than a real scan notifier: events
+// could be sent once started and the queue is closed once stopped.
+type Notifier struct {
+	mu      sync.Mutex
+	queue   chan gorsn.Event
+	stop    chan struct{}
+	running bool
+	paused  bool
+	done    bool
+	started bool
+	ended   chan struct{} // closed once terminated.
+	exit    error
+	sending sync.WaitGroup
+	sent    []gorsn.Event
+	stats   gorsn.Stats
+	subs    []subscription
+	only    bool // events sent to the subscriptions only.
+}
+
+// subscription is a queue which receives only some kinds of events.
+type subscription struct {
+	names map[gorsn.EventName]bool
+	queue chan gorsn.Event
+}
+
+// NewNotifier returns a fake notifier whose queue holds `size` events.
+func NewNotifier(size int) *Notifier {
+	return &Notifier{queue: make(chan gorsn.Event, size), stop: make(chan struct{}), ended: make(chan struct{})}
+}
+
+// SetSubscriptionsOnly sends the events to the queues returned by `QueueFor`
+// only, like `DeliveryOptions.SetSubscriptionsOnly` of a real notifier.
+func (n *Notifier) SetSubscriptionsOnly(v bool) *Notifier {
+	n.mu.Lock()
+	n.only = v
+	n.mu.Unlock()
+	return n
+}
+
+// Send delivers the event to the queue unless paused. It blocks while
+// the queue is full and returns false if the notifier is not running.
+func (n *Notifier) Send(ev gorsn.Event) bool {
+	n.mu.Lock()
+	if !n.running {
+		n.mu.Unlock()
+		return false
+	}
+	if n.paused {
+		n.mu.Unlock()
+		return true
+	}
+	stop := n.stop
+	subs := n.subs
+	main := !n.only
+	n.sending.Add(1)
+	n.mu.Unlock()
+	defer n.sending.Done()
+	if main {
+		select {
+		case n.queue <- ev:
+		case <-stop:
+			return false
+		}
+	}
+	for _, sub := range subs {
+		if !sub.names[ev.Name] {
+			continue
+		}
+		select {
+		case sub.queue <- ev:
+		case <-stop:
+			return false
+		}
+	}
+	n.mu.Lock()
+	n.sent = append(n.sent, ev)
+	n.stats.Delivered++
+	n.mu.Unlock()
+	return true
+}
+
+func (n *Notifier) Queue() <-chan gorsn.Event {
+	return n.queue
+}
+
+// QueueFor returns a queue which receives only the events named `names`.
+// Like a real notifier, the main queue still receives all the events unless
+// disabled with `SetSubscriptionsOnly`.
+func (n *Notifier) QueueFor(names ...gorsn.EventName) <-chan gorsn.Event {
+	sub := subscription{names: make(map[gorsn.EventName]bool, len(names)), queue: make(chan gorsn.Event, cap(n.queue))}
+	for _, name := range names {
+		sub.names[name] = true
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.done {
+		close(sub.queue)
+		return sub.queue
+	}
+	n.subs = append(n.subs, sub)
+	return sub.queue
+}
+
+// Start blocks until the notifier is stopped or `ctx` is done. Like a real
+// notifier, it could not be started again once stopped.
+func (n *Notifier) Start(ctx context.Context) error {
+	stop, err := n.launch()
+	if err != nil {
+		return err
+	}
+	n.run(ctx, stop)
+	return nil
+}
+
+// StartAsync works like Start but returns once started.
+func (n *Notifier) StartAsync(ctx context.Context) error {
+	stop, err := n.launch()
+	if err != nil {
+		return err
+	}
+	go n.run(ctx, stop)
+	return nil
+}
+
+// Run works like Start but returns the context error if it was
+// stopped by its context.
+func (n *Notifier) Run(ctx context.Context) error {
+	stop, err := n.launch()
+	if err != nil {
+		return err
+	}
+	n.run(ctx, stop)
+	return n.exit
+}
+
+// Wait blocks until the notifier is stopped and returns the context
+// error if it was stopped by its context.
+func (n *Notifier) Wait() error {
+	n.mu.Lock()
+	started := n.started
+	n.mu.Unlock()
+	if !started {
+		return gorsn.ErrScanIsNotRunning
+	}
+	<-n.ended
+	return n.exit
+}
+
+// launch marks the notifier as running and returns its stop channel.
+func (n *Notifier) launch() (chan struct{}, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.running {
+		return nil, gorsn.ErrScanAlreadyStarted
+	}
+	if n.done {
+		return nil, gorsn.ErrScanIsNotReady
+	}
+	n.running = true
+	n.started = true
+	return n.stop, nil
+}
+
+// run waits for the termination then closes the queues.
+func (n *Notifier) run(ctx context.Context, stop chan struct{}) {
+	defer close(n.ended)
+	select {
+	case <-stop:
+	case <-ctx.Done():
+		n.mu.Lock()
+		if n.stop == stop {
+			close(stop)
+		}
+		n.exit = ctx.Err()
+		n.mu.Unlock()
+	}
+	n.mu.Lock()
+	n.running = false
+	n.paused = false
+	n.done = true
+	n.mu.Unlock()
+	n.sending.Wait()
+	close(n.queue)
+	n.mu.Lock()
+	for _, sub := range n.subs {
+		close(sub.queue)
+	}
+	n.subs = nil
+	n.mu.Unlock()
+}
+
+func (n *Notifier) Stop() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if !n.running {
+		return gorsn.ErrScanIsNotRunning
+	}
+	close(n.stop)
+	n.stop = make(chan struct{})
+	return nil
+}
+
+func (n *Notifier) IsRunning() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.running
+}
+
+func (n *Notifier) IsPaused() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.running && n.paused
+}
+
+func (n *Notifier) State() gorsn.LifecycleState {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	switch {
+	case n.running && n.paused:
+		return gorsn.PAUSED
+	case n.running:
+		return gorsn.RUNNING
+	case n.done:
+		return gorsn.STOPPED
+	}
+	return gorsn.IDLE
+}
+
+func (n *Notifier) Flush() {}
+
+func (n *Notifier) Pause() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if !n.running {
+		return gorsn.ErrScanIsNotRunning
+	}
+	n.paused = true
+	return nil
+}
+
+func (n *Notifier) PauseImmediately() error {
+	return n.Pause()
+}
+
+// PauseFor pauses the fake notifier without resuming it automatically since
+// it does not scan. Use Resume to simulate the resumption.
+func (n *Notifier) PauseFor(d time.Duration) error {
+	return n.Pause()
+}
+
+// PauseUntil works like PauseFor.
+func (n *Notifier) PauseUntil(t time.Time) error {
+	return n.Pause()
+}
+
+func (n *Notifier) Resume() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if !n.running {
+		return gorsn.ErrScanIsNotRunning
+	}
+	if !n.paused {
+		return gorsn.ErrScanIsNotPaused
+	}
+	n.paused = false
+	return nil
+}
+
+// PausePath does nothing since the fake notifier does not scan.
+func (n *Notifier) PausePath(glob string) error {
+	return nil
+}
+
+// ResumePath does nothing since the fake notifier does not scan.
+func (n *Notifier) ResumePath(glob string) error {
+	return nil
+}
+
+// Export returns an empty state since the fake notifier has no items.
+func (n *Notifier) Export() *gorsn.State {
+	return &gorsn.State{Schema: gorsn.SchemaVersion, Paths: map[string]gorsn.PathState{}}
+}
+
+func (n *Notifier) Capabilities() gorsn.Capabilities {
+	return gorsn.Capabilities{}
+}
+
+// Emit sends a `CREATE` event for the path.
+func (n *Notifier) Emit(path string) error {
+	if !n.Send(Create(path)) {
+		return gorsn.ErrScanIsNotRunning
+	}
+	return nil
+}
+
+// Inject sends the event as is.
+func (n *Notifier) Inject(ev gorsn.Event) error {
+	if !n.Send(ev) {
+		return gorsn.ErrScanIsNotRunning
+	}
+	return nil
+}
+
+// History returns the delivered events accepted by the filters. The
+// time is ignored since the events are not timestamped.
+func (n *Notifier) History(_ time.Time, filters ...gorsn.Filter) []gorsn.Event {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	var events []gorsn.Event
+next:
+	for _, ev := range n.sent {
+		for _, f := range filters {
+			if f != nil && !f(ev) {
+				continue next
+			}
+		}
+		events = append(events, ev)
+	}
+	return events
+}
+
+// Health reports the lifecycle state and the queue usage.
+func (n *Notifier) Health() gorsn.Health {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	h := gorsn.Health{
+		Running:       n.running,
+		Paused:        n.paused,
+		QueueLength:   len(n.queue),
+		QueueCapacity: cap(n.queue),
+	}
+	if h.QueueCapacity > 0 {
+		h.QueueSaturation = float64(h.QueueLength) / float64(h.QueueCapacity)
+	}
+	return h
+}
+
+// Progress returns an empty progress since the fake notifier does not scan.
+func (n *Notifier) Progress() gorsn.Progress {
+	return gorsn.Progress{}
+}
+
+// LastScanAt returns the zero time since the fake notifier does not scan.
+func (n *Notifier) LastScanAt() time.Time {
+	return time.Time{}
+}
+
+// NextScanAt returns the zero time since the fake notifier does not scan.
+func (n *Notifier) NextScanAt() time.Time {
+	return time.Time{}
+}
+
+func (n *Notifier) Stats() gorsn.Stats {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.stats
+}
@@ -0,0 +1,129 @@
+package gorsntest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jeamon/gorsn"
+)
+
+func TestNotifierLifecycle(t *testing.T) {
+	n := NewNotifier(4)
+	if n.Send(Create("a")) {
+		t.Error("event sent before starting")
+	}
+	if err := n.Wait(); !errors.Is(err, gorsn.ErrScanIsNotRunning) {
+		t.Errorf("got error %v waiting before starting, want ErrScanIsNotRunning", err)
+	}
+	if got := n.State(); got != gorsn.IDLE {
+		t.Errorf("got state %v, want IDLE", got)
+	}
+	if err := n.StartAsync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.StartAsync(context.Background()); !errors.Is(err, gorsn.ErrScanAlreadyStarted) {
+		t.Errorf("got error %v starting twice, want ErrScanAlreadyStarted", err)
+	}
+	if !n.Send(Create("a")) || n.State() != gorsn.RUNNING {
+		t.Fatalf("event not sent while %v", n.State())
+	}
+
+	if err := n.Pause(); err != nil {
+		t.Fatal(err)
+	}
+	if !n.IsPaused() || !n.Send(Modify("a")) {
+		t.Error("event of a paused notifier was not silently dropped")
+	}
+	if err := n.Resume(); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Resume(); !errors.Is(err, gorsn.ErrScanIsNotPaused) {
+		t.Errorf("got error %v resuming twice, want ErrScanIsNotPaused", err)
+	}
+	if err := n.Inject(Delete("a")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := n.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Wait(); err != nil {
+		t.Errorf("got error %v once stopped, want nil", err)
+	}
+	var got []gorsn.EventName
+	for ev := range n.Queue() {
+		got = append(got, ev.Name)
+	}
+	if len(got) != 2 || got[0] != gorsn.CREATE || got[1] != gorsn.DELETE {
+		t.Errorf("got events %v, want CREATE and DELETE", got)
+	}
+	if s := n.Stats(); s.Delivered != 2 {
+		t.Errorf("got %d delivered events, want 2", s.Delivered)
+	}
+	if h := n.History(time.Time{}, func(ev gorsn.Event) bool { return ev.Name == gorsn.DELETE }); len(h) != 1 {
+		t.Errorf("got history %+v, want the DELETE event", h)
+	}
+	if n.State() != gorsn.STOPPED || n.Send(Create("b")) {
+		t.Error("event sent once stopped")
+	}
+	if err := n.Stop(); !errors.Is(err, gorsn.ErrScanIsNotRunning) {
+		t.Errorf("got error %v stopping twice, want ErrScanIsNotRunning", err)
+	}
+	if err := n.StartAsync(context.Background()); !errors.Is(err, gorsn.ErrScanIsNotReady) {
+		t.Errorf("got error %v restarting, want ErrScanIsNotReady", err)
+	}
+}
+
+func TestNotifierContextCancel(t *testing.T) {
+	n := NewNotifier(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() { errc <- n.Run(ctx) }()
+	for !n.IsRunning() {
+		time.Sleep(time.Millisecond)
+	}
+	n.Send(Create("a"))
+	// blocked on the full queue until cancelled.
+	sent := make(chan bool)
+	go func() { sent <- n.Send(Create("b")) }()
+	cancel()
+	if err := <-errc; !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v from Run, want context.Canceled", err)
+	}
+	if <-sent {
+		t.Error("blocked event reported as sent once cancelled")
+	}
+	if err := n.Wait(); !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v from Wait, want context.Canceled", err)
+	}
+	if _, ok := <-n.Queue(); !ok {
+		t.Error("got the queue closed before the pending event was read")
+	}
+	if _, ok := <-n.Queue(); ok {
+		t.Error("got the queue still open once cancelled")
+	}
+}
+
+func TestNotifierSubscriptions(t *testing.T) {
+	n := NewNotifier(4).SetSubscriptionsOnly(true)
+	deletes := n.QueueFor(gorsn.DELETE)
+	if err := n.StartAsync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	n.Send(Create("a"))
+	n.Send(Delete("a"))
+	EventuallyReceives(t, deletes, Like(Delete("a")))
+	if len(n.Queue()) != 0 {
+		t.Errorf("got %d events into the main queue, want none", len(n.Queue()))
+	}
+	n.Stop()
+	n.Wait()
+	if _, ok := <-deletes; ok {
+		t.Error("got the subscription still open once stopped")
+	}
+	if _, ok := <-n.QueueFor(gorsn.CREATE); ok {
+		t.Error("got an open subscription once stopped")
+	}
+}
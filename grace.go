@@ -0,0 +1,29 @@
+package gorsn
+
+import "io/fs"
+
+// inDeleteGrace reports whether a missing path is still within the delete
+// grace period, in which case its missing counter is increased.
+func (sn *snotifier) inDeleteGrace(pi *pathInfos) bool {
+	grace := sn.opts.events.deleteGrace.Load()
+	if grace == 0 && sn.opts.events.atomicSaves.Load() && pi.mode.IsRegular() {
+		// an atomic save could replace the file across two scan cycles.
+		grace = 1
+	}
+	if pi.missing >= grace {
+		return false
+	}
+	pi.missing++
+	return true
+}
+
+// reappeared resets the missing counter of a path found again during the
+// delete grace period and reports whether it now refers to another file.
+func (sn *snotifier) reappeared(pi *pathInfos, fi fs.FileInfo) bool {
+	if pi.missing == 0 {
+		return false
+	}
+	pi.missing = 0
+	sys := sysStat(fi)
+	return sys.valid && pi.sys.valid && sys.id() != pi.sys.id()
+}
@@ -0,0 +1,118 @@
+package gorsn
+
+import (
+	"sync"
+	"time"
+)
+
+// Health holds the status of the scan notifier. It is suited to back the
+// readiness and liveness probes of a service.
+type Health struct {
+	Running  bool
+	Paused   bool
+	Stopping bool
+	// ResumeAt is the time of the automatic resumption of a timed pause,
+	// zero if none, see `PauseFor`.
+	ResumeAt time.Time
+	// LastScan is the completion time of the latest successful scan cycle.
+	LastScan time.Time
+	// SinceLastScan is the time elapsed since `LastScan`. It is zero if no
+	// scan cycle succeeded yet.
+	SinceLastScan time.Duration
+	// ScanErrors is the number of consecutive scan cycles which failed to
+	// read the root path. It is reset by the next successful scan cycle.
+	ScanErrors int
+	// LastError is the error of the latest failed scan cycle if any.
+	LastError error
+	// QueueLength is the number of events waiting into the queue.
+	QueueLength int
+	// QueueCapacity is the number of events the queue could hold.
+	QueueCapacity int
+	// QueueSaturation is the ratio of the queue in use, from 0 to 1.
+	QueueSaturation float64
+}
+
+// scanHealth tracks the outcome of the scan cycles.
+type scanHealth struct {
+	mu      sync.Mutex
+	last    time.Time
+	failed  int
+	err     error
+	current error     // error of the in-flight scan cycle.
+	next    time.Time // start time of the next scan cycle.
+}
+
+// schedule records the start time of the next scan cycle,
+// zero if none is scheduled.
+func (h *scanHealth) schedule(next time.Time) {
+	h.mu.Lock()
+	h.next = next
+	h.mu.Unlock()
+}
+
+// fail records the error of the in-flight scan cycle.
+func (h *scanHealth) fail(err error) {
+	h.mu.Lock()
+	h.current = err
+	h.mu.Unlock()
+}
+
+// done records the outcome of the completed scan cycle.
+func (h *scanHealth) done(err error, now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err == nil {
+		err = h.current
+	}
+	h.current = nil
+	if err != nil {
+		h.failed++
+		h.err = err
+		return
+	}
+	h.failed = 0
+	h.last = now
+}
+
+// LastScanAt returns the completion time of the latest successful scan
+// cycle. It is zero if no scan cycle succeeded yet.
+func (sn *snotifier) LastScanAt() time.Time {
+	sn.health.mu.Lock()
+	defer sn.health.mu.Unlock()
+	return sn.health.last
+}
+
+// NextScanAt returns the time the next scan cycle is scheduled to start.
+// It is zero while a scan cycle is in progress or while the scan notifier
+// is paused or not running.
+func (sn *snotifier) NextScanAt() time.Time {
+	sn.health.mu.Lock()
+	defer sn.health.mu.Unlock()
+	return sn.health.next
+}
+
+// Health returns the status of the scan notifier.
+func (sn *snotifier) Health() Health {
+	h := Health{
+		Running:       sn.running.Load(),
+		Paused:        sn.paused.Load(),
+		Stopping:      sn.stopping.Load(),
+		QueueLength:   len(sn.queue),
+		QueueCapacity: cap(sn.queue),
+	}
+	if h.QueueCapacity > 0 {
+		h.QueueSaturation = float64(h.QueueLength) / float64(h.QueueCapacity)
+	}
+	if at := sn.resumeAt.Load(); at != 0 && h.Paused {
+		h.ResumeAt = time.Unix(0, at)
+	}
+	sn.health.mu.Lock()
+	h.LastScan = sn.health.last
+	h.ScanErrors = sn.health.failed
+	h.LastError = sn.health.err
+	sn.health.mu.Unlock()
+	if !h.LastScan.IsZero() {
+		h.SinceLastScan = sn.now().Sub(h.LastScan)
+	}
+	return h
+}
@@ -3,9 +3,13 @@ package gorsn
 import (
 	"io/fs"
 	"path/filepath"
+	"strings"
 )
 
-func getPathType(fm fs.FileMode) pathType {
+// permBits are the mode bits whose changes are reported by a `PERM` event.
+const permBits = fs.ModePerm | fs.ModeSetuid | fs.ModeSetgid | fs.ModeSticky
+
+func getPathType(fm fs.FileMode) PathType {
 	switch {
 	case fm.IsDir() || fm&fs.ModeDir != 0:
 		return DIR
@@ -13,55 +17,175 @@ func getPathType(fm fs.FileMode) pathType {
 		return FILE
 	case fm&fs.ModeSymlink != 0:
 		return SYMLINK
+	case fm&fs.ModeNamedPipe != 0:
+		return FIFO
+	case fm&fs.ModeSocket != 0:
+		return SOCKET
+	case fm&fs.ModeDevice != 0:
+		return DEVICE
 	default:
 		return UNSUPPORTED
 	}
 }
 
+// rel returns the slash-separated path of `s` relative to the root. A path
+// which is not under the root, e.g. already relative, is only slash-separated.
+func (sn *snotifier) rel(s string) string {
+	if sn.root != "." {
+		r, ok := strings.CutPrefix(s, sn.root)
+		if ok && (r == "" || isSeparator(r[0]) || isSeparator(sn.root[len(sn.root)-1])) {
+			s = strings.TrimLeft(r, "/"+string(filepath.Separator))
+		}
+	}
+	return filepath.ToSlash(s)
+}
+
+// isSeparator reports whether `c` separates the elements of a path.
+func isSeparator(c byte) bool {
+	return c == '/' || c == filepath.Separator
+}
+
+// relative returns the path relative to the root with the separator of the
+// backend, "." for the root itself.
+func (sn *snotifier) relative(s string) string {
+	if s == sn.root {
+		return "."
+	}
+	if sn.root == "." || !strings.HasPrefix(s, sn.root) {
+		return s
+	}
+	s = strings.TrimPrefix(s, sn.root)
+	s = strings.TrimPrefix(s, string(filepath.Separator))
+	return strings.TrimPrefix(s, "/")
+}
+
+// rootType returns the type of the monitored root path.
+func (sn *snotifier) rootType() PathType {
+	if sn.single {
+		return FILE
+	}
+	return DIR
+}
+
+// halt closes the stop channel once to signal all routines to exit.
+func (sn *snotifier) halt() {
+	sn.once.Do(func() { close(sn.stop) })
+}
+
 func (sn *snotifier) finalize() {
 	sn.stopping.Store(true)
-	close(sn.stop)
+	sn.halt()
+	<-sn.ddone
 	close(sn.iqueue)
-	close(sn.queue)
 	sn.wg.Wait()
+	// the events held by a cycle interrupted by the stop.
+	sn.flushOrdered()
+	sn.size = 0
+	sn.changes = nil
+	sn.maxDepth.Store(0)
+	sn.exceeded.Store(false)
+	sn.saveState()
+	sn.cwg.Wait()
+	sn.emu.Lock()
+	close(sn.queue)
+	sn.unsubscribeAll()
+	sn.emu.Unlock()
 	sn.flush()
+	// the stored states remain valid for a future instance.
+	sn.dirty.Range(func(key, _ any) bool {
+		sn.dirty.Delete(key)
+		return true
+	})
+	sn.acks.reset()
 	sn.running.Store(false)
 	sn.stopping.Store(false)
 	sn.paused.Store(false)
-	sn.ready = false
+	sn.abort.Store(false)
+	sn.idling = false
+	sn.ready.Store(false)
+	sn.stopped.Store(true)
+	sn.notifyStop()
 }
 
-func (sn *snotifier) check(s string, t pathType, err error) (bool, error) {
+// descend tells the walk to skip the content of the sub-directories
+// when only the immediate children of the root must be monitored.
+func (sn *snotifier) descend(s string, d fs.DirEntry) error {
+	if sn.opts.filters.topLevelOnly.Load() && s != sn.root && d.IsDir() {
+		return fs.SkipDir
+	}
+	return nil
+}
+
+func (sn *snotifier) check(s string, t PathType, err error) (bool, error) {
 	if t == UNSUPPORTED {
 		return true, nil
 	}
 
-	if s == sn.root {
+	if s == sn.root && !sn.single {
 		// skip root folder.
 		return true, nil
 	}
 
-	if sn.opts.excludePaths != nil && sn.opts.excludePaths.MatchString(s) {
+	name := s
+	if normalize := sn.opts.events.pathNormalizer(); normalize != nil {
+		name = normalize(s)
+	}
+
+	if re := sn.opts.filters.excludeRegex(); re != nil && re.MatchString(name) {
+		return true, nil
+	}
+
+	if re := sn.opts.filters.includeRegex(); re != nil && !re.MatchString(name) {
+		return true, nil
+	}
+
+	if sn.opts.filters.ignoreEditorTemp.Load() && t != DIR && isEditorTemp(s) {
+		return true, nil
+	}
+
+	if sn.opts.events.atomicSaves.Load() && t != DIR && isAtomicSaveTemp(s) {
 		return true, nil
 	}
 
-	if sn.opts.includePaths != nil && !sn.opts.includePaths.MatchString(s) {
+	if sn.opts.filters.ignoreArtifacts.Load() && isArtifact(s, t == DIR) {
+		if t == DIR {
+			return true, filepath.SkipDir
+		}
 		return true, nil
 	}
 
-	if t == FILE && sn.opts.event.ignoreFile.Load() {
+	if sn.gitignored(s, t) {
+		if t == DIR {
+			return true, filepath.SkipDir
+		}
 		return true, nil
 	}
 
-	if t == DIR && sn.opts.event.ignoreFolderContent.Load() {
+	if t == FILE && sn.opts.filters.ignoreFile.Load() {
+		return true, nil
+	}
+
+	if t == DIR && sn.opts.filters.ignoreFolderContent.Load() {
 		return true, filepath.SkipDir
 	}
 
-	if t == DIR && sn.opts.event.ignoreFolder.Load() {
+	if t == DIR && sn.opts.filters.ignoreFolder.Load() {
+		return true, nil
+	}
+
+	if t == SYMLINK && sn.opts.filters.ignoreSymlink.Load() {
+		return true, nil
+	}
+
+	if t == FIFO && sn.opts.filters.ignoreFifo.Load() {
+		return true, nil
+	}
+
+	if t == SOCKET && sn.opts.filters.ignoreSocket.Load() {
 		return true, nil
 	}
 
-	if t == SYMLINK && sn.opts.event.ignoreSymlink.Load() {
+	if t == DEVICE && sn.opts.filters.ignoreDevice.Load() {
 		return true, nil
 	}
 
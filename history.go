@@ -0,0 +1,122 @@
+package gorsn
+
+import (
+	"regexp"
+	"sync"
+	"time"
+)
+
+// Filter reports whether an event should be kept by `History`.
+type Filter func(Event) bool
+
+// FilterNames keeps the events with one of the given names.
+func FilterNames(names ...EventName) Filter {
+	return func(ev Event) bool {
+		for _, n := range names {
+			if ev.Name == n {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// FilterTypes keeps the events of paths with one of the given types.
+func FilterTypes(types ...PathType) Filter {
+	return func(ev Event) bool {
+		for _, t := range types {
+			if ev.Type == t {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// FilterPaths keeps the events of paths which match `re`.
+func FilterPaths(re *regexp.Regexp) Filter {
+	return func(ev Event) bool {
+		return re.MatchString(ev.Path)
+	}
+}
+
+// historyRecord is an event stamped with its delivery time.
+type historyRecord struct {
+	at time.Time
+	ev Event
+}
+
+// history is a ring of the latest delivered events.
+type history struct {
+	mu   sync.Mutex
+	recs []historyRecord
+	next int
+	full bool
+}
+
+// add records an event into the ring, which is resized first
+// if its depth was changed.
+func (h *history) add(ev Event, size int, now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.recs) != size {
+		h.resize(size)
+	}
+	if size == 0 {
+		return
+	}
+	h.recs[h.next] = historyRecord{now, ev}
+	h.next = (h.next + 1) % size
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// list returns the records from the oldest to the most recent.
+func (h *history) list() []historyRecord {
+	if !h.full {
+		return append([]historyRecord{}, h.recs[:h.next]...)
+	}
+	return append(append([]historyRecord{}, h.recs[h.next:]...), h.recs[:h.next]...)
+}
+
+// resize changes the depth of the ring and keeps the most recent records.
+func (h *history) resize(size int) {
+	recs := h.list()
+	if len(recs) > size {
+		recs = recs[len(recs)-size:]
+	}
+	h.recs, h.next, h.full = nil, 0, false
+	if size == 0 {
+		return
+	}
+	h.recs = make([]historyRecord, size)
+	copy(h.recs, recs)
+	h.next = len(recs) % size
+	h.full = len(recs) == size
+}
+
+// History returns the recorded events delivered since a given time.
+func (sn *snotifier) History(since time.Time, filters ...Filter) []Event {
+	sn.history.mu.Lock()
+	recs := sn.history.list()
+	sn.history.mu.Unlock()
+	var events []Event
+	for _, rec := range recs {
+		if rec.at.Before(since) || !keep(rec.ev, filters) {
+			continue
+		}
+		events = append(events, rec.ev)
+	}
+	return events
+}
+
+// keep reports whether an event is accepted by all filters.
+func keep(ev Event, filters []Filter) bool {
+	for _, f := range filters {
+		if f != nil && !f(ev) {
+			return false
+		}
+	}
+	return true
+}
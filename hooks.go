@@ -0,0 +1,99 @@
+package gorsn
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ScanSummary holds the statistics of a completed scan cycle.
+type ScanSummary struct {
+	// Cycle is the sequence number of the scan cycle.
+	Cycle int
+	// Visited is the number of paths sent for changes detection.
+	Visited int64
+	// Emitted is the number of events successfully queued.
+	Emitted int64
+	// Duration is the time spent to walk the tree and emit events.
+	Duration time.Duration
+	// Workers is the number of workers which checked the paths.
+	Workers int
+	// Counts is the number of events queued per kind.
+	Counts map[EventName]int64
+	// Errors is the number of events queued which carry an error.
+	Errors int64
+}
+
+// eventCounts counts the events queued during a scan cycle per kind.
+type eventCounts struct {
+	mu     sync.Mutex
+	names  map[EventName]int64
+	errors int64
+}
+
+func (c *eventCounts) add(ev Event) {
+	c.mu.Lock()
+	if c.names == nil {
+		c.names = make(map[EventName]int64)
+	}
+	c.names[ev.Name]++
+	if ev.Error != nil {
+		c.errors++
+	}
+	c.mu.Unlock()
+}
+
+func (c *eventCounts) reset() {
+	c.mu.Lock()
+	c.names, c.errors = nil, 0
+	c.mu.Unlock()
+}
+
+// snapshot returns a copy of the counts per kind and the number of errors.
+func (c *eventCounts) snapshot() (map[EventName]int64, int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	names := make(map[EventName]int64, len(c.names))
+	for n, v := range c.names {
+		names[n] = v
+	}
+	return names, c.errors
+}
+
+// beforeScan runs the user-defined pre-scan callback if any.
+func (sn *snotifier) beforeScan(cycle int) {
+	sn.traceCycle(cycle)
+	if fn, ok := sn.opts.scan.beforeScan.Load().(func(int)); ok && fn != nil {
+		fn(cycle)
+	}
+}
+
+// afterScan runs the user-defined post-scan callback if any
+// and the registered observers with the statistics collected
+// during the cycle.
+func (sn *snotifier) afterScan(cycle int, start time.Time) {
+	summary := ScanSummary{
+		Cycle:    cycle,
+		Visited:  sn.visited.Load(),
+		Emitted:  sn.emitted.Load(),
+		Duration: sn.now().Sub(start),
+		Workers:  int(sn.size),
+	}
+	summary.Counts, summary.Errors = sn.counts.snapshot()
+	sn.trace(traceRecord{
+		Kind:     traceCycleEnd,
+		Path:     sn.root,
+		Detail:   fmt.Sprintf("visited=%d emitted=%d", summary.Visited, summary.Emitted),
+		Duration: summary.Duration,
+	})
+	if fn, ok := sn.opts.scan.afterScan.Load().(func(int, ScanSummary)); ok && fn != nil {
+		fn(cycle, summary)
+	}
+	sn.notifyCycle(summary)
+	if sn.opts.events.scanSummary.Load() {
+		sn.deliver(Event{Path: sn.root, Type: sn.rootType(), Name: SCAN_SUMMARY, Cycle: cycle, Summary: &summary})
+	}
+	sn.detectAnomaly(summary.Emitted)
+	sn.checkSizes()
+	sn.checkBudget()
+}
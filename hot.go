@@ -0,0 +1,139 @@
+package gorsn
+
+import (
+	"context"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// hotPaths holds the paths scanned more frequently.
+type hotPaths struct {
+	patterns []*regexp.Regexp
+	interval time.Duration
+}
+
+// hotPaths returns the hot paths settings if enabled for the `interval`.
+func (so *ScanOptions) hotPaths(interval time.Duration) *hotPaths {
+	hp, _ := so.hot.Load().(*hotPaths)
+	if hp == nil || len(hp.patterns) == 0 || hp.interval <= 0 || hp.interval >= interval {
+		return nil
+	}
+	return hp
+}
+
+// rest waits for the next scan cycle and scans the hot paths
+// in the meantime. It returns earlier once stopped, cancelled or paused.
+func (sn *snotifier) rest(ctx context.Context) {
+	interval := sn.opts.scan.interval.Load().(time.Duration)
+	sn.health.schedule(sn.now().Add(interval))
+	defer sn.health.schedule(time.Time{})
+	if sn.watchChanges() {
+		sn.awaitChanges(ctx, interval)
+		return
+	}
+	hp := sn.opts.scan.hotPaths(interval)
+	if hp == nil {
+		sn.sleep(ctx, interval)
+		return
+	}
+	var waited time.Duration
+	for ; waited+hp.interval < interval; waited += hp.interval {
+		if !sn.sleep(ctx, hp.interval) || sn.paused.Load() {
+			return
+		}
+		sn.hotScan(ctx, hp)
+	}
+	sn.sleep(ctx, interval-waited)
+}
+
+// sleep waits for the duration `d`. It returns false if the wait
+// was interrupted by a stop or a context cancellation.
+func (sn *snotifier) sleep(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-sn.stop:
+		return false
+	case <-sn.opts.scan.clock.After(d):
+		return true
+	}
+}
+
+// hotScan checks the known paths which match the hot patterns
+// along with their content.
+func (sn *snotifier) hotScan(ctx context.Context, hp *hotPaths) {
+	sn.rescan(ctx, sn.hotRoots(hp))
+}
+
+// rescan checks the paths under `roots` along with their content
+// and reports the missing ones.
+func (sn *snotifier) rescan(ctx context.Context, roots []string) {
+	if len(roots) == 0 {
+		return
+	}
+	sn.aborted.Store(false)
+	sn.ordering.Store(sn.opts.delivery.ordered.Load())
+	sn.hsem = make(chan struct{}, sn.opts.scan.hashWorkers.Load())
+	for _, root := range roots {
+		walk(sn.opts.scan.backend, root, sn.walker(ctx))
+	}
+	sn.items.Wait()
+	sn.hwg.Wait()
+
+	if sn.aborted.Load() {
+		sn.unvisit()
+	} else if sn.needsMissingPaths() {
+		sn.missingPaths(roots)
+	}
+	sn.unvisitCold()
+	sn.flushLinks()
+	sn.flushPending()
+	sn.flushOrdered()
+}
+
+// hotRoots returns the known paths matching the hot patterns,
+// without the ones located under another returned path.
+func (sn *snotifier) hotRoots(hp *hotPaths) []string {
+	var roots []string
+	sn.paths.Range(func(key, _ any) bool {
+		path := key.(string)
+		rel := sn.rel(path)
+		for _, re := range hp.patterns {
+			if re.MatchString(rel) {
+				roots = append(roots, path)
+				break
+			}
+		}
+		return true
+	})
+	return topRoots(roots)
+}
+
+// topRoots sorts the paths and drops the ones located under another.
+func topRoots(roots []string) []string {
+	sort.Strings(roots)
+	var kept []string
+	for _, root := range roots {
+		if len(kept) > 0 && isUnder(root, kept[len(kept)-1:]) {
+			continue
+		}
+		kept = append(kept, root)
+	}
+	return kept
+}
+
+// isUnder reports whether the path is one of `roots` or located under one.
+func isUnder(path string, roots []string) bool {
+	for _, root := range roots {
+		if !strings.HasPrefix(path, root) {
+			continue
+		}
+		if len(path) == len(root) || path[len(root)] == '/' || path[len(root)] == filepath.Separator {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package gorsn
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSetHotPaths(t *testing.T) {
+	tests := []struct {
+		glob  string
+		fails bool
+	}{
+		{"logs/**", false},
+		{"**/*.[ch]", false},
+		{"[!.]*", false},
+		{"[", false},
+		{"[]", true},
+		{"[!]", true},
+		{"[]]", true},
+		{"[z-a]", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.glob, func(t *testing.T) {
+			opts := defaultOpts()
+			if err := opts.Scan().SetHotPaths([]string{"src"}, time.Millisecond); err != nil {
+				t.Fatal(err)
+			}
+			err := opts.Scan().SetHotPaths([]string{"docs", tt.glob}, time.Millisecond)
+			if !tt.fails {
+				if err != nil {
+					t.Fatalf("got error %v for a valid glob", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidOptions) {
+				t.Fatalf("got error %v, want ErrInvalidOptions", err)
+			}
+			hp := opts.Scan().hotPaths(time.Second)
+			if hp == nil || len(hp.patterns) != 1 || !hp.patterns[0].MatchString("src") {
+				t.Error("the previous hot paths were not kept")
+			}
+		})
+	}
+}
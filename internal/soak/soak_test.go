@@ -0,0 +1,211 @@
+//go:build soak
+
+// Package soak holds an opt-in long-duration test which runs the scan notifier
+// against a temporary tree continuously churned by random creates, writes,
+// renames, deletes and permissions changes. At regular checkpoints the churn is
+// paused and the tree rebuilt from the received events must match the real one.
+// Once stopped, goroutines and heap usage must come back close to their initial
+// values. It fails on the first failed assertion.
+//
+//	go test -tags soak ./internal/soak -timeout 0 -soak.duration 10m -soak.workers 4
+package soak
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/fs"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jeamon/gorsn"
+)
+
+var (
+	duration   = flag.Duration("soak.duration", 2*time.Minute, "total duration of the soak")
+	checkEvery = flag.Duration("soak.check", 15*time.Second, "delay between two checkpoints")
+	interval   = flag.Duration("soak.interval", 50*time.Millisecond, "scan interval of the notifier")
+	workers    = flag.Int("soak.workers", 4, "number of workers of the notifier")
+	maxEntries = flag.Int("soak.entries", 500, "maximum number of entries into the tree")
+	seed       = flag.Int64("soak.seed", time.Now().UnixNano(), "seed of the random churn")
+)
+
+// model is the tree rebuilt from the received events.
+type model struct {
+	mu    sync.Mutex
+	paths map[string]bool
+	count int
+}
+
+func (m *model) apply(ev gorsn.Event) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.count++
+	switch ev.Name {
+	case gorsn.CREATE, gorsn.LINK:
+		m.paths[ev.Path] = true
+	case gorsn.DELETE, gorsn.UNLINK:
+		delete(m.paths, ev.Path)
+	case gorsn.RENAME:
+		delete(m.paths, ev.OldPath)
+		m.paths[ev.Path] = true
+	}
+}
+
+// churner randomly mutates the tree rooted at root.
+type churner struct {
+	root string
+	rnd  *rand.Rand
+	ops  int
+}
+
+func (c *churner) entries() []string {
+	var list []string
+	filepath.WalkDir(c.root, func(p string, d fs.DirEntry, err error) error {
+		if err == nil && p != c.root {
+			list = append(list, p)
+		}
+		return nil
+	})
+	return list
+}
+
+func (c *churner) dirs(list []string) []string {
+	dirs := []string{c.root}
+	for _, p := range list {
+		if fi, err := os.Lstat(p); err == nil && fi.IsDir() {
+			dirs = append(dirs, p)
+		}
+	}
+	return dirs
+}
+
+func (c *churner) step() {
+	list := c.entries()
+	dirs := c.dirs(list)
+	name := fmt.Sprintf("e%06d", c.rnd.Intn(1_000_000))
+	parent := dirs[c.rnd.Intn(len(dirs))]
+	c.ops++
+	switch op := c.rnd.Intn(10); {
+	case op < 3 && len(list) < *maxEntries:
+		os.WriteFile(filepath.Join(parent, name+".txt"), []byte(name), 0o644)
+	case op < 4 && len(list) < *maxEntries:
+		os.Mkdir(filepath.Join(parent, name), 0o755)
+	case op < 6 && len(list) > 0:
+		p := list[c.rnd.Intn(len(list))]
+		if fi, err := os.Lstat(p); err == nil && fi.Mode().IsRegular() {
+			os.WriteFile(p, []byte(time.Now().String()), 0o644)
+		}
+	case op < 7 && len(list) > 0:
+		p := list[c.rnd.Intn(len(list))]
+		os.Chmod(p, fs.FileMode(0o600|c.rnd.Intn(0o100)<<3))
+	case op < 8 && len(list) > 0:
+		p := list[c.rnd.Intn(len(list))]
+		os.Rename(p, filepath.Join(c.root, name+".moved"))
+	case len(list) > 0:
+		os.RemoveAll(list[c.rnd.Intn(len(list))])
+	}
+}
+
+// check compares the tree rebuilt from events with the real one.
+func check(m *model, c *churner) error {
+	real := map[string]bool{}
+	for _, p := range c.entries() {
+		real[p] = true
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for p := range real {
+		if !m.paths[p] {
+			return fmt.Errorf("missing CREATE for %q", p)
+		}
+	}
+	for p := range m.paths {
+		if !real[p] {
+			return fmt.Errorf("missing DELETE for %q", p)
+		}
+	}
+	return nil
+}
+
+func heapInUse() uint64 {
+	runtime.GC()
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	return ms.HeapInuse
+}
+
+func TestSoak(t *testing.T) {
+	t.Logf("duration=%s seed=%d", *duration, *seed)
+	root := t.TempDir()
+
+	baseGoroutines := runtime.NumGoroutine()
+	baseHeap := heapInUse()
+
+	opts := &gorsn.Options{}
+	opts.Scan().SetInterval(*interval).SetMaxWorkers(*workers)
+	opts.Delivery().SetQueueSize(1000)
+	sn, err := gorsn.New(root, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &model{paths: map[string]bool{}}
+	consumed := make(chan struct{})
+	go func() {
+		for ev := range sn.Queue() {
+			m.apply(ev)
+		}
+		close(consumed)
+	}()
+
+	started := make(chan error, 1)
+	go func() { started <- sn.Start(context.Background()) }()
+
+	c := &churner{root: root, rnd: rand.New(rand.NewSource(*seed))}
+	end := time.Now().Add(*duration)
+	next := time.Now().Add(*checkEvery)
+	for time.Now().Before(end) {
+		c.step()
+		time.Sleep(time.Millisecond)
+		if time.Now().Before(next) {
+			continue
+		}
+		// let a few cycles complete without churn.
+		time.Sleep(5 * *interval)
+		if err := check(m, c); err != nil {
+			t.Fatalf("checkpoint failed after %d ops: %v", c.ops, err)
+		}
+		t.Logf("checkpoint ok - ops=%d events=%d goroutines=%d", c.ops, m.count, runtime.NumGoroutine())
+		next = time.Now().Add(*checkEvery)
+	}
+
+	time.Sleep(5 * *interval)
+	if err := check(m, c); err != nil {
+		t.Fatalf("final check failed after %d ops: %v", c.ops, err)
+	}
+	if err := sn.Stop(); err != nil {
+		t.Fatalf("stop failed: %v", err)
+	}
+	if err := <-started; err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	<-consumed
+
+	// leaks detection once everything exited.
+	time.Sleep(100 * time.Millisecond)
+	if n := runtime.NumGoroutine(); n > baseGoroutines {
+		buf := make([]byte, 1<<20)
+		t.Fatalf("%d goroutines leaked\n%s", n-baseGoroutines, buf[:runtime.Stack(buf, true)])
+	}
+	sn = nil
+	if heap := heapInUse(); heap > 2*baseHeap+(8<<20) {
+		t.Fatalf("heap grew from %d to %d bytes", baseHeap, heap)
+	}
+	t.Logf("success - ops=%d events=%d", c.ops, m.count)
+}
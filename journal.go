@@ -0,0 +1,208 @@
+package gorsn
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	journalPrefix = "journal-"
+	journalSuffix = ".jsonl"
+)
+
+// Journal is a Sink which appends each event as a JSON line into files of
+// a directory, before the event is sent to the queue. Files are rotated by
+// size and age so the events could be replayed with `ReplayJournal` after
+// a consumer crash.
+type Journal struct {
+	mu       sync.Mutex
+	dir      string
+	maxSize  int64
+	maxAge   time.Duration
+	maxFiles int
+	f        *os.File
+	size     int64
+	opened   time.Time
+}
+
+// NewJournal returns a Journal which writes into `dir`, created if needed.
+// A new file is started once the current one reached `maxSize` bytes or is
+// older than `maxAge`. Zero values disable the related rotation. All the
+// files are kept unless limited with `SetMaxFiles`. It should be closed once
+// the scan notifier has stopped.
+func NewJournal(dir string, maxSize int64, maxAge time.Duration) (*Journal, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrJournalFailure, err)
+	}
+	return &Journal{dir: dir, maxSize: maxSize, maxAge: maxAge}, nil
+}
+
+// Write appends the event to the current journal file.
+func (j *Journal) Write(ev Event) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	rotated, err := j.rotate()
+	if err != nil {
+		return err
+	}
+	line, err := json.Marshal(newAuditRecord(ev))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrJournalFailure, err)
+	}
+	n, err := j.f.Write(append(line, '\n'))
+	j.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrJournalFailure, err)
+	}
+	if rotated {
+		// prune once the event is safely recorded.
+		return j.prune()
+	}
+	return nil
+}
+
+// SetMaxFiles defines the number of most recent journal files kept into the
+// directory. The oldest ones are removed once a new file is started. A zero
+// or negative value keeps all of them, which is the default, so they must be
+// pruned by other means.
+func (j *Journal) SetMaxFiles(n int) *Journal {
+	if n < 0 {
+		n = 0
+	}
+	j.mu.Lock()
+	j.maxFiles = n
+	j.mu.Unlock()
+	return j
+}
+
+// rotate opens a new journal file if none is opened or if the current
+// one reached its maximum size or age. It reports whether it did so.
+func (j *Journal) rotate() (bool, error) {
+	if j.f != nil {
+		full := j.maxSize > 0 && j.size >= j.maxSize
+		old := j.maxAge > 0 && time.Since(j.opened) >= j.maxAge
+		if !full && !old {
+			return false, nil
+		}
+		j.f.Close()
+		j.f = nil
+	}
+	now := time.Now()
+	name := filepath.Join(j.dir, fmt.Sprintf("%s%020d%s", journalPrefix, now.UnixNano(), journalSuffix))
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrJournalFailure, err)
+	}
+	j.f, j.size, j.opened = f, 0, now
+	return true, nil
+}
+
+// prune removes the oldest journal files beyond the maximum number of files.
+func (j *Journal) prune() error {
+	if j.maxFiles == 0 {
+		return nil
+	}
+	names, err := journalFiles(j.dir)
+	if err != nil {
+		return err
+	}
+	for len(names) > j.maxFiles {
+		if err := os.Remove(filepath.Join(j.dir, names[0])); err != nil {
+			return fmt.Errorf("%w: %v", ErrJournalFailure, err)
+		}
+		names = names[1:]
+	}
+	return nil
+}
+
+// Close closes the current journal file.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.f == nil {
+		return nil
+	}
+	err := j.f.Close()
+	j.f = nil
+	return err
+}
+
+// ReplayJournal returns the events recorded into the journal files of `dir`
+// between `from` and `to` included, from the oldest to the most recent. A
+// zero `to` means no upper limit. An invalid last line of a file, such as
+// truncated by a crashed process, is skipped. Any other invalid line fails
+// with an error which wraps `ErrJournalFailure`.
+func ReplayJournal(dir string, from, to time.Time) ([]Event, error) {
+	names, err := journalFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	for _, name := range names {
+		recs, err := readJournal(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		for _, rec := range recs {
+			if rec.Time.Before(from) || (!to.IsZero() && rec.Time.After(to)) {
+				continue
+			}
+			events = append(events, rec.event())
+		}
+	}
+	return events, nil
+}
+
+// journalFiles returns the names of the journal files of `dir` from the
+// oldest to the most recent.
+func journalFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrJournalFailure, err)
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasPrefix(e.Name(), journalPrefix) && strings.HasSuffix(e.Name(), journalSuffix) {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// readJournal decodes the records of a journal file. An invalid line is
+// only tolerated at the end of the file.
+func readJournal(name string) ([]auditRecord, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrJournalFailure, err)
+	}
+	defer f.Close()
+	var recs []auditRecord
+	var bad error
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, 1<<20)
+	for n := 1; sc.Scan(); n++ {
+		if bad != nil {
+			return nil, bad
+		}
+		var rec auditRecord
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			bad = fmt.Errorf("%w: %s line %d: %v", ErrJournalFailure, name, n, err)
+			continue
+		}
+		recs = append(recs, rec)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrJournalFailure, name, err)
+	}
+	return recs, nil
+}
@@ -0,0 +1,99 @@
+package gorsn
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeJournal records an event of each path of `before` into a journal
+// file of `dir`, then appends the raw `tail` and the events of `after`.
+func writeJournal(t *testing.T, dir string, before []string, tail string, after []string) {
+	t.Helper()
+	j, err := NewJournal(dir, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	write := func(paths []string) {
+		for _, p := range paths {
+			if err := j.Write(Event{Path: p, Type: FILE, Name: CREATE, Time: time.Now()}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	write(before)
+	if _, err := j.f.WriteString(tail); err != nil {
+		t.Fatal(err)
+	}
+	write(after)
+	if err := j.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReplayJournal(t *testing.T) {
+	tests := []struct {
+		name   string
+		before []string
+		tail   string
+		after  []string
+		want   []string
+		fails  bool
+	}{
+		{"complete", []string{"a", "b"}, "", []string{"c"}, []string{"a", "b", "c"}, false},
+		{"truncated last line", []string{"a", "b"}, `{"path":"c","ty`, nil, []string{"a", "b"}, false},
+		{"invalid line", []string{"a"}, "{\n", []string{"c"}, nil, true},
+		{"line too long", []string{"a"}, `{"path":"` + strings.Repeat("x", 1<<20) + "\"}\n", nil, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeJournal(t, dir, tt.before, tt.tail, tt.after)
+			events, err := ReplayJournal(dir, time.Time{}, time.Time{})
+			if tt.fails {
+				if !errors.Is(err, ErrJournalFailure) {
+					t.Fatalf("got error %v, want ErrJournalFailure", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, ev := range events {
+				got = append(got, ev.Path)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got replayed paths %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJournalMaxFiles(t *testing.T) {
+	dir := t.TempDir()
+	j, err := NewJournal(dir, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	j.SetMaxFiles(2)
+	for _, p := range []string{"a", "b", "c", "d"} {
+		if err := j.Write(Event{Path: p, Type: FILE, Name: CREATE, Time: time.Now()}); err != nil {
+			t.Fatal(err)
+		}
+		// each event goes to its own file with a distinct name.
+		time.Sleep(time.Millisecond)
+	}
+	if err := j.Close(); err != nil {
+		t.Fatal(err)
+	}
+	events, err := ReplayJournal(dir, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].Path != "c" || events[1].Path != "d" {
+		t.Errorf("got replayed events %v, want the ones of c and d", events)
+	}
+}
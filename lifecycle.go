@@ -0,0 +1,32 @@
+package gorsn
+
+// LifecycleState is the stage of the scan notifier lifecycle.
+type LifecycleState string
+
+const (
+	IDLE     LifecycleState = "IDLE"     // created and not yet started.
+	RUNNING  LifecycleState = "RUNNING"  // scanning the root path.
+	PAUSED   LifecycleState = "PAUSED"   // started but not scanning.
+	STOPPING LifecycleState = "STOPPING" // in the process of closing.
+	STOPPED  LifecycleState = "STOPPED"  // closed and could not be restarted.
+)
+
+// IsPaused reports whether the scan notifier was paused and not resumed.
+func (sn *snotifier) IsPaused() bool {
+	return sn.running.Load() && sn.paused.Load()
+}
+
+// State returns the current stage of the scan notifier lifecycle.
+func (sn *snotifier) State() LifecycleState {
+	switch {
+	case sn.stopping.Load():
+		return STOPPING
+	case sn.running.Load() && sn.paused.Load():
+		return PAUSED
+	case sn.running.Load():
+		return RUNNING
+	case sn.stopped.Load():
+		return STOPPED
+	}
+	return IDLE
+}
@@ -0,0 +1,161 @@
+package gorsn
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// waitErr returns the reason returned by Wait or fails the test if the scan
+// notifier does not terminate after a few seconds.
+func waitErr(t *testing.T, sn ScanNotifier) error {
+	t.Helper()
+	errc := make(chan error, 1)
+	go func() { errc <- sn.Wait() }()
+	select {
+	case err := <-errc:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the termination")
+		return nil
+	}
+}
+
+func TestStartAsyncCancel(t *testing.T) {
+	sn, err := New(t.TempDir(), defaultOpts())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sn.Wait(); !errors.Is(err, ErrScanIsNotRunning) {
+		t.Errorf("got error %v from Wait before starting, want ErrScanIsNotRunning", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := sn.StartAsync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if s := sn.State(); s != RUNNING {
+		t.Errorf("got state %s once started, want %s", s, RUNNING)
+	}
+	cancel()
+	if err := waitErr(t, sn); !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v from Wait, want context.Canceled", err)
+	}
+	if _, ok := <-sn.Queue(); ok {
+		t.Error("got the queue open once terminated")
+	}
+	if s := sn.State(); s != STOPPED {
+		t.Errorf("got state %s once terminated, want %s", s, STOPPED)
+	}
+	// Wait keeps returning the reason once terminated.
+	if err := sn.Wait(); !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v from a second Wait, want context.Canceled", err)
+	}
+}
+
+func TestStartAsyncStop(t *testing.T) {
+	sn, err := New(t.TempDir(), defaultOpts())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sn.StartAsync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := sn.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if err := waitErr(t, sn); err != nil {
+		t.Errorf("got error %v from Wait once stopped, want nil", err)
+	}
+}
+
+func TestStartAsyncTwice(t *testing.T) {
+	sn, err := New(t.TempDir(), defaultOpts())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := sn.StartAsync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := sn.StartAsync(ctx); !errors.Is(err, ErrScanAlreadyStarted) {
+		t.Errorf("got error %v from a second start, want ErrScanAlreadyStarted", err)
+	}
+	if err := sn.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if err := waitErr(t, sn); err != nil {
+		t.Fatal(err)
+	}
+	if err := sn.StartAsync(ctx); !errors.Is(err, ErrScanIsNotReady) {
+		t.Errorf("got error %v from a start once stopped, want ErrScanIsNotReady", err)
+	}
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  func() (context.Context, context.CancelFunc)
+		stop bool
+		want error
+	}{
+		{"stopped", func() (context.Context, context.CancelFunc) {
+			return context.WithCancel(context.Background())
+		}, true, nil},
+		{"deadline exceeded", func() (context.Context, context.CancelFunc) {
+			return context.WithTimeout(context.Background(), 10*time.Millisecond)
+		}, false, context.DeadlineExceeded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sn, err := New(t.TempDir(), defaultOpts())
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx, cancel := tt.ctx()
+			defer cancel()
+			errc := make(chan error, 1)
+			go func() { errc <- sn.Run(ctx) }()
+			if tt.stop {
+				for !sn.IsRunning() {
+					time.Sleep(time.Millisecond)
+				}
+				if err := sn.Stop(); err != nil {
+					t.Fatal(err)
+				}
+			}
+			select {
+			case err := <-errc:
+				if !errors.Is(err, tt.want) {
+					t.Errorf("got error %v from Run, want %v", err, tt.want)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("timed out waiting for Run to return")
+			}
+			if err := sn.Wait(); !errors.Is(err, tt.want) {
+				t.Errorf("got error %v from Wait, want the reason returned by Run", err)
+			}
+		})
+	}
+}
+
+func TestRunTwice(t *testing.T) {
+	sn, err := New(t.TempDir(), defaultOpts())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sn.StartAsync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Stop()
+	errc := make(chan error, 1)
+	go func() { errc <- sn.Run(context.Background()) }()
+	select {
+	case err := <-errc:
+		if !errors.Is(err, ErrScanAlreadyStarted) {
+			t.Errorf("got error %v from Run of a started notifier, want ErrScanAlreadyStarted", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run of a started notifier blocked")
+	}
+}
@@ -0,0 +1,123 @@
+package gorsn
+
+import (
+	"io/fs"
+	"sort"
+)
+
+// LimitPolicy defines how new paths are handled once the number
+// of tracked paths reached the limit set via `SetMaxTrackedPaths`.
+type LimitPolicy uint32
+
+const (
+	// LIMIT_ERROR does not track the new paths and emits an `ERROR`
+	// event with `ErrTooManyPaths` once per scan cycle.
+	LIMIT_ERROR LimitPolicy = iota
+	// LIMIT_SKIP does not track the new paths nor descend into
+	// the new directories.
+	LIMIT_SKIP
+	// LIMIT_EVICT forgets the least recently seen paths to make room for
+	// the new ones. Evicted paths are not reported as deleted and are
+	// reported as created if they are seen again.
+	LIMIT_EVICT
+)
+
+// track registers the path into the cache.
+func (sn *snotifier) track(path string, pi *pathInfos) {
+	pi.seen.Store(sn.epoch.Load())
+	if _, loaded := sn.paths.Swap(path, pi); !loaded {
+		sn.tracked.Add(1)
+		sn.footprint.Add(pathCost + int64(len(path)))
+	}
+}
+
+// untrack removes the path from the cache and from the store.
+func (sn *snotifier) untrack(path any) {
+	if sn.forget(path.(string)) {
+		sn.changed(path.(string))
+	}
+}
+
+// forget removes the path from the cache only and reports whether it was
+// cached.
+func (sn *snotifier) forget(path string) bool {
+	if _, loaded := sn.paths.LoadAndDelete(path); !loaded {
+		return false
+	}
+	sn.unlocked(path)
+	sn.tracked.Add(-1)
+	sn.footprint.Add(-pathCost - int64(len(path)))
+	return true
+}
+
+// full reports whether the tracked paths reached the limit.
+func (sn *snotifier) full() bool {
+	max := sn.opts.scan.maxTracked.Load()
+	return max > 0 && sn.tracked.Load() >= max
+}
+
+// admit reports whether a new path could be tracked. It makes room
+// for it under the `LIMIT_EVICT` policy or emits an `ERROR` event
+// under the `LIMIT_ERROR` policy. New paths are not tracked while
+// the memory budget is exceeded.
+func (sn *snotifier) admit(path string, pt PathType) bool {
+	if sn.overBudget() {
+		sn.budgetExceeded(path, pt)
+		return false
+	}
+	if !sn.full() {
+		return true
+	}
+	switch LimitPolicy(sn.opts.scan.limitPolicy.Load()) {
+	case LIMIT_EVICT:
+		sn.evict()
+		return true
+	case LIMIT_ERROR:
+		if !sn.overflowed.Swap(true) && !sn.opts.events.ignoreErrors.Load() {
+			sn.queueEvent(Event{Path: path, Type: pt, Name: ERROR, Error: ErrTooManyPaths})
+		}
+	}
+	return false
+}
+
+// skipUntracked tells the scanner to skip a path not yet tracked under
+// the `LIMIT_SKIP` policy once the limit was reached.
+func (sn *snotifier) skipUntracked(s string, d fs.DirEntry) (bool, error) {
+	if LimitPolicy(sn.opts.scan.limitPolicy.Load()) != LIMIT_SKIP || !sn.full() {
+		return false, nil
+	}
+	if _, known := sn.paths.Load(s); known {
+		return false, nil
+	}
+	if d.IsDir() {
+		return true, fs.SkipDir
+	}
+	return true, nil
+}
+
+// evict forgets a tenth of the tracked paths, the least recently seen first.
+func (sn *snotifier) evict() {
+	sn.tmu.Lock()
+	defer sn.tmu.Unlock()
+	if !sn.full() {
+		// room made by a concurrent eviction.
+		return
+	}
+	type entry struct {
+		path string
+		seen int64
+	}
+	var entries []entry
+	sn.paths.Range(func(key, value any) bool {
+		entries = append(entries, entry{key.(string), value.(*pathInfos).seen.Load()})
+		return true
+	})
+	sort.Slice(entries, func(i, j int) bool { return entries[i].seen < entries[j].seen })
+	n := len(entries)/10 + 1
+	for _, e := range entries[:n] {
+		if v, ok := sn.paths.Load(e.path); ok {
+			sn.linkRemove(e.path, v.(*pathInfos).sys)
+		}
+		sn.untrack(e.path)
+	}
+}
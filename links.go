@@ -0,0 +1,166 @@
+package gorsn
+
+import (
+	"io/fs"
+	"time"
+)
+
+// fileID identifies a file on a device regardless of its paths.
+type fileID struct {
+	dev uint64
+	ino uint64
+}
+
+// linkIndex keeps the monitored paths of each file identity.
+type linkIndex map[fileID]map[string]struct{}
+
+func (s sysInfo) id() fileID {
+	return fileID{s.dev, s.ino}
+}
+
+// linkAdd registers the path of a file and reports whether
+// that file was already known under another path.
+func (sn *snotifier) linkAdd(path string, sys sysInfo) (string, bool) {
+	if !sys.valid || !sn.opts.events.trackLinks.Load() {
+		return "", false
+	}
+	sn.lmu.Lock()
+	defer sn.lmu.Unlock()
+	if sn.links == nil {
+		sn.links = make(linkIndex)
+	}
+	paths, ok := sn.links[sys.id()]
+	if !ok {
+		paths = make(map[string]struct{})
+		sn.links[sys.id()] = paths
+	}
+	if _, exists := paths[path]; exists {
+		return "", false
+	}
+	var other string
+	for p := range paths {
+		other = p
+		break
+	}
+	paths[path] = struct{}{}
+	return other, other != ""
+}
+
+// linkRemove unregisters the path of a file and reports whether
+// that file is still known under another path.
+func (sn *snotifier) linkRemove(path string, sys sysInfo) (string, bool) {
+	if !sys.valid {
+		return "", false
+	}
+	sn.lmu.Lock()
+	defer sn.lmu.Unlock()
+	paths, ok := sn.links[sys.id()]
+	if !ok {
+		return "", false
+	}
+	delete(paths, path)
+	if len(paths) == 0 {
+		delete(sn.links, sys.id())
+		return "", false
+	}
+	if !sn.opts.events.trackLinks.Load() {
+		return "", false
+	}
+	var other string
+	for p := range paths {
+		other = p
+		break
+	}
+	return other, true
+}
+
+// heldLink is a change of the number of links of a known path held until
+// the end of the scan cycle.
+type heldLink struct {
+	ev    Event
+	id    fileID
+	delta int
+}
+
+// holdLink keeps the `LINK` or `UNLINK` event of a known path whose number
+// of links changed by `delta` until the paths linked and unlinked within the
+// root directory during the cycle are known.
+func (sn *snotifier) holdLink(ev Event, id fileID, delta int) {
+	sn.lmu.Lock()
+	sn.held = append(sn.held, heldLink{ev, id, delta})
+	sn.lmu.Unlock()
+}
+
+// countLink records a path of the file `id` linked (+1) or unlinked (-1)
+// within the root directory during the cycle.
+func (sn *snotifier) countLink(id fileID, delta int) {
+	sn.lmu.Lock()
+	if sn.linked == nil {
+		sn.linked = make(map[fileID]int)
+	}
+	sn.linked[id] += delta
+	sn.lmu.Unlock()
+}
+
+// flushLinks emits the held events whose change of the number of links is
+// not fully explained by the paths linked or unlinked within the root
+// directory, which are already reported on their own path.
+func (sn *snotifier) flushLinks() {
+	sn.lmu.Lock()
+	held, linked := sn.held, sn.linked
+	sn.held, sn.linked = nil, nil
+	sn.lmu.Unlock()
+	for _, h := range held {
+		if h.delta != linked[h.id] {
+			sn.queueEvent(h.ev)
+		}
+	}
+}
+
+// linksReset clears the index of file identities.
+func (sn *snotifier) linksReset() {
+	sn.lmu.Lock()
+	sn.links = nil
+	sn.lmu.Unlock()
+}
+
+// cachedSum is a content digest computed during the current cycle.
+type cachedSum struct {
+	modTime time.Time
+	size    int64
+	sum     string
+	blocks  *blockSums
+}
+
+// digest returns the checksum of a file and the digests of its blocks. Files
+// with multiple hard links are hashed once per cycle regardless of the number
+// of paths to them.
+func (sn *snotifier) digest(path string, fi fs.FileInfo, sys sysInfo) (string, *blockSums, error) {
+	if !sys.valid || sys.nlink < 2 {
+		return sn.checksum(path)
+	}
+	sn.lmu.Lock()
+	c, ok := sn.sums[sys.id()]
+	sn.lmu.Unlock()
+	if ok && c.modTime.Equal(fi.ModTime()) && c.size == fi.Size() {
+		return c.sum, c.blocks, nil
+	}
+	sum, blocks, err := sn.checksum(path)
+	if err != nil {
+		return "", nil, err
+	}
+	sn.lmu.Lock()
+	if sn.sums == nil {
+		sn.sums = make(map[fileID]cachedSum)
+	}
+	sn.sums[sys.id()] = cachedSum{fi.ModTime(), fi.Size(), sum, blocks}
+	sn.lmu.Unlock()
+	return sum, blocks, nil
+}
+
+// sumsReset clears the digests computed during the previous cycle.
+func (sn *snotifier) sumsReset() {
+	sn.lmu.Lock()
+	sn.sums = nil
+	sn.lmu.Unlock()
+}
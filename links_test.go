@@ -0,0 +1,72 @@
+//go:build unix
+
+package gorsn
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHardLinks(t *testing.T) {
+	dir, out := t.TempDir(), t.TempDir()
+	writeFiles(t, dir, "1", "a")
+	a, b := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+
+	var sn ScanNotifier
+	steps := []func(){
+		func() { os.Link(a, b) },
+		func() { os.Remove(b) },
+		func() { os.Link(a, filepath.Join(out, "c")) },
+	}
+	done := make(chan struct{})
+	opts := defaultOpts()
+	opts.Scan().SetInterval(time.Millisecond).
+		SetBeforeScan(func(cycle int) {
+			if cycle > 1 && cycle-2 < len(steps) {
+				steps[cycle-2]()
+			}
+		}).
+		SetAfterScan(func(cycle int, _ ScanSummary) {
+			if cycle == len(steps)+1 {
+				sn.Pause()
+				close(done)
+			}
+		})
+	opts.Events().SetTrackLinks(true)
+	sn, err := New(dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sn.StartAsync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Stop()
+	wait(t, done, "scan cycles")
+
+	type link struct {
+		path, other string
+		name        EventName
+	}
+	var got []link
+	for {
+		var ev Event
+		select {
+		case ev = <-sn.Queue():
+		default:
+		}
+		if ev.Name == "" {
+			break
+		}
+		if ev.Type == FILE {
+			got = append(got, link{ev.Path, ev.OldPath, ev.Name})
+		}
+	}
+	want := []link{{b, a, LINK}, {b, a, UNLINK}, {a, "", LINK}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got events %v, want %v", got, want)
+	}
+}
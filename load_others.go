@@ -0,0 +1,9 @@
+//go:build !linux
+
+package gorsn
+
+// systemLoad reports the load as unknown on this platform so scans
+// are never paused by the load threshold.
+func systemLoad() (float64, bool) {
+	return 0, false
+}
@@ -0,0 +1,23 @@
+package gorsn
+
+import "fmt"
+
+// lockedFailure handles the failure to access a path. If the detection of the
+// locked files is enabled and the path is locked by another process, it emits
+// a `LOCKED` event which wraps `ErrFileLocked` the first time and reports true
+// so the failure is not reported by an `ERROR` event each scan cycle.
+func (sn *snotifier) lockedFailure(path string, pt PathType, err error) bool {
+	if !sn.opts.events.detectLocked.Load() || !isLocked(err) {
+		return false
+	}
+	if _, reported := sn.locked.LoadOrStore(path, struct{}{}); !reported {
+		sn.queueEvent(Event{Path: path, Type: pt, Name: LOCKED, Error: fmt.Errorf("%w: %v", ErrFileLocked, err)})
+	}
+	return true
+}
+
+// unlocked forgets a path once accessible again, so it is reported by a new
+// `LOCKED` event if it gets locked again.
+func (sn *snotifier) unlocked(path string) {
+	sn.locked.Delete(path)
+}
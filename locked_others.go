@@ -0,0 +1,8 @@
+//go:build !unix && !windows
+
+package gorsn
+
+// isLocked reports false since the locked files could not be detected.
+func isLocked(err error) bool {
+	return false
+}
@@ -0,0 +1,14 @@
+//go:build unix
+
+package gorsn
+
+import (
+	"errors"
+	"syscall"
+)
+
+// isLocked reports whether the error is due to a file busy or locked by
+// another process, such as a running executable or a mandatory lock.
+func isLocked(err error) bool {
+	return errors.Is(err, syscall.EBUSY) || errors.Is(err, syscall.ETXTBSY) || errors.Is(err, syscall.EAGAIN)
+}
@@ -0,0 +1,19 @@
+//go:build windows
+
+package gorsn
+
+import (
+	"errors"
+	"syscall"
+)
+
+const (
+	errorSharingViolation syscall.Errno = 32
+	errorLockViolation    syscall.Errno = 33
+)
+
+// isLocked reports whether the error is due to a file opened without sharing
+// or whose range is locked by another process.
+func isLocked(err error) bool {
+	return errors.Is(err, errorSharingViolation) || errors.Is(err, errorLockViolation)
+}
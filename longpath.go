@@ -0,0 +1,58 @@
+package gorsn
+
+// PathForm defines the form of the Windows paths emitted into the events
+// so the consumers see consistent paths whatever the form of the root.
+type PathForm uint32
+
+const (
+	// PATH_FORM_NATIVE keeps the paths in the form of the root path.
+	PATH_FORM_NATIVE PathForm = iota
+	// PATH_FORM_DOS emits the drive-letter paths such as `C:\dir\file`
+	// and the UNC paths such as `\\server\share\file`, i.e. without the
+	// `\\?\` and `\\?\UNC\` extended-length prefixes.
+	PATH_FORM_DOS
+	// PATH_FORM_EXTENDED emits the absolute paths into the extended-length
+	// form such as `\\?\C:\dir\file` and `\\?\UNC\server\share\file`.
+	PATH_FORM_EXTENDED
+)
+
+// formPaths sets the root and its label of the event and converts its paths into the
+// relative paths, the configured Unicode normalization form and Windows
+// form. The Windows form is left untouched on the other platforms.
+func (sn *snotifier) formPaths(ev Event) Event {
+	ev.Root = sn.root
+	if ev.RootLabel = sn.opts.events.rootLabel(); ev.RootLabel == "" {
+		ev.RootLabel = sn.label
+	}
+	form := PathForm(sn.opts.events.pathForm.Load())
+	normalizer := sn.opts.events.pathNormalizer()
+	relative := sn.opts.events.relativePaths.Load()
+	if form == PATH_FORM_NATIVE && normalizer == nil && !relative {
+		return ev
+	}
+	normalize := func(p string) string {
+		if normalizer != nil {
+			p = normalizer(p)
+		}
+		return toPathForm(p, form)
+	}
+	convert := func(p string) string {
+		if relative {
+			p = sn.relative(p)
+		}
+		return normalize(p)
+	}
+	ev.Path = convert(ev.Path)
+	if ev.OldPath != "" {
+		ev.OldPath = convert(ev.OldPath)
+	}
+	if len(ev.Prefixes) > 0 {
+		prefixes := make([]string, len(ev.Prefixes))
+		for i, p := range ev.Prefixes {
+			prefixes[i] = convert(p)
+		}
+		ev.Prefixes = prefixes
+	}
+	ev.Root = normalize(ev.Root)
+	return ev
+}
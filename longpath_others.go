@@ -0,0 +1,13 @@
+//go:build !windows
+
+package gorsn
+
+// longPath returns the path as is since only Windows limits its length.
+func longPath(name string) string {
+	return name
+}
+
+// toPathForm returns the path as is since the forms are Windows specific.
+func toPathForm(name string, form PathForm) string {
+	return name
+}
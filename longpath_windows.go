@@ -0,0 +1,77 @@
+//go:build windows
+
+package gorsn
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// maxPath is the length from which the paths need the extended-length
+	// form. It is MAX_PATH minus the room of a 8.3 filename, like for the
+	// creation of the directories.
+	maxPath = 248
+
+	extendedPrefix = `\\?\`
+	extendedUNC    = `\\?\UNC\`
+)
+
+// longPath returns the extended-length form of a long path so the Win32
+// calls do not fail when walking deep trees. Unlike the `os` package, it
+// also supports the relative paths by making them absolute beforehand.
+func longPath(name string) string {
+	if len(name) < maxPath || strings.HasPrefix(name, extendedPrefix) || strings.HasPrefix(name, `\\.\`) {
+		return name
+	}
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return name
+	}
+	return toExtended(abs)
+}
+
+// toPathForm converts a path into the given form.
+func toPathForm(name string, form PathForm) string {
+	switch form {
+	case PATH_FORM_DOS:
+		return toDOS(name)
+	case PATH_FORM_EXTENDED:
+		return toExtended(name)
+	}
+	return name
+}
+
+// toExtended converts an absolute drive-letter or UNC path into the
+// extended-length form. Relative and device paths are left untouched.
+// The path is cleaned since the extended-length form disables the Win32
+// normalization, e.g. of the slashes.
+func toExtended(name string) string {
+	if strings.HasPrefix(name, extendedPrefix) || strings.HasPrefix(name, `\\.\`) {
+		return name
+	}
+	name = filepath.Clean(name)
+	switch {
+	case strings.HasPrefix(name, `\\`):
+		return extendedUNC + name[2:]
+	case filepath.IsAbs(name):
+		return extendedPrefix + name
+	}
+	return name
+}
+
+// toDOS removes the extended-length prefix of a drive-letter or UNC path.
+// Other paths such as the volume GUID ones are left untouched.
+func toDOS(name string) string {
+	switch {
+	case hasPrefixFold(name, extendedUNC):
+		return `\\` + name[len(extendedUNC):]
+	case strings.HasPrefix(name, extendedPrefix) && len(name) >= len(extendedPrefix)+2 && name[len(extendedPrefix)+1] == ':':
+		return name[len(extendedPrefix):]
+	}
+	return name
+}
+
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
@@ -0,0 +1,221 @@
+package gorsn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// LabeledEvent is an event along with the label of the watch which emitted it.
+type LabeledEvent struct {
+	Label string
+	Event
+}
+
+// Manager owns many independent scan notifiers, each watching its own root
+// with its own options, and merges their events into a single queue. Watches
+// could be added or removed at anytime. Like a scan notifier, a manager could
+// not be started again once stopped.
+type Manager struct {
+	mu      sync.Mutex
+	watches map[string]ScanNotifier
+	cancels map[string]context.CancelFunc
+	queue   chan LabeledEvent
+	stop    chan struct{}
+	once    sync.Once
+	ctx     context.Context
+	started bool
+	done    bool
+	wg      sync.WaitGroup
+}
+
+// NewManager returns an empty manager whose queue holds `size` events.
+func NewManager(size int) *Manager {
+	if size < 0 {
+		size = 0
+	}
+	return &Manager{
+		watches: make(map[string]ScanNotifier),
+		cancels: make(map[string]context.CancelFunc),
+		queue:   make(chan LabeledEvent, size),
+		stop:    make(chan struct{}),
+	}
+}
+
+// Add creates a scan notifier for `root` with the options `opts` and registers
+// it under `label`. It is started at once if the manager is already running.
+// The label is the `RootLabel` of its events unless defined by `SetRootLabel`.
+func (m *Manager) Add(label, root string, opts *Options) (ScanNotifier, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.done {
+		return nil, ErrScanIsNotReady
+	}
+	if _, exists := m.watches[label]; exists {
+		return nil, fmt.Errorf("%w: %s", ErrWatchExists, label)
+	}
+	sn, err := newSnotifier(root, opts, nil)
+	if err != nil {
+		return nil, err
+	}
+	sn.label = label
+	m.watches[label] = sn
+	if m.started {
+		m.run(label, sn)
+	}
+	return sn, nil
+}
+
+// Remove stops the scan notifier registered under `label` and forgets it.
+// Its events already received are still delivered.
+func (m *Manager) Remove(label string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.watches[label]; !exists {
+		return fmt.Errorf("%w: %s", ErrWatchNotFound, label)
+	}
+	if cancel, ok := m.cancels[label]; ok {
+		cancel()
+	}
+	delete(m.watches, label)
+	delete(m.cancels, label)
+	return nil
+}
+
+// Watch returns the scan notifier registered under `label`.
+func (m *Manager) Watch(label string) (ScanNotifier, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sn, exists := m.watches[label]
+	return sn, exists
+}
+
+// Labels returns the sorted labels of the registered watches.
+func (m *Manager) Labels() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	labels := make([]string, 0, len(m.watches))
+	for label := range m.watches {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+// Queue returns the channel to listen on for receiving the events of all
+// the watches. It is closed once the manager stopped, the events not read
+// by then are dropped.
+func (m *Manager) Queue() <-chan LabeledEvent {
+	return m.queue
+}
+
+// Start starts all the watches and blocks until `ctx` is done or
+// `Stop` is called. It then stops the watches and closes the queue.
+func (m *Manager) Start(ctx context.Context) error {
+	m.mu.Lock()
+	if m.started {
+		m.mu.Unlock()
+		return ErrScanAlreadyStarted
+	}
+	if m.done {
+		m.mu.Unlock()
+		return ErrScanIsNotReady
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	m.started = true
+	m.ctx = ctx
+	for label, sn := range m.watches {
+		m.run(label, sn)
+	}
+	m.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+	case <-m.stop:
+	}
+
+	m.mu.Lock()
+	m.started = false
+	m.done = true
+	cancel()
+	m.mu.Unlock()
+	m.wg.Wait()
+	close(m.queue)
+	return nil
+}
+
+// run starts a watch and forwards its events until it stops or the manager
+// stops, so a queue no longer read does not block the manager shutdown.
+// It must be called with the lock held.
+func (m *Manager) run(label string, sn ScanNotifier) {
+	ctx, cancel := context.WithCancel(m.ctx)
+	m.cancels[label] = cancel
+	done := m.ctx.Done()
+	m.wg.Add(2)
+	go func() {
+		defer m.wg.Done()
+		defer cancel()
+		sn.Start(ctx)
+	}()
+	go func() {
+		defer m.wg.Done()
+		for ev := range sn.Queue() {
+			select {
+			case m.queue <- LabeledEvent{Label: label, Event: ev}:
+			case <-done:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops all the watches then the manager.
+func (m *Manager) Stop() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.started {
+		return ErrScanIsNotRunning
+	}
+	m.once.Do(func() { close(m.stop) })
+	return nil
+}
+
+// Pause pauses all the running watches. See `ScanNotifier.Pause`.
+func (m *Manager) Pause() error {
+	return m.each(ScanNotifier.Pause)
+}
+
+// PauseFor pauses all the running watches for the duration `d`.
+// See `ScanNotifier.PauseFor`.
+func (m *Manager) PauseFor(d time.Duration) error {
+	return m.each(func(sn ScanNotifier) error { return sn.PauseFor(d) })
+}
+
+// PauseUntil pauses all the running watches until the time `t`.
+// See `ScanNotifier.PauseUntil`.
+func (m *Manager) PauseUntil(t time.Time) error {
+	return m.each(func(sn ScanNotifier) error { return sn.PauseUntil(t) })
+}
+
+// Resume resumes all the paused watches. See `ScanNotifier.Resume`.
+func (m *Manager) Resume() error {
+	return m.each(ScanNotifier.Resume)
+}
+
+// each applies the action to all the watches and returns
+// the joined errors labeled with their watch.
+func (m *Manager) each(action func(ScanNotifier) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var errs []error
+	for label, sn := range m.watches {
+		if err := action(sn); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", label, err))
+		}
+	}
+	return errors.Join(errs...)
+}
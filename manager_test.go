@@ -0,0 +1,44 @@
+package gorsn
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestManagerStopUnreadQueue(t *testing.T) {
+	dir := t.TempDir()
+	done := make(chan struct{})
+	opts := defaultOpts()
+	opts.Scan().SetInterval(5 * time.Millisecond).
+		SetBeforeScan(func(cycle int) {
+			if cycle == 2 {
+				writeFiles(t, dir, "1", "a", "b")
+			}
+		}).
+		SetAfterScan(func(cycle int, _ ScanSummary) {
+			if cycle == 2 {
+				close(done)
+			}
+		})
+	m := NewManager(0)
+	if _, err := m.Add("dir", dir, opts); err != nil {
+		t.Fatal(err)
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- m.Start(context.Background()) }()
+
+	// the events are never read from the manager queue.
+	wait(t, done, "scan cycle")
+	if err := m.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-exited:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("manager blocked on its unread queue")
+	}
+}
@@ -0,0 +1,75 @@
+package gorsn
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+)
+
+// MQTTPublisher defines the publishing operation of an MQTT client, so that
+// this module does not have to depend on any MQTT implementation. With the
+// Eclipse Paho client, it could be implemented as below:
+//
+//	type pahoPublisher struct{ c mqtt.Client }
+//
+//	func (p pahoPublisher) Publish(topic string, qos byte, retained bool, payload []byte) error {
+//		t := p.c.Publish(topic, qos, retained, payload)
+//		t.WaitTimeout(5 * time.Second)
+//		return t.Error()
+//	}
+type MQTTPublisher interface {
+	Publish(topic string, qos byte, retained bool, payload []byte) error
+}
+
+// MQTTConfig defines how the events are published by the MQTT sink.
+type MQTTConfig struct {
+	Publisher MQTTPublisher
+	// Root is the monitored root path which is stripped from the events path.
+	// Leave it empty if the paths are already relative, see `SetRelativePaths`.
+	Root string
+	// Prefix is the topic under which the relative paths are published,
+	// such as `gateways/gw-42/drops`.
+	Prefix   string
+	QoS      byte
+	Retained bool
+	// Filter selects the events to publish. A nil filter selects all of them.
+	Filter Filter
+}
+
+type mqttSink struct {
+	cfg MQTTConfig
+}
+
+// NewMQTTSink returns a Sink which publishes each event selected by the filter
+// as a JSON document, like the audit log lines, to the topic made of the prefix
+// and the path relative to the root, e.g. `gateways/gw-42/drops/in/a.csv`. The
+// `+` and `#` wildcards are replaced by `_` into the topic. Events of the root
+// itself are published to the prefix.
+func NewMQTTSink(cfg MQTTConfig) Sink {
+	cfg.Prefix = strings.TrimSuffix(cfg.Prefix, "/")
+	return &mqttSink{cfg: cfg}
+}
+
+func (s *mqttSink) Write(ev Event) error {
+	if s.cfg.Filter != nil && !s.cfg.Filter(ev) {
+		return nil
+	}
+	payload, err := json.Marshal(newAuditRecord(ev))
+	if err != nil {
+		return err
+	}
+	return s.cfg.Publisher.Publish(s.topic(ev.Path), s.cfg.QoS, s.cfg.Retained, payload)
+}
+
+// topic returns the topic of the path.
+func (s *mqttSink) topic(p string) string {
+	rel, err := filepath.Rel(s.cfg.Root, p)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return s.cfg.Prefix
+	}
+	rel = strings.NewReplacer("+", "_", "#", "_").Replace(filepath.ToSlash(rel))
+	if s.cfg.Prefix == "" {
+		return rel
+	}
+	return s.cfg.Prefix + "/" + rel
+}
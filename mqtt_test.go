@@ -0,0 +1,88 @@
+package gorsn
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// publication is a message published through the fake MQTT publisher.
+type publication struct {
+	topic    string
+	qos      byte
+	retained bool
+	payload  []byte
+}
+
+// fakePublisher records the publications and fails with `err` if set.
+type fakePublisher struct {
+	published []publication
+	err       error
+}
+
+func (p *fakePublisher) Publish(topic string, qos byte, retained bool, payload []byte) error {
+	p.published = append(p.published, publication{topic, qos, retained, payload})
+	return p.err
+}
+
+func TestMQTTSinkTopic(t *testing.T) {
+	root := filepath.Join(string(filepath.Separator), "data", "drops")
+	tests := []struct {
+		name   string
+		root   string
+		prefix string
+		path   string
+		want   string
+	}{
+		{"nested file", root, "gw/42/", filepath.Join(root, "in", "a.csv"), "gw/42/in/a.csv"},
+		{"no prefix", root, "", filepath.Join(root, "in", "a.csv"), "in/a.csv"},
+		{"wildcards", root, "gw", filepath.Join(root, "a+b", "#1"), "gw/a_b/_1"},
+		{"root itself", root, "gw", root, "gw"},
+		{"outside of the root", root, "gw", filepath.Join(string(filepath.Separator), "data", "other"), "gw"},
+		{"sibling sharing the root prefix", root, "gw", root + "2", "gw"},
+		{"relative paths", "", "gw", filepath.Join("in", "a.csv"), "gw/in/a.csv"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &fakePublisher{}
+			sink := NewMQTTSink(MQTTConfig{Publisher: p, Root: tt.root, Prefix: tt.prefix, QoS: 1, Retained: true})
+			if err := sink.Write(Event{Path: tt.path, Type: FILE, Name: CREATE}); err != nil {
+				t.Fatal(err)
+			}
+			if len(p.published) != 1 {
+				t.Fatalf("got %d publications, want 1", len(p.published))
+			}
+			pub := p.published[0]
+			if pub.topic != tt.want || pub.qos != 1 || !pub.retained {
+				t.Errorf("got topic %q with QoS %d and retained %t, want %q with QoS 1 and retained", pub.topic, pub.qos, pub.retained, tt.want)
+			}
+			var rec map[string]any
+			if err := json.Unmarshal(pub.payload, &rec); err != nil {
+				t.Fatal(err)
+			}
+			if rec["path"] != tt.path || rec["event"] != "CREATE" {
+				t.Errorf("got payload %s, want the audit record of the event", pub.payload)
+			}
+		})
+	}
+}
+
+func TestMQTTSinkFilter(t *testing.T) {
+	p := &fakePublisher{err: errors.New("not connected")}
+	sink := NewMQTTSink(MQTTConfig{Publisher: p, Root: "/data", Filter: func(ev Event) bool { return ev.Name == DELETE }})
+	if err := sink.Write(Event{Path: "/data/a", Type: FILE, Name: CREATE}); err != nil {
+		t.Errorf("got error %v for a filtered out event", err)
+	}
+	if err := sink.Write(Event{Path: "/data/a", Type: FILE, Name: DELETE}); !errors.Is(err, p.err) {
+		t.Errorf("got error %v, want the failure of the publisher", err)
+	}
+	var topics []string
+	for _, pub := range p.published {
+		topics = append(topics, pub.topic)
+	}
+	if !reflect.DeepEqual(topics, []string{"a"}) {
+		t.Errorf("got topics %q, want only the one of the DELETE event", topics)
+	}
+}
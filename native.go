@@ -0,0 +1,140 @@
+package gorsn
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+)
+
+// changeLatency is the delay to gather the paths notified in a burst
+// so they are checked at once.
+const changeLatency = 50 * time.Millisecond
+
+// ChangeSource is optionally implemented by backends which are notified of
+// the changes by the operating system. The notified paths are checked as
+// soon as reported while full scans keep running at the scan interval in
+// order to reconcile any missed notification. Hot paths are not needed
+// and ignored in that case.
+type ChangeSource interface {
+	// Watch reports the paths changed under the root until `stop` is
+	// closed, then closes the returned channel. Reporting the root path
+	// itself asks for a full scan, e.g. once some notifications were lost.
+	Watch(root string, stop <-chan struct{}) (<-chan string, error)
+}
+
+// watchChanges starts to watch the root directory if the backend is a
+// change source. It reports whether the changes are notified, otherwise
+// the scanner keeps polling. It is retried at each cycle until it works.
+func (sn *snotifier) watchChanges() bool {
+	if sn.changes != nil {
+		return true
+	}
+	cs, ok := sn.opts.scan.backend.(ChangeSource)
+	if !ok || sn.single || sn.lost {
+		return false
+	}
+	changes, err := cs.Watch(sn.root, sn.stop)
+	if err != nil {
+		return false
+	}
+	sn.changes = changes
+	return true
+}
+
+// awaitChanges waits for the next scan cycle and checks the notified
+// paths in the meantime. It returns earlier once cancelled, stopped or
+// paused or if a full scan is needed. Once the backend stopped to notify,
+// it just waits.
+func (sn *snotifier) awaitChanges(ctx context.Context, interval time.Duration) {
+	next := sn.opts.scan.clock.After(interval)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sn.stop:
+			return
+		case <-next:
+			return
+		case path, ok := <-sn.changes:
+			if !ok {
+				// nil channel blocks until the next cycle which retries.
+				sn.changes = nil
+				continue
+			}
+			paths, full := sn.gatherChanges(path)
+			if full || sn.paused.Load() {
+				return
+			}
+			sn.rescan(ctx, sn.notifiedRoots(paths))
+		}
+	}
+}
+
+// gatherChanges collects the paths notified shortly after `path`. It
+// reports whether a full scan was requested.
+func (sn *snotifier) gatherChanges(path string) ([]string, bool) {
+	paths := []string{path}
+	settled := sn.opts.scan.clock.After(changeLatency)
+	for {
+		if path == sn.root {
+			return nil, true
+		}
+		select {
+		case <-sn.stop:
+			return nil, false
+		case <-settled:
+			return paths, false
+		case p, ok := <-sn.changes:
+			if !ok {
+				sn.changes = nil
+				return paths, false
+			}
+			path = p
+			paths = append(paths, p)
+		}
+	}
+}
+
+// notifiedRoots returns the notified paths to check. Known directories
+// still present are dropped since the changes of their children are
+// notified on their own, so they are not walked entirely. Paths whose
+// parent directory is not monitored are dropped as well.
+func (sn *snotifier) notifiedRoots(paths []string) []string {
+	var roots []string
+	for _, path := range paths {
+		if !sn.reachable(path) {
+			continue
+		}
+		if val, ok := sn.paths.Load(path); ok && getPathType(val.(*pathInfos).mode) == DIR {
+			if fi, err := sn.opts.scan.backend.Lstat(path); err == nil && fi.IsDir() {
+				continue
+			}
+		}
+		roots = append(roots, path)
+	}
+	return topRoots(roots)
+}
+
+// reachable reports whether the path would be visited by a full scan
+// based on its parent directory.
+func (sn *snotifier) reachable(path string) bool {
+	parent := filepath.Dir(path)
+	if parent == sn.root {
+		return true
+	}
+	if sn.opts.filters.topLevelOnly.Load() {
+		return false
+	}
+	_, known := sn.paths.Load(parent)
+	return known
+}
+
+// sendChange reports the changed path unless stopped.
+func sendChange(path string, stop <-chan struct{}, changes chan<- string) bool {
+	select {
+	case changes <- path:
+		return true
+	case <-stop:
+		return false
+	}
+}
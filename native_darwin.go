@@ -0,0 +1,170 @@
+//go:build cgo
+
+package gorsn
+
+/*
+#cgo LDFLAGS: -framework CoreServices
+#include <stdlib.h>
+#include <CoreServices/CoreServices.h>
+#include <dispatch/dispatch.h>
+
+extern void gorsnFSEvents(ConstFSEventStreamRef stream, uintptr_t info, size_t n, char **paths, FSEventStreamEventFlags *flags, FSEventStreamEventId *ids);
+
+static void gorsnStopStream(FSEventStreamRef stream, dispatch_queue_t queue) {
+	FSEventStreamStop(stream);
+	FSEventStreamInvalidate(stream);
+	FSEventStreamRelease(stream);
+	dispatch_release(queue);
+}
+
+static FSEventStreamRef gorsnStartStream(const char *root, uintptr_t id, CFTimeInterval latency, dispatch_queue_t *queue) {
+	CFStringRef path = CFStringCreateWithCString(NULL, root, kCFStringEncodingUTF8);
+	if (path == NULL) {
+		return NULL;
+	}
+	CFArrayRef paths = CFArrayCreate(NULL, (const void **)&path, 1, &kCFTypeArrayCallBacks);
+	FSEventStreamContext ctx = {0, (void *)id, NULL, NULL, NULL};
+	FSEventStreamRef stream = FSEventStreamCreate(NULL, (FSEventStreamCallback)gorsnFSEvents, &ctx, paths,
+		kFSEventStreamEventIdSinceNow, latency, kFSEventStreamCreateFlagFileEvents | kFSEventStreamCreateFlagNoDefer);
+	CFRelease(paths);
+	CFRelease(path);
+	if (stream == NULL) {
+		return NULL;
+	}
+	*queue = dispatch_queue_create("gorsn.fsevents", DISPATCH_QUEUE_SERIAL);
+	FSEventStreamSetDispatchQueue(stream, *queue);
+	if (!FSEventStreamStart(stream)) {
+		gorsnStopStream(stream, *queue);
+		return NULL;
+	}
+	return stream;
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// fseventsLost are the flags telling that some changes were not notified.
+const fseventsLost = C.kFSEventStreamEventFlagMustScanSubDirs |
+	C.kFSEventStreamEventFlagUserDropped |
+	C.kFSEventStreamEventFlagKernelDropped |
+	C.kFSEventStreamEventFlagRootChanged
+
+var (
+	fseventsSeq atomic.Uintptr
+	// fseventsStreams maps the identifier given to each stream callback
+	// to its *fseventsStream so late callbacks are safely ignored.
+	fseventsStreams sync.Map
+)
+
+// fseventsStream forwards the paths notified by an FSEvents stream.
+type fseventsStream struct {
+	root    string // monitored root path.
+	real    string // absolute root path with symlinks resolved.
+	mu      sync.RWMutex
+	closed  bool
+	changes chan string
+	stop    <-chan struct{}
+}
+
+// nativeBackend is the local file system backend which is notified of the
+// changes by an FSEvents stream.
+type nativeBackend struct {
+	osBackend
+}
+
+// NativeBackend returns the local file system backend which is notified of
+// the changes by an FSEvents stream. Only the affected subtrees are checked
+// on the fly and full scans only run at the scan interval to reconcile the
+// missed changes, so that interval could be much longer than with polling.
+func NativeBackend() Backend {
+	return nativeBackend{}
+}
+
+// Watch implements ChangeSource.
+func (nativeBackend) Watch(root string, stop <-chan struct{}) (<-chan string, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, &fs.PathError{Op: "watch", Path: root, Err: err}
+	}
+	// events are reported under the real path, e.g. /private/tmp.
+	real, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return nil, &fs.PathError{Op: "watch", Path: root, Err: err}
+	}
+	w := &fseventsStream{root: root, real: real, changes: make(chan string, 64), stop: stop}
+	id := fseventsSeq.Add(1)
+	fseventsStreams.Store(id, w)
+
+	croot := C.CString(real)
+	defer C.free(unsafe.Pointer(croot))
+	var queue C.dispatch_queue_t
+	stream := C.gorsnStartStream(croot, C.uintptr_t(id), C.CFTimeInterval(changeLatency.Seconds()), &queue)
+	if stream == nil {
+		fseventsStreams.Delete(id)
+		return nil, &fs.PathError{Op: "watch", Path: root, Err: errors.New("cannot start events stream")}
+	}
+
+	go func() {
+		<-stop
+		C.gorsnStopStream(stream, queue)
+		fseventsStreams.Delete(id)
+		w.mu.Lock()
+		w.closed = true
+		close(w.changes)
+		w.mu.Unlock()
+	}()
+	return w.changes, nil
+}
+
+// local converts a notified path to its equivalent under the root.
+// The root itself is returned if the path is not under the root.
+func (w *fseventsStream) local(path string) string {
+	rel, ok := strings.CutPrefix(path, w.real)
+	if !ok || (rel != "" && rel[0] != '/') {
+		return w.root
+	}
+	return filepath.Join(w.root, rel)
+}
+
+// send reports the changed path unless stopped.
+func (w *fseventsStream) send(path string) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return false
+	}
+	select {
+	case w.changes <- path:
+		return true
+	case <-w.stop:
+		return false
+	}
+}
+
+//export gorsnFSEvents
+func gorsnFSEvents(stream C.ConstFSEventStreamRef, info C.uintptr_t, n C.size_t, paths **C.char, flags *C.FSEventStreamEventFlags, ids *C.FSEventStreamEventId) {
+	val, ok := fseventsStreams.Load(uintptr(info))
+	if !ok {
+		return
+	}
+	w := val.(*fseventsStream)
+	fl := unsafe.Slice(flags, int(n))
+	for i, p := range unsafe.Slice(paths, int(n)) {
+		path := w.root
+		if fl[i]&fseventsLost == 0 {
+			path = w.local(C.GoString(p))
+		}
+		if !w.send(path) {
+			return
+		}
+	}
+}
@@ -0,0 +1,226 @@
+//go:build dragonfly || freebsd || netbsd || openbsd || (darwin && !cgo)
+
+package gorsn
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+const (
+	// kqueueFlags selects the changes notified for each watched path.
+	kqueueFlags = syscall.NOTE_WRITE | syscall.NOTE_EXTEND | syscall.NOTE_ATTRIB |
+		syscall.NOTE_DELETE | syscall.NOTE_RENAME | syscall.NOTE_LINK
+
+	// kqueuePoll is the delay to check for the stop signal.
+	kqueuePoll = 200 * time.Millisecond
+)
+
+// nativeBackend is the local file system backend which is notified of the
+// changes by kqueue.
+type nativeBackend struct {
+	osBackend
+}
+
+// NativeBackend returns the local file system backend which is notified of
+// the changes by kqueue. The notified paths are checked on the fly and full
+// scans only run at the scan interval to reconcile the missed changes, so
+// that interval could be much longer than with polling. Each directory and
+// file of the tree is opened to be watched, so the files which could not be
+// opened, e.g. once out of file descriptors, are only checked by full scans.
+func NativeBackend() Backend {
+	return nativeBackend{}
+}
+
+// kqueueWatcher tracks the paths watched by a kqueue.
+type kqueueWatcher struct {
+	kq        int
+	root      string
+	fds       map[string]int             // watched paths to their descriptor.
+	paths     map[int]string             // descriptors to their watched path.
+	entries   map[string]map[string]bool // names of the watched directories entries.
+	exhausted bool                       // no more descriptors for the files.
+	changes   chan string
+	stop      <-chan struct{}
+}
+
+// Watch implements ChangeSource.
+func (nativeBackend) Watch(root string, stop <-chan struct{}) (<-chan string, error) {
+	kq, err := syscall.Kqueue()
+	if err != nil {
+		return nil, &fs.PathError{Op: "watch", Path: root, Err: err}
+	}
+	syscall.CloseOnExec(kq)
+	w := &kqueueWatcher{
+		kq:      kq,
+		root:    root,
+		fds:     make(map[string]int),
+		paths:   make(map[int]string),
+		entries: make(map[string]map[string]bool),
+		changes: make(chan string, 64),
+		stop:    stop,
+	}
+	if err := w.add(root); err != nil {
+		w.close()
+		return nil, &fs.PathError{Op: "watch", Path: root, Err: err}
+	}
+	go w.run()
+	return w.changes, nil
+}
+
+// add watches the path and the whole content of a directory.
+func (w *kqueueWatcher) add(path string) error {
+	if _, ok := w.fds[path]; ok {
+		return nil
+	}
+	fi, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	if !fi.IsDir() && (!fi.Mode().IsRegular() || w.exhausted) {
+		return nil
+	}
+	fd, err := syscall.Open(path, syscall.O_RDONLY|syscall.O_NONBLOCK|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		if err == syscall.EMFILE || err == syscall.ENFILE {
+			w.exhausted = true
+		}
+		return err
+	}
+	var ev syscall.Kevent_t
+	syscall.SetKevent(&ev, fd, syscall.EVFILT_VNODE, syscall.EV_ADD|syscall.EV_CLEAR)
+	ev.Fflags = kqueueFlags
+	if _, err := syscall.Kevent(w.kq, []syscall.Kevent_t{ev}, nil, nil); err != nil {
+		syscall.Close(fd)
+		return err
+	}
+	w.fds[path] = fd
+	w.paths[fd] = path
+	if fi.IsDir() {
+		names := readNames(path)
+		w.entries[path] = names
+		for name := range names {
+			w.add(filepath.Join(path, name))
+		}
+	}
+	return nil
+}
+
+// remove stops to watch the path and everything under it.
+func (w *kqueueWatcher) remove(path string) {
+	under := []string{path}
+	for p, fd := range w.fds {
+		if isUnder(p, under) {
+			syscall.Close(fd)
+			delete(w.fds, p)
+			delete(w.paths, fd)
+			delete(w.entries, p)
+		}
+	}
+	if w.exhausted {
+		// descriptors were released.
+		w.exhausted = false
+	}
+}
+
+// close releases the descriptors and closes the changes channel.
+func (w *kqueueWatcher) close() {
+	for _, fd := range w.fds {
+		syscall.Close(fd)
+	}
+	syscall.Close(w.kq)
+	close(w.changes)
+}
+
+// run reports the changes until stopped or until the root is gone.
+func (w *kqueueWatcher) run() {
+	defer w.close()
+	events := make([]syscall.Kevent_t, 64)
+	timeout := syscall.NsecToTimespec(int64(kqueuePoll))
+	for {
+		select {
+		case <-w.stop:
+			return
+		default:
+		}
+		n, err := syscall.Kevent(w.kq, nil, events, &timeout)
+		if err == syscall.EINTR {
+			continue
+		}
+		if err != nil {
+			return
+		}
+		for _, ev := range events[:n] {
+			path, ok := w.paths[int(ev.Ident)]
+			if !ok {
+				continue
+			}
+			if !w.handle(path, uint32(ev.Fflags)) {
+				return
+			}
+		}
+	}
+}
+
+// handle reports the paths affected by a change of the watched path.
+// It returns false once stopped or once the root is gone.
+func (w *kqueueWatcher) handle(path string, flags uint32) bool {
+	if flags&(syscall.NOTE_DELETE|syscall.NOTE_RENAME) != 0 {
+		w.remove(path)
+		if path == w.root {
+			// a full scan reports the loss of the root.
+			sendChange(path, w.stop, w.changes)
+			return false
+		}
+		// the path could have been replaced by another file.
+		w.add(path)
+		return sendChange(path, w.stop, w.changes)
+	}
+	names, dir := w.entries[path]
+	if !dir {
+		return sendChange(path, w.stop, w.changes)
+	}
+	if flags&syscall.NOTE_WRITE == 0 {
+		// directories metadata changes are left to the full scans.
+		return true
+	}
+	current := readNames(path)
+	w.entries[path] = current
+	for name := range names {
+		if !current[name] {
+			p := filepath.Join(path, name)
+			w.remove(p)
+			if !sendChange(p, w.stop, w.changes) {
+				return false
+			}
+		}
+	}
+	for name := range current {
+		if !names[name] {
+			p := filepath.Join(path, name)
+			w.add(p)
+			if !sendChange(p, w.stop, w.changes) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// readNames returns the names of the directory entries.
+func readNames(dir string) map[string]bool {
+	names := make(map[string]bool)
+	f, err := os.Open(dir)
+	if err != nil {
+		return names
+	}
+	defer f.Close()
+	list, _ := f.Readdirnames(-1)
+	for _, name := range list {
+		names[name] = true
+	}
+	return names
+}
@@ -0,0 +1,9 @@
+//go:build !darwin && !dragonfly && !freebsd && !netbsd && !openbsd && !windows
+
+package gorsn
+
+// NativeBackend returns the local file system backend. Changes are not
+// notified by the operating system on this platform so it keeps polling.
+func NativeBackend() Backend {
+	return osBackend{}
+}
@@ -0,0 +1,127 @@
+//go:build windows
+
+package gorsn
+
+import (
+	"io/fs"
+	"path/filepath"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	// fileNotifyChangeSecurity is missing from the syscall package.
+	fileNotifyChangeSecurity = 0x100
+
+	// watchMask selects the changes notified by ReadDirectoryChangesW.
+	watchMask = syscall.FILE_NOTIFY_CHANGE_FILE_NAME |
+		syscall.FILE_NOTIFY_CHANGE_DIR_NAME |
+		syscall.FILE_NOTIFY_CHANGE_ATTRIBUTES |
+		syscall.FILE_NOTIFY_CHANGE_SIZE |
+		syscall.FILE_NOTIFY_CHANGE_LAST_WRITE |
+		syscall.FILE_NOTIFY_CHANGE_CREATION |
+		fileNotifyChangeSecurity
+
+	// watchBufferSize is the size of the buffer which receives the changes.
+	// ReadDirectoryChangesW fails over the network beyond 64KB.
+	watchBufferSize = 64 * 1024
+
+	// watchPollMs is the delay in milliseconds to check for the stop signal.
+	watchPollMs = 200
+)
+
+// nativeBackend is the local file system backend which is notified of the
+// changes by ReadDirectoryChangesW.
+type nativeBackend struct {
+	osBackend
+}
+
+// NativeBackend returns the local file system backend which is notified of
+// the changes by ReadDirectoryChangesW. It dramatically reduces the CPU usage
+// and the latency on large trees since the notified paths are checked on the
+// fly and full scans only run at the scan interval to reconcile the missed
+// changes, so that interval could be much longer than with polling.
+func NativeBackend() Backend {
+	return nativeBackend{}
+}
+
+// Watch implements ChangeSource.
+func (nativeBackend) Watch(root string, stop <-chan struct{}) (<-chan string, error) {
+	name, err := syscall.UTF16PtrFromString(longPath(root))
+	if err != nil {
+		return nil, &fs.PathError{Op: "watch", Path: root, Err: err}
+	}
+	h, err := syscall.CreateFile(name, syscall.FILE_LIST_DIRECTORY,
+		syscall.FILE_SHARE_READ|syscall.FILE_SHARE_WRITE|syscall.FILE_SHARE_DELETE,
+		nil, syscall.OPEN_EXISTING, syscall.FILE_FLAG_BACKUP_SEMANTICS|syscall.FILE_FLAG_OVERLAPPED, 0)
+	if err != nil {
+		return nil, &fs.PathError{Op: "watch", Path: root, Err: err}
+	}
+	changes := make(chan string, 64)
+	go readChanges(h, root, stop, changes)
+	return changes, nil
+}
+
+// readChanges reports the changes of the directory handle `h` until
+// stopped or until the directory could no longer be watched.
+func readChanges(h syscall.Handle, root string, stop <-chan struct{}, changes chan<- string) {
+	defer close(changes)
+	defer syscall.CloseHandle(h)
+	buf := make([]byte, watchBufferSize)
+	for {
+		// without event, the handle is signaled once the read completes.
+		var ov syscall.Overlapped
+		err := syscall.ReadDirectoryChanges(h, &buf[0], uint32(len(buf)), true, watchMask, nil, &ov, 0)
+		if err != nil && err != syscall.ERROR_IO_PENDING {
+			return
+		}
+		if !waitChanges(h, &ov, stop) {
+			return
+		}
+		if ov.Internal&0x80000000 != 0 {
+			// failure status, e.g. the root was deleted.
+			return
+		}
+		n := uint32(ov.InternalHigh)
+		if n == 0 {
+			// the buffer overflowed so the changes are unknown.
+			if !sendChange(root, stop, changes) {
+				return
+			}
+			continue
+		}
+		for off := uint32(0); off < n; {
+			fni := (*syscall.FileNotifyInformation)(unsafe.Pointer(&buf[off]))
+			rel := unsafe.Slice(&fni.FileName, fni.FileNameLength/2)
+			if !sendChange(filepath.Join(root, syscall.UTF16ToString(rel)), stop, changes) {
+				return
+			}
+			if fni.NextEntryOffset == 0 {
+				break
+			}
+			off += fni.NextEntryOffset
+		}
+	}
+}
+
+// waitChanges waits for the pending read to complete. It reports false
+// once stopped, after the read was cancelled.
+func waitChanges(h syscall.Handle, ov *syscall.Overlapped, stop <-chan struct{}) bool {
+	for {
+		ev, err := syscall.WaitForSingleObject(h, watchPollMs)
+		if err != nil {
+			return false
+		}
+		if ev == syscall.WAIT_OBJECT_0 {
+			return true
+		}
+		select {
+		case <-stop:
+			// the buffer must not be released while still in use.
+			syscall.CancelIoEx(h, ov)
+			syscall.WaitForSingleObject(h, syscall.INFINITE)
+			return false
+		default:
+		}
+	}
+}
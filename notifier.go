@@ -9,9 +9,8 @@ package gorsn
 import (
 	"context"
 	"fmt"
+	"hash/maphash"
 	"io/fs"
-	"os"
-	"path/filepath"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -20,7 +19,10 @@ import (
 const (
 	DEFAULT_QUEUE_SIZE    = 10
 	DEFAULT_MAX_WORKERS   = 1
+	DEFAULT_HASH_WORKERS  = 2
 	DEFAULT_SCAN_INTERVAL = 1 * time.Second
+
+	DEFAULT_ANOMALY_SENSITIVITY = 100.0
 )
 
 // ScanNotifier is an interface which defines a set of available actions.
@@ -32,12 +34,32 @@ type ScanNotifier interface {
 	// Start begins periodic scanning of root directory and emitting events.
 	Start(context.Context) error
 
+	// StartAsync works like Start but returns once the scanner is launched.
+	StartAsync(context.Context) error
+
+	// Run works like Start but returns the reason of the termination like
+	// Wait, so it composes with supervisors such as `errgroup.Group`.
+	Run(context.Context) error
+
+	// Wait blocks until the scan notifier started terminates and returns
+	// the reason: nil once stopped by Stop, the context error once it is
+	// done or the fatal error which stopped the scans.
+	Wait() error
+
 	// Stop aborts the scanning of root directory and sending events.
 	Stop() error
 
 	// IsRunning reports whether the scan notifier has started.
 	IsRunning() bool
 
+	// IsPaused reports whether the scan notifier was paused and not resumed.
+	IsPaused() bool
+
+	// State returns the current stage of the scan notifier lifecycle so a
+	// supervisor could distinguish a notifier not yet started from a stopped
+	// one which could not be started again.
+	State() LifecycleState
+
 	// Flush clears internal cache history of files and directories under monitoring.
 	// Once succeeded, `CREATE` is the next event for each item under monitoring.
 	// This could be used directly after initialization of the scan notifier instance
@@ -48,18 +70,116 @@ type ScanNotifier interface {
 	Flush()
 
 	// Pause instructs the scanner to escape at each polling interval so no changes
-	// detection will happen then no new events will be sent.
-	// Use Resume() to restart the normal scanning and event notification processes.
+	// detection will happen then no new events will be sent. The in-flight scan
+	// cycle is completed and the debounced events are delivered before idling.
+	// It does not wait for that, use a `LifecycleObserver` to be notified once
+	// idle. Use Resume() to restart the normal scanning and event notification processes.
 	Pause() error
 
+	// PauseImmediately works like Pause but abandons the in-flight scan cycle.
+	// Items not yet checked are left for the cycle following the resumption and
+	// no deletion is reported for the abandoned cycle.
+	PauseImmediately() error
+
+	// PauseFor works like Pause then resumes automatically once the duration `d`
+	// elapsed, e.g. for a maintenance window. Pausing or resuming meanwhile
+	// cancels the automatic resumption.
+	PauseFor(d time.Duration) error
+
+	// PauseUntil works like PauseFor but resumes automatically at the time `t`.
+	PauseUntil(t time.Time) error
+
 	// Resume restarts the scanner and notifier after being into `paused` state.
 	Resume() error
+
+	// PausePath stops the monitoring of the subtrees matching the glob, e.g. a
+	// build output directory, while the rest of the root is still monitored.
+	PausePath(glob string) error
+
+	// ResumePath restarts the monitoring of the subtrees paused with the glob.
+	ResumePath(glob string) error
+
+	// Export returns a copy of the internal cache history of the items under
+	// monitoring. It could be passed to `NewFromState` in order to hand off the
+	// monitoring to a new instance (e.g. with different options) without losing
+	// the changes which happen in between.
+	Export() *State
+
+	// Capabilities reports the changes detection features supported by the
+	// current platform and backend.
+	Capabilities() Capabilities
+
+	// Emit sends a `CREATE` event built from the cached state of a path under
+	// monitoring to the queue and sinks. This allows a consumer joining while
+	// the scan notifier is running to learn about specific items without the
+	// need to `Flush` the whole history.
+	Emit(path string) error
+
+	// Inject sends a caller-built event through the same filtering, debouncing
+	// and delivery as the detected changes, so consumers could be exercised
+	// with events which are hard to trigger with real files.
+	Inject(ev Event) error
+
+	// History returns the latest delivered events which were recorded at or
+	// after `since` and accepted by all `filters`, from the oldest to the most
+	// recent. Its depth is defined via `Options.Delivery().SetHistorySize`.
+	History(since time.Time, filters ...Filter) []Event
+
+	// Stats returns the counters of the events delivery.
+	Stats() Stats
+
+	// QueueFor returns a channel which receives only the events of the given
+	// names (e.g. `CREATE` and `DELETE`) so consumers interested in a few kinds
+	// of events do not need to filter the whole stream.
+	QueueFor(names ...EventName) <-chan Event
+
+	// Health returns the lifecycle state, the outcome of the latest scan
+	// cycles and the saturation of the queue.
+	Health() Health
+
+	// Progress returns the number of paths visited so far, the current
+	// directory and the estimated completion of the in-flight scan cycle.
+	Progress() Progress
+
+	// LastScanAt returns the completion time of the latest successful scan
+	// cycle and NextScanAt the scheduled start of the next one, so health
+	// checks could alert once the scans stop making progress.
+	LastScanAt() time.Time
+	NextScanAt() time.Time
 }
 
+// pathInfos holds the latest known state of a path. Its content infos are
+// written by the worker which checks the path, then by the hashing worker,
+// while they could be read at anytime, e.g. by `Export` or `Emit`, so they
+// are guarded by `mu`. The other fields are owned by the scanning routines.
 type pathInfos struct {
-	modTime time.Time
-	mode    fs.FileMode
-	visited bool
+	mu       sync.RWMutex // guards modTime, mode, size, sum and sys.
+	modTime  time.Time
+	mode     fs.FileMode
+	visited  bool
+	size     int64
+	sum      string
+	blocks   *blockSums // digests of the content blocks if the changed regions are enabled.
+	sys      sysInfo
+	settling bool
+	quiet    uint32
+	missing  uint32
+	seen     atomic.Int64 // latest scan cycle which visited the path.
+
+	modeUnknown bool // permissions not recorded by an older exported state.
+}
+
+// newPathInfos builds the infos to keep into the cache history of a path.
+// The content digest is left to the caller.
+func (sn *snotifier) newPathInfos(fi fs.FileInfo, visited bool) *pathInfos {
+	pi := &pathInfos{
+		modTime: fi.ModTime(),
+		mode:    fi.Mode(),
+		visited: visited,
+		size:    fi.Size(),
+		sys:     sysStat(fi),
+	}
+	return pi
 }
 
 type fsEntry struct {
@@ -69,17 +189,92 @@ type fsEntry struct {
 }
 
 type snotifier struct {
-	root     string
-	opts     *Options
-	paths    sync.Map
-	queue    chan Event
-	iqueue   chan *fsEntry
-	stop     chan struct{}
-	ready    bool
-	wg       *sync.WaitGroup
-	running  atomic.Bool
-	stopping atomic.Bool
-	paused   atomic.Bool
+	root       string
+	single     bool
+	opts       *Options
+	paths      sync.Map
+	tracked    atomic.Int64 // number of paths into the cache.
+	footprint  atomic.Int64 // estimated memory used by the paths cache.
+	exceeded   atomic.Bool  // memory budget exceeded was reported.
+	maxDepth   atomic.Int32 // depth of the scanned paths, zero if unlimited.
+	tmu        sync.Mutex
+	epoch      atomic.Int64 // sequence number of the in-flight scan cycle.
+	overflowed atomic.Bool
+	queue      chan Event
+	iqueue     chan *fsEntry
+	stop       chan struct{}
+	changes    <-chan string // paths notified by the backend, nil if polling.
+	ready      atomic.Bool
+	stopped    atomic.Bool
+	started    atomic.Bool
+	done       chan struct{} // closed once terminated.
+	exit       error         // reason of the termination.
+	lost       bool          // root path could not be found by the latest scan.
+	seed       *State
+	store      StateStore
+	dirty      sync.Map    // paths changed since the latest save into the store.
+	lookup     StateLookup // store of the items not cached, nil unless the cache is limited.
+	resumed    bool        // items of the root already stored when created.
+	vmu        sync.Mutex
+	visits     []uint64 // hashes of the stored paths not cached found by the scan.
+	vseed      maphash.Seed
+	cfailed    atomic.Bool // a lookup failed during the current scan, reported once.
+	locked     sync.Map    // paths reported as locked by another process.
+	dedup      dedupper
+	label      string // default label of the root, e.g. of the manager watch.
+	cycle      int
+	visited    atomic.Int64
+	emitted    atomic.Int64
+	counts     eventCounts // events queued per kind during the in-flight scan cycle.
+	baseline   rateBaseline
+	oversized  map[string]bool // supervised directories over their size limit.
+	wg         *sync.WaitGroup
+	cwg        sync.WaitGroup // routines of the config file watcher.
+	running    atomic.Bool
+	stopping   atomic.Bool
+	paused     atomic.Bool
+	subtrees   pausedPaths  // globs of the subtrees paused by `PausePath`.
+	resumeAt   atomic.Int64 // unix nano time of the automatic resumption, zero if none.
+	idling     bool
+	abort      atomic.Bool
+	aborted    atomic.Bool
+	ordering   atomic.Bool
+	omu        sync.Mutex
+	ordered    []Event
+	pmu        sync.Mutex
+	pending    []pendingCreate
+	pendingIDs map[fileID][]int // indexes of the pending creates not yet renamed.
+	lmu        sync.Mutex
+	links      linkIndex
+	held       []heldLink     // changes of links count held until the end of the cycle.
+	linked     map[fileID]int // paths linked and unlinked within the root during the cycle.
+	sums       map[fileID]cachedSum
+	debounce   debouncer
+	hsem       chan struct{}
+	hwg        sync.WaitGroup
+	items      sync.WaitGroup // entries of the current scan not yet checked.
+	size       uint32         // number of workers into the pool.
+	target     uint32         // workers pool size for the next scan cycle.
+	backlog    int            // highest internal queue length of the current scan.
+	ddone      chan struct{}
+	once       sync.Once
+	emu        sync.RWMutex
+	closed     bool         // queues were closed.
+	subs       atomic.Value // []*subscription
+	history    history
+	acks       ackTracker
+	health     scanHealth
+	progress   progress
+	pacer      pacer
+
+	delivered   atomic.Int64
+	redelivered atomic.Int64
+	dropped     atomic.Int64
+	rateLimited atomic.Int64
+	overflow    overflow // events dropped from the main queue.
+	limiter     rateLimiter
+
+	gitignore atomic.Value // *gitignore
 }
 
 // Queue returns a read only channel of events.
@@ -96,7 +291,7 @@ func (sn *snotifier) Stop() error {
 	if !sn.IsRunning() {
 		return ErrScanIsNotRunning
 	}
-	sn.stop <- struct{}{}
+	sn.halt()
 	return nil
 }
 
@@ -121,9 +316,10 @@ func (sn *snotifier) flush() {
 		return
 	}
 	sn.paths.Range(func(key interface{}, value interface{}) bool {
-		sn.paths.Delete(key)
+		sn.untrack(key)
 		return true
 	})
+	sn.linksReset()
 }
 
 // New provides an initialized object which satisfies the ScanNotifier interface.
@@ -132,28 +328,84 @@ func (sn *snotifier) flush() {
 // parsed and loaded based on the options provided by `opts`. It returns and error
 // which wraps `ErrInvalidRootDirPath` in case the root path is not an accessible
 // directory. `ErrInitialization` means the initialization encoutered an error.
+// An error which wraps `ErrInvalidOptions` is returned for contradictory options.
+// The `root` could also be the path to a regular file. In that case, only that
+// file existence, content and permissions changes are monitored.
 func New(root string, opts *Options) (ScanNotifier, error) {
-	if fi, err := os.Stat(root); err != nil || !fi.IsDir() {
-		return nil, fmt.Errorf("%w: %v", ErrInvalidRootDirPath, err)
+	return newSnotifier(root, opts, nil)
+}
+
+// NewFromState works like `New` but uses the exported `state` of another instance
+// as the initial cache history. Items found into the root directory are compared
+// against their imported state on the first scan, so changes made during the hand
+// off are notified, items which appeared are reported as created and the ones
+// which disappeared are reported as deleted. It returns an error which wraps
+// `ErrInvalidState` if the state does not belong to `root` or was produced by a
+// newer version with an unsupported `SchemaVersion`. This is equivalent to set
+// the state via `Options.Persistence().SetState`.
+func NewFromState(root string, opts *Options, state *State) (ScanNotifier, error) {
+	if state == nil {
+		return nil, fmt.Errorf("%w: root %q", ErrInvalidState, root)
 	}
+	return newSnotifier(root, opts, state)
+}
 
+func newSnotifier(root string, opts *Options, state *State) (*snotifier, error) {
 	opts = opts.setup()
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if state == nil {
+		state = opts.persist.state
+	}
+	lookup, _ := opts.persist.store.(StateLookup)
+	if opts.persist.maxCached == 0 {
+		lookup = nil
+	}
+	if state == nil && opts.persist.store != nil && lookup == nil {
+		var err error
+		if state, err = opts.persist.store.Load(root); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidState, err)
+		}
+	}
+	if state != nil && (state.Root != root || state.Schema > SchemaVersion) {
+		return nil, fmt.Errorf("%w: root %q", ErrInvalidState, root)
+	}
+
+	fi, err := opts.scan.backend.Stat(root)
+	if err != nil || !(fi.IsDir() || fi.Mode().IsRegular()) {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRootDirPath, err)
+	}
 
 	sn := &snotifier{
-		root:  root,
-		opts:  opts,
-		paths: sync.Map{},
+		root:   root,
+		opts:   opts,
+		paths:  sync.Map{},
+		seed:   state,
+		store:  opts.persist.store,
+		lookup: lookup,
+		vseed:  maphash.MakeSeed(),
+		single: !fi.IsDir(),
+	}
+	if lookup != nil && state == nil {
+		// the stored items are looked up by the scans instead of being loaded.
+		if sn.resumed, err = sn.resumable(); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidState, err)
+		}
 	}
 
-	if err := filepath.WalkDir(sn.root, sn.init); err != nil {
+	sn.loadGitignore()
+	if err := walk(sn.opts.scan.backend, sn.root, sn.init); err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrInitialization, err)
 	}
+	sn.importMissing()
 
-	sn.queue = make(chan Event, opts.queueSize)
-	sn.iqueue = make(chan *fsEntry, opts.queueSize)
+	sn.queue = make(chan Event, opts.delivery.queueSize.Load())
+	sn.iqueue = make(chan *fsEntry, opts.delivery.queueSize.Load())
 	sn.stop = make(chan struct{})
+	sn.done = make(chan struct{})
 	sn.wg = &sync.WaitGroup{}
-	sn.ready = true
+	sn.ready.Store(true)
 	return sn, nil
 }
 
@@ -164,108 +416,349 @@ func (sn *snotifier) init(s string, d fs.DirEntry, err error) error {
 
 	t := getPathType(d.Type())
 	if ignore, err := sn.check(s, t, err); ignore {
+		if err == nil {
+			err = sn.descend(s, d)
+		}
 		return err
 	}
 
+	if skip, err := sn.skipUntracked(s, d); skip {
+		return err
+	}
+	if pi, ok := sn.seedInfos(s); ok {
+		if !sn.ownerFiltered(t, pi.sys) && sn.admit(s, t) {
+			sn.track(s, pi)
+		}
+		return sn.descend(s, d)
+	}
+	if sn.seed != nil || sn.resumed {
+		// created since the state was recorded or stored, reported by the
+		// first scan.
+		return sn.descend(s, d)
+	}
+
 	if fi, err := d.Info(); err == nil {
-		sn.paths.Store(s, &pathInfos{fi.ModTime(), d.Type(), false})
+		if sn.ownerFiltered(t, sysStat(fi)) || !sn.admit(s, t) {
+			return nil
+		}
+		pi := sn.newPathInfos(fi, false)
+		if fi.Mode().IsRegular() && sn.opts.scan.checksum.Load() {
+			sum, blocks, _ := sn.digest(s, fi, pi.sys)
+			pi.mu.Lock()
+			pi.sum, pi.blocks = sum, blocks
+			pi.mu.Unlock()
+		}
+		sn.track(s, pi)
+		sn.changed(s)
+		sn.linkAdd(s, pi.sys)
+		if sn.lookup != nil && sn.tracked.Load() >= 2*sn.opts.persist.maxCached {
+			// record the items by batches instead of caching them all.
+			if err := sn.saveState(); err != nil {
+				return err
+			}
+			sn.cool()
+		}
 	}
 
-	return err
+	return sn.descend(s, d)
 }
 
 // Start is a blocking method which pre-boots the consumers
 // and starts the infinite loop scanner to monitor the root
 // directory contents.
 func (sn *snotifier) Start(ctx context.Context) error {
-	if sn.IsRunning() {
-		return ErrScanAlreadyStarted
+	if err := sn.launch(); err != nil {
+		return err
 	}
+	sn.run(ctx)
+	return nil
+}
+
+// StartAsync starts the scanner and events notifications routines
+// into the background. Use Wait to block until their termination.
+func (sn *snotifier) StartAsync(ctx context.Context) error {
+	if err := sn.launch(); err != nil {
+		return err
+	}
+	go sn.run(ctx)
+	return nil
+}
+
+// Run starts the scan notifier, blocks until its termination and returns
+// the reason: nil once stopped by Stop, the context error once it is done
+// or the fatal error which stopped the scans (e.g. the root path was lost).
+func (sn *snotifier) Run(ctx context.Context) error {
+	if err := sn.launch(); err != nil {
+		return err
+	}
+	sn.run(ctx)
+	return sn.exit
+}
+
+// Wait blocks until the scan notifier terminates and returns the reason.
+// It returns `ErrScanIsNotRunning` if the scan notifier was not started.
+func (sn *snotifier) Wait() error {
+	if !sn.started.Load() {
+		return ErrScanIsNotRunning
+	}
+	<-sn.done
+	return sn.exit
+}
+
+// launch marks the scan notifier as running and starts the debouncer.
+func (sn *snotifier) launch() error {
 	if sn.isStopping() {
 		return ErrScanIsStopping
 	}
-	if !sn.ready {
+	if !sn.ready.Load() {
 		return ErrScanIsNotReady
 	}
-	sn.running.Store(true)
-	// sn.workers()
-	sn.scanner(ctx)
+	if !sn.running.CompareAndSwap(false, true) {
+		return ErrScanAlreadyStarted
+	}
+	if err := sn.watchConfig(); err != nil {
+		sn.running.Store(false)
+		return err
+	}
+	sn.started.Store(true)
+	sn.ddone = make(chan struct{})
+	go sn.debounceLoop()
+	sn.notifyStart()
 	return nil
 }
 
+// run scans until termination then records its reason.
+func (sn *snotifier) run(ctx context.Context) {
+	sn.scanner(ctx)
+	close(sn.done)
+}
+
 // scanner runs an infinite scan loop after each interval of time.
 // it exits on context cancellation or on call to stop the notifier.
 func (sn *snotifier) scanner(ctx context.Context) {
-	var done atomic.Bool
 	for {
 		select {
 		case <-sn.stop:
 			sn.finalize()
 			return
 		case <-ctx.Done():
+			if sn.exit == nil {
+				sn.exit = ctx.Err()
+			}
 			sn.finalize()
 			return
 		default:
 			if sn.paused.Load() {
-				time.Sleep(sn.opts.scanInterval.Load().(time.Duration))
+				sn.idle()
+				sn.sleep(ctx, sn.opts.scan.interval.Load().(time.Duration))
 				continue
 			}
-			done.Store(false)
-			sn.workers(&done)
-			filepath.WalkDir(sn.root, sn.scan)
-			done.Store(true)
-			sn.wg.Wait()
-
-			if !sn.opts.event.ignoreDelete.Load() {
-				sn.missingPaths()
+			sn.wake()
+			sn.cycle++
+			sn.epoch.Store(int64(sn.cycle))
+			sn.overflowed.Store(false)
+			sn.aborted.Store(false)
+			sn.ordering.Store(sn.opts.delivery.ordered.Load())
+			sn.loadGitignore()
+			sn.sumsReset()
+			sn.beforeScan(sn.cycle)
+			start := sn.now()
+			sn.visited.Store(0)
+			sn.emitted.Store(0)
+			sn.counts.reset()
+			sn.progress.begin(sn.cycle, start)
+			sn.hsem = make(chan struct{}, sn.opts.scan.hashWorkers.Load())
+			sn.backlog = 0
+			sn.resize(sn.poolSize())
+			err := walk(sn.opts.scan.backend, sn.root, sn.walker(ctx))
+			sn.items.Wait()
+			sn.hwg.Wait()
+			if sn.cancelled(ctx) {
+				// the walk was abandoned, exit on next loop.
+				sn.unvisit()
+				continue
+			}
+			if !sn.aborted.Load() {
+				sn.health.done(err, sn.now())
+			}
+
+			if sn.aborted.Load() {
+				sn.unvisit()
+			} else if sn.needsMissingPaths() {
+				sn.missingPaths(nil)
 			}
-			time.Sleep(sn.opts.scanInterval.Load().(time.Duration))
+			sn.flushLinks()
+			sn.flushPending()
+			sn.flushOrdered()
+			sn.reportOverflows()
+			sn.saveState()
+			sn.cool()
+			sn.progress.end(sn.visited.Load())
+			if !sn.aborted.Load() {
+				// the statistics of an abandoned cycle are partial.
+				sn.afterScan(sn.cycle, start)
+			}
+			sn.scale(sn.now().Sub(start))
+			sn.rest(ctx)
 		}
 	}
 }
 
+// cancelled reports whether the notifier was stopped or its context cancelled.
+func (sn *snotifier) cancelled(ctx context.Context) bool {
+	select {
+	case <-sn.stop:
+		return true
+	case <-ctx.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+// walker returns the function called for each visited path of a scan. It
+// abandons the walk as soon as the notifier is stopped or the context is
+// cancelled so shutdown does not wait for the end of a long scan.
+func (sn *snotifier) walker(ctx context.Context) fs.WalkDirFunc {
+	return func(s string, d fs.DirEntry, err error) error {
+		if sn.cancelled(ctx) {
+			sn.aborted.Store(true)
+			return fs.SkipAll
+		}
+		return sn.scan(s, d, err)
+	}
+}
+
 func (sn *snotifier) scan(s string, d fs.DirEntry, err error) error {
+	if sn.abort.Load() {
+		sn.aborted.Store(true)
+		return fs.SkipAll
+	}
+	if d == nil {
+		// root could not be read (e.g. watched file was removed).
+		if s == sn.root && err != nil {
+			sn.health.fail(err)
+			sn.lose(err)
+		}
+		return nil
+	}
+	if s == sn.root {
+		sn.restore()
+	}
 	t := getPathType(d.Type())
+	if sn.pathPaused(s) {
+		sn.trace(traceRecord{Kind: traceSkip, Path: s, Type: t, Detail: "paused"})
+		if d.IsDir() {
+			return fs.SkipDir
+		}
+		return nil
+	}
 	if ignore, cerr := sn.check(s, t, err); ignore {
+		sn.trace(traceRecord{Kind: traceSkip, Path: s, Type: t})
+		if cerr == nil {
+			cerr = sn.descend(s, d)
+		}
 		return cerr
 	}
-
-	fse := &fsEntry{path: s, d: d}
-	if err != nil {
-		fse.err = err
+	if skip, serr := sn.skipUntracked(s, d); skip {
+		sn.trace(traceRecord{Kind: traceSkip, Path: s, Type: t, Detail: "too many paths"})
+		return serr
 	}
+	collapse, berr := sn.collapsed(s, d)
+	if collapse {
+		sn.trace(traceRecord{Kind: traceSkip, Path: s, Type: t, Detail: "memory budget"})
+		return berr
+	}
+	sn.trace(traceRecord{Kind: traceVisit, Path: s, Type: t})
+
+	fse := entries.Get().(*fsEntry)
+	fse.path, fse.d, fse.err = s, d, err
 
+	if d.IsDir() {
+		sn.awaitLoad()
+		sn.pace(2)
+		sn.progress.enter(s)
+	} else {
+		sn.pace(1)
+	}
+	sn.reportProgress(sn.visited.Add(1))
+	sn.grow()
+	sn.items.Add(1)
 	sn.iqueue <- fse
-	return nil
+	if berr != nil {
+		return berr
+	}
+	return sn.descend(s, d)
+}
+
+// needsMissingPaths tells whether deleted paths must be looked for.
+func (sn *snotifier) needsMissingPaths() bool {
+	return !sn.opts.events.ignoreDelete.Load() ||
+		sn.opts.events.trackRenames.Load() ||
+		sn.opts.events.trackLinks.Load()
 }
 
 // missingPaths scans all latest registered paths to find
 // deleted paths and trigger a `DELETE` event for each if
-// this option was enabled. It aborts once the notifier is
-// stopped.
-func (sn *snotifier) missingPaths() {
+// this option was enabled. Paths are reported only once
+// missing for longer than the delete grace period. If
+// `under` is not nil, only the paths under these ones
+// are checked. It aborts once the notifier is stopped.
+func (sn *snotifier) missingPaths(under []string) {
+	sn.warmMissing(under)
+	var deleted []Event
+	collapse := sn.opts.events.collapseDeletes.Load()
+	defer func() {
+		for _, ev := range collapseDeletes(deleted) {
+			sn.queueEvent(ev)
+		}
+	}()
 	sn.paths.Range(func(key, value any) bool {
 		if !sn.running.Load() {
 			return false
 		}
 		path := key.(string)
 		pi := value.(*pathInfos)
+		if under != nil && !isUnder(path, under) {
+			return true
+		}
 		if pi.visited {
 			pi.visited = false
 			return true
 		}
+		if sn.pathPaused(path) {
+			// not scanned while paused.
+			return true
+		}
 
-		if !sn.opts.event.ignoreDelete.Load() {
-			ev := Event{path, getPathType(pi.mode), DELETE, nil}
+		ev, renamed := sn.renamed(path, pi)
+		if !renamed && sn.inDeleteGrace(pi) {
+			sn.trace(traceRecord{Kind: traceMissing, Path: path, Type: getPathType(pi.mode), Detail: "delete grace"})
+			return true
+		}
+		sn.trace(traceRecord{Kind: traceMissing, Path: path, Type: getPathType(pi.mode)})
+		other, unlinked := sn.linkRemove(path, pi.sys)
+		if renamed {
 			sn.queueEvent(ev)
+		} else if unlinked {
+			sn.countLink(pi.sys.id(), -1)
+			sn.queueEvent(Event{Path: path, OldPath: other, Type: getPathType(pi.mode), Name: UNLINK})
+		} else if !sn.opts.events.ignoreDelete.Load() {
+			ev := Event{Path: path, Type: getPathType(pi.mode), Name: DELETE}
+			if collapse {
+				deleted = append(deleted, ev)
+			} else {
+				sn.queueEvent(ev)
+			}
 		}
-		sn.paths.Delete(path)
+		sn.untrack(path)
 		return true
 	})
 }
 
 // Pause triggers the scanner routine to escape at each intervall
 // so that no new changes will be detected and no events to be sent.
+// The current scan cycle runs until its end.
 func (sn *snotifier) Pause() error {
 	if sn.isStopping() {
 		return ErrScanIsStopping
@@ -273,6 +766,22 @@ func (sn *snotifier) Pause() error {
 	if !sn.IsRunning() {
 		return ErrScanIsNotRunning
 	}
+	sn.resumeAt.Store(0)
+	sn.paused.Store(true)
+	return nil
+}
+
+// PauseImmediately triggers the scanner routine to abandon the
+// current scan cycle then to escape at each intervall like Pause.
+func (sn *snotifier) PauseImmediately() error {
+	if sn.isStopping() {
+		return ErrScanIsStopping
+	}
+	if !sn.IsRunning() {
+		return ErrScanIsNotRunning
+	}
+	sn.abort.Store(true)
+	sn.resumeAt.Store(0)
 	sn.paused.Store(true)
 	return nil
 }
@@ -290,6 +799,8 @@ func (sn *snotifier) Resume() error {
 	if !sn.paused.Load() {
 		return ErrScanIsNotPaused
 	}
+	sn.resumeAt.Store(0)
+	sn.abort.Store(false)
 	sn.paused.Store(false)
 	return nil
 }
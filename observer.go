@@ -0,0 +1,71 @@
+package gorsn
+
+// LifecycleObserver receives notifications about the internal lifecycle
+// of a scan notifier. Methods are called synchronously from the notifier
+// routines so implementations are expected to return quickly.
+type LifecycleObserver interface {
+	// OnStart is called once the scan notifier has started.
+	OnStart()
+	// OnStop is called once the scan notifier has fully stopped.
+	OnStop()
+	// OnPause is called once the scan notifier has been paused and
+	// is idle, so all the events of the last scan cycle were delivered.
+	OnPause()
+	// OnResume is called once the scan notifier resumed the scanning.
+	OnResume()
+	// OnCycle is called at the end of each scan cycle.
+	OnCycle(ScanSummary)
+}
+
+// lifecycleHook is an observer which calls the defined callbacks.
+type lifecycleHook struct {
+	start, stop, pause, resume func()
+}
+
+func (h lifecycleHook) OnStart()            { call(h.start) }
+func (h lifecycleHook) OnStop()             { call(h.stop) }
+func (h lifecycleHook) OnPause()            { call(h.pause) }
+func (h lifecycleHook) OnResume()           { call(h.resume) }
+func (h lifecycleHook) OnCycle(ScanSummary) {}
+
+func call(fn func()) {
+	if fn != nil {
+		fn()
+	}
+}
+
+// observers returns the current list of registered observers.
+func (do *DeliveryOptions) observers() []LifecycleObserver {
+	obs, _ := do.lobservers.Load().([]LifecycleObserver)
+	return obs
+}
+
+func (sn *snotifier) notifyStart() {
+	for _, ob := range sn.opts.delivery.observers() {
+		ob.OnStart()
+	}
+}
+
+func (sn *snotifier) notifyStop() {
+	for _, ob := range sn.opts.delivery.observers() {
+		ob.OnStop()
+	}
+}
+
+func (sn *snotifier) notifyPause() {
+	for _, ob := range sn.opts.delivery.observers() {
+		ob.OnPause()
+	}
+}
+
+func (sn *snotifier) notifyResume() {
+	for _, ob := range sn.opts.delivery.observers() {
+		ob.OnResume()
+	}
+}
+
+func (sn *snotifier) notifyCycle(summary ScanSummary) {
+	for _, ob := range sn.opts.delivery.observers() {
+		ob.OnCycle(summary)
+	}
+}
@@ -1,42 +1,151 @@
 package gorsn
 
 import (
+	"fmt"
+	"hash"
+	"io"
+	"math"
+	"path"
+	"path/filepath"
 	"regexp"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 )
 
-// eventOps represents optional fields to define which event to produce.
-type eventOps struct {
-	ignoreErrors        atomic.Bool
-	ignoreNoChange      atomic.Bool // should not emit event when nothing changed. default to true.
-	ignoreDelete        atomic.Bool
-	ignoreCreate        atomic.Bool
-	ignoreModify        atomic.Bool
-	ignorePerm          atomic.Bool
-	ignoreFile          atomic.Bool // should emit event for regular files.
-	ignoreFolder        atomic.Bool // should emit event for directories.
+// ScanOptions groups the settings of the scanning process.
+type ScanOptions struct {
+	interval    atomic.Value // time.Duration
+	maxworkers  atomic.Uint32
+	backend     Backend
+	clock       Clock
+	checksum    atomic.Bool  // should compare content digest of regular files.
+	hasher      atomic.Value // func() hash.Hash
+	hashName    atomic.Value // string
+	hashWorkers atomic.Uint32
+	beforeScan  atomic.Value // func(cycle int)
+	afterScan   atomic.Value // func(cycle int, summary ScanSummary)
+	trace       atomic.Value // *tracer
+	hot         atomic.Value // *hotPaths
+	scale       atomic.Value // *autoscale
+	progress    atomic.Value // *progressReport
+	maxTracked  atomic.Int64
+	limitPolicy atomic.Uint32
+	compare     atomic.Value  // Comparator
+	throttle    atomic.Int64  // file system operations per second, zero if unlimited.
+	maxLoad     atomic.Uint64 // bits of the system load which pauses the scans.
+	memBudget   atomic.Int64  // bytes of the paths cache, zero if unlimited.
+	configFile  string        // config file to reload on changes.
+}
+
+// FilterOptions groups the settings which define the paths to monitor.
+type FilterOptions struct {
+	excludePaths        atomic.Value // *regexp.Regexp
+	includePaths        atomic.Value // *regexp.Regexp
+	ignoreFile          atomic.Bool  // should emit event for regular files.
+	ignoreFolder        atomic.Bool  // should emit event for directories.
 	ignoreSymlink       atomic.Bool
-	ignoreFolderContent atomic.Bool // should emit event for each sub-content of a directory included the directory itself.
+	ignoreFifo          atomic.Bool
+	ignoreSocket        atomic.Bool
+	ignoreDevice        atomic.Bool  // should emit event for block and character devices.
+	ignoreFolderContent atomic.Bool  // should emit event for each sub-content of a directory included the directory itself.
+	gitignore           atomic.Bool  // should skip paths ignored by root `.gitignore` file.
+	topLevelOnly        atomic.Bool  // should skip the content of sub-directories.
+	ignoreEditorTemp    atomic.Bool  // should skip temporary files of editors.
+	ignoreArtifacts     atomic.Bool  // should skip system, temporary and partial files.
+	ctypes              atomic.Value // []string
+	uids                atomic.Value // []int
+	gids                atomic.Value // []int
+}
+
+// EventOptions groups the settings which define which event to produce.
+type EventOptions struct {
+	ignoreErrors       atomic.Bool
+	ignoreNoChange     atomic.Bool // should not emit event when nothing changed. default to true.
+	ignoreDelete       atomic.Bool
+	ignoreCreate       atomic.Bool
+	ignoreModify       atomic.Bool
+	ignorePerm         atomic.Bool
+	ignoreAttrib       atomic.Bool
+	trackOwner         atomic.Bool // should emit event on ownership change.
+	trackRenames       atomic.Bool // should emit a single event for a moved file.
+	trackLinks         atomic.Bool // should emit event on hard links changes.
+	settleCycles       atomic.Uint32
+	deleteGrace        atomic.Uint32
+	vanished           atomic.Uint32 // VanishedMode
+	anomalyWindow      atomic.Uint32
+	anomalySensitivity atomic.Value // float64
+	sizeLimits         atomic.Value // map[string]int64
+	stopOnRootLost     atomic.Bool
+	collapseDeletes    atomic.Bool   // should report a deleted directory without its content.
+	detectTouch        atomic.Bool   // should emit `TOUCH` when only the modification time changed.
+	atomicSaves        atomic.Bool   // should report a file saved via a temporary file as modified.
+	classify           atomic.Value  // func(Event) Severity
+	tags               atomic.Value  // []tagRule
+	scanSummary        atomic.Bool   // should emit `SCAN_SUMMARY` at the end of each scan cycle.
+	filter             atomic.Value  // *filterExpr
+	detectLocked       atomic.Bool   // should emit a single `LOCKED` event for a file locked by another process.
+	pathForm           atomic.Uint32 // PathForm
+	normalizer         atomic.Value  // func(string) string
+	relativePaths      atomic.Bool   // should emit the paths relative to the root.
+	label              atomic.Value  // string
+	includeChecksum    atomic.Bool   // should set the content digest of regular files into the events.
+	detectContentType  atomic.Bool   // should sniff the MIME type of regular files.
+	dedupWindow        atomic.Value  // time.Duration
+	detectRotation     atomic.Bool   // should emit `ROTATE` when a file is replaced by a much smaller one.
+	appends            atomic.Value  // []*regexp.Regexp
+	blockSize          atomic.Int64  // bytes of the blocks compared to find the changed regions, zero if disabled.
+	mu                 sync.Mutex
+}
+
+// DeliveryOptions groups the settings of the events delivery to consumers.
+type DeliveryOptions struct {
+	queueSize  atomic.Int64
+	debounce   atomic.Value // time.Duration
+	history    atomic.Uint32
+	ackTimeout atomic.Value  // time.Duration
+	ordered    atomic.Bool   // should sort the events of a scan cycle.
+	maxRate    atomic.Int64  // events delivered per second, zero if unlimited.
+	overflow   atomic.Uint32 // OverflowPolicy
+	subsOnly   atomic.Bool   // events sent to the subscriptions only.
+	lobservers atomic.Value  // []LifecycleObserver
+	esinks     atomic.Value  // []Sink
+	mu         sync.Mutex
+}
+
+// PersistenceOptions groups the settings related to the state of the items
+// under monitoring which outlives a scan notifier instance.
+type PersistenceOptions struct {
+	state     *State
+	store     StateStore
+	maxCached int64
 }
 
+// Options holds all the settings of a scan notifier. They are organized into
+// groups accessible via their own method. Apart from the queue size, backend
+// and the initial state, settings are safe to be modified at anytime even by
+// multiple goroutines while the scan notifier is running.
 type Options struct {
-	queueSize    int
-	maxworkers   atomic.Uint32
-	event        eventOps
-	scanInterval atomic.Value
-	excludePaths *regexp.Regexp
-	includePaths *regexp.Regexp
+	scan     ScanOptions
+	filters  FilterOptions
+	events   EventOptions
+	delivery DeliveryOptions
+	persist  PersistenceOptions
 }
 
 func defaultOpts() *Options {
 	o := &Options{}
-	o.queueSize = DEFAULT_QUEUE_SIZE
-	o.maxworkers.Store(DEFAULT_MAX_WORKERS)
-	o.scanInterval.Store(DEFAULT_SCAN_INTERVAL)
-	o.excludePaths = nil
-	o.includePaths = nil
-	o.event.ignoreNoChange.Store(true)
+	o.delivery.queueSize.Store(DEFAULT_QUEUE_SIZE)
+	o.delivery.debounce.Store(time.Duration(0))
+	o.delivery.ackTimeout.Store(time.Duration(0))
+	o.events.dedupWindow.Store(time.Duration(0))
+	o.scan.maxworkers.Store(DEFAULT_MAX_WORKERS)
+	o.scan.hashWorkers.Store(DEFAULT_HASH_WORKERS)
+	o.scan.interval.Store(DEFAULT_SCAN_INTERVAL)
+	o.scan.backend = osBackend{}
+	o.scan.clock = realClock{}
+	o.events.ignoreNoChange.Store(true)
 	return o
 }
 
@@ -45,101 +154,950 @@ func (o *Options) setup() *Options {
 		o = defaultOpts()
 		return o
 	}
-	if o.queueSize <= 0 {
-		o.queueSize = DEFAULT_QUEUE_SIZE
+	if o.scan.backend == nil {
+		o.scan.backend = osBackend{}
 	}
-	if o.maxworkers.Load() == 0 {
+	if o.scan.clock == nil {
+		o.scan.clock = realClock{}
+	}
+	if o.delivery.queueSize.Load() <= 0 {
+		o.delivery.queueSize.Store(DEFAULT_QUEUE_SIZE)
+	}
+	if o.scan.maxworkers.Load() == 0 {
 		// maxworkers was not set.
-		o.maxworkers.Store(DEFAULT_MAX_WORKERS)
+		o.scan.maxworkers.Store(DEFAULT_MAX_WORKERS)
+	}
+	if o.scan.hashWorkers.Load() == 0 {
+		// hashWorkers was not set.
+		o.scan.hashWorkers.Store(DEFAULT_HASH_WORKERS)
+	}
+	if o.scan.interval.Load() == nil {
+		// scan interval was not set.
+		o.scan.interval.Store(DEFAULT_SCAN_INTERVAL)
 	}
-	if o.scanInterval.Load() == nil {
-		// scanInterval was not set.
-		o.scanInterval.Store(DEFAULT_SCAN_INTERVAL)
+	if o.delivery.debounce.Load() == nil {
+		// debounce was not set.
+		o.delivery.debounce.Store(time.Duration(0))
 	}
-	o.event.ignoreNoChange.Store(true)
+	if o.delivery.ackTimeout.Load() == nil {
+		// ack timeout was not set.
+		o.delivery.ackTimeout.Store(time.Duration(0))
+	}
+	if o.events.dedupWindow.Load() == nil {
+		// dedup window was not set.
+		o.events.dedupWindow.Store(time.Duration(0))
+	}
+	o.events.ignoreNoChange.Store(true)
 	return o
 }
 
+// Scan returns the settings of the scanning process.
+func (o *Options) Scan() *ScanOptions {
+	return &o.scan
+}
+
+// Filters returns the settings which define the paths to monitor.
+func (o *Options) Filters() *FilterOptions {
+	return &o.filters
+}
+
+// Events returns the settings which define which event to produce.
+func (o *Options) Events() *EventOptions {
+	return &o.events
+}
+
+// Delivery returns the settings of the events delivery to consumers.
+func (o *Options) Delivery() *DeliveryOptions {
+	return &o.delivery
+}
+
+// Persistence returns the settings related to the state of the items.
+func (o *Options) Persistence() *PersistenceOptions {
+	return &o.persist
+}
+
+// RegexOpts returns an Options instance which skips the paths matching `eregex`
+// and monitors only the ones matching `iregex`. Both could be nil.
 func RegexOpts(eregex, iregex *regexp.Regexp) *Options {
-	if eregex != nil && eregex.String() == "" {
-		eregex = nil
+	o := &Options{}
+	o.filters.SetExcludeRegex(eregex).SetIncludeRegex(iregex)
+	return o
+}
+
+// SetInterval defines the delay between two scan cycles.
+// A negative value falls back to `DEFAULT_SCAN_INTERVAL`.
+func (so *ScanOptions) SetInterval(v time.Duration) *ScanOptions {
+	if v < 0 {
+		so.interval.Store(DEFAULT_SCAN_INTERVAL)
+		return so
 	}
-	if iregex != nil && iregex.String() == "" {
-		iregex = nil
+	so.interval.Store(v)
+	return so
+}
+
+// SetMaxWorkers defines the number of goroutines which build the events.
+// A zero or negative value falls back to `DEFAULT_MAX_WORKERS`.
+func (so *ScanOptions) SetMaxWorkers(v int) *ScanOptions {
+	if v <= 0 {
+		so.maxworkers.Store(DEFAULT_MAX_WORKERS)
+		return so
+	}
+	so.maxworkers.Store(uint32(v))
+	return so
+}
+
+// SetAutoscale lets the number of workers vary between `min` and `max` instead
+// of the fixed `SetMaxWorkers` value. Workers are added during a scan cycle when
+// the paths to check pile up and the pool of the next cycle grows when a cycle
+// lasts longer than the scan interval or shrinks when workers were mostly idle.
+// A zero or negative `max` disables it. A zero or negative `min` defaults to 1.
+func (so *ScanOptions) SetAutoscale(min, max int) *ScanOptions {
+	if max <= 0 {
+		so.scale.Store((*autoscale)(nil))
+		return so
+	}
+	if min <= 0 {
+		min = 1
 	}
+	if min > max {
+		min = max
+	}
+	so.scale.Store(&autoscale{min: uint32(min), max: uint32(max)})
+	return so
+}
 
-	return &Options{excludePaths: eregex, includePaths: iregex}
+// SetMaxTrackedPaths limits the number of paths kept into the cache so a
+// directory which suddenly holds a huge number of items could not exhaust
+// the memory. The `policy` defines how the new paths are handled once the
+// limit is reached. A zero or negative `n` removes the limit (default).
+func (so *ScanOptions) SetMaxTrackedPaths(n int, policy LimitPolicy) *ScanOptions {
+	if n < 0 {
+		n = 0
+	}
+	so.maxTracked.Store(int64(n))
+	so.limitPolicy.Store(uint32(policy))
+	return so
 }
 
-func (o *Options) SetQueueSize(v int) *Options {
-	o.queueSize = v
-	return o
+// SetScanThrottle paces the scans so they run at most `opsPerSecond` file
+// system operations, which avoids to hurt the other users of shared volumes
+// such as NAS. Each visited item counts as one operation and directories
+// count one more for their listing. A zero or negative value removes the
+// limit (default).
+func (so *ScanOptions) SetScanThrottle(opsPerSecond int) *ScanOptions {
+	if opsPerSecond < 0 {
+		opsPerSecond = 0
+	}
+	so.throttle.Store(int64(opsPerSecond))
+	return so
+}
+
+// SetLoadThreshold pauses the scans while the system load average over the
+// last minute is above `load`. The load is only known on linux so it has no
+// effect on other platforms. A zero or negative value disables it (default).
+func (so *ScanOptions) SetLoadThreshold(load float64) *ScanOptions {
+	if load < 0 {
+		load = 0
+	}
+	so.maxLoad.Store(math.Float64bits(load))
+	return so
+}
+
+// SetMemoryBudget bounds the estimated memory used by the cache of the
+// tracked paths. Once exceeded, a `BUDGET_EXCEEDED` event which wraps
+// `ErrMemoryBudget` is emitted, new paths are no longer tracked and the
+// content of the deepest directories is forgotten and no longer scanned,
+// one level per scan cycle until it fits. Changes into the collapsed
+// subtrees are then only seen through their top directory. A zero or
+// negative value removes the budget (default).
+func (so *ScanOptions) SetMemoryBudget(bytes int64) *ScanOptions {
+	if bytes < 0 {
+		bytes = 0
+	}
+	so.memBudget.Store(bytes)
+	return so
+}
+
+// SetBackend defines the file system to scan. It must be set before
+// creating the scan notifier. Default to the local file system.
+func (so *ScanOptions) SetBackend(b Backend) *ScanOptions {
+	so.backend = b
+	return so
+}
+
+// SetConfigFile defines a YAML or JSON config file, such as the one passed to
+// `OptionsFromFile`, which is monitored by the scan notifier once started.
+// On each change, its settings are applied at once, such as the interval, the
+// workers, the filters and the ignore flags, then a `CONFIG_RELOADED` event is
+// emitted. The settings which only apply at creation, such as the queue size,
+// are ignored. If the file is invalid, the settings are left untouched and an
+// `ERROR` event which wraps `ErrInvalidConfig` is emitted. It must be set
+// before starting the scan notifier, which fails with an error which wraps
+// `ErrInvalidConfig` if the file could not be monitored.
+func (so *ScanOptions) SetConfigFile(name string) *ScanOptions {
+	so.configFile = name
+	return so
+}
+
+// SetClock defines the source of time used to schedule the scan cycles and
+// to stamp the events history, debouncing and acknowledgements. It allows
+// tests and simulations to drive the scan cycles deterministically. It must
+// be set before creating the scan notifier. Default to the system clock.
+func (so *ScanOptions) SetClock(c Clock) *ScanOptions {
+	so.clock = c
+	return so
+}
+
+// SetChecksum enables the comparison of regular files content digest so a
+// change is detected even if the modification time was preserved.
+func (so *ScanOptions) SetChecksum(v bool) *ScanOptions {
+	so.checksum.Store(v)
+	return so
+}
+
+// SetComparator defines the strategy which tells whether the content of a
+// known item changed, trading accuracy for speed. `COMPARE_CHECKSUM` also
+// enables the checksum option. A nil value falls back to the default
+// `COMPARE_MTIME_SIZE`.
+func (so *ScanOptions) SetComparator(c Comparator) *ScanOptions {
+	if c == nil {
+		c = COMPARE_MTIME_SIZE
+	}
+	if c == COMPARE_CHECKSUM {
+		so.checksum.Store(true)
+	}
+	so.compare.Store(c)
+	return so
+}
+
+// SetHasher defines the hash function used to compute the content digest of
+// regular files when checksum is enabled. A nil value falls back to SHA-256.
+// It should be set before creating the scan notifier since digests built with
+// different functions are not comparable.
+func (so *ScanOptions) SetHasher(fn func() hash.Hash) *ScanOptions {
+	so.hasher.Store(fn)
+	return so
 }
 
-func (o *Options) SetMaxWorkers(v int) *Options {
+// SetHashName defines the name of the hash function set by `SetHasher`, such as
+// "blake2b-256", reported into the `ChecksumAlgorithm` field of the events.
+// It defaults to "sha256" if no hash function is set, to empty otherwise.
+func (so *ScanOptions) SetHashName(name string) *ScanOptions {
+	so.hashName.Store(name)
+	return so
+}
+
+// SetHashWorkers defines the number of goroutines which compute the content
+// digests, apart from the workers building the events so large files do not
+// delay the changes detection of the other items. A zero or negative value
+// falls back to `DEFAULT_HASH_WORKERS`.
+func (so *ScanOptions) SetHashWorkers(v int) *ScanOptions {
 	if v <= 0 {
-		o.maxworkers.Store(DEFAULT_MAX_WORKERS)
-		return o
+		so.hashWorkers.Store(DEFAULT_HASH_WORKERS)
+		return so
 	}
-	o.maxworkers.Store(uint32(v))
-	return o
+	so.hashWorkers.Store(uint32(v))
+	return so
+}
+
+// SetHotPaths defines the glob patterns, relative to the root directory and
+// using the `.gitignore` syntax, of the paths to scan every `interval` between
+// two normal scan cycles, so changes under these paths are detected sooner.
+// Hot paths must already be known from a previous scan cycle, new ones are
+// picked up by the next normal scan cycle. Hooks and observers are not called
+// for the scans of the hot paths. An empty list or an interval not shorter
+// than the scan interval disables it. It returns an error which wraps
+// `ErrInvalidOptions` if a glob is malformed, the settings are then unchanged.
+func (so *ScanOptions) SetHotPaths(globs []string, interval time.Duration) error {
+	hp := &hotPaths{interval: interval}
+	for _, g := range globs {
+		g = strings.Trim(g, "/")
+		if g == "" {
+			continue
+		}
+		re, err := regexp.Compile(globToRegexp(g))
+		if err != nil {
+			return fmt.Errorf("%w: hot path glob %q: %v", ErrInvalidOptions, g, err)
+		}
+		hp.patterns = append(hp.patterns, re)
+	}
+	so.hot.Store(hp)
+	return nil
+}
+
+// SetBeforeScan registers a callback invoked at the beginning of each scan cycle.
+func (so *ScanOptions) SetBeforeScan(fn func(cycle int)) *ScanOptions {
+	so.beforeScan.Store(fn)
+	return so
+}
+
+// SetAfterScan registers a callback invoked at the end of each scan cycle
+// with the statistics of that cycle. It is not invoked for a cycle abandoned
+// by `PauseImmediately`.
+func (so *ScanOptions) SetAfterScan(fn func(cycle int, summary ScanSummary)) *ScanOptions {
+	so.afterScan.Store(fn)
+	return so
+}
+
+// SetProgress registers a callback invoked from the scanner routine each time
+// `every` more paths were visited during a scan cycle. It is meant to report
+// the advancement of long scans of huge trees. A nil callback or a zero or
+// negative `every` removes it. See also `ScanNotifier.Progress`.
+func (so *ScanOptions) SetProgress(every int, fn func(Progress)) *ScanOptions {
+	if fn == nil || every <= 0 {
+		so.progress.Store((*progressReport)(nil))
+		return so
+	}
+	so.progress.Store(&progressReport{every: every, fn: fn})
+	return so
+}
+
+// SetTrace enables the diagnostics mode which records each step of the scan
+// cycles (paths visited or skipped, missing paths, emitted events and timings)
+// as JSON lines into `w`. Such a trace could be attached to a bug report about
+// missed or spurious events. It is safe to enable it only for a few cycles via
+// the scan hooks. A nil value disables the trace.
+func (so *ScanOptions) SetTrace(w io.Writer) *ScanOptions {
+	var t *tracer
+	if w != nil {
+		t = newTracer(w)
+	}
+	so.trace.Store(t)
+	return so
+}
+
+// SetExcludeRegex defines the pattern of the paths to skip. A nil or empty
+// pattern disables the exclusion. It could be changed while running.
+func (fo *FilterOptions) SetExcludeRegex(re *regexp.Regexp) *FilterOptions {
+	if re != nil && re.String() == "" {
+		re = nil
+	}
+	fo.excludePaths.Store(re)
+	return fo
+}
+
+// SetIncludeRegex defines the pattern of the only paths to monitor. A nil or
+// empty pattern disables the inclusion. It could be changed while running.
+func (fo *FilterOptions) SetIncludeRegex(re *regexp.Regexp) *FilterOptions {
+	if re != nil && re.String() == "" {
+		re = nil
+	}
+	fo.includePaths.Store(re)
+	return fo
+}
+
+// excludeRegex returns the current pattern of the paths to skip if any.
+func (fo *FilterOptions) excludeRegex() *regexp.Regexp {
+	re, _ := fo.excludePaths.Load().(*regexp.Regexp)
+	return re
+}
+
+// includeRegex returns the current pattern of the only paths to monitor if any.
+func (fo *FilterOptions) includeRegex() *regexp.Regexp {
+	re, _ := fo.includePaths.Load().(*regexp.Regexp)
+	return re
+}
+
+// SetIgnoreFiles defines whether regular files should be skipped.
+func (fo *FilterOptions) SetIgnoreFiles(v bool) *FilterOptions {
+	fo.ignoreFile.Store(v)
+	return fo
+}
+
+// SetIgnoreFolders defines whether directories should be skipped. Their
+// content is still monitored unless `SetIgnoreFolderContent` is enabled.
+func (fo *FilterOptions) SetIgnoreFolders(v bool) *FilterOptions {
+	fo.ignoreFolder.Store(v)
+	return fo
+}
+
+// SetIgnoreSymlinks defines whether symbolic links should be skipped.
+func (fo *FilterOptions) SetIgnoreSymlinks(v bool) *FilterOptions {
+	fo.ignoreSymlink.Store(v)
+	return fo
+}
+
+// SetIgnoreFifos defines whether named pipes should be skipped.
+func (fo *FilterOptions) SetIgnoreFifos(v bool) *FilterOptions {
+	fo.ignoreFifo.Store(v)
+	return fo
+}
+
+// SetIgnoreSockets defines whether unix domain sockets should be skipped.
+func (fo *FilterOptions) SetIgnoreSockets(v bool) *FilterOptions {
+	fo.ignoreSocket.Store(v)
+	return fo
+}
+
+// SetIgnoreDevices defines whether block and character devices should be skipped.
+func (fo *FilterOptions) SetIgnoreDevices(v bool) *FilterOptions {
+	fo.ignoreDevice.Store(v)
+	return fo
+}
+
+// SetIgnoreFolderContent defines whether the sub-directories and
+// all their content should be skipped.
+func (fo *FilterOptions) SetIgnoreFolderContent(v bool) *FilterOptions {
+	fo.ignoreFolderContent.Store(v)
+	return fo
+}
+
+// SetRecursive defines whether the content of the sub-directories is monitored.
+// If disabled, only the immediate children of the root directory are tracked,
+// including the sub-directories themselves. Default to true.
+func (fo *FilterOptions) SetRecursive(v bool) *FilterOptions {
+	fo.topLevelOnly.Store(!v)
+	return fo
+}
+
+// SetGitignore enables the skipping of the `.git` directory and the paths
+// matching the patterns of the root `.gitignore` file. This file is reloaded
+// at the beginning of each scan cycle.
+func (fo *FilterOptions) SetGitignore(v bool) *FilterOptions {
+	fo.gitignore.Store(v)
+	return fo
+}
+
+// SetIgnoreEditorTemp enables the skipping of temporary files created by
+// common editors while saving such as vim swap or emacs lock files.
+func (fo *FilterOptions) SetIgnoreEditorTemp(v bool) *FilterOptions {
+	fo.ignoreEditorTemp.Store(v)
+	return fo
+}
+
+// SetIgnoreArtifacts enables the skipping of the files left by operating
+// systems and applications, such as `.DS_Store`, `Thumbs.db`, office lock files,
+// `*.tmp` files and partial downloads like `*.crdownload` or `*.part`, and of
+// the directories such as `.Trashes` or `$RECYCLE.BIN`.
+func (fo *FilterOptions) SetIgnoreArtifacts(v bool) *FilterOptions {
+	fo.ignoreArtifacts.Store(v)
+	return fo
+}
+
+// SetContentTypes defines the MIME types of the regular files whose `CREATE`
+// and `MODIFY` events are emitted, such as "application/pdf", or "image/" to
+// match all the images. Their content type is sniffed like with
+// `SetDetectContentType` and files which could not be read are skipped. No
+// types disable the filtering which is the default.
+func (fo *FilterOptions) SetContentTypes(types ...string) *FilterOptions {
+	fo.ctypes.Store(append([]string(nil), types...))
+	return fo
+}
+
+// contentTypes returns the allowed MIME types, none if not filtered.
+func (fo *FilterOptions) contentTypes() []string {
+	types, _ := fo.ctypes.Load().([]string)
+	return types
 }
 
-func (o *Options) SetScanInterval(v time.Duration) *Options {
+// SetOwnerFilter defines the user IDs owning the items to monitor, such as the
+// producers of a shared drop directory. The items of other users are skipped
+// except the directories, so their content is still monitored. An item whose
+// owner changes to another user is then reported as deleted. It has no effect
+// on the platforms which do not report the ownership. No IDs disable the
+// filtering which is the default.
+func (fo *FilterOptions) SetOwnerFilter(uids []int) *FilterOptions {
+	fo.uids.Store(append([]int(nil), uids...))
+	return fo
+}
+
+// ownerFilter returns the allowed user IDs, none if not filtered.
+func (fo *FilterOptions) ownerFilter() []int {
+	uids, _ := fo.uids.Load().([]int)
+	return uids
+}
+
+// SetGroupFilter defines the group IDs owning the items to monitor, the same
+// way as `SetOwnerFilter` does for the users. Both could be combined.
+func (fo *FilterOptions) SetGroupFilter(gids []int) *FilterOptions {
+	fo.gids.Store(append([]int(nil), gids...))
+	return fo
+}
+
+// groupFilter returns the allowed group IDs, none if not filtered.
+func (fo *FilterOptions) groupFilter() []int {
+	gids, _ := fo.gids.Load().([]int)
+	return gids
+}
+
+// SetIgnoreErrors defines whether `ERROR` events should not be emitted.
+func (eo *EventOptions) SetIgnoreErrors(v bool) *EventOptions {
+	eo.ignoreErrors.Store(v)
+	return eo
+}
+
+// SetIgnoreNoChange defines whether `NOCHANGE` events should not be emitted.
+func (eo *EventOptions) SetIgnoreNoChange(v bool) *EventOptions {
+	eo.ignoreNoChange.Store(v)
+	return eo
+}
+
+// SetIgnoreDelete defines whether `DELETE` events should not be emitted.
+func (eo *EventOptions) SetIgnoreDelete(v bool) *EventOptions {
+	eo.ignoreDelete.Store(v)
+	return eo
+}
+
+// SetIgnoreCreate defines whether `CREATE` events should not be emitted.
+func (eo *EventOptions) SetIgnoreCreate(v bool) *EventOptions {
+	eo.ignoreCreate.Store(v)
+	return eo
+}
+
+// SetIgnoreModify defines whether `MODIFY` events should not be emitted.
+func (eo *EventOptions) SetIgnoreModify(v bool) *EventOptions {
+	eo.ignoreModify.Store(v)
+	return eo
+}
+
+// SetIgnorePerm defines whether `PERM` events should not be emitted.
+func (eo *EventOptions) SetIgnorePerm(v bool) *EventOptions {
+	eo.ignorePerm.Store(v)
+	return eo
+}
+
+// SetIgnoreAttrib defines whether `ATTRIB` events should not be emitted.
+// These events report the change of a windows file attributes among the
+// read-only, hidden, system and archive ones.
+func (eo *EventOptions) SetIgnoreAttrib(v bool) *EventOptions {
+	eo.ignoreAttrib.Store(v)
+	return eo
+}
+
+// SetTrackOwnership enables the emission of `OWNER` event when the
+// owner user or group of an item changes. Only on unix platforms.
+func (eo *EventOptions) SetTrackOwnership(v bool) *EventOptions {
+	eo.trackOwner.Store(v)
+	return eo
+}
+
+// SetTrackRenames enables the emission of a single `RENAME` event instead
+// of a `DELETE` and a `CREATE` events when an item is moved within the root
+// directory. It relies on the files identity so only on unix platforms.
+func (eo *EventOptions) SetTrackRenames(v bool) *EventOptions {
+	eo.trackRenames.Store(v)
+	return eo
+}
+
+// SetTrackLinks enables the emission of `LINK` and `UNLINK` events when a
+// hard link to a monitored file is created or removed. A new path to a known
+// file is reported as `LINK` instead of `CREATE` and a removed path to a file
+// still reachable via another path is reported as `UNLINK` instead of `DELETE`,
+// with another path of the file as `OldPath`. A change of the number of links
+// of a file made outside of the root directory is reported on its own paths at
+// the end of the scan cycle. It relies on the files identity so only on unix
+// platforms.
+func (eo *EventOptions) SetTrackLinks(v bool) *EventOptions {
+	eo.trackLinks.Store(v)
+	return eo
+}
+
+// SetSettleCycles enables the emission of a `STABLE` event once a created or
+// modified regular file kept the same size and modification time during `n`
+// consecutive scans. This allows to process files such as uploads only once
+// fully written. A zero value disables these events.
+func (eo *EventOptions) SetSettleCycles(n int) *EventOptions {
+	if n < 0 {
+		n = 0
+	}
+	eo.settleCycles.Store(uint32(n))
+	return eo
+}
+
+// SetDeleteGrace defines the number of consecutive scans during which a path
+// must be missing before its `DELETE` event is emitted. This avoids reporting
+// files which are briefly removed then recreated such as during atomic swaps
+// or logs rotation. If the path reappears as another file, a `REPLACE` event
+// is emitted instead, otherwise the usual `MODIFY` event if it was changed.
+// Like `MODIFY`, the `REPLACE` event is filtered by `SetIgnoreModify`.
+// A zero value reports deletions at the first scan they are noticed.
+func (eo *EventOptions) SetDeleteGrace(n int) *EventOptions {
+	if n < 0 {
+		n = 0
+	}
+	eo.deleteGrace.Store(uint32(n))
+	return eo
+}
+
+// SetVanished defines how a path which disappeared between its listing and
+// its check is reported. Default to `VANISHED_ERROR`.
+func (eo *EventOptions) SetVanished(m VanishedMode) *EventOptions {
+	eo.vanished.Store(uint32(m))
+	return eo
+}
+
+// SetSizeThreshold supervises the cumulative size of the regular files under
+// the directory `dir`, relative to the root directory and slash-separated
+// ("." for the root itself), and emits a `SIZE_THRESHOLD` event at the end of
+// the scan cycle which found it over `bytes`. A zero or negative `bytes` stops
+// the supervision of that directory.
+func (eo *EventOptions) SetSizeThreshold(dir string, bytes int64) *EventOptions {
+	dir = path.Clean(strings.Trim(filepath.ToSlash(dir), "/"))
+	eo.mu.Lock()
+	defer eo.mu.Unlock()
+	limits := make(map[string]int64)
+	for d, n := range eo.sizeThresholds() {
+		limits[d] = n
+	}
+	if bytes > 0 {
+		limits[dir] = bytes
+	} else {
+		delete(limits, dir)
+	}
+	eo.sizeLimits.Store(limits)
+	return eo
+}
+
+// SetDetectAtomicSaves defines whether the saves made by writing a temporary
+// file then renaming it over the real file are reported as a single `MODIFY`
+// event of the real file. If enabled, the temporary files of editors and the
+// `.tmp` files are ignored, a regular file is reported as deleted only once
+// missing for two scan cycles and a file replaced meanwhile is reported as
+// modified instead of replaced. Default to false.
+func (eo *EventOptions) SetDetectAtomicSaves(v bool) *EventOptions {
+	eo.atomicSaves.Store(v)
+	return eo
+}
+
+// SetScanSummary defines whether a `SCAN_SUMMARY` event which carries the
+// statistics of the cycle into its `Summary` field is emitted at the end of
+// each scan cycle. Default to false.
+func (eo *EventOptions) SetScanSummary(v bool) *EventOptions {
+	eo.scanSummary.Store(v)
+	return eo
+}
+
+// SetDetectLocked defines whether a file which could not be read because it is
+// locked or busy by another process, such as a file opened without sharing on
+// Windows, is reported by a single `LOCKED` event which wraps `ErrFileLocked`
+// instead of an `ERROR` event each scan cycle. A new `LOCKED` event is emitted
+// if it gets locked again once accessible. Default to false.
+func (eo *EventOptions) SetDetectLocked(v bool) *EventOptions {
+	eo.detectLocked.Store(v)
+	return eo
+}
+
+// SetPathForm defines the form of the paths emitted into the events on Windows,
+// e.g. `PATH_FORM_DOS` to report `C:\dir\file` even if the root path uses the
+// `\\?\` extended-length prefix. Default to `PATH_FORM_NATIVE` which keeps
+// the form of the root path. It has no effect on other platforms.
+func (eo *EventOptions) SetPathForm(f PathForm) *EventOptions {
+	eo.pathForm.Store(uint32(f))
+	return eo
+}
+
+// SetRelativePaths defines whether the paths of the events are relative to the
+// root path, e.g. `dir/file` instead of `/root/dir/file`. The root path itself
+// is reported as ".". The root path is always available into the `Root` field
+// of the events. Default to false.
+func (eo *EventOptions) SetRelativePaths(v bool) *EventOptions {
+	eo.relativePaths.Store(v)
+	return eo
+}
+
+// SetIncludeChecksum defines whether the `CREATE`, `MODIFY`, `REPLACE` and
+// `TOUCH` events of the regular files carry their content digest and the name
+// of the hash function into the `Checksum` and `ChecksumAlgorithm` fields when
+// checksum is enabled, so consumers do not need to read and hash the files
+// again. These events are then emitted by the hashing workers once the digest
+// is computed. Default to false.
+func (eo *EventOptions) SetIncludeChecksum(v bool) *EventOptions {
+	eo.includeChecksum.Store(v)
+	return eo
+}
+
+// SetDetectContentType defines whether the `CREATE` and `MODIFY` events of the
+// regular files carry their MIME type, sniffed from their first 512 bytes with
+// `http.DetectContentType`, into the `ContentType` field. Default to false.
+func (eo *EventOptions) SetDetectContentType(v bool) *EventOptions {
+	eo.detectContentType.Store(v)
+	return eo
+}
+
+// SetDedupWindow defines the period during which the `MODIFY` events of a path
+// following an emitted one are suppressed, e.g. for a file whose modification
+// time ticks each scan cycle because of an external process. The number of the
+// suppressed events is reported into the `Suppressed` field of the next event
+// emitted for the path. A zero or negative value disables it which is the
+// default.
+func (eo *EventOptions) SetDedupWindow(d time.Duration) *EventOptions {
+	eo.dedupWindow.Store(d)
+	return eo
+}
+
+// SetRootLabel defines the label of the root path set into the `RootLabel` field
+// of the events, e.g. a tenant name, so that the events of many scan notifiers
+// could be routed without parsing their paths. Default to empty, or to the
+// label of the watch for the scan notifiers of a `Manager`.
+func (eo *EventOptions) SetRootLabel(label string) *EventOptions {
+	eo.label.Store(label)
+	return eo
+}
+
+// rootLabel returns the label of the root path, empty if none.
+func (eo *EventOptions) rootLabel() string {
+	label, _ := eo.label.Load().(string)
+	return label
+}
+
+// SetPathNormalizer defines the Unicode normalization of the paths emitted into
+// the events and matched by the include and exclude regexes and the filtering
+// expression, so the same filename is seen identically whether it is stored
+// decomposed (NFD, e.g. on macOS) or composed (NFC, e.g. on Linux). This
+// package does not embed the Unicode tables so the normalization form is
+// chosen by passing e.g. `norm.NFC.String` of `golang.org/x/text/unicode/norm`.
+// The items are still accessed through their stored names. Default to nil
+// which keeps the paths as stored.
+func (eo *EventOptions) SetPathNormalizer(fn func(string) string) *EventOptions {
+	eo.normalizer.Store(fn)
+	return eo
+}
+
+// pathNormalizer returns the Unicode normalization of the paths, nil if none.
+func (eo *EventOptions) pathNormalizer() func(string) string {
+	fn, _ := eo.normalizer.Load().(func(string) string)
+	return fn
+}
+
+// SetSeverityFunc defines the classification of the events into their
+// `Severity` field. A nil function falls back to `DefaultSeverity`.
+func (eo *EventOptions) SetSeverityFunc(fn func(Event) Severity) *EventOptions {
+	eo.classify.Store(fn)
+	return eo
+}
+
+// SetDetectRotation defines whether a regular file replaced under the same name
+// by another file at least half its size, such as a log file rotated by
+// logrotate, is reported by a `ROTATE` event instead of a `MODIFY` or `REPLACE`
+// event, so the consumers tailing it know to read it again from the beginning.
+// Like `MODIFY`, the `ROTATE` event is filtered by `SetIgnoreModify`. It relies
+// on the files identity so only on unix platforms. Default to false.
+func (eo *EventOptions) SetDetectRotation(v bool) *EventOptions {
+	eo.detectRotation.Store(v)
+	return eo
+}
+
+// SetDetectTouch defines whether a regular file whose modification time changed
+// while its size did not is reported by a `TOUCH` event instead of a `MODIFY`
+// event, so consumers could skip the processing of unchanged files. If checksum
+// is enabled, a `MODIFY` event is still emitted if the content digest changed.
+// Otherwise the size is the only hint, so a rewrite with a content of the same
+// size is reported as a touch. Default to false.
+func (eo *EventOptions) SetDetectTouch(v bool) *EventOptions {
+	eo.detectTouch.Store(v)
+	return eo
+}
+
+// SetCollapseDeletes defines whether a deleted directory is reported by a
+// single `DELETE` event whose `ChildrenCount` is the number of its deleted
+// sub-items, instead of a `DELETE` event for each of them. Default to false.
+func (eo *EventOptions) SetCollapseDeletes(v bool) *EventOptions {
+	eo.collapseDeletes.Store(v)
+	return eo
+}
+
+// SetStopOnRootLost defines whether the scan notifier stops once the root path
+// could not be found anymore (e.g. deleted or unmounted). Otherwise it keeps
+// polling and emits a `ROOT_RESTORED` event followed by a `CREATE` event for
+// each item once the root path is available again. In both cases a `ROOT_LOST`
+// event is emitted instead of a `DELETE` event for each item. Default to false.
+func (eo *EventOptions) SetStopOnRootLost(v bool) *EventOptions {
+	eo.stopOnRootLost.Store(v)
+	return eo
+}
+
+// SetAnomalyDetection enables the emission of `ANOMALY` event when the number of
+// events of a cycle is `sensitivity` times above the mean of the latest `window`
+// cycles, or when no events are emitted while each of these cycles emitted some.
+// A zero `window` disables the detection. A `sensitivity` lower or equal to one
+// falls back to `DEFAULT_ANOMALY_SENSITIVITY`.
+func (eo *EventOptions) SetAnomalyDetection(window int, sensitivity float64) *EventOptions {
+	if window < 0 {
+		window = 0
+	}
+	if sensitivity <= 1 {
+		sensitivity = DEFAULT_ANOMALY_SENSITIVITY
+	}
+	eo.anomalySensitivity.Store(sensitivity)
+	eo.anomalyWindow.Store(uint32(window))
+	return eo
+}
+
+// SetQueueSize defines the capacity of the events queue. It must be set
+// before creating the scan notifier. A zero or negative value falls back
+// to `DEFAULT_QUEUE_SIZE`.
+func (do *DeliveryOptions) SetQueueSize(v int) *DeliveryOptions {
+	do.queueSize.Store(int64(v))
+	return do
+}
+
+// SetDebounce delays the path changes events until their path did not change
+// for the duration `v`. Successive events of a same path are coalesced into a
+// single one. A zero or negative duration disables the debouncing.
+func (do *DeliveryOptions) SetDebounce(v time.Duration) *DeliveryOptions {
 	if v < 0 {
-		o.scanInterval.Store(DEFAULT_SCAN_INTERVAL)
-		return o
+		v = 0
 	}
-	o.scanInterval.Store(v)
-	return o
+	do.debounce.Store(v)
+	return do
 }
 
-func (o *Options) SetIgnoreErrors(v bool) *Options {
-	o.event.ignoreErrors.Store(v)
-	return o
+// SetDeterministicOrder enables the sorting of the events produced during a
+// scan cycle by path, then by kind of event, before they are sent to the queue.
+// This makes the events order reproducible (e.g. for golden files tests) at
+// the cost of delivering them only once the cycle completed. The events held by
+// a cycle interrupted by Stop are sent on stop as long as the queue has room.
+func (do *DeliveryOptions) SetDeterministicOrder(v bool) *DeliveryOptions {
+	do.ordered.Store(v)
+	return do
 }
 
-func (o *Options) SetIgnoreNoChangeEvent(v bool) *Options {
-	o.event.ignoreNoChange.Store(v)
-	return o
+// SetAckTimeout enables the at-least-once delivery mode. Each event received
+// from the queue must then be acknowledged with `Event.Ack`, otherwise it is
+// sent again to the queue (but not to the sinks) once `v` elapsed, then after
+// a delay doubled on each attempt up to 64 times `v`. Redeliveries follow the
+// overflow policy and wait for room into the queue. At most `DEFAULT_MAX_UNACKED`
+// events wait for acknowledgement, the oldest ones are abandoned beyond. Events
+// not acknowledged when the scan notifier stops are dropped. A zero or negative
+// duration disables the mode.
+func (do *DeliveryOptions) SetAckTimeout(v time.Duration) *DeliveryOptions {
+	if v < 0 {
+		v = 0
+	}
+	do.ackTimeout.Store(v)
+	return do
 }
 
-func (o *Options) SetIgnoreDeleteEvent(v bool) *Options {
-	o.event.ignoreDelete.Store(v)
-	return o
+// SetOverflowPolicy defines how the events are delivered once a queue is full.
+// Default to `OVERFLOW_BLOCK`.
+func (do *DeliveryOptions) SetOverflowPolicy(p OverflowPolicy) *DeliveryOptions {
+	do.overflow.Store(uint32(p))
+	return do
 }
 
-func (o *Options) SetIgnoreCreateEvent(v bool) *Options {
-	o.event.ignoreCreate.Store(v)
-	return o
+// SetMaxEventsPerSecond limits the number of events delivered per second to the
+// sinks and the queues, with bursts of up to `n` events, so a bulk operation such
+// as the extraction of a large archive does not overwhelm the consumers. Over
+// the limit, the delivery waits like for a full queue or, under the
+// `OVERFLOW_DROP` policy, the events are dropped and reported by an `OVERFLOW`
+// event. They are counted into `Stats().RateLimited`. A zero or negative value
+// disables the limit which is the default.
+func (do *DeliveryOptions) SetMaxEventsPerSecond(n int) *DeliveryOptions {
+	if n < 0 {
+		n = 0
+	}
+	do.maxRate.Store(int64(n))
+	return do
 }
 
-func (o *Options) SetIgnoreModifyEvent(v bool) *Options {
-	o.event.ignoreModify.Store(v)
-	return o
+// SetSubscriptionsOnly sends the events to the queues returned by `QueueFor`
+// only, so consumers of a few kinds of events do not need to drain the main
+// queue which then receives nothing. Default to false.
+func (do *DeliveryOptions) SetSubscriptionsOnly(v bool) *DeliveryOptions {
+	do.subsOnly.Store(v)
+	return do
 }
 
-func (o *Options) SetIgnorePermEvent(v bool) *Options {
-	o.event.ignorePerm.Store(v)
-	return o
+// SetHistorySize defines the number of latest delivered events kept into
+// memory to be queried with `History`. A zero or negative value disables
+// the history.
+func (do *DeliveryOptions) SetHistorySize(v int) *DeliveryOptions {
+	if v < 0 {
+		v = 0
+	}
+	do.history.Store(uint32(v))
+	return do
 }
 
-func (o *Options) SetIgnoreFileEvent(v bool) *Options {
-	o.event.ignoreFile.Store(v)
-	return o
+// AddSink registers a sink to receive a copy of each emitted event.
+// It could be called at anytime, even once the scan notifier started.
+func (do *DeliveryOptions) AddSink(s Sink) *DeliveryOptions {
+	if s == nil {
+		return do
+	}
+	do.mu.Lock()
+	sinks := append(append([]Sink{}, do.sinks()...), s)
+	do.esinks.Store(sinks)
+	do.mu.Unlock()
+	return do
 }
 
-func (o *Options) SetIgnoreFolderEvent(v bool) *Options {
-	o.event.ignoreFolder.Store(v)
-	return o
+// AddObserver registers a read-only observer of the lifecycle events.
+// It could be called at anytime, even once the scan notifier started.
+func (do *DeliveryOptions) AddObserver(ob LifecycleObserver) *DeliveryOptions {
+	if ob == nil {
+		return do
+	}
+	do.mu.Lock()
+	obs := append(append([]LifecycleObserver{}, do.observers()...), ob)
+	do.lobservers.Store(obs)
+	do.mu.Unlock()
+	return do
 }
 
-func (o *Options) SetIgnoreSymlink(v bool) *Options {
-	o.event.ignoreSymlink.Store(v)
-	return o
+// OnStart registers a callback invoked once the scan notifier has started.
+func (do *DeliveryOptions) OnStart(fn func()) *DeliveryOptions {
+	return do.addHook(lifecycleHook{start: fn})
 }
 
-func (o *Options) SetIgnoreFolderContentEvent(v bool) *Options {
-	o.event.ignoreFolderContent.Store(v)
-	return o
+// OnStop registers a callback invoked once the scan notifier has fully stopped.
+func (do *DeliveryOptions) OnStop(fn func()) *DeliveryOptions {
+	return do.addHook(lifecycleHook{stop: fn})
+}
+
+// OnPause registers a callback invoked once the scan notifier is paused and idle.
+func (do *DeliveryOptions) OnPause(fn func()) *DeliveryOptions {
+	return do.addHook(lifecycleHook{pause: fn})
+}
+
+// OnResume registers a callback invoked once the scan notifier resumed the scanning.
+func (do *DeliveryOptions) OnResume(fn func()) *DeliveryOptions {
+	return do.addHook(lifecycleHook{resume: fn})
+}
+
+// addHook registers the callback as a lifecycle observer unless nil.
+func (do *DeliveryOptions) addHook(h lifecycleHook) *DeliveryOptions {
+	if h.start == nil && h.stop == nil && h.pause == nil && h.resume == nil {
+		return do
+	}
+	return do.AddObserver(h)
+}
+
+// SetState defines the exported state of another instance to use as the
+// initial cache history. It must be set before creating the scan notifier.
+// See `NewFromState` for more details.
+func (po *PersistenceOptions) SetState(st *State) *PersistenceOptions {
+	po.state = st
+	return po
+}
+
+// SetStateStore defines where the states of the items are saved at the end of
+// each scan cycle. If no state was set, the scan notifier is seeded from the
+// store when created so the changes made meanwhile are reported on the first
+// scan. Like the initial state, it should be set before creating the scan
+// notifier. The states are still cached into memory unless limited by
+// `SetMaxCachedPaths`.
+func (po *PersistenceOptions) SetStateStore(store StateStore) *PersistenceOptions {
+	po.store = store
+	return po
+}
+
+// SetMaxCachedPaths keeps at most about `n` items into memory so trees too
+// large for it could be monitored. It requires a state store which implements
+// `StateLookup`, such as the one of the `boltstore` package. The items beyond
+// the limit, the least recently seen first, are evicted at the end of each
+// scan cycle once saved, then looked up into the store when visited. Only a
+// hash of their paths is kept during a scan cycle to find the deleted ones.
+// The items changed, being hashed or in their delete grace period stay into
+// memory until the next cycle. The features working on the cached items, such
+// as the hot paths, the memory budget and the native notifications of a new
+// item whose directory is not cached, only see these ones, the next scan cycle
+// catches up the rest. Like the store, it must be set before creating the scan
+// notifier. A zero or negative `n` caches all the items (default).
+func (po *PersistenceOptions) SetMaxCachedPaths(n int) *PersistenceOptions {
+	if n < 0 {
+		n = 0
+	}
+	po.maxCached = int64(n)
+	return po
 }
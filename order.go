@@ -0,0 +1,62 @@
+package gorsn
+
+import "sort"
+
+// eventRank defines the order of the events of a same path
+// when the deterministic order is enabled. It lists every known
+// event name.
+var eventRank = map[EventName]int{
+	CREATE:    0,
+	RENAME:    1,
+	LINK:      2,
+	REPLACE:   3,
+	ROTATE:    3,
+	MODIFY:    4,
+	TOUCH:     4,
+	PERM:      5,
+	OWNER:     6,
+	ATTRIB:    7,
+	STABLE:    8,
+	UNLINK:    9,
+	DELETE:    10,
+	TRANSIENT: 11,
+	ERROR:     12,
+	NOCHANGE:  13,
+	ANOMALY:   14,
+
+	SIZE_THRESHOLD: 15,
+	ROOT_LOST:      16,
+	ROOT_RESTORED:  17,
+
+	BUDGET_EXCEEDED: 18,
+	OVERFLOW:        19,
+	SCAN_SUMMARY:    20,
+	LOCKED:          21,
+	CONFIG_RELOADED: 22,
+}
+
+// hold keeps an event of the current scan cycle until its end.
+func (sn *snotifier) hold(ev Event) {
+	sn.omu.Lock()
+	sn.ordered = append(sn.ordered, ev)
+	sn.omu.Unlock()
+}
+
+// flushOrdered sorts the events held during the scan cycle by path
+// then by kind of event and sends them to the queue.
+func (sn *snotifier) flushOrdered() {
+	sn.ordering.Store(false)
+	sn.omu.Lock()
+	events := sn.ordered
+	sn.ordered = nil
+	sn.omu.Unlock()
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].Path != events[j].Path {
+			return events[i].Path < events[j].Path
+		}
+		return eventRank[events[i].Name] < eventRank[events[j].Name]
+	})
+	for _, ev := range events {
+		sn.deliver(ev)
+	}
+}
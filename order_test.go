@@ -0,0 +1,113 @@
+package gorsn
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFlushOrdered(t *testing.T) {
+	opts := defaultOpts()
+	opts.Delivery().SetDeterministicOrder(true)
+	sn, err := newSnotifier(t.TempDir(), opts, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sn.running.Store(true)
+	sn.ordering.Store(true)
+
+	held := []Event{
+		{Path: "b", Type: FILE, Name: DELETE},
+		{Path: "a", Type: FILE, Name: MODIFY},
+		{Path: "b", Type: FILE, Name: CREATE},
+		{Path: "a", Type: FILE, Name: ERROR},
+		{Path: "a", Type: FILE, Name: PERM},
+		{Path: "a", Type: FILE, Name: CREATE},
+	}
+	for _, ev := range held {
+		ev.Path = filepath.Join(sn.root, ev.Path)
+		if !sn.queueEvent(ev) {
+			t.Fatal("got the event rejected")
+		}
+	}
+	if got := pending(sn); len(got) != 0 {
+		t.Fatalf("got events %v before the end of the cycle, want them held", got)
+	}
+
+	sn.flushOrdered()
+	var got []string
+	for _, ev := range pending(sn) {
+		got = append(got, filepath.Base(ev.Path)+":"+string(ev.Name))
+	}
+	want := []string{"a:CREATE", "a:MODIFY", "a:PERM", "a:ERROR", "b:CREATE", "b:DELETE"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got events %q, want %q", got, want)
+	}
+	if sn.ordering.Load() {
+		t.Error("got events still held once flushed")
+	}
+}
+
+func TestFlushOrderedOnStop(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, "1", "b", "a")
+	if err := os.Mkdir(filepath.Join(dir, "zz"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	backend := &gatedBackend{
+		Backend: osBackend{},
+		dir:     filepath.Join(dir, "zz"),
+		read:    newGate(),
+		info:    newGate(),
+	}
+	opts := defaultOpts()
+	opts.Scan().SetInterval(5 * time.Millisecond).SetBackend(backend).
+		SetBeforeScan(func(cycle int) {
+			if cycle == 2 {
+				writeFiles(t, dir, "22", "b", "a")
+				backend.read.armed.Store(true)
+			}
+		})
+	opts.Delivery().SetDeterministicOrder(true)
+	sn, err := New(dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sn.StartAsync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// the walker is stuck on `zz` while the changes of `a` and `b` are held.
+	wait(t, backend.read.entered, "walker")
+	s := sn.(*snotifier)
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) {
+		s.omu.Lock()
+		n := len(s.ordered)
+		s.omu.Unlock()
+		if n == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("got %d events held, want 2", n)
+		}
+	}
+	if err := sn.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	close(backend.read.release)
+	if err := sn.Wait(); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for ev := range sn.Queue() {
+		got = append(got, filepath.Base(ev.Path)+":"+string(ev.Name))
+	}
+	want := []string{"a:MODIFY", "b:MODIFY"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got events %q on stop, want %q", got, want)
+	}
+}
@@ -0,0 +1,149 @@
+package gorsn
+
+import (
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// OverflowPolicy defines how the events are delivered once a queue is full.
+type OverflowPolicy uint32
+
+const (
+	// OVERFLOW_BLOCK waits for room into the queue, which slows down the
+	// scans until the consumer catches up (default).
+	OVERFLOW_BLOCK OverflowPolicy = iota
+	// OVERFLOW_DROP drops the events while the queue is full. Once there is
+	// room again, an `OVERFLOW` event which wraps `ErrQueueOverflow` reports
+	// the number of dropped events and the directories of their paths.
+	OVERFLOW_DROP
+)
+
+// maxOverflowPrefixes is the number of directories reported by an
+// `OVERFLOW` event beyond which only the root is reported.
+const maxOverflowPrefixes = 32
+
+// overflow accounts the events dropped from a queue since
+// its latest `OVERFLOW` event.
+type overflow struct {
+	mu       sync.Mutex
+	dropped  int
+	prefixes map[string]bool
+	all      bool // too many prefixes, only the root is reported.
+}
+
+// drop records the dropped event.
+func (of *overflow) drop(ev Event) {
+	of.dropped++
+	if of.all {
+		return
+	}
+	if len(of.prefixes) >= maxOverflowPrefixes {
+		of.all = true
+		of.prefixes = nil
+		return
+	}
+	if of.prefixes == nil {
+		of.prefixes = make(map[string]bool)
+	}
+	of.prefixes[filepath.Dir(ev.Path)] = true
+}
+
+// dropAll records the event dropped before its delivery into the overflow
+// of each queue which would have received it.
+func (sn *snotifier) dropAll(ev Event) {
+	if !sn.opts.delivery.subsOnly.Load() {
+		sn.overflow.mu.Lock()
+		sn.overflow.drop(ev)
+		sn.overflow.mu.Unlock()
+	}
+	for _, sub := range sn.subscriptions() {
+		if !sub.names[ev.Name] {
+			continue
+		}
+		sub.overflow.mu.Lock()
+		sub.overflow.drop(ev)
+		sub.overflow.mu.Unlock()
+	}
+}
+
+// report builds the `OVERFLOW` event of the dropped events.
+func (of *overflow) report(root string, pt PathType, now time.Time) Event {
+	prefixes := []string{root}
+	if !of.all {
+		prefixes = prefixes[:0]
+		for p := range of.prefixes {
+			prefixes = append(prefixes, p)
+		}
+		sort.Strings(prefixes)
+	}
+	return Event{Path: root, Type: pt, Name: OVERFLOW, Error: ErrQueueOverflow, Dropped: of.dropped, Prefixes: prefixes, Time: now}
+}
+
+// send sends the event to the queue according to the overflow policy. An
+// `OVERFLOW` event is sent first if some events were dropped. It reports
+// whether the event was sent and false for `alive` if the scan notifier
+// stopped meanwhile.
+func (sn *snotifier) send(queue chan Event, of *overflow, ev Event) (sent, alive bool) {
+	if OverflowPolicy(sn.opts.delivery.overflow.Load()) != OVERFLOW_DROP {
+		// room is used first so the events delivered while stopping are kept.
+		select {
+		case queue <- ev:
+			return true, true
+		default:
+		}
+		select {
+		case queue <- ev:
+			return true, true
+		case <-sn.stop:
+			return false, false
+		}
+	}
+	of.mu.Lock()
+	defer of.mu.Unlock()
+	if sn.flushOverflow(queue, of) {
+		select {
+		case queue <- ev:
+			return true, true
+		default:
+		}
+	}
+	of.drop(ev)
+	sn.dropped.Add(1)
+	return false, true
+}
+
+// flushOverflow sends the `OVERFLOW` event of the queue if some events were
+// dropped and there is room. It reports whether no events are left to report.
+// It must be called with the overflow lock held.
+func (sn *snotifier) flushOverflow(queue chan Event, of *overflow) bool {
+	if of.dropped == 0 {
+		return true
+	}
+	select {
+	case queue <- sn.classify(of.report(sn.root, sn.rootType(), sn.now())):
+		of.dropped, of.prefixes, of.all = 0, nil, false
+		return true
+	default:
+		return false
+	}
+}
+
+// reportOverflows sends the pending `OVERFLOW` events at the end of a scan
+// cycle so they do not wait for the next event.
+func (sn *snotifier) reportOverflows() {
+	sn.emu.RLock()
+	defer sn.emu.RUnlock()
+	if sn.closed {
+		return
+	}
+	sn.overflow.mu.Lock()
+	sn.flushOverflow(sn.queue, &sn.overflow)
+	sn.overflow.mu.Unlock()
+	for _, sub := range sn.subscriptions() {
+		sub.overflow.mu.Lock()
+		sn.flushOverflow(sub.queue, &sub.overflow)
+		sub.overflow.mu.Unlock()
+	}
+}
@@ -0,0 +1,120 @@
+package gorsn
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// pending returns the events already sent to the queue.
+func pending(sn *snotifier) []Event {
+	var evs []Event
+	for {
+		select {
+		case ev := <-sn.queue:
+			evs = append(evs, ev)
+		default:
+			return evs
+		}
+	}
+}
+
+func TestOverflowDrop(t *testing.T) {
+	root := t.TempDir()
+	opts := defaultOpts()
+	opts.Delivery().SetQueueSize(2).SetOverflowPolicy(OVERFLOW_DROP)
+	sn, err := newSnotifier(root, opts, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sn.running.Store(true)
+
+	for _, p := range []string{"a/1", "a/2", "b/3", "a/4", "c/5"} {
+		sn.queueEvent(Event{Path: filepath.Join(root, p), Type: FILE, Name: CREATE})
+	}
+	if got := sn.Stats().Dropped; got != 3 {
+		t.Errorf("got %d dropped events, want 3", got)
+	}
+	if evs := pending(sn); len(evs) != 2 || evs[0].Path != filepath.Join(root, "a/1") || evs[1].Path != filepath.Join(root, "a/2") {
+		t.Fatalf("got events %+v, want the first two ones", evs)
+	}
+
+	// the next event is preceded by the report of the dropped ones.
+	sn.queueEvent(Event{Path: filepath.Join(root, "d/6"), Type: FILE, Name: CREATE})
+	evs := pending(sn)
+	if len(evs) != 2 {
+		t.Fatalf("got events %+v, want the OVERFLOW and the CREATE events", evs)
+	}
+	of := evs[0]
+	if of.Name != OVERFLOW || !errors.Is(of.Error, ErrQueueOverflow) || of.Path != root || of.Dropped != 3 {
+		t.Errorf("got %s event of %q with error %v and %d dropped, want an OVERFLOW of the root with 3 dropped", of.Name, of.Path, of.Error, of.Dropped)
+	}
+	want := []string{filepath.Join(root, "a"), filepath.Join(root, "b"), filepath.Join(root, "c")}
+	if !reflect.DeepEqual(of.Prefixes, want) {
+		t.Errorf("got prefixes %q, want %q", of.Prefixes, want)
+	}
+	if evs[1].Name != CREATE || evs[1].Path != filepath.Join(root, "d/6") {
+		t.Errorf("got %s event of %q, want the CREATE of d/6", evs[1].Name, evs[1].Path)
+	}
+	if got := sn.Stats().Dropped; got != 3 {
+		t.Errorf("got %d dropped events once reported, want the total of 3", got)
+	}
+}
+
+func TestOverflowReportAtEndOfCycle(t *testing.T) {
+	root := t.TempDir()
+	opts := defaultOpts()
+	opts.Delivery().SetQueueSize(1).SetOverflowPolicy(OVERFLOW_DROP)
+	sn, err := newSnotifier(root, opts, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sn.running.Store(true)
+
+	n := maxOverflowPrefixes + 2
+	for i := 0; i < n; i++ {
+		sn.queueEvent(Event{Path: filepath.Join(root, fmt.Sprint(i), "f"), Type: FILE, Name: CREATE})
+	}
+	// no room, the report waits.
+	sn.reportOverflows()
+	if evs := pending(sn); len(evs) != 1 || evs[0].Name != CREATE {
+		t.Fatalf("got events %+v, want the first CREATE only", evs)
+	}
+	sn.reportOverflows()
+	evs := pending(sn)
+	if len(evs) != 1 || evs[0].Name != OVERFLOW {
+		t.Fatalf("got events %+v, want an OVERFLOW event", evs)
+	}
+	// too many directories are summarized by the root.
+	if evs[0].Dropped != n-1 || !reflect.DeepEqual(evs[0].Prefixes, []string{root}) {
+		t.Errorf("got %d dropped under %q, want %d under the root", evs[0].Dropped, evs[0].Prefixes, n-1)
+	}
+	sn.reportOverflows()
+	if evs := pending(sn); len(evs) != 0 {
+		t.Errorf("got events %+v, want no report once nothing was dropped", evs)
+	}
+}
+
+func TestOverflowBlock(t *testing.T) {
+	opts := defaultOpts()
+	opts.Delivery().SetQueueSize(1)
+	sn, err := newSnotifier(t.TempDir(), opts, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sn.running.Store(true)
+	sn.queueEvent(Event{Path: "a", Type: FILE, Name: CREATE})
+	done := make(chan bool)
+	go func() { done <- sn.queueEvent(Event{Path: "b", Type: FILE, Name: CREATE}) }()
+	if ev := <-sn.queue; ev.Path != "a" {
+		t.Errorf("got event of %q, want a", ev.Path)
+	}
+	if ev := <-sn.queue; ev.Path != "b" {
+		t.Errorf("got event of %q, want b", ev.Path)
+	}
+	if !<-done || sn.Stats().Dropped != 0 {
+		t.Errorf("got %d dropped events, want the blocked event delivered", sn.Stats().Dropped)
+	}
+}
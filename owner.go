@@ -0,0 +1,26 @@
+package gorsn
+
+// ownerFiltered reports whether the item should be skipped since its owner
+// user or group is not among the allowed ones. Directories are never skipped
+// so their content is still monitored, neither are the items whose ownership
+// is unknown.
+func (sn *snotifier) ownerFiltered(t PathType, sys sysInfo) bool {
+	if t == DIR || !sys.valid {
+		return false
+	}
+	fo := &sn.opts.filters
+	return !allowedID(fo.ownerFilter(), sys.uid) || !allowedID(fo.groupFilter(), sys.gid)
+}
+
+// allowedID reports whether `id` is among `ids`, or if `ids` is empty.
+func allowedID(ids []int, id uint32) bool {
+	if len(ids) == 0 {
+		return true
+	}
+	for _, v := range ids {
+		if v >= 0 && uint32(v) == id {
+			return true
+		}
+	}
+	return false
+}
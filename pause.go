@@ -0,0 +1,159 @@
+package gorsn
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// pausedPaths holds the globs of the subtrees paused by `PausePath`.
+type pausedPaths struct {
+	mu    sync.Mutex
+	globs atomic.Value // map[string]*regexp.Regexp
+}
+
+// PauseFor pauses the scan notifier like Pause then resumes it automatically
+// once the duration `d` elapsed, unless resumed or paused again meanwhile.
+func (sn *snotifier) PauseFor(d time.Duration) error {
+	return sn.PauseUntil(sn.now().Add(d))
+}
+
+// PauseUntil pauses the scan notifier like Pause then resumes it automatically
+// at the time `t`, unless resumed or paused again meanwhile.
+func (sn *snotifier) PauseUntil(t time.Time) error {
+	if err := sn.Pause(); err != nil {
+		return err
+	}
+	at := t.UnixNano()
+	sn.resumeAt.Store(at)
+	go sn.autoResume(at, t.Sub(sn.now()))
+	return nil
+}
+
+// autoResume resumes the scan notifier after the delay `d` unless it was
+// stopped, paused or resumed again meanwhile, i.e. the resumption time `at`
+// was changed.
+func (sn *snotifier) autoResume(at int64, d time.Duration) {
+	select {
+	case <-sn.stop:
+		return
+	case <-sn.opts.scan.clock.After(d):
+	}
+	if sn.resumeAt.CompareAndSwap(at, 0) {
+		sn.abort.Store(false)
+		sn.paused.Store(false)
+	}
+}
+
+// idle is called by the scanner while paused. On the first call, it
+// delivers the events still held by the debouncer then notifies the
+// observers that the scan notifier is idle.
+func (sn *snotifier) idle() {
+	if sn.idling {
+		return
+	}
+	sn.idling = true
+	sn.abort.Store(false)
+	for _, ev := range sn.debounce.ready(0, sn.now()) {
+		sn.deliver(ev)
+	}
+	sn.notifyPause()
+}
+
+// wake is called by the scanner before each scan cycle and notifies
+// the observers if the scan notifier was idle.
+func (sn *snotifier) wake() {
+	if !sn.idling {
+		return
+	}
+	sn.idling = false
+	sn.notifyResume()
+}
+
+// unvisit resets the visited flag of all paths after an abandoned cycle
+// so their deletion is detected during the next complete cycle.
+func (sn *snotifier) unvisit() {
+	sn.paths.Range(func(_, value any) bool {
+		value.(*pathInfos).visited = false
+		return true
+	})
+	sn.unvisitCold()
+}
+
+// PausePath stops the monitoring of the paths matching the glob, relative to
+// the root directory, along with their content, e.g. `build` or `**/dist`.
+// Changes which happen under them meanwhile are reported once resumed with
+// ResumePath, while the rest of the root keeps being monitored. Globs use the
+// `.gitignore` syntax. It returns an error which wraps `ErrInvalidOptions` if
+// the glob is empty or malformed.
+func (sn *snotifier) PausePath(glob string) error {
+	glob = strings.Trim(glob, "/")
+	if glob == "" {
+		return fmt.Errorf("%w: empty paused glob", ErrInvalidOptions)
+	}
+	re, err := regexp.Compile(globToRegexp(glob))
+	if err != nil {
+		return fmt.Errorf("%w: paused glob %q: %v", ErrInvalidOptions, glob, err)
+	}
+	pp := &sn.subtrees
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+	globs := make(map[string]*regexp.Regexp)
+	for g, r := range pp.paused() {
+		globs[g] = r
+	}
+	globs[glob] = re
+	pp.globs.Store(globs)
+	return nil
+}
+
+// ResumePath restarts the monitoring of the paths paused by PausePath with the
+// same glob. It returns `ErrScanIsNotPaused` if the glob was not paused.
+func (sn *snotifier) ResumePath(glob string) error {
+	glob = strings.Trim(glob, "/")
+	pp := &sn.subtrees
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+	if _, ok := pp.paused()[glob]; !ok {
+		return fmt.Errorf("%w: %s", ErrScanIsNotPaused, glob)
+	}
+	globs := make(map[string]*regexp.Regexp)
+	for g, r := range pp.paused() {
+		if g != glob {
+			globs[g] = r
+		}
+	}
+	pp.globs.Store(globs)
+	return nil
+}
+
+// paused returns the paused globs.
+func (pp *pausedPaths) paused() map[string]*regexp.Regexp {
+	globs, _ := pp.globs.Load().(map[string]*regexp.Regexp)
+	return globs
+}
+
+// pathPaused reports whether the path or one of its parent directories
+// matches a paused glob.
+func (sn *snotifier) pathPaused(s string) bool {
+	globs := sn.subtrees.paused()
+	if len(globs) == 0 || s == sn.root {
+		return false
+	}
+	rel := sn.rel(s)
+	for {
+		for _, re := range globs {
+			if re.MatchString(rel) {
+				return true
+			}
+		}
+		i := strings.LastIndexByte(rel, '/')
+		if i < 0 {
+			return false
+		}
+		rel = rel[:i]
+	}
+}
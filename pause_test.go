@@ -0,0 +1,472 @@
+package gorsn
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// gate blocks the first call to `pass` once armed until released.
+type gate struct {
+	armed   atomic.Bool
+	entered chan struct{}
+	release chan struct{}
+}
+
+func newGate() *gate {
+	return &gate{entered: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (g *gate) pass() {
+	if g.armed.CompareAndSwap(true, false) {
+		close(g.entered)
+		<-g.release
+	}
+}
+
+// gatedBackend is the local file system whose reading of the directory `dir`
+// and the infos of the file `file` could be blocked to freeze a scan cycle.
+type gatedBackend struct {
+	Backend
+	dir, file string
+	read      *gate
+	info      *gate
+}
+
+func (b *gatedBackend) ReadDir(name string) ([]fs.DirEntry, error) {
+	if name == b.dir {
+		b.read.pass()
+	}
+	entries, err := b.Backend.ReadDir(name)
+	for i, d := range entries {
+		if b.Join(name, d.Name()) == b.file {
+			entries[i] = gatedEntry{d, b.info}
+		}
+	}
+	return entries, err
+}
+
+// gatedEntry is a directory entry whose infos are read once its gate passed.
+type gatedEntry struct {
+	fs.DirEntry
+	g *gate
+}
+
+func (d gatedEntry) Info() (fs.FileInfo, error) {
+	d.g.pass()
+	return d.DirEntry.Info()
+}
+
+// writeFiles creates or rewrites the files of `dir` with `content`.
+func writeFiles(t *testing.T, dir, content string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// received returns the events already sent to the queue, keyed by the
+// base name of their path.
+func received(sn ScanNotifier) map[string][]EventName {
+	evs := make(map[string][]EventName)
+	for {
+		select {
+		case ev := <-sn.Queue():
+			name := filepath.Base(ev.Path)
+			evs[name] = append(evs[name], ev.Name)
+		default:
+			return evs
+		}
+	}
+}
+
+// wait fails the test if the channel is not closed after a few seconds.
+func wait(t *testing.T, c <-chan struct{}, what string) {
+	t.Helper()
+	select {
+	case <-c:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for %s", what)
+	}
+}
+
+func TestPause(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, "a", "a")
+
+	var (
+		mu    sync.Mutex
+		steps []string
+	)
+	step := func(s string) {
+		mu.Lock()
+		steps = append(steps, s)
+		mu.Unlock()
+	}
+	var sn ScanNotifier
+	paused := make(chan struct{})
+	opts := defaultOpts()
+	opts.Scan().SetInterval(5 * time.Millisecond).
+		SetBeforeScan(func(cycle int) {
+			if cycle == 2 {
+				writeFiles(t, dir, "b", "b")
+				if err := sn.Pause(); err != nil {
+					t.Error(err)
+				}
+			}
+		}).
+		SetAfterScan(func(cycle int, _ ScanSummary) {
+			if cycle == 2 {
+				step("cycle")
+			}
+		})
+	opts.Delivery().SetDebounce(time.Hour).OnPause(func() {
+		step("pause")
+		close(paused)
+	})
+	sn, err := New(dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sn.StartAsync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Stop()
+
+	wait(t, paused, "OnPause")
+	if !sn.IsPaused() {
+		t.Error("scan notifier is not paused")
+	}
+	// the debounced event must be sent before the observers are notified.
+	if evs := received(sn); len(evs["b"]) != 1 || evs["b"][0] != CREATE {
+		t.Errorf("got events %v, want a single CREATE of b", evs)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if got := strings.Join(steps, ","); got != "cycle,pause" {
+		t.Errorf("got steps %q, want the cycle completed before pausing", got)
+	}
+}
+
+func TestPauseImmediately(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{"a", "b", "c", "d", "gone"}
+	writeFiles(t, dir, "1", files...)
+	if err := os.Mkdir(filepath.Join(dir, "zz"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, filepath.Join(dir, "zz"), "1", "x")
+
+	backend := &gatedBackend{
+		Backend: osBackend{},
+		dir:     filepath.Join(dir, "zz"),
+		file:    filepath.Join(dir, "a"),
+		read:    newGate(),
+		info:    newGate(),
+	}
+	paused := make(chan struct{}, 1)
+	resumed := make(chan struct{})
+	opts := defaultOpts()
+	opts.Scan().SetInterval(5 * time.Millisecond).SetMaxWorkers(1).SetBackend(backend).
+		SetBeforeScan(func(cycle int) {
+			if cycle != 2 {
+				return
+			}
+			writeFiles(t, dir, "22", files[:4]...)
+			if err := os.Remove(filepath.Join(dir, "gone")); err != nil {
+				t.Error(err)
+			}
+			backend.read.armed.Store(true)
+			backend.info.armed.Store(true)
+		}).
+		SetAfterScan(func(cycle int, _ ScanSummary) {
+			if cycle == 3 {
+				close(resumed)
+			}
+		})
+	opts.Delivery().SetQueueSize(64).OnPause(func() { paused <- struct{}{} })
+	sn, err := New(dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sn.StartAsync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Stop()
+
+	// the single worker is stuck on `a` while the other entries are queued
+	// and the walker is stuck on reading `zz`.
+	wait(t, backend.info.entered, "worker")
+	wait(t, backend.read.entered, "walker")
+	if err := sn.PauseImmediately(); err != nil {
+		t.Fatal(err)
+	}
+	close(backend.read.release)
+	for !sn.(*snotifier).aborted.Load() {
+		time.Sleep(time.Millisecond)
+	}
+	close(backend.info.release)
+
+	select {
+	case <-paused:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for OnPause")
+	}
+	evs := received(sn)
+	for _, name := range []string{"b", "c", "d", "gone"} {
+		if len(evs[name]) != 0 {
+			t.Errorf("got events %v of %s from the abandoned cycle", evs[name], name)
+		}
+	}
+
+	// `a` was visited by the abandoned cycle.
+	if err := os.Remove(filepath.Join(dir, "a")); err != nil {
+		t.Fatal(err)
+	}
+	if err := sn.Resume(); err != nil {
+		t.Fatal(err)
+	}
+	wait(t, resumed, "next cycle")
+	evs = received(sn)
+	for _, name := range []string{"a", "gone"} {
+		if len(evs[name]) != 1 || evs[name][0] != DELETE {
+			t.Errorf("got events %v of %s, want a single DELETE after resuming", evs[name], name)
+		}
+	}
+	for _, name := range []string{"b", "c", "d"} {
+		if len(evs[name]) != 1 || evs[name][0] != MODIFY {
+			t.Errorf("got events %v of %s, want a single MODIFY after resuming", evs[name], name)
+		}
+	}
+}
+
+func TestPauseFor(t *testing.T) {
+	// timers waits for the auto-resume routine to wait on the clock.
+	timers := func(t *testing.T, clock *fakeClock, n int) {
+		t.Helper()
+		for deadline := time.Now().Add(5 * time.Second); clock.pending() != n; {
+			if time.Now().After(deadline) {
+				t.Fatalf("got %d timers, want %d", clock.pending(), n)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	// resumed waits for the scan notifier to be resumed.
+	resumed := func(t *testing.T, sn *snotifier) {
+		t.Helper()
+		for deadline := time.Now().Add(5 * time.Second); sn.IsPaused(); {
+			if time.Now().After(deadline) {
+				t.Fatal("timed out waiting for the automatic resumption")
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	tests := []struct {
+		name      string
+		meanwhile func(sn *snotifier) error
+		after     time.Duration // delay of the resumption, zero if none.
+	}{
+		{"resumed at the deadline", nil, time.Minute},
+		{"paused again", (*snotifier).Pause, 0},
+		{"paused immediately", (*snotifier).PauseImmediately, 0},
+		{"resumed then paused", func(sn *snotifier) error {
+			if err := sn.Resume(); err != nil {
+				return err
+			}
+			return sn.Pause()
+		}, 0},
+		{"paused for longer", func(sn *snotifier) error {
+			return sn.PauseFor(2 * time.Minute)
+		}, 2 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newFakeClock()
+			opts := defaultOpts()
+			opts.Scan().SetClock(clock)
+			sn, err := newSnotifier(t.TempDir(), opts, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sn.running.Store(true)
+
+			if err := sn.PauseFor(time.Minute); err != nil {
+				t.Fatal(err)
+			}
+			timers(t, clock, 1)
+			if tt.meanwhile != nil {
+				if err := tt.meanwhile(sn); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.after > time.Minute {
+				timers(t, clock, 2)
+			}
+			clock.advance(time.Minute - time.Second)
+			if !sn.IsPaused() {
+				t.Fatal("resumed before the deadline")
+			}
+			clock.advance(time.Second)
+			if tt.after == time.Minute {
+				resumed(t, sn)
+				if at := sn.resumeAt.Load(); at != 0 {
+					t.Errorf("got resumption time %d once resumed, want none", at)
+				}
+				return
+			}
+			// leaves the outdated routine the time to resume wrongly.
+			time.Sleep(10 * time.Millisecond)
+			if !sn.IsPaused() {
+				t.Fatal("resumed at the outdated deadline")
+			}
+			if tt.after == 0 {
+				return
+			}
+			clock.advance(tt.after - time.Minute)
+			resumed(t, sn)
+		})
+	}
+}
+
+func TestPauseUntilPast(t *testing.T) {
+	clock := newFakeClock()
+	opts := defaultOpts()
+	opts.Scan().SetClock(clock)
+	sn, err := newSnotifier(t.TempDir(), opts, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sn.running.Store(true)
+
+	if err := sn.PauseUntil(clock.Now().Add(-time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	for deadline := time.Now().Add(5 * time.Second); sn.IsPaused(); {
+		if time.Now().After(deadline) {
+			t.Fatal("not resumed for a time already past")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestPauseForNotRunning(t *testing.T) {
+	sn, err := newSnotifier(t.TempDir(), defaultOpts(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sn.PauseFor(time.Minute); err != ErrScanIsNotRunning {
+		t.Errorf("got error %v, want ErrScanIsNotRunning", err)
+	}
+	if at := sn.resumeAt.Load(); at != 0 {
+		t.Errorf("got resumption time %d, want none", at)
+	}
+}
+
+func TestPausePath(t *testing.T) {
+	dir := t.TempDir()
+	for _, d := range []string{"build/deep", "src"} {
+		if err := os.MkdirAll(filepath.Join(dir, d), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFiles(t, filepath.Join(dir, "build"), "1", "out", "gone")
+	writeFiles(t, filepath.Join(dir, "build", "deep"), "1", "x")
+	writeFiles(t, filepath.Join(dir, "src"), "1", "main")
+
+	clock := newFakeClock()
+	cycles := make(chan int, 1)
+	opts := defaultOpts()
+	opts.Scan().SetInterval(time.Second).SetClock(clock).
+		SetAfterScan(func(cycle int, _ ScanSummary) { cycles <- cycle })
+	sn, err := New(dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sn.StartAsync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Stop()
+
+	// next advances the clock until the scan cycle `n` completed.
+	next := func(n int) {
+		t.Helper()
+		for deadline := time.Now().Add(5 * time.Second); ; clock.advance(time.Second) {
+			select {
+			case c := <-cycles:
+				if c != n {
+					t.Fatalf("got cycle %d, want %d", c, n)
+				}
+				return
+			case <-time.After(time.Millisecond):
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for cycle %d", n)
+			}
+		}
+	}
+	next(1)
+	if err := sn.PausePath("/build/"); err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, filepath.Join(dir, "build"), "22", "out", "new")
+	writeFiles(t, filepath.Join(dir, "build", "deep"), "22", "x")
+	writeFiles(t, filepath.Join(dir, "src"), "22", "main")
+	if err := os.Remove(filepath.Join(dir, "build", "gone")); err != nil {
+		t.Fatal(err)
+	}
+	next(2)
+	evs := received(sn)
+	if len(evs) != 1 || len(evs["main"]) != 1 || evs["main"][0] != MODIFY {
+		t.Errorf("got events %v, want only a MODIFY of main out of the paused subtree", evs)
+	}
+
+	if err := sn.ResumePath("build"); err != nil {
+		t.Fatal(err)
+	}
+	next(3)
+	evs = received(sn)
+	for name, want := range map[string]EventName{"out": MODIFY, "x": MODIFY, "new": CREATE, "gone": DELETE} {
+		if len(evs[name]) != 1 || evs[name][0] != want {
+			t.Errorf("got events %v of %s, want a single %s once resumed", evs[name], name, want)
+		}
+	}
+	if len(evs["main"]) != 0 {
+		t.Errorf("got events %v of main, want none", evs["main"])
+	}
+}
+
+func TestPausePathErrors(t *testing.T) {
+	sn, err := newSnotifier(t.TempDir(), defaultOpts(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, glob := range []string{"", "/"} {
+		if err := sn.PausePath(glob); !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("got error %v for glob %q, want ErrInvalidOptions", err, glob)
+		}
+	}
+	if err := sn.PausePath("**/dist"); err != nil {
+		t.Fatal(err)
+	}
+	if err := sn.ResumePath("dist"); !errors.Is(err, ErrScanIsNotPaused) {
+		t.Errorf("got error %v for a glob not paused, want ErrScanIsNotPaused", err)
+	}
+	if !sn.pathPaused(filepath.Join(sn.root, "a", "dist", "x")) {
+		t.Error("got a/dist/x not paused, want it paused by **/dist")
+	}
+	if err := sn.ResumePath("**/dist/"); err != nil {
+		t.Fatal(err)
+	}
+	if sn.pathPaused(filepath.Join(sn.root, "a", "dist", "x")) {
+		t.Error("got a/dist/x paused once resumed")
+	}
+}
@@ -0,0 +1,63 @@
+package gorsn
+
+import (
+	"io"
+	"time"
+)
+
+const (
+	// DEFAULT_INTEGRITY_ANOMALY_WINDOW is the number of cycles used as baseline
+	// by the anomaly detection of the integrity monitoring profile.
+	DEFAULT_INTEGRITY_ANOMALY_WINDOW = 10
+
+	// DEFAULT_DEV_DEBOUNCE is the quiet period used by the developer profile.
+	DEFAULT_DEV_DEBOUNCE = 100 * time.Millisecond
+
+	// DEFAULT_DEV_SETTLE_CYCLES is the number of unchanged scans after
+	// which the developer profile reports a file as fully written.
+	DEFAULT_DEV_SETTLE_CYCLES = 2
+)
+
+// UseIntegrityProfile configures the options for file integrity monitoring (FIM).
+// It enables the content checksum, ownership, rename and links tracking with the
+// anomaly detection and ensures that all changes events are emitted. When `audit`
+// is not nil, each event is also appended as a JSON line into it.
+func (o *Options) UseIntegrityProfile(audit io.Writer) *Options {
+	o.Scan().SetChecksum(true)
+	o.Events().SetTrackOwnership(true).
+		SetTrackRenames(true).
+		SetTrackLinks(true).
+		SetAnomalyDetection(DEFAULT_INTEGRITY_ANOMALY_WINDOW, DEFAULT_ANOMALY_SENSITIVITY).
+		SetIgnoreErrors(false).
+		SetIgnoreCreate(false).
+		SetIgnoreDelete(false).
+		SetIgnoreModify(false).
+		SetIgnorePerm(false).
+		SetIgnoreAttrib(false)
+	if audit != nil {
+		o.Delivery().AddSink(NewAuditLogSink(audit))
+	}
+	return o
+}
+
+// UseCommonIgnorePreset skips the temporary files of editors, such as vim swap
+// or emacs backup files, and the artifacts of operating systems, office suites
+// and downloads in progress, such as `.DS_Store`, `Thumbs.db`, `*.tmp` or
+// `*.crdownload` files. See `SetIgnoreEditorTemp` and `SetIgnoreArtifacts`.
+func (o *Options) UseCommonIgnorePreset() *Options {
+	o.Filters().SetIgnoreEditorTemp(true).SetIgnoreArtifacts(true)
+	return o
+}
+
+// UseDevProfile configures the options for developer tooling such as build
+// tools or live reloaders. It skips editors temporary files, the `.git` folder
+// and the paths ignored by the root `.gitignore` file and debounces the events
+// so a burst of saves results into a single event per path. A `STABLE` event
+// is emitted once a changed file is fully written. `actions.NewDevCommand`
+// applies it along with a hook which runs a program on these events.
+func (o *Options) UseDevProfile() *Options {
+	o.Filters().SetIgnoreEditorTemp(true).SetGitignore(true)
+	o.Events().SetSettleCycles(DEFAULT_DEV_SETTLE_CYCLES)
+	o.Delivery().SetDebounce(DEFAULT_DEV_DEBOUNCE)
+	return o
+}
@@ -0,0 +1,107 @@
+package gorsn
+
+import (
+	"sync"
+	"time"
+)
+
+// Progress describes the advancement of the in-flight scan cycle.
+type Progress struct {
+	// Cycle is the sequence number of the in-flight or latest scan cycle.
+	Cycle int
+	// Scanning reports whether a scan cycle is in progress.
+	Scanning bool
+	// Started is the start time of the scan cycle.
+	Started time.Time
+	// Visited is the number of paths visited so far.
+	Visited int64
+	// Directory is the latest directory entered by the scanner.
+	Directory string
+	// Expected is the number of paths visited by the previous scan cycle.
+	// It is zero during the first scan cycle.
+	Expected int64
+	// Percent is the estimated completion of the scan cycle from 0 to 100
+	// based on `Expected`.
+	Percent float64
+	// Remaining is the estimated time to complete the scan cycle.
+	Remaining time.Duration
+}
+
+// progress tracks the advancement of the scan cycles.
+type progress struct {
+	mu       sync.Mutex
+	cycle    int
+	scanning bool
+	started  time.Time
+	dir      string
+	expected int64
+}
+
+func (p *progress) begin(cycle int, now time.Time) {
+	p.mu.Lock()
+	p.cycle = cycle
+	p.scanning = true
+	p.started = now
+	p.dir = ""
+	p.mu.Unlock()
+}
+
+func (p *progress) enter(dir string) {
+	p.mu.Lock()
+	p.dir = dir
+	p.mu.Unlock()
+}
+
+func (p *progress) end(visited int64) {
+	p.mu.Lock()
+	p.scanning = false
+	p.expected = visited
+	p.mu.Unlock()
+}
+
+// Progress returns the advancement of the in-flight scan cycle or
+// the final figures of the latest one.
+func (sn *snotifier) Progress() Progress {
+	sn.progress.mu.Lock()
+	p := Progress{
+		Cycle:     sn.progress.cycle,
+		Scanning:  sn.progress.scanning,
+		Started:   sn.progress.started,
+		Directory: sn.progress.dir,
+		Expected:  sn.progress.expected,
+	}
+	sn.progress.mu.Unlock()
+	p.Visited = sn.visited.Load()
+	if !p.Scanning {
+		p.Expected = p.Visited
+	}
+	if p.Expected <= 0 {
+		return p
+	}
+	if p.Visited >= p.Expected {
+		p.Percent = 100
+		return p
+	}
+	p.Percent = float64(p.Visited) * 100 / float64(p.Expected)
+	if p.Visited > 0 {
+		elapsed := sn.now().Sub(p.Started)
+		p.Remaining = time.Duration(float64(elapsed) * float64(p.Expected-p.Visited) / float64(p.Visited))
+	}
+	return p
+}
+
+// reportProgress calls the user-defined progress callback every
+// configured number of visited paths.
+func (sn *snotifier) reportProgress(visited int64) {
+	pr, ok := sn.opts.scan.progress.Load().(*progressReport)
+	if !ok || pr == nil || visited%int64(pr.every) != 0 {
+		return
+	}
+	pr.fn(sn.Progress())
+}
+
+// progressReport holds the user-defined progress callback.
+type progressReport struct {
+	every int
+	fn    func(Progress)
+}
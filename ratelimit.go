@@ -0,0 +1,72 @@
+package gorsn
+
+import (
+	"sync"
+	"time"
+)
+
+// rateLimiter is a token bucket which limits the number of events delivered
+// per second. Its capacity is one second of events so short bursts are
+// delivered at once.
+type rateLimiter struct {
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// reserve takes a token and returns how long to wait for it, zero if one was
+// available. If `wait` is false, it does not take the missing token and
+// reports false.
+func (rl *rateLimiter) reserve(rate int, now time.Time, wait bool) (time.Duration, bool) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if rl.last.IsZero() {
+		rl.tokens = float64(rate)
+	} else if elapsed := now.Sub(rl.last); elapsed > 0 {
+		rl.tokens += elapsed.Seconds() * float64(rate)
+	}
+	if rl.tokens > float64(rate) {
+		rl.tokens = float64(rate)
+	}
+	rl.last = now
+	if rl.tokens >= 1 {
+		rl.tokens--
+		return 0, true
+	}
+	if !wait {
+		return 0, false
+	}
+	rl.tokens--
+	return time.Duration(-rl.tokens / float64(rate) * float64(time.Second)), true
+}
+
+// limit applies the events rate limit before the delivery of the event. Under
+// the `OVERFLOW_DROP` policy, the events over the limit are dropped and
+// reported by an `OVERFLOW` event of each queue which would have received
+// them, otherwise it waits until the event could be delivered. It reports
+// whether the event is allowed, and false for `alive` if the scan notifier
+// stopped meanwhile.
+func (sn *snotifier) limit(ev Event) (allowed, alive bool) {
+	rate := int(sn.opts.delivery.maxRate.Load())
+	if rate <= 0 {
+		return true, true
+	}
+	block := OverflowPolicy(sn.opts.delivery.overflow.Load()) != OVERFLOW_DROP
+	wait, ok := sn.limiter.reserve(rate, sn.now(), block)
+	if !ok {
+		sn.rateLimited.Add(1)
+		sn.dropped.Add(1)
+		sn.dropAll(ev)
+		return false, true
+	}
+	if wait <= 0 {
+		return true, true
+	}
+	sn.rateLimited.Add(1)
+	select {
+	case <-sn.opts.scan.clock.After(wait):
+		return true, true
+	case <-sn.stop:
+		return false, false
+	}
+}
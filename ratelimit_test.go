@@ -0,0 +1,94 @@
+package gorsn
+
+import (
+	"testing"
+	"time"
+)
+
+// newLimited returns a running scan notifier which delivers at most
+// `rate` events per second according to the fake clock.
+func newLimited(t *testing.T, clock *fakeClock, rate int, policy OverflowPolicy) *snotifier {
+	t.Helper()
+	opts := defaultOpts()
+	opts.Scan().SetClock(clock)
+	opts.Delivery().SetQueueSize(16).SetMaxEventsPerSecond(rate).SetOverflowPolicy(policy)
+	sn, err := newSnotifier(t.TempDir(), opts, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sn.running.Store(true)
+	return sn
+}
+
+func TestRateLimitDrop(t *testing.T) {
+	clock := newFakeClock()
+	sn := newLimited(t, clock, 2, OVERFLOW_DROP)
+	send := func(path string) {
+		sn.queueEvent(Event{Path: path, Type: FILE, Name: CREATE})
+	}
+
+	// a burst of up to one second of events is delivered at once.
+	send("a")
+	send("b")
+	send("c")
+	if got := len(sn.queue); got != 2 {
+		t.Errorf("got %d events of the burst, want 2", got)
+	}
+	if s := sn.Stats(); s.RateLimited != 1 || s.Dropped != 1 {
+		t.Errorf("got %d rate limited and %d dropped events, want 1 and 1", s.RateLimited, s.Dropped)
+	}
+
+	// a token is refilled every half second.
+	clock.advance(499 * time.Millisecond)
+	send("d")
+	clock.advance(time.Millisecond)
+	send("e")
+	if s := sn.Stats(); s.RateLimited != 2 || s.Dropped != 2 {
+		t.Errorf("got %d rate limited and %d dropped events, want 2 and 2", s.RateLimited, s.Dropped)
+	}
+	evs := pending(sn)
+	var paths []string
+	for _, ev := range evs {
+		paths = append(paths, ev.Path)
+	}
+	if len(evs) != 4 || evs[2].Name != OVERFLOW || evs[2].Dropped != 2 || evs[3].Path != "e" {
+		t.Errorf("got events of %q, want a, b, the OVERFLOW of c and d, then e", paths)
+	}
+
+	// the refill is capped to one second of events.
+	clock.advance(time.Hour)
+	for _, p := range []string{"f", "g", "h"} {
+		send(p)
+	}
+	if got := len(pending(sn)); got != 2 {
+		t.Errorf("got %d events after an idle hour, want a burst of 2", got)
+	}
+}
+
+func TestRateLimitBlock(t *testing.T) {
+	clock := newFakeClock()
+	sn := newLimited(t, clock, 1, OVERFLOW_BLOCK)
+	sn.queueEvent(Event{Path: "a", Type: FILE, Name: CREATE})
+	done := make(chan struct{})
+	go func() {
+		sn.queueEvent(Event{Path: "b", Type: FILE, Name: CREATE})
+		close(done)
+	}()
+	for clock.pending() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	clock.advance(999 * time.Millisecond)
+	select {
+	case <-done:
+		t.Fatal("event delivered before a token was refilled")
+	case <-time.After(10 * time.Millisecond):
+	}
+	clock.advance(time.Millisecond)
+	wait(t, done, "the delayed event")
+	if evs := pending(sn); len(evs) != 2 || evs[1].Path != "b" {
+		t.Errorf("got events %+v, want a then b", evs)
+	}
+	if s := sn.Stats(); s.RateLimited != 1 || s.Dropped != 0 {
+		t.Errorf("got %d rate limited and %d dropped events, want 1 and 0", s.RateLimited, s.Dropped)
+	}
+}
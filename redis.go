@@ -0,0 +1,74 @@
+package gorsn
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// RedisStreamAdder defines the `XADD` operation of a Redis client, so that
+// this module does not have to depend on any Redis implementation. With
+// go-redis, it could be implemented as below:
+//
+//	type goRedisAdder struct{ c *redis.Client }
+//
+//	func (a goRedisAdder) XAdd(stream string, maxLen int64, values map[string]string) error {
+//		return a.c.XAdd(context.Background(), &redis.XAddArgs{
+//			Stream: stream, MaxLen: maxLen, Approx: true, ID: "*", Values: values,
+//		}).Err()
+//	}
+type RedisStreamAdder interface {
+	XAdd(stream string, maxLen int64, values map[string]string) error
+}
+
+// RedisStreamConfig defines how the events are written by the Redis sink.
+type RedisStreamConfig struct {
+	Client RedisStreamAdder
+	Stream string
+	// MaxLen is the approximate number of entries kept into the stream,
+	// zero to keep all of them.
+	MaxLen int64
+	// Filter selects the events to write. A nil filter selects all of them.
+	Filter Filter
+}
+
+type redisSink struct {
+	cfg RedisStreamConfig
+}
+
+// NewRedisStreamSink returns a Sink which appends each event selected by the
+// filter to a Redis Stream, so several consumer groups could read them at their
+// own pace. Entries IDs are generated by Redis. Each entry holds flat fields
+// `event`, `type`, `path`, `time` (RFC 3339) and `cycle`, plus `old_path`,
+// `error`, `severity` and `tags` (JSON array) when set.
+func NewRedisStreamSink(cfg RedisStreamConfig) Sink {
+	return &redisSink{cfg: cfg}
+}
+
+func (s *redisSink) Write(ev Event) error {
+	if s.cfg.Filter != nil && !s.cfg.Filter(ev) {
+		return nil
+	}
+	values := map[string]string{
+		"event":    string(ev.Name),
+		"type":     string(ev.Type),
+		"path":     ev.Path,
+		"time":     ev.Time.Format(time.RFC3339Nano),
+		"cycle":    strconv.Itoa(ev.Cycle),
+		"severity": ev.Severity.String(),
+	}
+	if ev.OldPath != "" {
+		values["old_path"] = ev.OldPath
+	}
+	if ev.Error != nil {
+		values["error"] = ev.Error.Error()
+	}
+	if len(ev.Tags) > 0 {
+		tags, err := json.Marshal(ev.Tags)
+		if err != nil {
+			return err
+		}
+		values["tags"] = string(tags)
+	}
+	return s.cfg.Client.XAdd(s.cfg.Stream, s.cfg.MaxLen, values)
+}
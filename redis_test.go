@@ -0,0 +1,81 @@
+package gorsn
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// streamEntry is an entry added through the fake Redis client.
+type streamEntry struct {
+	stream string
+	maxLen int64
+	values map[string]string
+}
+
+// fakeStreamAdder records the added entries and fails with `err` if set.
+type fakeStreamAdder struct {
+	entries []streamEntry
+	err     error
+}
+
+func (a *fakeStreamAdder) XAdd(stream string, maxLen int64, values map[string]string) error {
+	a.entries = append(a.entries, streamEntry{stream, maxLen, values})
+	return a.err
+}
+
+func TestRedisStreamSink(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	tests := []struct {
+		name string
+		ev   Event
+		want map[string]string
+	}{
+		{"plain event", Event{Path: "/data/a", Type: FILE, Name: CREATE, Time: at, Cycle: 3}, map[string]string{
+			"event": "CREATE", "type": "FILE", "path": "/data/a", "time": "2024-01-02T03:04:05.000000006Z",
+			"cycle": "3", "severity": "INFO",
+		}},
+		{"optional fields", Event{
+			Path: "/data/b", OldPath: "/data/a", Type: FILE, Name: RENAME, Time: at, Cycle: 4,
+			Error: errors.New("partial"), Severity: SEVERITY_WARNING, Tags: []string{"db", "hot"},
+		}, map[string]string{
+			"event": "RENAME", "type": "FILE", "path": "/data/b", "time": "2024-01-02T03:04:05.000000006Z",
+			"cycle": "4", "severity": SEVERITY_WARNING.String(), "old_path": "/data/a", "error": "partial",
+			"tags": `["db","hot"]`,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeStreamAdder{}
+			sink := NewRedisStreamSink(RedisStreamConfig{Client: client, Stream: "gorsn:events", MaxLen: 1000})
+			if err := sink.Write(tt.ev); err != nil {
+				t.Fatal(err)
+			}
+			if len(client.entries) != 1 {
+				t.Fatalf("got %d entries, want 1", len(client.entries))
+			}
+			e := client.entries[0]
+			if e.stream != "gorsn:events" || e.maxLen != 1000 {
+				t.Errorf("got stream %q with max length %d, want gorsn:events with 1000", e.stream, e.maxLen)
+			}
+			if !reflect.DeepEqual(e.values, tt.want) {
+				t.Errorf("got fields %v, want %v", e.values, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedisStreamSinkFilter(t *testing.T) {
+	client := &fakeStreamAdder{err: errors.New("connection refused")}
+	sink := NewRedisStreamSink(RedisStreamConfig{Client: client, Stream: "s", Filter: func(ev Event) bool { return ev.Name == DELETE }})
+	if err := sink.Write(Event{Path: "/data/a", Type: FILE, Name: CREATE}); err != nil {
+		t.Errorf("got error %v for a filtered out event", err)
+	}
+	if err := sink.Write(Event{Path: "/data/a", Type: FILE, Name: DELETE}); !errors.Is(err, client.err) {
+		t.Errorf("got error %v, want the failure of the client", err)
+	}
+	if len(client.entries) != 1 || client.entries[0].values["event"] != "DELETE" || client.entries[0].maxLen != 0 {
+		t.Errorf("got entries %v, want only the DELETE event without max length", client.entries)
+	}
+}
@@ -0,0 +1,109 @@
+package gorsn
+
+import (
+	"bytes"
+	"fmt"
+	"hash"
+	"io"
+)
+
+// Region is a range of bytes of a regular file.
+type Region struct {
+	Offset int64 `json:"offset"`
+	Length int64 `json:"length"`
+}
+
+// blockSums holds the digests of the successive blocks of a file content.
+type blockSums struct {
+	size   int64  // bytes of each block but the last one.
+	length int64  // bytes of the whole content.
+	width  int    // bytes of each digest.
+	sums   []byte // raw digests of the blocks, one after the other.
+}
+
+// count returns the number of blocks.
+func (b *blockSums) count() int {
+	if b.width == 0 {
+		return 0
+	}
+	return len(b.sums) / b.width
+}
+
+// sum returns the digest of the block `i`.
+func (b *blockSums) sum(i int) []byte {
+	return b.sums[i*b.width : (i+1)*b.width]
+}
+
+// SetChangedRegions enables the computation of the digests of the successive
+// blocks of `blockSize` bytes of the regular files when checksum is enabled,
+// so their `MODIFY` events carry the ranges of bytes which changed into their
+// `Regions` field, such as for an incremental sync of large files. Adjacent
+// changed blocks are merged into a single region. The bytes past the current
+// size of a shrunk file are not reported. The regions are unknown, thus not
+// set, for the first change of a file since enabled. The `MODIFY` events are
+// then emitted by the hashing workers once the digests are computed. The
+// digests of the blocks of each file are kept in memory, i.e. 32 bytes per
+// block with SHA-256 such as 256 KiB for a 1 GiB file with 128 KiB blocks,
+// so the block size should grow with the size of the monitored files. A zero
+// or negative value disables it which is the default.
+func (eo *EventOptions) SetChangedRegions(blockSize int) *EventOptions {
+	if blockSize < 0 {
+		blockSize = 0
+	}
+	eo.blockSize.Store(int64(blockSize))
+	return eo
+}
+
+// hashBlocks writes the content read from `r` to `h` and returns the digests
+// of its blocks of `size` bytes.
+func (sn *snotifier) hashBlocks(r io.Reader, h hash.Hash, size int64) (*blockSums, error) {
+	bh := sn.hasher()
+	blocks := &blockSums{size: size, width: bh.Size()}
+	w := io.MultiWriter(h, bh)
+	for {
+		bh.Reset()
+		n, err := io.CopyN(w, r, size)
+		if n > 0 {
+			blocks.sums = bh.Sum(blocks.sums)
+			blocks.length += n
+		}
+		if err == io.EOF {
+			return blocks, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrChecksumFailure, err)
+		}
+	}
+}
+
+// changedRegions returns the merged ranges of the blocks of `cur` which differ
+// from the ones of `prev`, an empty slice if none and nil if not comparable.
+func changedRegions(prev, cur *blockSums) []Region {
+	if prev == nil || cur == nil || prev.size != cur.size || prev.width != cur.width {
+		return nil
+	}
+	regions := []Region{}
+	for i, count := 0, cur.count(); i < count; i++ {
+		if i < prev.count() && bytes.Equal(prev.sum(i), cur.sum(i)) {
+			continue
+		}
+		off := int64(i) * cur.size
+		n := cur.size
+		if off+n > cur.length {
+			n = cur.length - off
+		}
+		if k := len(regions) - 1; k >= 0 && regions[k].Offset+regions[k].Length == off {
+			regions[k].Length += n
+			continue
+		}
+		regions = append(regions, Region{Offset: off, Length: n})
+	}
+	return regions
+}
+
+// withRegions sets the changed ranges of bytes of a `MODIFY` event.
+func withRegions(ev *Event, prev, cur *blockSums) {
+	if ev.Name == MODIFY {
+		ev.Regions = changedRegions(prev, cur)
+	}
+}
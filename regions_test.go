@@ -0,0 +1,60 @@
+package gorsn
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// blocksOf returns the digests of the blocks of `size` bytes of `content`.
+func blocksOf(t *testing.T, content string, size int64) *blockSums {
+	t.Helper()
+	sn := &snotifier{opts: defaultOpts()}
+	h := sha256.New()
+	blocks, err := sn.hashBlocks(strings.NewReader(content), h, size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum := sha256.Sum256([]byte(content)); !bytes.Equal(h.Sum(nil), sum[:]) {
+		t.Error("got a wrong digest of the whole content")
+	}
+	return blocks
+}
+
+func TestHashBlocks(t *testing.T) {
+	blocks := blocksOf(t, "aaaabbbbcc", 4)
+	if blocks.count() != 3 || blocks.length != 10 {
+		t.Fatalf("got %d blocks of %d bytes, want 3 of 10", blocks.count(), blocks.length)
+	}
+	for i, part := range []string{"aaaa", "bbbb", "cc"} {
+		if sum := sha256.Sum256([]byte(part)); !bytes.Equal(blocks.sum(i), sum[:]) {
+			t.Errorf("got a wrong digest of block %d", i)
+		}
+	}
+}
+
+func TestChangedRegions(t *testing.T) {
+	prev := blocksOf(t, "aaaabbbbcccc", 4)
+	tests := []struct {
+		name    string
+		cur     *blockSums
+		regions []Region
+	}{
+		{"unchanged", blocksOf(t, "aaaabbbbcccc", 4), []Region{}},
+		{"middle", blocksOf(t, "aaaaXbbbcccc", 4), []Region{{4, 4}}},
+		{"merged", blocksOf(t, "aaaaXbbbXccc", 4), []Region{{4, 8}}},
+		{"appended", blocksOf(t, "aaaabbbbccccdd", 4), []Region{{12, 2}}},
+		{"other block size", blocksOf(t, "aaaabbbbcccc", 3), nil},
+		{"unknown", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := changedRegions(prev, tt.cur)
+			if !reflect.DeepEqual(got, tt.regions) {
+				t.Errorf("got regions %#v, want %#v", got, tt.regions)
+			}
+		})
+	}
+}
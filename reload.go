@@ -0,0 +1,125 @@
+package gorsn
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// watchConfig monitors the config file defined by `SetConfigFile`, if any,
+// with a scan notifier of its own until the scan notifier is stopped. Its
+// settings are applied on each change. The routines are tracked by `cwg` so
+// the queue is closed only once they exited.
+func (sn *snotifier) watchConfig() error {
+	name := sn.opts.scan.configFile
+	if name == "" {
+		return nil
+	}
+	opts := defaultOpts()
+	opts.Scan().SetInterval(sn.opts.scan.interval.Load().(time.Duration))
+	cw, err := newSnotifier(name, opts, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	queue := cw.Queue()
+	if err := cw.StartAsync(context.Background()); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	sn.cwg.Add(2)
+	go func() {
+		defer sn.cwg.Done()
+		<-sn.stop
+		cw.halt()
+	}()
+	go func() {
+		defer sn.cwg.Done()
+		for ev := range queue {
+			switch ev.Name {
+			case CREATE, MODIFY, REPLACE:
+				sn.reloadConfig(name)
+				cw.opts.Scan().SetInterval(sn.opts.scan.interval.Load().(time.Duration))
+			}
+		}
+	}()
+	return nil
+}
+
+// reloadConfig applies the reloadable settings of the config file then
+// emits a `CONFIG_RELOADED` event. The settings missing from the file are
+// reset to their default value. The settings are left untouched if the file
+// is invalid and an `ERROR` event which wraps `ErrInvalidConfig` is emitted
+// instead.
+func (sn *snotifier) reloadConfig(name string) {
+	sn.emu.RLock()
+	defer sn.emu.RUnlock()
+	if sn.isStopping() {
+		return
+	}
+	cfg, err := loadConfig(name)
+	if err == nil {
+		opts := defaultOpts()
+		if aerr := cfg.apply(opts); aerr != nil {
+			err = fmt.Errorf("%w: %v", ErrInvalidConfig, aerr)
+		} else {
+			sn.opts.reload(opts)
+		}
+	}
+	if err != nil {
+		if !sn.opts.events.ignoreErrors.Load() {
+			sn.queueEvent(Event{Path: name, Type: FILE, Name: ERROR, Error: err})
+		}
+		return
+	}
+	sn.queueEvent(Event{Path: name, Type: FILE, Name: CONFIG_RELOADED})
+}
+
+// reload copies from `src` the settings which could be changed by reloading
+// the config file: the interval, the workers, the filters and the ignore flags.
+func (o *Options) reload(src *Options) {
+	so, sso := o.Scan(), src.Scan()
+	so.SetInterval(sso.GetInterval())
+	so.SetMaxWorkers(sso.GetMaxWorkers())
+	so.SetAutoscale(sso.GetAutoscale())
+
+	fo, sfo := o.Filters(), src.Filters()
+	fo.SetExcludeRegex(sfo.GetExcludeRegex()).
+		SetIncludeRegex(sfo.GetIncludeRegex()).
+		SetIgnoreFiles(sfo.GetIgnoreFiles()).
+		SetIgnoreFolders(sfo.GetIgnoreFolders()).
+		SetIgnoreSymlinks(sfo.GetIgnoreSymlinks()).
+		SetIgnoreFifos(sfo.GetIgnoreFifos()).
+		SetIgnoreSockets(sfo.GetIgnoreSockets()).
+		SetIgnoreDevices(sfo.GetIgnoreDevices()).
+		SetIgnoreFolderContent(sfo.GetIgnoreFolderContent()).
+		SetRecursive(sfo.GetRecursive()).
+		SetGitignore(sfo.GetGitignore()).
+		SetIgnoreEditorTemp(sfo.GetIgnoreEditorTemp()).
+		SetIgnoreArtifacts(sfo.GetIgnoreArtifacts()).
+		SetContentTypes(sfo.GetContentTypes()...).
+		SetOwnerFilter(sfo.GetOwnerFilter()).
+		SetGroupFilter(sfo.GetGroupFilter())
+
+	eo, seo := o.Events(), src.Events()
+	eo.SetIgnoreErrors(seo.GetIgnoreErrors()).
+		SetIgnoreDelete(seo.GetIgnoreDelete()).
+		SetIgnoreCreate(seo.GetIgnoreCreate()).
+		SetIgnoreModify(seo.GetIgnoreModify()).
+		SetIgnorePerm(seo.GetIgnorePerm()).
+		SetIgnoreAttrib(seo.GetIgnoreAttrib())
+}
+
+// loadConfig reads and decodes the YAML or JSON config file. Errors
+// wrap `ErrInvalidConfig`.
+func loadConfig(name string) (*config, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	defer f.Close()
+	data, err := readConfig(f)
+	if err != nil {
+		return nil, err
+	}
+	return decodeConfig(data)
+}
@@ -0,0 +1,117 @@
+package gorsn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReloadConfig(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "gorsn.yaml")
+	write := func(yaml string) {
+		t.Helper()
+		if err := os.WriteFile(name, []byte(yaml), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("scan:\n  interval: 2s\n  max_workers: 3\nfilters:\n  exclude: '\\.log$'\nevents:\n  ignore_delete: true\n")
+	opts := defaultOpts()
+	opts.Delivery().SetQueueSize(7)
+	opts.Events().SetTrackRenames(true)
+	sn, err := newSnotifier(dir, opts, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sn.running.Store(true)
+
+	sn.reloadConfig(name)
+	if ev := <-sn.queue; ev.Name != CONFIG_RELOADED {
+		t.Fatalf("got %s event, want CONFIG_RELOADED", ev.Name)
+	}
+	if got := opts.Scan().GetInterval(); got != 2*time.Second {
+		t.Errorf("got interval %v, want 2s", got)
+	}
+	if got := opts.Scan().GetMaxWorkers(); got != 3 {
+		t.Errorf("got %d workers, want 3", got)
+	}
+	if re := opts.Filters().GetExcludeRegex(); re == nil || re.String() != `\.log$` {
+		t.Errorf("got exclude pattern %v, want \\.log$", re)
+	}
+	if !opts.Events().GetIgnoreDelete() {
+		t.Error("DELETE events are not ignored")
+	}
+
+	// the removed keys are reset, the settings not reloadable are kept.
+	write("scan:\n  interval: 1s\ndelivery:\n  queue_size: 50\nevents:\n  track_renames: false\n")
+	sn.reloadConfig(name)
+	if ev := <-sn.queue; ev.Name != CONFIG_RELOADED {
+		t.Fatalf("got %s event, want CONFIG_RELOADED", ev.Name)
+	}
+	if got := opts.Scan().GetMaxWorkers(); got != DEFAULT_MAX_WORKERS {
+		t.Errorf("got %d workers, want the default %d", got, DEFAULT_MAX_WORKERS)
+	}
+	if re := opts.Filters().GetExcludeRegex(); re != nil {
+		t.Errorf("got exclude pattern %v, want none", re)
+	}
+	if opts.Events().GetIgnoreDelete() {
+		t.Error("DELETE events are still ignored")
+	}
+	if got := opts.Delivery().GetQueueSize(); got != 7 {
+		t.Errorf("got queue size %d, want 7", got)
+	}
+	if !opts.Events().GetTrackRenames() {
+		t.Error("renames are no longer tracked")
+	}
+
+	// an invalid file leaves the settings untouched.
+	write("filters:\n  exclude: '('\n")
+	sn.reloadConfig(name)
+	if ev := <-sn.queue; ev.Name != ERROR || !errors.Is(ev.Error, ErrInvalidConfig) {
+		t.Fatalf("got %s event with error %v, want ErrInvalidConfig", ev.Name, ev.Error)
+	}
+	if got := opts.Scan().GetInterval(); got != time.Second {
+		t.Errorf("got interval %v, want 1s", got)
+	}
+
+	sn.stopping.Store(true)
+	sn.reloadConfig(name)
+	select {
+	case ev := <-sn.queue:
+		t.Errorf("got %s event while stopping", ev.Name)
+	default:
+	}
+}
+
+func TestReloadConfigWhileStopping(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(t.TempDir(), "gorsn.yaml")
+	if err := os.WriteFile(name, []byte("scan:\n  interval: 1ms\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		opts := defaultOpts()
+		opts.Scan().SetInterval(time.Millisecond).SetConfigFile(name)
+		opts.Delivery().SetQueueSize(1)
+		sn, err := New(dir, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := sn.StartAsync(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		content := fmt.Sprintf("scan:\n  interval: 1ms\n  max_workers: %d\n", i+1)
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(time.Duration(i%4) * time.Millisecond)
+		sn.Stop()
+		if err := sn.Wait(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
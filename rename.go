@@ -0,0 +1,68 @@
+package gorsn
+
+// pendingCreate is a `CREATE` event held until the end of the
+// cycle to be matched with a deleted path of the same file.
+type pendingCreate struct {
+	ev      Event
+	sys     sysInfo
+	renamed bool
+}
+
+// addPending holds a `CREATE` event until the end of the cycle. It is indexed
+// by file identity so matching the deleted paths does not scan all of them.
+func (sn *snotifier) addPending(ev Event, sys sysInfo) {
+	sn.pmu.Lock()
+	defer sn.pmu.Unlock()
+	if sys.valid {
+		if sn.pendingIDs == nil {
+			sn.pendingIDs = make(map[fileID][]int)
+		}
+		sn.pendingIDs[sys.id()] = append(sn.pendingIDs[sys.id()], len(sn.pending))
+	}
+	sn.pending = append(sn.pending, pendingCreate{ev: ev, sys: sys})
+}
+
+// renamed returns a `RENAME` event if a path created during the cycle
+// refers to the same file than the deleted `path`.
+func (sn *snotifier) renamed(path string, pi *pathInfos) (Event, bool) {
+	if !pi.sys.valid {
+		return Event{}, false
+	}
+	sn.pmu.Lock()
+	defer sn.pmu.Unlock()
+	id := pi.sys.id()
+	idx := sn.pendingIDs[id]
+	if len(idx) == 0 {
+		return Event{}, false
+	}
+	if len(idx) == 1 {
+		delete(sn.pendingIDs, id)
+	} else {
+		sn.pendingIDs[id] = idx[1:]
+	}
+	pc := &sn.pending[idx[0]]
+	pc.renamed = true
+	return Event{Path: pc.ev.Path, OldPath: path, Type: pc.ev.Type, Name: RENAME, Error: pc.ev.Error}, true
+}
+
+// flushPending emits the `CREATE` events which were not matched.
+func (sn *snotifier) flushPending() {
+	sn.pmu.Lock()
+	pending := sn.pending
+	sn.pending = nil
+	sn.pendingIDs = nil
+	sn.pmu.Unlock()
+	if sn.opts.events.ignoreCreate.Load() {
+		return
+	}
+	for _, pc := range pending {
+		if pc.renamed {
+			continue
+		}
+		// the digests are known since the hashing workers are done.
+		if v, ok := sn.paths.Load(pc.ev.Path); ok {
+			sn.withChecksum(&pc.ev, v.(*pathInfos).state().Checksum)
+		}
+		sn.queueEvent(pc.ev)
+	}
+}
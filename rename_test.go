@@ -0,0 +1,100 @@
+package gorsn
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRenamed(t *testing.T) {
+	sn, err := newSnotifier(t.TempDir(), defaultOpts(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sn.running.Store(true)
+	file := sysInfo{valid: true, dev: 1, ino: 7}
+	sn.addPending(Event{Path: "l1", Type: FILE, Name: CREATE}, file)
+	sn.addPending(Event{Path: "other", Type: FILE, Name: CREATE}, sysInfo{valid: true, dev: 1, ino: 8})
+	sn.addPending(Event{Path: "l2", Type: FILE, Name: CREATE}, file)
+	sn.addPending(Event{Path: "unknown", Type: FILE, Name: CREATE}, sysInfo{})
+
+	var got []string
+	for _, old := range []string{"a", "b", "c"} {
+		if ev, ok := sn.renamed(old, &pathInfos{sys: file}); ok {
+			got = append(got, ev.OldPath+">"+ev.Path)
+		}
+	}
+	if want := []string{"a>l1", "b>l2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got renames %q, want %q", got, want)
+	}
+	if _, ok := sn.renamed("d", &pathInfos{}); ok {
+		t.Error("got a rename matched without file identity")
+	}
+
+	sn.flushPending()
+	got = got[:0]
+	for _, ev := range pending(sn) {
+		got = append(got, ev.Path)
+	}
+	if want := []string{"other", "unknown"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got creates %q, want only the ones not renamed %q", got, want)
+	}
+	if len(sn.pendingIDs) != 0 {
+		t.Errorf("got pending creates %v indexed once flushed", sn.pendingIDs)
+	}
+}
+
+func TestTrackRenames(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, "1", "a", "b")
+	scanned := make(chan struct{})
+	opts := defaultOpts()
+	opts.Scan().SetInterval(5 * time.Millisecond).
+		SetBeforeScan(func(cycle int) {
+			if cycle != 2 {
+				return
+			}
+			for _, names := range [][2]string{{"a", "x"}, {"b", "y"}} {
+				if err := os.Rename(filepath.Join(dir, names[0]), filepath.Join(dir, names[1])); err != nil {
+					t.Error(err)
+				}
+			}
+			writeFiles(t, dir, "1", "z")
+		}).
+		SetAfterScan(func(cycle int, _ ScanSummary) {
+			if cycle == 2 {
+				close(scanned)
+			}
+		})
+	opts.Events().SetTrackRenames(true)
+	sn, err := New(dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sn.StartAsync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Stop()
+	wait(t, scanned, "second cycle")
+
+	got := make(map[string]string)
+	for {
+		select {
+		case ev := <-sn.Queue():
+			got[filepath.Base(ev.Path)] = string(ev.Name)
+			if ev.OldPath != "" {
+				got[filepath.Base(ev.Path)] += " " + filepath.Base(ev.OldPath)
+			}
+			continue
+		default:
+		}
+		break
+	}
+	want := map[string]string{"x": "RENAME a", "y": "RENAME b", "z": "CREATE"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got events %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,29 @@
+package gorsn
+
+import "fmt"
+
+// lose reports the root path as lost once. The cache is cleared without
+// `DELETE` events so the items are reported as created once restored.
+// The scan notifier is stopped if configured so.
+func (sn *snotifier) lose(err error) {
+	if sn.lost {
+		return
+	}
+	sn.lost = true
+	sn.flush()
+	lost := fmt.Errorf("%w: %v", ErrRootLost, err)
+	sn.queueEvent(Event{Path: sn.root, Type: sn.rootType(), Name: ROOT_LOST, Error: lost})
+	if sn.opts.events.stopOnRootLost.Load() {
+		sn.exit = lost
+		sn.halt()
+	}
+}
+
+// restore reports the root path as available again once lost.
+func (sn *snotifier) restore() {
+	if !sn.lost {
+		return
+	}
+	sn.lost = false
+	sn.queueEvent(Event{Path: sn.root, Type: sn.rootType(), Name: ROOT_RESTORED})
+}
@@ -0,0 +1,21 @@
+package gorsn
+
+import "io/fs"
+
+// rotateShrinkRatio is the factor by which a regular file replaced under the
+// same name must at least shrink to be reported as rotated.
+const rotateShrinkRatio = 2
+
+// rotated reports whether a known regular file was replaced under the same
+// name by another file at least `rotateShrinkRatio` times smaller, like a log
+// file renamed then recreated by logrotate.
+func (sn *snotifier) rotated(pt PathType, fi fs.FileInfo, pi *pathInfos) bool {
+	if pt != FILE || !sn.opts.events.detectRotation.Load() || pi.size <= 0 {
+		return false
+	}
+	sys := sysStat(fi)
+	if !sys.valid || !pi.sys.valid || sys.id() == pi.sys.id() {
+		return false
+	}
+	return fi.Size() <= pi.size/rotateShrinkRatio
+}
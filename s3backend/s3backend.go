@@ -0,0 +1,189 @@
+// Package s3backend provides a gorsn.Backend to poll the keys of an object
+// storage bucket such as Amazon S3, MinIO or Google Cloud Storage. Keys are
+// exposed as slash-separated paths where each common prefix is a directory,
+// so a bucket prefix could be monitored like a local folder with the same
+// events and options filters. The bucket root is named ".".
+//
+// The backend relies on the `Lister` interface so that this package does not
+// have to depend on any SDK. With the AWS SDK, a lister could be implemented
+// with a `ListObjectsV2` paginator using "/" as delimiter:
+//
+//	func (l *awsLister) List(ctx context.Context, prefix string) ([]s3backend.Object, []string, error) {
+//		var objs []s3backend.Object
+//		var prefixes []string
+//		p := s3.NewListObjectsV2Paginator(l.client, &s3.ListObjectsV2Input{
+//			Bucket: aws.String(l.bucket), Prefix: aws.String(prefix), Delimiter: aws.String("/"),
+//		})
+//		for p.HasMorePages() {
+//			page, err := p.NextPage(ctx)
+//			if err != nil {
+//				return nil, nil, err
+//			}
+//			for _, o := range page.Contents {
+//				objs = append(objs, s3backend.Object{Key: *o.Key, Size: *o.Size, LastModified: *o.LastModified})
+//			}
+//			for _, cp := range page.CommonPrefixes {
+//				prefixes = append(prefixes, *cp.Prefix)
+//			}
+//		}
+//		return objs, prefixes, nil
+//	}
+//
+// Since the SDK is only needed by the lister of the caller, this package is
+// kept into the gorsn module instead of a separate one without pulling the
+// AWS SDK into its dependency graph.
+package s3backend
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jeamon/gorsn"
+)
+
+// ErrReadNotSupported is returned when reading an object while the
+// client does not implement the `Getter` interface.
+var ErrReadNotSupported = errors.New("s3backend: client does not support reading objects")
+
+// Object describes a stored object.
+type Object struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+// Lister lists the objects and the common prefixes located directly
+// under `prefix` by using "/" as delimiter. Returned prefixes include
+// the trailing delimiter like `photos/2023/`.
+type Lister interface {
+	List(ctx context.Context, prefix string) (objects []Object, prefixes []string, err error)
+}
+
+// Getter is optionally implemented by clients which could read objects.
+// It is needed to enable the checksum option of the scan notifier.
+type Getter interface {
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+}
+
+type backend struct {
+	ctx    context.Context
+	client Lister
+}
+
+// New returns a gorsn.Backend which scans the bucket via `client`. The
+// context `ctx` is passed to each request made to the object storage.
+func New(ctx context.Context, client Lister) gorsn.Backend {
+	return &backend{ctx, client}
+}
+
+// prefix returns the listing prefix of the directory `name`.
+func prefix(name string) string {
+	if name == "." || name == "" {
+		return ""
+	}
+	return strings.TrimSuffix(name, "/") + "/"
+}
+
+// Stat looks for an object named `name` or for a common prefix
+// named `name/` into the listing of its parent directory.
+func (b *backend) Stat(name string) (fs.FileInfo, error) {
+	if name == "." || name == "" {
+		return &objectInfo{name: ".", dir: true}, nil
+	}
+	objs, prefixes, err := b.client.List(b.ctx, prefix(path.Dir(name)))
+	if err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: err}
+	}
+	for _, p := range prefixes {
+		if p == name+"/" {
+			return &objectInfo{name: path.Base(name), dir: true}, nil
+		}
+	}
+	for _, o := range objs {
+		if o.Key == name {
+			return &objectInfo{name: path.Base(name), size: o.Size, modTime: o.LastModified}, nil
+		}
+	}
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+// Lstat is same as Stat since object storages have no symlinks.
+func (b *backend) Lstat(name string) (fs.FileInfo, error) {
+	return b.Stat(name)
+}
+
+// ReadDir lists the objects and the sub-prefixes of the directory `name`.
+// Zero-length objects used as directory markers are skipped.
+func (b *backend) ReadDir(name string) ([]fs.DirEntry, error) {
+	pfx := prefix(name)
+	objs, prefixes, err := b.client.List(b.ctx, pfx)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: err}
+	}
+	entries := make([]fs.DirEntry, 0, len(objs)+len(prefixes))
+	for _, p := range prefixes {
+		base := strings.TrimSuffix(strings.TrimPrefix(p, pfx), "/")
+		if base == "" {
+			continue
+		}
+		entries = append(entries, fs.FileInfoToDirEntry(&objectInfo{name: base, dir: true}))
+	}
+	for _, o := range objs {
+		base := strings.TrimPrefix(o.Key, pfx)
+		if base == "" || strings.HasSuffix(base, "/") {
+			continue
+		}
+		entries = append(entries, fs.FileInfoToDirEntry(&objectInfo{name: base, size: o.Size, modTime: o.LastModified}))
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	return entries, nil
+}
+
+func (b *backend) Join(elem ...string) string {
+	return path.Join(elem...)
+}
+
+// Open reads the object `name` if the client implements `Getter`.
+func (b *backend) Open(name string) (io.ReadCloser, error) {
+	g, ok := b.client.(Getter)
+	if !ok {
+		return nil, ErrReadNotSupported
+	}
+	return g.Get(b.ctx, name)
+}
+
+// objectInfo implements fs.FileInfo for objects and common prefixes.
+type objectInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	dir     bool
+}
+
+func (oi *objectInfo) Name() string       { return oi.name }
+func (oi *objectInfo) Size() int64        { return oi.size }
+func (oi *objectInfo) ModTime() time.Time { return oi.modTime }
+func (oi *objectInfo) IsDir() bool        { return oi.dir }
+func (oi *objectInfo) Sys() any           { return nil }
+
+func (oi *objectInfo) Mode() fs.FileMode {
+	if oi.dir {
+		return fs.ModeDir | 0o755
+	}
+	return 0o644
+}
+
+// Capabilities reports that objects could be hashed only when the client
+// implements `Getter`. Listings expose times with a one second precision.
+func (b *backend) Capabilities() gorsn.Capabilities {
+	_, ok := b.client.(Getter)
+	return gorsn.Capabilities{Checksum: ok}
+}
@@ -0,0 +1,225 @@
+package s3backend
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jeamon/gorsn"
+)
+
+var errUnavailable = errors.New("service unavailable")
+
+// fakeBucket is a Lister of in-memory objects safe for concurrent use.
+type fakeBucket struct {
+	mu      sync.Mutex
+	objects map[string]Object
+	content map[string]string
+	err     error
+}
+
+func newFakeBucket(keys ...string) *fakeBucket {
+	b := &fakeBucket{objects: make(map[string]Object), content: make(map[string]string)}
+	for _, k := range keys {
+		b.put(k, k)
+	}
+	return b
+}
+
+func (b *fakeBucket) put(key, content string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.objects[key] = Object{Key: key, Size: int64(len(content)), LastModified: time.Now()}
+	b.content[key] = content
+}
+
+func (b *fakeBucket) remove(key string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.objects, key)
+	delete(b.content, key)
+}
+
+func (b *fakeBucket) List(_ context.Context, prefix string) ([]Object, []string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.err != nil {
+		return nil, nil, b.err
+	}
+	var objs []Object
+	seen := make(map[string]bool)
+	var prefixes []string
+	for key, o := range b.objects {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		rest := strings.TrimPrefix(key, prefix)
+		if i := strings.Index(rest, "/"); i >= 0 {
+			p := prefix + rest[:i+1]
+			if !seen[p] {
+				seen[p] = true
+				prefixes = append(prefixes, p)
+			}
+			continue
+		}
+		objs = append(objs, o)
+	}
+	return objs, prefixes, nil
+}
+
+// fakeReadableBucket is a fakeBucket whose objects could be read.
+type fakeReadableBucket struct {
+	*fakeBucket
+}
+
+func (b fakeReadableBucket) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	content, ok := b.content[key]
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+	return io.NopCloser(strings.NewReader(content)), nil
+}
+
+// open reads the object through the optional reading method of the backend.
+func open(b gorsn.Backend, name string) (io.ReadCloser, error) {
+	return b.(interface {
+		Open(name string) (io.ReadCloser, error)
+	}).Open(name)
+}
+
+// walk returns the paths found under `root` by reading its directories.
+func walk(t *testing.T, b gorsn.Backend, root string) []string {
+	t.Helper()
+	entries, err := b.ReadDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, d := range entries {
+		p := b.Join(root, d.Name())
+		paths = append(paths, p)
+		if d.IsDir() {
+			paths = append(paths, walk(t, b, p)...)
+		}
+	}
+	return paths
+}
+
+func TestBackend(t *testing.T) {
+	bucket := newFakeBucket("a.txt", "photos/2023/b.jpg", "photos/c.jpg", "photos/", "empty/")
+	b := New(context.Background(), bucket)
+
+	got := walk(t, b, ".")
+	want := []string{"a.txt", "empty", "photos", "photos/2023", "photos/2023/b.jpg", "photos/c.jpg"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got paths %q, want %q", got, want)
+	}
+	if !sort.StringsAreSorted(got[:3]) {
+		t.Errorf("got entries %q, want them sorted", got[:3])
+	}
+
+	for _, tt := range []struct {
+		name string
+		dir  bool
+		size int64
+	}{
+		{".", true, 0},
+		{"photos", true, 0},
+		{"photos/2023", true, 0},
+		{"photos/c.jpg", false, int64(len("photos/c.jpg"))},
+	} {
+		for op, stat := range map[string]func(string) (fs.FileInfo, error){"Stat": b.Stat, "Lstat": b.Lstat} {
+			fi, err := stat(tt.name)
+			if err != nil {
+				t.Errorf("%s(%q): %v", op, tt.name, err)
+				continue
+			}
+			if fi.IsDir() != tt.dir || fi.Size() != tt.size || fi.Mode().IsDir() != tt.dir {
+				t.Errorf("%s(%q) got dir %v and size %d, want %v and %d", op, tt.name, fi.IsDir(), fi.Size(), tt.dir, tt.size)
+			}
+		}
+	}
+}
+
+func TestBackendErrors(t *testing.T) {
+	bucket := newFakeBucket("a.txt")
+	b := New(context.Background(), bucket)
+	if _, err := b.Lstat("missing.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("got error %v for a missing key, want fs.ErrNotExist", err)
+	}
+	if _, err := open(b, "a.txt"); !errors.Is(err, ErrReadNotSupported) {
+		t.Errorf("got error %v reading without Getter, want ErrReadNotSupported", err)
+	}
+	if c, ok := b.(interface{ Capabilities() gorsn.Capabilities }); !ok || c.Capabilities().Checksum {
+		t.Error("got checksum capability without Getter")
+	}
+
+	bucket.err = errUnavailable
+	var perr *fs.PathError
+	if _, err := b.Stat("a.txt"); !errors.As(err, &perr) || perr.Op != "stat" || !errors.Is(err, errUnavailable) {
+		t.Errorf("got error %v from Stat, want a stat path error wrapping the client error", err)
+	}
+	if _, err := b.ReadDir("."); !errors.As(err, &perr) || perr.Op != "readdir" || !errors.Is(err, errUnavailable) {
+		t.Errorf("got error %v from ReadDir, want a readdir path error wrapping the client error", err)
+	}
+}
+
+func TestBackendOpen(t *testing.T) {
+	b := New(context.Background(), fakeReadableBucket{newFakeBucket("a.txt")})
+	r, err := open(b, "a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if data, err := io.ReadAll(r); err != nil || string(data) != "a.txt" {
+		t.Errorf("got content %q and error %v, want a.txt", data, err)
+	}
+	if _, err := open(b, "missing.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("got error %v for a missing key, want fs.ErrNotExist", err)
+	}
+	if c, ok := b.(interface{ Capabilities() gorsn.Capabilities }); !ok || !c.Capabilities().Checksum {
+		t.Error("got no checksum capability with Getter")
+	}
+}
+
+func TestBackendEvents(t *testing.T) {
+	bucket := newFakeBucket("in/a.txt", "in/b.txt")
+	opts := &gorsn.Options{}
+	opts.Scan().SetBackend(New(context.Background(), fakeReadableBucket{bucket})).
+		SetInterval(5 * time.Millisecond).SetChecksum(true)
+	sn, err := gorsn.New("in", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sn.StartAsync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Stop()
+
+	bucket.put("in/c.txt", "c")
+	bucket.put("in/a.txt", "changed")
+	bucket.remove("in/b.txt")
+	want := map[string]gorsn.EventName{"in/a.txt": gorsn.MODIFY, "in/b.txt": gorsn.DELETE, "in/c.txt": gorsn.CREATE}
+	got := make(map[string]gorsn.EventName)
+	timeout := time.After(5 * time.Second)
+	for len(got) < len(want) {
+		select {
+		case ev := <-sn.Queue():
+			got[ev.Path] = ev.Name
+		case <-timeout:
+			t.Fatalf("got events %v, want %v", got, want)
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got events %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,27 @@
+package gorsn
+
+// markChanging flags a regular file as being changed so a `STABLE`
+// event is emitted once it stays unchanged long enough.
+func (sn *snotifier) markChanging(pi *pathInfos, pt PathType) {
+	if pt != FILE || sn.opts.events.settleCycles.Load() == 0 {
+		return
+	}
+	pi.settling = true
+	pi.quiet = 0
+}
+
+// checkSettled counts the consecutive scans during which a changing file
+// kept the same size and modification time and emits a `STABLE` event once
+// the configured number of cycles is reached.
+func (sn *snotifier) checkSettled(path string, pi *pathInfos, pt PathType) {
+	n := sn.opts.events.settleCycles.Load()
+	if !pi.settling || n == 0 {
+		return
+	}
+	pi.quiet++
+	if pi.quiet < n {
+		return
+	}
+	pi.settling = false
+	sn.queueEvent(Event{Path: path, Type: pt, Name: STABLE})
+}
@@ -0,0 +1,56 @@
+package gorsn
+
+// Severity classifies the events so they could be routed into logging
+// systems. Severities are ordered so `ev.Severity >= SEVERITY_WARNING`
+// selects the warnings and the errors.
+type Severity int
+
+const (
+	// SEVERITY_INFO is the severity of the regular changes.
+	SEVERITY_INFO Severity = iota
+	// SEVERITY_WARNING is the severity of the changes which deserve some
+	// attention such as permissions or ownership changes.
+	SEVERITY_WARNING
+	// SEVERITY_ERROR is the severity of the failures.
+	SEVERITY_ERROR
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SEVERITY_INFO:
+		return "INFO"
+	case SEVERITY_WARNING:
+		return "WARNING"
+	case SEVERITY_ERROR:
+		return "ERROR"
+	}
+	return "UNKNOWN"
+}
+
+// DefaultSeverity is the default classification of the events. `ERROR` and
+// `ROOT_LOST` events are errors. `PERM`, `OWNER`, `ANOMALY`, `SIZE_THRESHOLD`,
+// `BUDGET_EXCEEDED`, `OVERFLOW` and `LOCKED` events are warnings. Others are
+// infos.
+func DefaultSeverity(ev Event) Severity {
+	switch ev.Name {
+	case ERROR, ROOT_LOST:
+		return SEVERITY_ERROR
+	case PERM, OWNER, ANOMALY, SIZE_THRESHOLD, BUDGET_EXCEEDED, OVERFLOW, LOCKED:
+		return SEVERITY_WARNING
+	}
+	return SEVERITY_INFO
+}
+
+// classify sets the severity of the event.
+func (sn *snotifier) classify(ev Event) Event {
+	ev.Severity = sn.opts.events.severity()(ev)
+	return ev
+}
+
+// severity returns the configured classification of the events.
+func (eo *EventOptions) severity() func(Event) Severity {
+	if fn, ok := eo.classify.Load().(func(Event) Severity); ok && fn != nil {
+		return fn
+	}
+	return DefaultSeverity
+}
@@ -0,0 +1,77 @@
+// Package sftpbackend provides a gorsn.Backend to poll a directory located on
+// a remote SFTP server. It relies on the `Client` interface which is satisfied
+// by `*sftp.Client` from github.com/pkg/sftp, so that this package does not
+// have to depend on any SFTP implementation.
+//
+//	conn, _ := ssh.Dial("tcp", "host:22", config)
+//	client, _ := sftp.NewClient(conn)
+//	opts := &gorsn.Options{}
+//	opts.Scan().SetBackend(sftpbackend.New(client))
+//	sn, err := gorsn.New("/remote/drops", opts)
+//
+// It only imports the standard library and gorsn, so it is a package of the
+// gorsn module rather than a module of its own: importing it does not add
+// github.com/pkg/sftp or golang.org/x/crypto/ssh to the dependencies of the
+// gorsn module. Only the programs which create the client depend on them.
+package sftpbackend
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+
+	"github.com/jeamon/gorsn"
+)
+
+// Client defines the remote file system operations needed by the backend.
+type Client interface {
+	Stat(p string) (os.FileInfo, error)
+	Lstat(p string) (os.FileInfo, error)
+	ReadDir(p string) ([]os.FileInfo, error)
+}
+
+type backend struct {
+	client Client
+}
+
+// New returns a gorsn.Backend which scans the remote file system via `client`.
+// Paths are slash-separated like the ones handled by SFTP servers.
+func New(client Client) gorsn.Backend {
+	return &backend{client}
+}
+
+func (b *backend) Stat(name string) (fs.FileInfo, error) {
+	return b.client.Stat(name)
+}
+
+func (b *backend) Lstat(name string) (fs.FileInfo, error) {
+	return b.client.Lstat(name)
+}
+
+// ReadDir lists the remote directory and sorts its entries by filename
+// since servers do not guarantee any order.
+func (b *backend) ReadDir(name string) ([]fs.DirEntry, error) {
+	infos, err := b.client.ReadDir(name)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]fs.DirEntry, 0, len(infos))
+	for _, fi := range infos {
+		entries = append(entries, fs.FileInfoToDirEntry(fi))
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	return entries, nil
+}
+
+func (b *backend) Join(elem ...string) string {
+	return path.Join(elem...)
+}
+
+// Capabilities reports that SFTP v3 only exposes modification times
+// with a precision of one second and no files identity.
+func (b *backend) Capabilities() gorsn.Capabilities {
+	return gorsn.Capabilities{}
+}
@@ -0,0 +1,219 @@
+package sftpbackend
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jeamon/gorsn"
+)
+
+var errConnLost = errors.New("connection lost")
+
+// fakeInfo describes a remote file.
+type fakeInfo struct {
+	name    string
+	size    int64
+	dir     bool
+	modTime time.Time
+}
+
+func (fi fakeInfo) Name() string       { return fi.name }
+func (fi fakeInfo) Size() int64        { return fi.size }
+func (fi fakeInfo) ModTime() time.Time { return fi.modTime }
+func (fi fakeInfo) IsDir() bool        { return fi.dir }
+func (fi fakeInfo) Sys() any           { return nil }
+
+func (fi fakeInfo) Mode() fs.FileMode {
+	if fi.dir {
+		return fs.ModeDir | 0o755
+	}
+	return 0o644
+}
+
+// fakeServer is a Client of an in-memory remote tree safe for concurrent use.
+// Like SFTP servers, it lists the directories in no particular order and
+// reports the missing files with an error which wraps `os.ErrNotExist`.
+type fakeServer struct {
+	mu    sync.Mutex
+	files map[string]fakeInfo
+	err   error
+}
+
+func newFakeServer(dirs []string, files ...string) *fakeServer {
+	s := &fakeServer{files: map[string]fakeInfo{"/": {name: "/", dir: true}}}
+	for _, d := range dirs {
+		s.files[d] = fakeInfo{name: path.Base(d), dir: true}
+	}
+	for _, f := range files {
+		s.put(f, 1)
+	}
+	return s
+}
+
+func (s *fakeServer) put(name string, size int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[name] = fakeInfo{name: path.Base(name), size: size, modTime: time.Now()}
+}
+
+func (s *fakeServer) remove(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.files, name)
+}
+
+func (s *fakeServer) Stat(p string) (os.FileInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return nil, s.err
+	}
+	fi, ok := s.files[p]
+	if !ok {
+		return nil, &fs.PathError{Op: "stat", Path: p, Err: os.ErrNotExist}
+	}
+	return fi, nil
+}
+
+func (s *fakeServer) Lstat(p string) (os.FileInfo, error) {
+	return s.Stat(p)
+}
+
+func (s *fakeServer) ReadDir(p string) ([]os.FileInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return nil, s.err
+	}
+	var infos []os.FileInfo
+	for name, fi := range s.files {
+		if name != "/" && path.Dir(name) == p {
+			infos = append(infos, fi)
+		}
+	}
+	return infos, nil
+}
+
+// walk returns the paths found under `root` by reading its directories.
+func walk(t *testing.T, b gorsn.Backend, root string) []string {
+	t.Helper()
+	entries, err := b.ReadDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, d := range entries {
+		p := b.Join(root, d.Name())
+		paths = append(paths, p)
+		if d.IsDir() {
+			paths = append(paths, walk(t, b, p)...)
+		}
+	}
+	return paths
+}
+
+func TestBackend(t *testing.T) {
+	server := newFakeServer([]string{"/drops", "/drops/sub"}, "/drops/c.csv", "/drops/a.csv", "/drops/sub/b.csv")
+	b := New(server)
+
+	got := walk(t, b, "/drops")
+	want := []string{"/drops/a.csv", "/drops/c.csv", "/drops/sub", "/drops/sub/b.csv"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got paths %q, want %q", got, want)
+	}
+	entries, err := b.ReadDir("/drops")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sort.SliceIsSorted(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() }) {
+		t.Error("got entries not sorted by name")
+	}
+
+	for op, stat := range map[string]func(string) (fs.FileInfo, error){"Stat": b.Stat, "Lstat": b.Lstat} {
+		if fi, err := stat("/drops/sub"); err != nil || !fi.IsDir() {
+			t.Errorf("%s got %v and error %v, want a directory", op, fi, err)
+		}
+		if fi, err := stat("/drops/a.csv"); err != nil || fi.IsDir() || fi.Size() != 1 {
+			t.Errorf("%s got %v and error %v, want a file of one byte", op, fi, err)
+		}
+	}
+	if got := b.Join("/drops", "sub", "b.csv"); got != "/drops/sub/b.csv" {
+		t.Errorf("got joined path %q, want a slash-separated one", got)
+	}
+}
+
+func TestBackendErrors(t *testing.T) {
+	server := newFakeServer(nil)
+	b := New(server)
+	if _, err := b.Lstat("/missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("got error %v for a missing file, want fs.ErrNotExist", err)
+	}
+	// the backend does not read the files so the checksum is not supported.
+	if _, ok := b.(interface {
+		Open(string) (io.ReadCloser, error)
+	}); ok {
+		t.Error("got a backend able to read the files")
+	}
+	opts := &gorsn.Options{}
+	opts.Scan().SetBackend(b).SetChecksum(true)
+	if _, err := gorsn.New("/", opts); !errors.Is(err, gorsn.ErrInvalidOptions) {
+		t.Errorf("got error %v with the checksum enabled, want ErrInvalidOptions", err)
+	}
+
+	server.err = errConnLost
+	if _, err := b.Stat("/"); !errors.Is(err, errConnLost) {
+		t.Errorf("got error %v from Stat, want the client error", err)
+	}
+	if _, err := b.ReadDir("/"); !errors.Is(err, errConnLost) {
+		t.Errorf("got error %v from ReadDir, want the client error", err)
+	}
+}
+
+func TestBackendEvents(t *testing.T) {
+	server := newFakeServer([]string{"/drops"}, "/drops/a.csv", "/drops/b.csv")
+	opts := &gorsn.Options{}
+	opts.Scan().SetBackend(New(server)).SetInterval(5 * time.Millisecond)
+	sn, err := gorsn.New("/drops", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sn.StartAsync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Stop()
+
+	server.put("/drops/c.csv", 1)
+	server.put("/drops/a.csv", 2)
+	server.remove("/drops/b.csv")
+	want := map[string]gorsn.EventName{"/drops/a.csv": gorsn.MODIFY, "/drops/b.csv": gorsn.DELETE, "/drops/c.csv": gorsn.CREATE}
+	got := make(map[string]gorsn.EventName)
+	timeout := time.After(5 * time.Second)
+	for len(got) < len(want) {
+		select {
+		case ev := <-sn.Queue():
+			got[ev.Path] = ev.Name
+		case <-timeout:
+			t.Fatalf("got events %v, want %v", got, want)
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got events %v, want %v", got, want)
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	b := New(newFakeServer(nil))
+	c, ok := b.(interface{ Capabilities() gorsn.Capabilities })
+	if !ok || !reflect.DeepEqual(c.Capabilities(), gorsn.Capabilities{}) {
+		t.Error("got capabilities beyond the SFTP v3 ones")
+	}
+}
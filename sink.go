@@ -0,0 +1,34 @@
+package gorsn
+
+import "fmt"
+
+// Sink is a destination to which each emitted event is written in addition
+// to the queue. Write is called from the notifier routines so implementations
+// must be safe for concurrent use and are expected to return quickly.
+type Sink interface {
+	Write(Event) error
+}
+
+// sinks returns the current list of registered sinks.
+func (do *DeliveryOptions) sinks() []Sink {
+	sinks, _ := do.esinks.Load().([]Sink)
+	return sinks
+}
+
+// sink writes the event to all registered sinks. It returns the `ERROR`
+// events of the failures which are not written to the sinks, to avoid loops.
+func (sn *snotifier) sink(ev Event) []Event {
+	var failures []Event
+	for _, s := range sn.opts.delivery.sinks() {
+		err := s.Write(ev)
+		if err == nil || sn.opts.events.ignoreErrors.Load() {
+			continue
+		}
+		failure := Event{
+			Path: ev.Path, Type: ev.Type, Name: ERROR, Error: fmt.Errorf("%w: %v", ErrSinkFailure, err),
+			Time: sn.now(), Cycle: ev.Cycle, Root: ev.Root, RootLabel: ev.RootLabel,
+		}
+		failures = append(failures, sn.tag(sn.classify(failure)))
+	}
+	return failures
+}
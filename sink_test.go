@@ -0,0 +1,59 @@
+package gorsn
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// failingSink is a sink which fails to write any event.
+type failingSink struct{}
+
+func (failingSink) Write(Event) error {
+	return errors.New("unavailable")
+}
+
+func TestSinkFailure(t *testing.T) {
+	dir := t.TempDir()
+	var sn ScanNotifier
+	done := make(chan struct{})
+	opts := defaultOpts()
+	opts.Delivery().SetQueueSize(8).SetHistorySize(8).SetSubscriptionsOnly(true).AddSink(failingSink{})
+	opts.Scan().SetInterval(5 * time.Millisecond).
+		SetBeforeScan(func(cycle int) {
+			if cycle == 2 {
+				writeFiles(t, dir, "1", "a", "b", "c")
+			}
+		}).
+		SetAfterScan(func(cycle int, _ ScanSummary) {
+			if cycle == 2 {
+				sn.Pause()
+				close(done)
+			}
+		})
+	sn, err := New(dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs := sn.QueueFor(ERROR)
+	if err := sn.StartAsync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Stop()
+
+	wait(t, done, "scan cycle")
+	for i := 0; i < 3; i++ {
+		select {
+		case ev := <-errs:
+			if !errors.Is(ev.Error, ErrSinkFailure) {
+				t.Fatalf("got error %v, want ErrSinkFailure", ev.Error)
+			}
+		default:
+			t.Fatalf("got %d sink failures, want 3", i)
+		}
+	}
+	if n := len(sn.History(time.Time{}, FilterNames(ERROR))); n != 3 {
+		t.Errorf("got %d sink failures into the history, want 3", n)
+	}
+}
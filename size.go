@@ -0,0 +1,79 @@
+package gorsn
+
+import (
+	"fmt"
+	"io/fs"
+	"sort"
+)
+
+// sizeThresholds returns the byte limits of the supervised directories
+// keyed by their path relative to the root directory.
+func (eo *EventOptions) sizeThresholds() map[string]int64 {
+	limits, _ := eo.sizeLimits.Load().(map[string]int64)
+	return limits
+}
+
+// checkSizes sums the size of the regular files under each supervised
+// directory and emits a `SIZE_THRESHOLD` event for each directory whose
+// size went over its limit since the previous scan cycle. The event is
+// emitted again once the size went below then over the limit again.
+func (sn *snotifier) checkSizes() {
+	limits := sn.opts.events.sizeThresholds()
+	if len(limits) == 0 {
+		sn.oversized = nil
+		return
+	}
+	dirs := make([]string, 0, len(limits))
+	for dir := range limits {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	sizes := make(map[string]int64, len(dirs))
+	add := func(path string, mode fs.FileMode, size int64) {
+		if !mode.IsRegular() {
+			return
+		}
+		rel := sn.rel(path)
+		for _, dir := range dirs {
+			if dir == "." || isUnder(rel, []string{dir}) {
+				sizes[dir] += size
+			}
+		}
+	}
+	sn.paths.Range(func(key, value any) bool {
+		pi := value.(*pathInfos)
+		add(key.(string), pi.mode, pi.size)
+		return true
+	})
+	if sn.lookup != nil {
+		// the files not cached are only stored.
+		err := sn.lookup.Range(sn.root, func(path string, ps PathState) bool {
+			_, cached := sn.paths.Load(path)
+			if _, removed := sn.dirty.Load(path); !cached && !removed {
+				add(path, ps.Mode, ps.Size)
+			}
+			return true
+		})
+		if err != nil {
+			sn.storeFailed(err)
+			return
+		}
+	}
+	over := make(map[string]bool, len(dirs))
+	for _, dir := range dirs {
+		if sizes[dir] <= limits[dir] {
+			continue
+		}
+		over[dir] = true
+		if sn.oversized[dir] {
+			continue
+		}
+		p := sn.root
+		if dir != "." {
+			p = sn.opts.scan.backend.Join(sn.root, dir)
+		}
+		err := fmt.Errorf("%w: size of %d bytes over the limit of %d bytes", ErrSizeThreshold, sizes[dir], limits[dir])
+		sn.queueEvent(Event{Path: p, Type: DIR, Name: SIZE_THRESHOLD, Error: err})
+	}
+	sn.oversized = over
+}
@@ -0,0 +1,240 @@
+package gorsn
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SQLDialect defines the SQL flavor of the database of the SQL sink.
+type SQLDialect uint32
+
+const (
+	SQL_POSTGRES SQLDialect = iota
+	SQL_SQLITE
+)
+
+const (
+	// DEFAULT_SQL_TABLE is the default name of the events table.
+	DEFAULT_SQL_TABLE = "gorsn_events"
+
+	// DEFAULT_SQL_BATCH_SIZE is the default number of events inserted at once.
+	DEFAULT_SQL_BATCH_SIZE = 100
+
+	// DEFAULT_SQL_FLUSH_INTERVAL is the default maximum time an event waits
+	// before being inserted.
+	DEFAULT_SQL_FLUSH_INTERVAL = time.Second
+
+	// DEFAULT_SQL_RETRIES is the default number of retries of a failed batch.
+	DEFAULT_SQL_RETRIES = 3
+)
+
+// sqlTableName restricts the table names to plain identifiers since they
+// could not be passed as query parameters.
+var sqlTableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// SQLSchema returns the statement which creates the events table expected by
+// the SQL sink for the dialect. An empty table name falls back to
+// `DEFAULT_SQL_TABLE`.
+func SQLSchema(dialect SQLDialect, table string) string {
+	if table == "" {
+		table = DEFAULT_SQL_TABLE
+	}
+	id := "BIGSERIAL PRIMARY KEY"
+	ts := "TIMESTAMPTZ"
+	if dialect == SQL_SQLITE {
+		id = "INTEGER PRIMARY KEY AUTOINCREMENT"
+		ts = "TIMESTAMP"
+	}
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %[1]s (
+	id %[2]s,
+	detected_at %[3]s NOT NULL,
+	event TEXT NOT NULL,
+	type TEXT NOT NULL,
+	path TEXT NOT NULL,
+	old_path TEXT,
+	error TEXT,
+	severity TEXT NOT NULL,
+	cycle INTEGER NOT NULL,
+	tags TEXT
+);
+CREATE INDEX IF NOT EXISTS %[4]s_detected_at ON %[1]s (detected_at);
+CREATE INDEX IF NOT EXISTS %[4]s_path ON %[1]s (path);`, table, id, ts, strings.ReplaceAll(table, ".", "_"))
+}
+
+// SQLConfig defines the database and the batching of the SQL sink.
+type SQLConfig struct {
+	DB      *sql.DB
+	Dialect SQLDialect
+	Table   string // default to `DEFAULT_SQL_TABLE`.
+	// Filter selects the events to insert. A nil filter selects all of them.
+	Filter Filter
+	// BatchSize, FlushInterval and Retries fall back to their default value
+	// if zero. Each event takes 9 parameters of the insert statement, which
+	// SQLite limits to 999 before its version 3.32.
+	BatchSize     int
+	FlushInterval time.Duration
+	Retries       int
+}
+
+// SQLSink is a Sink which inserts the events into a database table.
+type SQLSink struct {
+	cfg    SQLConfig
+	insert string
+	events chan Event
+	done   chan struct{}
+	once   sync.Once
+
+	mu  sync.Mutex
+	err error // failure of the latest batch, reported by the next write.
+}
+
+// NewSQLSink returns a Sink which inserts the events selected by the filter
+// into the table created by `SQLSchema`, by batches of `BatchSize` events or
+// every `FlushInterval`. A failed batch is retried with a growing delay then
+// dropped and its failure is returned by the next write, so it is reported by
+// an `ERROR` event. Call `Close` once the scan notifier stopped to insert the
+// pending events. It returns an error which wraps `ErrInvalidOptions` for an
+// invalid table name.
+func NewSQLSink(cfg SQLConfig) (*SQLSink, error) {
+	if cfg.Table == "" {
+		cfg.Table = DEFAULT_SQL_TABLE
+	}
+	if !sqlTableName.MatchString(cfg.Table) {
+		return nil, fmt.Errorf("%w: invalid table name %q", ErrInvalidOptions, cfg.Table)
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DEFAULT_SQL_BATCH_SIZE
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = DEFAULT_SQL_FLUSH_INTERVAL
+	}
+	if cfg.Retries <= 0 {
+		cfg.Retries = DEFAULT_SQL_RETRIES
+	}
+	s := &SQLSink{
+		cfg:    cfg,
+		insert: fmt.Sprintf("INSERT INTO %s (detected_at, event, type, path, old_path, error, severity, cycle, tags) VALUES ", cfg.Table),
+		events: make(chan Event, 4*cfg.BatchSize),
+		done:   make(chan struct{}),
+	}
+	go s.run()
+	return s, nil
+}
+
+func (s *SQLSink) Write(ev Event) error {
+	s.mu.Lock()
+	err := s.err
+	s.err = nil
+	s.mu.Unlock()
+	if s.cfg.Filter != nil && !s.cfg.Filter(ev) {
+		return err
+	}
+	select {
+	case s.events <- ev:
+		return err
+	default:
+		return fmt.Errorf("sql sink backlog full, event of %q dropped", ev.Path)
+	}
+}
+
+// Close inserts the pending events then stops the sink. Writes after Close
+// must not happen.
+func (s *SQLSink) Close() error {
+	s.once.Do(func() { close(s.events) })
+	<-s.done
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// run collects the events into batches until the sink is closed.
+func (s *SQLSink) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.cfg.FlushInterval)
+	defer ticker.Stop()
+	batch := make([]Event, 0, s.cfg.BatchSize)
+	for {
+		select {
+		case ev, ok := <-s.events:
+			if !ok {
+				s.flush(batch)
+				return
+			}
+			batch = append(batch, ev)
+			if len(batch) < s.cfg.BatchSize {
+				continue
+			}
+		case <-ticker.C:
+		}
+		s.flush(batch)
+		batch = batch[:0]
+	}
+}
+
+// flush inserts the batch, with retries.
+func (s *SQLSink) flush(batch []Event) {
+	if len(batch) == 0 {
+		return
+	}
+	var err error
+	for i := 0; i <= s.cfg.Retries; i++ {
+		if i > 0 {
+			time.Sleep(time.Duration(i) * s.cfg.FlushInterval)
+		}
+		if err = s.exec(batch); err == nil {
+			return
+		}
+	}
+	s.mu.Lock()
+	s.err = fmt.Errorf("sql sink: %d events dropped: %w", len(batch), err)
+	s.mu.Unlock()
+}
+
+// exec inserts the batch with a single statement.
+func (s *SQLSink) exec(batch []Event) error {
+	var b strings.Builder
+	b.WriteString(s.insert)
+	args := make([]any, 0, 9*len(batch))
+	for i, ev := range batch {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString("(")
+		for j := 0; j < 9; j++ {
+			if j > 0 {
+				b.WriteString(", ")
+			}
+			if s.cfg.Dialect == SQL_POSTGRES {
+				fmt.Fprintf(&b, "$%d", len(args)+j+1)
+			} else {
+				b.WriteString("?")
+			}
+		}
+		b.WriteString(")")
+		var oldPath, errText, tags sql.NullString
+		if ev.OldPath != "" {
+			oldPath = sql.NullString{String: ev.OldPath, Valid: true}
+		}
+		if ev.Error != nil {
+			errText = sql.NullString{String: ev.Error.Error(), Valid: true}
+		}
+		if len(ev.Tags) > 0 {
+			t, err := json.Marshal(ev.Tags)
+			if err != nil {
+				return err
+			}
+			tags = sql.NullString{String: string(t), Valid: true}
+		}
+		args = append(args, ev.Time.UTC(), string(ev.Name), string(ev.Type), ev.Path, oldPath, errText, ev.Severity.String(), ev.Cycle, tags)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*s.cfg.FlushInterval)
+	defer cancel()
+	_, err := s.cfg.DB.ExecContext(ctx, b.String(), args...)
+	return err
+}
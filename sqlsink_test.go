@@ -0,0 +1,240 @@
+package gorsn
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeDB is a database/sql driver which records the executed statements
+// and fails the first `fails` ones.
+type fakeDB struct {
+	mu      sync.Mutex
+	fails   int
+	block   chan struct{} // if not nil, the statements wait for it.
+	blocked chan struct{} // receives once a statement waits.
+	queries []string
+	args    [][]driver.Value
+}
+
+type fakeConn struct{ db *fakeDB }
+
+func (db *fakeDB) Connect(context.Context) (driver.Conn, error) { return fakeConn{db}, nil }
+func (db *fakeDB) Driver() driver.Driver                        { return nil }
+
+func (c fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c fakeConn) Close() error                        { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if c.db.block != nil {
+		select {
+		case c.db.blocked <- struct{}{}:
+		default:
+		}
+		<-c.db.block
+	}
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	if c.db.fails > 0 {
+		c.db.fails--
+		return nil, errors.New("connection reset")
+	}
+	values := make([]driver.Value, len(args))
+	for i, a := range args {
+		values[i] = a.Value
+	}
+	c.db.queries = append(c.db.queries, query)
+	c.db.args = append(c.db.args, values)
+	return driver.RowsAffected(len(args) / 9), nil
+}
+
+// inserted returns the number of rows of each recorded statement.
+func (db *fakeDB) inserted() []int {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	rows := make([]int, len(db.args))
+	for i, args := range db.args {
+		rows[i] = len(args) / 9
+	}
+	return rows
+}
+
+func newTestSQLSink(t *testing.T, db *fakeDB, cfg SQLConfig) *SQLSink {
+	t.Helper()
+	cfg.DB = sql.OpenDB(db)
+	t.Cleanup(func() { cfg.DB.Close() })
+	s, err := NewSQLSink(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestSQLSinkBatches(t *testing.T) {
+	db := &fakeDB{}
+	s := newTestSQLSink(t, db, SQLConfig{BatchSize: 3, FlushInterval: time.Hour})
+	for i := 0; i < 7; i++ {
+		if err := s.Write(Event{Path: "/root/a", Type: FILE, Name: MODIFY}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for deadline := time.Now().Add(5 * time.Second); len(db.inserted()) < 2; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("got batches %v, want 2 full batches before closing", db.inserted())
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := db.inserted(); !reflect.DeepEqual(got, []int{3, 3, 1}) {
+		t.Errorf("got batches %v, want the full ones then the rest on close", got)
+	}
+}
+
+func TestSQLSinkFlushInterval(t *testing.T) {
+	db := &fakeDB{}
+	s := newTestSQLSink(t, db, SQLConfig{FlushInterval: 5 * time.Millisecond})
+	defer s.Close()
+	if err := s.Write(Event{Path: "/root/a", Type: FILE, Name: CREATE}); err != nil {
+		t.Fatal(err)
+	}
+	for deadline := time.Now().Add(5 * time.Second); len(db.inserted()) == 0; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the periodic insert")
+		}
+	}
+}
+
+func TestSQLSinkStatement(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.FixedZone("x", 3600))
+	events := []Event{
+		{Path: "/root/b", OldPath: "/root/a", Type: FILE, Name: RENAME, Time: at, Cycle: 2},
+		{Path: "/root", Type: DIR, Name: ERROR, Error: errors.New("denied"), Severity: SEVERITY_ERROR, Tags: []string{"x", "y"}, Time: at, Cycle: 3},
+	}
+	tests := []struct {
+		dialect SQLDialect
+		values  string
+	}{
+		{SQL_POSTGRES, "($1, $2, $3, $4, $5, $6, $7, $8, $9), ($10, $11, $12, $13, $14, $15, $16, $17, $18)"},
+		{SQL_SQLITE, "(?, ?, ?, ?, ?, ?, ?, ?, ?), (?, ?, ?, ?, ?, ?, ?, ?, ?)"},
+	}
+	for _, tt := range tests {
+		db := &fakeDB{}
+		s := newTestSQLSink(t, db, SQLConfig{Dialect: tt.dialect, Table: "audit.events", BatchSize: 2})
+		for _, ev := range events {
+			if err := s.Write(ev); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := s.Close(); err != nil {
+			t.Fatal(err)
+		}
+		want := "INSERT INTO audit.events (detected_at, event, type, path, old_path, error, severity, cycle, tags) VALUES " + tt.values
+		if len(db.queries) != 1 || db.queries[0] != want {
+			t.Fatalf("got statements %q, want %q", db.queries, want)
+		}
+		wantArgs := []driver.Value{
+			at.UTC(), "RENAME", "FILE", "/root/b", "/root/a", nil, SEVERITY_INFO.String(), int64(2), nil,
+			at.UTC(), "ERROR", "DIRECTORY", "/root", nil, "denied", "ERROR", int64(3), `["x","y"]`,
+		}
+		if !reflect.DeepEqual(db.args[0], wantArgs) {
+			t.Errorf("got arguments %v, want %v", db.args[0], wantArgs)
+		}
+	}
+}
+
+func TestSQLSinkRetries(t *testing.T) {
+	db := &fakeDB{fails: 2}
+	s := newTestSQLSink(t, db, SQLConfig{BatchSize: 1, FlushInterval: time.Millisecond, Retries: 2})
+	if err := s.Write(Event{Path: "/root/a", Type: FILE, Name: CREATE}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("got error %v once inserted on the last retry", err)
+	}
+	if got := db.inserted(); !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("got batches %v, want the retried one", got)
+	}
+
+	db = &fakeDB{fails: 3}
+	s = newTestSQLSink(t, db, SQLConfig{BatchSize: 1, FlushInterval: time.Millisecond, Retries: 2})
+	defer s.Close()
+	if err := s.Write(Event{Path: "/root/a", Type: FILE, Name: CREATE}); err != nil {
+		t.Fatal(err)
+	}
+	// the failure is reported by the next write.
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) {
+		err := s.Write(Event{Path: "/root/b", Type: FILE, Name: CREATE})
+		if err != nil {
+			if !strings.Contains(err.Error(), "1 events dropped") || !strings.Contains(err.Error(), "connection reset") {
+				t.Errorf("got error %q, want the dropped batch and its cause", err)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the failure of the batch")
+		}
+	}
+}
+
+func TestSQLSinkBacklogFull(t *testing.T) {
+	db := &fakeDB{block: make(chan struct{}), blocked: make(chan struct{}, 1)}
+	s := newTestSQLSink(t, db, SQLConfig{BatchSize: 1, FlushInterval: time.Hour})
+	if err := s.Write(Event{Path: "/root/a", Type: FILE, Name: CREATE}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-db.blocked:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the insert")
+	}
+	// the first event is stuck into the database and the next ones fill the backlog.
+	var err error
+	for i := 0; i < 4; i++ {
+		if err = s.Write(Event{Path: "/root/a", Type: FILE, Name: CREATE}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = s.Write(Event{Path: "/root/a", Type: FILE, Name: CREATE})
+	if err == nil || !strings.Contains(err.Error(), "backlog full") {
+		t.Errorf("got error %v, want the backlog full", err)
+	}
+	close(db.block)
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := db.inserted(); len(got) != 5 {
+		t.Errorf("got batches %v, want the blocked one and the 4 of the backlog", got)
+	}
+}
+
+func TestSQLSinkFilter(t *testing.T) {
+	db := &fakeDB{}
+	s := newTestSQLSink(t, db, SQLConfig{Filter: func(ev Event) bool { return ev.Name == DELETE }})
+	for _, name := range []EventName{CREATE, DELETE, MODIFY} {
+		if err := s.Write(Event{Path: "/root/a", Type: FILE, Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(db.args) != 1 || len(db.args[0]) != 9 || db.args[0][1] != "DELETE" {
+		t.Errorf("got arguments %v, want only the DELETE event", db.args)
+	}
+}
+
+func TestNewSQLSinkInvalidTable(t *testing.T) {
+	for _, table := range []string{"events; DROP TABLE x", "a.b.c", "1events"} {
+		if _, err := NewSQLSink(SQLConfig{Table: table}); !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("got error %v for table %q, want ErrInvalidOptions", err, table)
+		}
+	}
+}
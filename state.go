@@ -0,0 +1,94 @@
+package gorsn
+
+import (
+	"io/fs"
+	"time"
+)
+
+// PathState describes the latest known state of a monitored item.
+type PathState struct {
+	ModTime  time.Time
+	Mode     fs.FileMode
+	Size     int64
+	Checksum string
+	// ChangeTime is the inode change time where available.
+	ChangeTime time.Time
+}
+
+// State is a point-in-time copy of the items under monitoring by a
+// scan notifier. It could be exported from a live instance and used
+// to seed a new instance via `NewFromState`.
+type State struct {
+	Schema int
+	Root   string
+	Paths  map[string]PathState
+}
+
+// Export returns a copy of the current internal cache history. The items not
+// cached into memory, see `SetMaxCachedPaths`, are read from the store. They
+// are missing if the store failed.
+func (sn *snotifier) Export() *State {
+	st := &State{Schema: SchemaVersion, Root: sn.root, Paths: make(map[string]PathState)}
+	sn.paths.Range(func(key, value any) bool {
+		pi := value.(*pathInfos)
+		st.Paths[key.(string)] = pi.state()
+		return true
+	})
+	if sn.lookup != nil {
+		sn.lookup.Range(sn.root, func(path string, ps PathState) bool {
+			if _, cached := st.Paths[path]; cached {
+				return true
+			}
+			if _, removed := sn.dirty.Load(path); !removed {
+				st.Paths[path] = ps
+			}
+			return true
+		})
+	}
+	return st
+}
+
+// seedInfos returns the imported infos of a given path if any.
+func (sn *snotifier) seedInfos(s string) (*pathInfos, bool) {
+	if sn.seed == nil {
+		return nil, false
+	}
+	ps, ok := sn.seed.Paths[s]
+	if !ok {
+		return nil, false
+	}
+	return statePathInfos(ps, sn.seed.Schema), true
+}
+
+// statePathInfos builds the infos of an imported or stored path. The states
+// of schema 1 recorded only the type of the items so their permissions are
+// learned from the first scan instead of being reported as changed.
+func statePathInfos(ps PathState, schema int) *pathInfos {
+	pi := &pathInfos{modTime: ps.ModTime, mode: ps.Mode, size: ps.Size, sum: ps.Checksum, modeUnknown: schema < 2}
+	if !ps.ChangeTime.IsZero() {
+		pi.sys.ctime = ps.ChangeTime.UnixNano()
+	}
+	return pi
+}
+
+// importMissing loads imported paths which were not found during the
+// initialization so they will be reported as deleted on the first scan.
+// Paths no longer matching the current options are discarded.
+func (sn *snotifier) importMissing() {
+	if sn.seed == nil {
+		return
+	}
+	for s, ps := range sn.seed.Paths {
+		if _, exists := sn.paths.Load(s); exists {
+			continue
+		}
+		if ignore, _ := sn.check(s, getPathType(ps.Mode), nil); ignore {
+			continue
+		}
+		if !sn.admit(s, getPathType(ps.Mode)) {
+			continue
+		}
+		sn.track(s, statePathInfos(ps, sn.seed.Schema))
+	}
+	sn.seed = nil
+}
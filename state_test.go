@@ -0,0 +1,94 @@
+package gorsn
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// churn rewrites the files of `dir` until `done` is closed.
+func churn(t *testing.T, dir string, files int, done <-chan struct{}) {
+	t.Helper()
+	for i := 0; ; i++ {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		name := filepath.Join(dir, fmt.Sprintf("f%d", i%files))
+		if err := os.WriteFile(name, []byte(fmt.Sprint(i)), 0o644); err != nil {
+			t.Error(err)
+			return
+		}
+		os.Chmod(name, os.FileMode(0o600|(i%2)*0o044))
+		os.Chtimes(name, time.Now(), time.Now().Add(time.Duration(i)*time.Millisecond))
+	}
+}
+
+// drain consumes the events of the scan notifier until its queue is closed.
+func drain(sn ScanNotifier) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		for range sn.Queue() {
+		}
+		close(done)
+	}()
+	return done
+}
+
+// TestLiveStateReads reads the state of the paths through `Export` and `Emit`
+// while the workers update it. It is meant to be run with the race detector.
+func TestLiveStateReads(t *testing.T) {
+	dir := t.TempDir()
+	const files = 8
+	for i := 0; i < files; i++ {
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("f%d", i)), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	opts := defaultOpts()
+	opts.Scan().SetInterval(time.Millisecond).SetMaxWorkers(4).SetChecksum(true)
+	sn, err := New(dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	drained := drain(sn)
+	if err := sn.StartAsync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		churn(t, dir, files, done)
+	}()
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if st := sn.Export(); len(st.Paths) == 0 {
+				t.Error("exported state has no paths")
+				return
+			}
+			if err := sn.Emit("f0"); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	time.Sleep(200 * time.Millisecond)
+	close(done)
+	wg.Wait()
+	sn.Stop()
+	<-drained
+}
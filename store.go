@@ -0,0 +1,275 @@
+package gorsn
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sync"
+)
+
+// StateStore persists the states of the items under monitoring so that a new
+// scan notifier instance resumes from them, like with `NewFromState`, after a
+// restart. `NewFileStateStore` keeps them into a file while the `boltstore`
+// package keeps them into an embedded key-value store, which also implements
+// `StateLookup`.
+type StateStore interface {
+	// Load returns the stored state of the root, nil if there is none.
+	Load(root string) (*State, error)
+	// Save records the states of the items changed or added since the latest
+	// save and the removal of the ones no longer under monitoring.
+	Save(root string, changed map[string]PathState, removed []string) error
+}
+
+// StateLookup is implemented by a StateStore which could return the state of
+// a single item and range over the stored ones. It allows the scan notifier
+// to keep only a part of the items into memory, see `SetMaxCachedPaths`.
+type StateLookup interface {
+	StateStore
+	// Lookup returns the stored state of the item `path` of the root and
+	// whether there is one.
+	Lookup(root, path string) (PathState, bool, error)
+	// Range calls `fn` for each stored item of the root until it returns
+	// false. The store must not be saved into from `fn`.
+	Range(root string, fn func(path string, ps PathState) bool) error
+}
+
+// saveState records the changes of the items into the store, if any. Changes
+// which could not be saved are kept for the next attempt and the failure is
+// reported by an `ERROR` event which wraps `ErrStateStore`, then returned.
+func (sn *snotifier) saveState() error {
+	if sn.store == nil {
+		return nil
+	}
+	changed := make(map[string]PathState)
+	var removed []string
+	sn.dirty.Range(func(key, _ any) bool {
+		sn.dirty.Delete(key)
+		path := key.(string)
+		if val, ok := sn.paths.Load(path); ok {
+			changed[path] = val.(*pathInfos).state()
+		} else {
+			removed = append(removed, path)
+		}
+		return true
+	})
+	if len(changed) == 0 && len(removed) == 0 {
+		return nil
+	}
+	err := sn.store.Save(sn.root, changed, removed)
+	if err == nil {
+		return nil
+	}
+	for path := range changed {
+		sn.dirty.Store(path, struct{}{})
+	}
+	for _, path := range removed {
+		sn.dirty.Store(path, struct{}{})
+	}
+	sn.storeFailed(err)
+	return err
+}
+
+// storeFailed reports a failure of the store by an `ERROR` event.
+func (sn *snotifier) storeFailed(err error) {
+	if !sn.opts.events.ignoreErrors.Load() {
+		sn.queueEvent(Event{Path: sn.root, Type: sn.rootType(), Name: ERROR, Error: fmt.Errorf("%w: %v", ErrStateStore, err)})
+	}
+}
+
+// changed marks the path to be saved into the store.
+func (sn *snotifier) changed(path string) {
+	if sn.store != nil {
+		sn.dirty.Store(path, struct{}{})
+	}
+}
+
+// fileStore is a StateStore which appends the changes as JSON lines to a
+// file which is compacted when loaded.
+type fileStore struct {
+	mu   sync.Mutex
+	name string
+}
+
+type fileStoreHeader struct {
+	Schema int    `json:"schema"`
+	Root   string `json:"root"`
+}
+
+type fileStoreRecord struct {
+	Path    string     `json:"path"`
+	State   *PathState `json:"state,omitempty"`
+	Removed bool       `json:"removed,omitempty"`
+}
+
+// NewFileStateStore returns a StateStore which keeps the states of a single
+// root into the file `name`, created if needed. Each save appends the changes
+// so it stays cheap for huge trees, and the file is compacted when loaded.
+func NewFileStateStore(name string) StateStore {
+	return &fileStore{name: name}
+}
+
+func (s *fileStore) Load(root string) (*State, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.Open(s.name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	if !sc.Scan() {
+		return nil, sc.Err()
+	}
+	var h fileStoreHeader
+	if err := json.Unmarshal(sc.Bytes(), &h); err != nil {
+		return nil, err
+	}
+	if h.Root != root {
+		return nil, fmt.Errorf("%w: store of root %q", ErrInvalidState, h.Root)
+	}
+	st := &State{Schema: h.Schema, Root: h.Root, Paths: make(map[string]PathState)}
+	lines := 0
+	for sc.Scan() {
+		var rec fileStoreRecord
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			// a partially written record of a crash, ignore it.
+			continue
+		}
+		lines++
+		if rec.Removed || rec.State == nil {
+			delete(st.Paths, rec.Path)
+			continue
+		}
+		st.Paths[rec.Path] = *rec.State
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if lines > 2*len(st.Paths) {
+		if err := s.compact(st); err != nil {
+			return nil, err
+		}
+	}
+	return st, nil
+}
+
+func (s *fileStore) Save(root string, changed map[string]PathState, removed []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.OpenFile(s.name, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return err
+	}
+	err = s.append(f, root, changed, removed)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// append writes the records at the end of the file, after the header if it
+// is empty. A partial last line left by a crash is truncated first so that
+// the next record does not get merged into it.
+func (s *fileStore) append(f *os.File, root string, changed map[string]PathState, removed []string) error {
+	size, err := truncatePartialLine(f)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Seek(size, io.SeekStart); err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	if size == 0 {
+		if err := enc.Encode(fileStoreHeader{Schema: SchemaVersion, Root: root}); err != nil {
+			return err
+		}
+	}
+	for path, ps := range changed {
+		ps := ps
+		if err := enc.Encode(fileStoreRecord{Path: path, State: &ps}); err != nil {
+			return err
+		}
+	}
+	for _, path := range removed {
+		if err := enc.Encode(fileStoreRecord{Path: path, Removed: true}); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// truncatePartialLine truncates the file after its last newline and returns
+// its new size. The file is emptied if it has none, so the header of a store
+// whose first write was interrupted is written again.
+func truncatePartialLine(f *os.File) (int64, error) {
+	fi, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	end := fi.Size()
+	buf := make([]byte, 4096)
+	for off := end; off > 0; {
+		n := int64(len(buf))
+		if off < n {
+			n = off
+		}
+		off -= n
+		if _, err := f.ReadAt(buf[:n], off); err != nil {
+			return 0, err
+		}
+		if i := bytes.LastIndexByte(buf[:n], '\n'); i >= 0 {
+			size := off + int64(i) + 1
+			if size == end {
+				return end, nil
+			}
+			return size, f.Truncate(size)
+		}
+	}
+	return 0, f.Truncate(0)
+}
+
+// compact rewrites the file with only the current states.
+func (s *fileStore) compact(st *State) error {
+	tmp := s.name + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	err = enc.Encode(fileStoreHeader{Schema: st.Schema, Root: st.Root})
+	for path, ps := range st.Paths {
+		if err != nil {
+			break
+		}
+		ps := ps
+		err = enc.Encode(fileStoreRecord{Path: path, State: &ps})
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, s.name)
+}
@@ -0,0 +1,205 @@
+package gorsn
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFileStateStore(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "state.jsonl")
+	store := NewFileStateStore(name)
+	if st, err := store.Load("/root"); st != nil || err != nil {
+		t.Fatalf("got state %v and error %v without file, want none", st, err)
+	}
+
+	ps := func(size int64) PathState {
+		return PathState{ModTime: time.Unix(size, 0).UTC(), Mode: 0o644, Size: size}
+	}
+	saves := []struct {
+		changed map[string]PathState
+		removed []string
+	}{
+		{map[string]PathState{"/root/a": ps(1), "/root/b": ps(2)}, nil},
+		{map[string]PathState{"/root/a": ps(3)}, []string{"/root/b"}},
+		{map[string]PathState{"/root/c": ps(4)}, nil},
+	}
+	for _, s := range saves {
+		if err := store.Save("/root", s.changed, s.removed); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// a partially written record of a crash.
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"path":"/root/d","sta`)
+	f.Close()
+
+	st, err := store.Load("/root")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &State{Schema: SchemaVersion, Root: "/root", Paths: map[string]PathState{"/root/a": ps(3), "/root/c": ps(4)}}
+	if !reflect.DeepEqual(st, want) {
+		t.Errorf("got state %+v, want %+v", st, want)
+	}
+	// the 5 records of the 2 paths were compacted when loaded.
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := bytes.Count(data, []byte("\n")); n != 3 {
+		t.Errorf("got %d lines once compacted, want the header and 2 records", n)
+	}
+	if st, err := store.Load("/root"); err != nil || !reflect.DeepEqual(st, want) {
+		t.Errorf("got state %+v and error %v once compacted, want %+v", st, err, want)
+	}
+
+	if _, err := store.Load("/other"); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("got error %v for another root, want ErrInvalidState", err)
+	}
+}
+
+func TestFileStateStoreInterruptedSave(t *testing.T) {
+	ps := PathState{ModTime: time.Unix(1, 0).UTC(), Mode: 0o644, Size: 1}
+	tests := []struct {
+		name    string
+		initial map[string]PathState
+		partial string
+	}{
+		{"partial record", map[string]PathState{"/root/a": ps}, `{"path":"/root/d","sta`},
+		{"partial header", nil, `{"schema":`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name := filepath.Join(t.TempDir(), "state.jsonl")
+			store := NewFileStateStore(name)
+			if tt.initial != nil {
+				if err := store.Save("/root", tt.initial, nil); err != nil {
+					t.Fatal(err)
+				}
+			}
+			f, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f.WriteString(tt.partial)
+			f.Close()
+
+			// the next save must not merge its first record into the partial line.
+			if err := store.Save("/root", map[string]PathState{"/root/b": ps}, nil); err != nil {
+				t.Fatal(err)
+			}
+			st, err := store.Load("/root")
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := map[string]PathState{"/root/b": ps}
+			for path, ps := range tt.initial {
+				want[path] = ps
+			}
+			if st == nil || !reflect.DeepEqual(st.Paths, want) {
+				t.Errorf("got state %+v, want paths %+v", st, want)
+			}
+		})
+	}
+}
+
+func TestStateStoreResume(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, "1", "a", "b")
+	store := NewFileStateStore(filepath.Join(t.TempDir(), "state.jsonl"))
+
+	// run scans the root once with the store and returns the events.
+	run := func() map[string][]EventName {
+		t.Helper()
+		scanned := make(chan struct{})
+		var once sync.Once
+		opts := defaultOpts()
+		opts.Scan().SetAfterScan(func(int, ScanSummary) { once.Do(func() { close(scanned) }) })
+		opts.Delivery().SetQueueSize(16)
+		opts.Persistence().SetStateStore(store)
+		sn, err := New(dir, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := sn.StartAsync(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		wait(t, scanned, "first cycle")
+		evs := received(sn)
+		if err := sn.Stop(); err != nil {
+			t.Fatal(err)
+		}
+		if err := sn.Wait(); err != nil {
+			t.Fatal(err)
+		}
+		return evs
+	}
+	if evs := run(); len(evs) != 0 {
+		t.Errorf("got events %v on the first run, want none", evs)
+	}
+
+	writeFiles(t, dir, "22", "a", "c")
+	if err := os.Remove(filepath.Join(dir, "b")); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]EventName{"a": {MODIFY}, "b": {DELETE}, "c": {CREATE}}
+	if evs := run(); !reflect.DeepEqual(evs, want) {
+		t.Errorf("got events %v after a restart, want %v", evs, want)
+	}
+	if evs := run(); len(evs) != 0 {
+		t.Errorf("got events %v after a restart without changes, want none", evs)
+	}
+}
+
+// failingStore is a StateStore whose saves fail until `fail` is cleared.
+type failingStore struct {
+	fail    bool
+	removed []string
+}
+
+func (s *failingStore) Load(string) (*State, error) { return nil, nil }
+
+func (s *failingStore) Save(_ string, _ map[string]PathState, removed []string) error {
+	if s.fail {
+		return errors.New("disk full")
+	}
+	s.removed = append(s.removed, removed...)
+	return nil
+}
+
+func TestStateStoreSaveError(t *testing.T) {
+	store := &failingStore{fail: true}
+	opts := defaultOpts()
+	opts.Persistence().SetStateStore(store)
+	sn, err := newSnotifier(t.TempDir(), opts, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sn.running.Store(true)
+	gone := filepath.Join(sn.root, "gone")
+	sn.changed(gone)
+
+	sn.saveState()
+	got := pending(sn)
+	if len(got) != 1 || got[0].Name != ERROR || !errors.Is(got[0].Error, ErrStateStore) {
+		t.Fatalf("got events %v, want an ERROR which wraps ErrStateStore", got)
+	}
+	store.fail = false
+	sn.saveState()
+	if !reflect.DeepEqual(store.removed, []string{gone}) {
+		t.Errorf("got removed paths %q saved on the next attempt, want %q", store.removed, gone)
+	}
+	if got := pending(sn); len(got) != 0 {
+		t.Errorf("got events %v once saved, want none", got)
+	}
+}
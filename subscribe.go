@@ -0,0 +1,59 @@
+package gorsn
+
+// subscription is a queue which receives only some kinds of events.
+type subscription struct {
+	names    map[EventName]bool
+	queue    chan Event
+	overflow overflow
+}
+
+// QueueFor returns a read-only channel which receives a copy of the events
+// named `names` only. The main queue still receives all the events unless
+// disabled with `SetSubscriptionsOnly`. Like the main queue, it holds the
+// configured queue size, follows the overflow policy and is closed once the
+// scan notifier stopped.
+func (sn *snotifier) QueueFor(names ...EventName) <-chan Event {
+	sub := &subscription{names: make(map[EventName]bool, len(names)), queue: make(chan Event, cap(sn.queue))}
+	for _, name := range names {
+		sub.names[name] = true
+	}
+	sn.emu.Lock()
+	defer sn.emu.Unlock()
+	if sn.closed {
+		close(sub.queue)
+		return sub.queue
+	}
+	subs, _ := sn.subs.Load().([]*subscription)
+	sn.subs.Store(append(append([]*subscription{}, subs...), sub))
+	return sub.queue
+}
+
+// subscriptions returns the current list of subscriptions.
+func (sn *snotifier) subscriptions() []*subscription {
+	subs, _ := sn.subs.Load().([]*subscription)
+	return subs
+}
+
+// publish sends the event to the matching subscriptions. It reports
+// false if the scan notifier stopped meanwhile.
+func (sn *snotifier) publish(ev Event, subs []*subscription) bool {
+	for _, sub := range subs {
+		if !sub.names[ev.Name] {
+			continue
+		}
+		if _, alive := sn.send(sub.queue, &sub.overflow, ev); !alive {
+			return false
+		}
+	}
+	return true
+}
+
+// unsubscribeAll closes the queues of all the subscriptions.
+// It must be called with the emit lock held.
+func (sn *snotifier) unsubscribeAll() {
+	for _, sub := range sn.subscriptions() {
+		close(sub.queue)
+	}
+	sn.subs.Store([]*subscription(nil))
+	sn.closed = true
+}
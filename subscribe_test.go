@@ -0,0 +1,93 @@
+package gorsn
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestQueueFor(t *testing.T) {
+	for _, only := range []bool{false, true} {
+		dir := t.TempDir()
+		opts := defaultOpts()
+		opts.Delivery().SetQueueSize(8).SetSubscriptionsOnly(only)
+		var sn ScanNotifier
+		done := make(chan struct{})
+		opts.Scan().SetInterval(5 * time.Millisecond).
+			SetBeforeScan(func(cycle int) {
+				if cycle == 2 {
+					writeFiles(t, dir, "1", "a", "b")
+				}
+			}).
+			SetAfterScan(func(cycle int, _ ScanSummary) {
+				if cycle == 2 {
+					sn.Pause()
+					close(done)
+				}
+			})
+		sn, err := New(dir, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		creates := sn.QueueFor(CREATE)
+		// reading the main queue must not change the delivery.
+		_ = len(sn.Queue())
+		if err := sn.StartAsync(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		wait(t, done, "scan cycle")
+		if n := len(creates); n != 2 {
+			t.Errorf("subscriptions only %t: got %d events into the subscription, want 2", only, n)
+		}
+		want := 2
+		if only {
+			want = 0
+		}
+		if n := len(sn.Queue()); n != want {
+			t.Errorf("subscriptions only %t: got %d events into the main queue, want %d", only, n, want)
+		}
+		sn.Stop()
+	}
+}
+
+func TestQueueForRateLimited(t *testing.T) {
+	dir := t.TempDir()
+	var sn ScanNotifier
+	done := make(chan struct{})
+	opts := defaultOpts()
+	opts.Delivery().SetQueueSize(8).SetSubscriptionsOnly(true).
+		SetOverflowPolicy(OVERFLOW_DROP).SetMaxEventsPerSecond(1)
+	opts.Scan().SetInterval(5 * time.Millisecond).
+		SetBeforeScan(func(cycle int) {
+			if cycle == 2 {
+				writeFiles(t, dir, "1", "a", "b", "c")
+			}
+		}).
+		SetAfterScan(func(cycle int, _ ScanSummary) {
+			if cycle == 2 {
+				sn.Pause()
+				close(done)
+			}
+		})
+	sn, err := New(dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	creates := sn.QueueFor(CREATE)
+	if err := sn.StartAsync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Stop()
+
+	wait(t, done, "scan cycle")
+	var got []EventName
+	dropped := 0
+	for len(creates) > 0 {
+		ev := <-creates
+		got = append(got, ev.Name)
+		dropped += ev.Dropped
+	}
+	if len(got) != 2 || got[0] != CREATE || got[1] != OVERFLOW || dropped != 2 {
+		t.Errorf("got events %v reporting %d dropped, want a CREATE and an OVERFLOW of 2", got, dropped)
+	}
+}
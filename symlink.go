@@ -0,0 +1,28 @@
+package gorsn
+
+// linkResolver is implemented by backends which could resolve symlinks.
+type linkResolver interface {
+	// Readlink returns the destination of the named symlink.
+	Readlink(name string) (string, error)
+	// EvalSymlinks returns the path name after the evaluation of any symlink.
+	EvalSymlinks(name string) (string, error)
+}
+
+// resolve sets the destination and the fully resolved path of a symlink
+// into its events, apart from the `DELETE` and `ERROR` ones. They are left
+// empty if they could not be resolved, e.g. the real path of a dangling link.
+func (sn *snotifier) resolve(ev *Event) {
+	if ev.Type != SYMLINK || ev.Name == DELETE || ev.Name == ERROR || ev.LinkTarget != "" {
+		return
+	}
+	lr, ok := sn.opts.scan.backend.(linkResolver)
+	if !ok {
+		return
+	}
+	target, err := lr.Readlink(ev.Path)
+	if err != nil {
+		return
+	}
+	ev.LinkTarget = target
+	ev.RealPath, _ = lr.EvalSymlinks(ev.Path)
+}
@@ -0,0 +1,40 @@
+package gorsn
+
+// sysInfo holds the ownership and the identity of a file as
+// reported by the operating system. It is only valid on the
+// platforms which expose them. The attributes are only known
+// on windows.
+type sysInfo struct {
+	valid    bool
+	uid      uint32
+	gid      uint32
+	dev      uint64
+	ino      uint64
+	nlink    uint64
+	hasAttrs bool
+	attrs    uint32
+	ctime    int64 // inode change time in nanoseconds, zero if unknown.
+}
+
+// sameFile reports whether both infos refer to the same file.
+func (s sysInfo) sameFile(o sysInfo) bool {
+	return s.valid && o.valid && s.dev == o.dev && s.ino == o.ino
+}
+
+// sameOwner reports whether both infos have the same ownership.
+// Infos which are not valid are considered to have the same owner.
+func (s sysInfo) sameOwner(o sysInfo) bool {
+	if !s.valid || !o.valid {
+		return true
+	}
+	return s.uid == o.uid && s.gid == o.gid
+}
+
+// sameAttributes reports whether both infos have the same attributes.
+// Infos without attributes are considered to have the same attributes.
+func (s sysInfo) sameAttributes(o sysInfo) bool {
+	if !s.hasAttrs || !o.hasAttrs {
+		return true
+	}
+	return s.attrs == o.attrs
+}
@@ -0,0 +1,15 @@
+//go:build !unix && !windows
+
+package gorsn
+
+import "io/fs"
+
+var platformCapabilities = Capabilities{
+	Checksum: true,
+}
+
+// sysStat returns invalid infos since ownership and identity
+// of files are not available on this platform.
+func sysStat(fi fs.FileInfo) sysInfo {
+	return sysInfo{}
+}
@@ -0,0 +1,32 @@
+//go:build unix
+
+package gorsn
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+var platformCapabilities = Capabilities{
+	Ownership:      true,
+	InodeIdentity:  true,
+	SubSecondMtime: true,
+	Checksum:       true,
+}
+
+// sysStat extracts the ownership and identity of a file from its infos.
+func sysStat(fi fs.FileInfo) sysInfo {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok || st == nil {
+		return sysInfo{}
+	}
+	return sysInfo{
+		valid: true,
+		uid:   uint32(st.Uid),
+		gid:   uint32(st.Gid),
+		dev:   uint64(st.Dev),
+		ino:   uint64(st.Ino),
+		nlink: uint64(st.Nlink),
+		ctime: statCtime(st),
+	}
+}
@@ -0,0 +1,30 @@
+//go:build windows
+
+package gorsn
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// attrsMask selects the file attributes which are monitored.
+const attrsMask = syscall.FILE_ATTRIBUTE_READONLY |
+	syscall.FILE_ATTRIBUTE_HIDDEN |
+	syscall.FILE_ATTRIBUTE_SYSTEM |
+	syscall.FILE_ATTRIBUTE_ARCHIVE
+
+var platformCapabilities = Capabilities{
+	SubSecondMtime:  true,
+	AttributeEvents: true,
+	Checksum:        true,
+}
+
+// sysStat extracts the attributes of a file from its infos. Ownership
+// and identity are not available without opening the file on windows.
+func sysStat(fi fs.FileInfo) sysInfo {
+	d, ok := fi.Sys().(*syscall.Win32FileAttributeData)
+	if !ok || d == nil {
+		return sysInfo{}
+	}
+	return sysInfo{hasAttrs: true, attrs: d.FileAttributes & attrsMask}
+}
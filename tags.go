@@ -0,0 +1,68 @@
+package gorsn
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// tagRule labels the events whose path matches a glob.
+type tagRule struct {
+	pattern *regexp.Regexp
+	tags    []string
+}
+
+// AddTagRule labels the events whose path, relative to the root directory,
+// matches the glob with `tags` into their `Tags` field. Globs use the
+// `.gitignore` syntax, e.g. `secrets/**` or `**/*.yaml`, and an event gets
+// the tags of all matching rules. A `RENAME` event is tagged based on its
+// new path. It returns an error which wraps `ErrInvalidOptions` if the glob
+// is malformed.
+func (eo *EventOptions) AddTagRule(glob string, tags ...string) error {
+	glob = strings.Trim(glob, "/")
+	if glob == "" || len(tags) == 0 {
+		return nil
+	}
+	re, err := regexp.Compile(globToRegexp(glob))
+	if err != nil {
+		return fmt.Errorf("%w: tag rule glob %q: %v", ErrInvalidOptions, glob, err)
+	}
+	rule := tagRule{pattern: re, tags: append([]string(nil), tags...)}
+	eo.mu.Lock()
+	defer eo.mu.Unlock()
+	rules := append(append([]tagRule(nil), eo.tagRules()...), rule)
+	eo.tags.Store(rules)
+	return nil
+}
+
+// tagRules returns the registered tag rules.
+func (eo *EventOptions) tagRules() []tagRule {
+	rules, _ := eo.tags.Load().([]tagRule)
+	return rules
+}
+
+// tag sets the sorted labels of the rules matching the path of the event.
+func (sn *snotifier) tag(ev Event) Event {
+	rules := sn.opts.events.tagRules()
+	if len(rules) == 0 {
+		return ev
+	}
+	rel := sn.rel(ev.Path)
+	seen := map[string]bool{}
+	var tags []string
+	for _, r := range rules {
+		if !r.pattern.MatchString(rel) {
+			continue
+		}
+		for _, t := range r.tags {
+			if !seen[t] {
+				seen[t] = true
+				tags = append(tags, t)
+			}
+		}
+	}
+	sort.Strings(tags)
+	ev.Tags = tags
+	return ev
+}
@@ -0,0 +1,94 @@
+package gorsn
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestAddTagRule(t *testing.T) {
+	tests := []struct {
+		glob  string
+		fails bool
+	}{
+		{"secrets/**", false},
+		{"**/*.[ch]", false},
+		{"[]", true},
+		{"[!]", true},
+		{"[]]", true},
+		{"[z-a]", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.glob, func(t *testing.T) {
+			opts := defaultOpts()
+			if err := opts.Events().AddTagRule("**/*.key", "secret"); err != nil {
+				t.Fatal(err)
+			}
+			err := opts.Events().AddTagRule(tt.glob, "other")
+			if tt.fails != (err != nil) {
+				t.Fatalf("got error %v, want a failure %t", err, tt.fails)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidOptions) {
+				t.Fatalf("got error %v, want ErrInvalidOptions", err)
+			}
+			root := t.TempDir()
+			sn, err := New(root, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ev := sn.(*snotifier).tag(Event{Path: filepath.Join(root, "a", "id.key")})
+			if !reflect.DeepEqual(ev.Tags, []string{"secret"}) {
+				t.Errorf("got tags %v, want [secret]", ev.Tags)
+			}
+		})
+	}
+}
+
+func TestTagRelativePaths(t *testing.T) {
+	opts := defaultOpts()
+	opts.Events().SetRelativePaths(true)
+	if err := opts.Events().AddTagRule("database/**", "db"); err != nil {
+		t.Fatal(err)
+	}
+	sn, err := newSnotifier(t.TempDir(), opts, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sn.running.Store(true)
+	// a relative root which prefixes the relative path of the event.
+	sn.root = "data"
+	if rel := sn.rel("database/x.csv"); rel != "database/x.csv" {
+		t.Errorf("got relative path %q of a path not under the root", rel)
+	}
+	if !sn.deliver(Event{Path: filepath.Join("data", "database", "x.csv"), Type: FILE, Name: CREATE}) {
+		t.Fatal("got the event rejected")
+	}
+	got := pending(sn)
+	if len(got) != 1 || got[0].Path != filepath.Join("database", "x.csv") || !reflect.DeepEqual(got[0].Tags, []string{"db"}) {
+		t.Errorf("got events %+v, want database/x.csv tagged with db", got)
+	}
+}
+
+func TestTagConvertedPaths(t *testing.T) {
+	opts := defaultOpts()
+	// a conversion which moves the path out of the root like the
+	// extended-length form of a Windows root in the DOS form.
+	opts.Events().SetPathNormalizer(func(p string) string { return `\\?\` + p })
+	if err := opts.Events().AddTagRule("logs/**", "log"); err != nil {
+		t.Fatal(err)
+	}
+	sn, err := newSnotifier(t.TempDir(), opts, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sn.running.Store(true)
+	path := filepath.Join(sn.root, "logs", "a.log")
+	if !sn.deliver(Event{Path: path, Type: FILE, Name: MODIFY}) {
+		t.Fatal("got the event rejected")
+	}
+	got := pending(sn)
+	if len(got) != 1 || got[0].Path != `\\?\`+path || !reflect.DeepEqual(got[0].Tags, []string{"log"}) {
+		t.Errorf("got events %+v, want the converted path tagged with log", got)
+	}
+}
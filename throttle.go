@@ -0,0 +1,60 @@
+package gorsn
+
+import (
+	"math"
+	"time"
+)
+
+// loadRecheck is the delay between two checks of the system load.
+const loadRecheck = time.Second
+
+// pacer spaces the file system operations of the scans. It is only
+// used by the walking routine.
+type pacer struct {
+	next    time.Time // earliest time of the next operation.
+	checked time.Time // latest check of the system load.
+}
+
+// loadThreshold returns the system load above which scans are paused.
+func (so *ScanOptions) loadThreshold() float64 {
+	return math.Float64frombits(so.maxLoad.Load())
+}
+
+// pace waits until the throttle allows `ops` more file system operations.
+func (sn *snotifier) pace(ops int) {
+	rate := sn.opts.scan.throttle.Load()
+	if rate <= 0 {
+		return
+	}
+	now := sn.now()
+	if sn.pacer.next.Before(now) {
+		sn.pacer.next = now
+	}
+	wait := sn.pacer.next.Sub(now)
+	sn.pacer.next = sn.pacer.next.Add(time.Duration(ops) * time.Second / time.Duration(rate))
+	if wait > 0 {
+		sn.opts.scan.clock.Sleep(wait)
+	}
+}
+
+// awaitLoad waits while the system load is over the threshold. The load
+// is checked at most once per second. It returns earlier once stopped or
+// if the scan is abandoned.
+func (sn *snotifier) awaitLoad() {
+	max := sn.opts.scan.loadThreshold()
+	if max <= 0 || sn.now().Sub(sn.pacer.checked) < loadRecheck {
+		return
+	}
+	for {
+		sn.pacer.checked = sn.now()
+		load, ok := systemLoad()
+		if !ok || load <= max || sn.abort.Load() {
+			return
+		}
+		select {
+		case <-sn.stop:
+			return
+		case <-sn.opts.scan.clock.After(loadRecheck):
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package gorsn
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Kinds of the trace records.
+const (
+	traceCycleStart = "cycle_start"
+	traceCycleEnd   = "cycle_end"
+	traceVisit      = "visit"
+	traceSkip       = "skip"
+	traceMissing    = "missing"
+	traceVanished   = "vanished"
+	traceEvent      = "event"
+)
+
+// traceRecord is the JSON representation of a step into the trace.
+type traceRecord struct {
+	Schema   int           `json:"schema"`
+	Cycle    int64         `json:"cycle"`
+	Time     time.Time     `json:"time"`
+	Kind     string        `json:"kind"`
+	Path     string        `json:"path,omitempty"`
+	Type     PathType      `json:"type,omitempty"`
+	Event    EventName     `json:"event,omitempty"`
+	Detail   string        `json:"detail,omitempty"`
+	Duration time.Duration `json:"duration,omitempty"`
+}
+
+// tracer writes the diagnostics trace of the scan cycles.
+type tracer struct {
+	mu    sync.Mutex
+	enc   *json.Encoder
+	cycle atomic.Int64
+}
+
+// tracer returns the current tracer or nil if the trace is disabled.
+func (sn *snotifier) tracer() *tracer {
+	t, _ := sn.opts.scan.trace.Load().(*tracer)
+	return t
+}
+
+// trace records a step of the current scan cycle if the trace is enabled.
+func (sn *snotifier) trace(rec traceRecord) {
+	t := sn.tracer()
+	if t == nil {
+		return
+	}
+	rec.Schema = SchemaVersion
+	rec.Cycle = t.cycle.Load()
+	rec.Time = sn.now()
+	t.mu.Lock()
+	t.enc.Encode(rec)
+	t.mu.Unlock()
+}
+
+// traceCycle records the start of a scan cycle.
+func (sn *snotifier) traceCycle(cycle int) {
+	if t := sn.tracer(); t != nil {
+		t.cycle.Store(int64(cycle))
+		sn.trace(traceRecord{Kind: traceCycleStart, Path: sn.root})
+	}
+}
+
+// newTracer returns a tracer writing JSON lines into `w`.
+func newTracer(w io.Writer) *tracer {
+	return &tracer{enc: json.NewEncoder(w)}
+}
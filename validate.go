@@ -0,0 +1,121 @@
+package gorsn
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+)
+
+// Validate reports the contradictory settings which would prevent the scan
+// notifier to do any useful work, such as all events or all kinds of paths
+// ignored, an include pattern which matches nothing or an exclude pattern
+// which matches everything. The returned error wraps `ErrInvalidOptions`
+// and describes each problem found. It is called by `New`.
+func (o *Options) Validate() error {
+	if o == nil {
+		return nil
+	}
+	var errs []string
+	fo, eo, so := &o.filters, &o.events, &o.scan
+
+	if re := fo.includeRegex(); re != nil && matchesNothing(re) {
+		errs = append(errs, fmt.Sprintf("include pattern %q matches no path", re))
+	}
+	if re := fo.excludeRegex(); re != nil && matchesEverything(re) {
+		errs = append(errs, fmt.Sprintf("exclude pattern %q matches every path", re))
+	}
+	if fo.ignoreFile.Load() && fo.ignoreFolder.Load() && fo.ignoreSymlink.Load() &&
+		fo.ignoreFifo.Load() && fo.ignoreSocket.Load() && fo.ignoreDevice.Load() {
+		errs = append(errs, "all kinds of paths are ignored")
+	}
+	if eo.ignoreCreate.Load() && eo.ignoreDelete.Load() && eo.ignoreModify.Load() &&
+		eo.ignorePerm.Load() && eo.ignoreAttrib.Load() && eo.ignoreErrors.Load() &&
+		!eo.trackOwner.Load() && !eo.trackRenames.Load() && !eo.trackLinks.Load() &&
+		eo.settleCycles.Load() == 0 && eo.anomalyWindow.Load() == 0 &&
+		!eo.scanSummary.Load() && len(eo.sizeThresholds()) == 0 && !eo.detectLocked.Load() &&
+		!eo.detectRotation.Load() && !eo.detectTouch.Load() && !eo.stopOnRootLost.Load() {
+		errs = append(errs, "all events are ignored")
+	}
+	if so.checksum.Load() && so.backend != nil {
+		if _, ok := so.backend.(opener); !ok {
+			errs = append(errs, "checksum enabled but not supported by the backend")
+		}
+	}
+	if po := &o.persist; po.maxCached > 0 {
+		if _, ok := po.store.(StateLookup); !ok {
+			errs = append(errs, "cached paths limited without a state store supporting lookups")
+		}
+		if so.maxTracked.Load() > 0 {
+			errs = append(errs, "cached paths limited along with the tracked paths")
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrInvalidOptions, strings.Join(errs, "; "))
+}
+
+// matchesNothing reports whether the pattern could never match.
+func matchesNothing(re *regexp.Regexp) bool {
+	r, err := syntax.Parse(re.String(), syntax.Perl)
+	if err != nil {
+		return false
+	}
+	return noMatch(r.Simplify())
+}
+
+func noMatch(r *syntax.Regexp) bool {
+	switch r.Op {
+	case syntax.OpNoMatch:
+		return true
+	case syntax.OpCharClass:
+		return len(r.Rune) == 0
+	case syntax.OpConcat:
+		for _, sub := range r.Sub {
+			if noMatch(sub) {
+				return true
+			}
+		}
+	case syntax.OpAlternate:
+		for _, sub := range r.Sub {
+			if !noMatch(sub) {
+				return false
+			}
+		}
+		return true
+	case syntax.OpCapture, syntax.OpPlus:
+		return noMatch(r.Sub[0])
+	case syntax.OpRepeat:
+		return r.Min > 0 && noMatch(r.Sub[0])
+	}
+	return false
+}
+
+// matchesEverything reports whether the pattern matches any path. This is
+// the case if it matches an empty string without any position assertion.
+func matchesEverything(re *regexp.Regexp) bool {
+	if !re.MatchString("") {
+		return false
+	}
+	r, err := syntax.Parse(re.String(), syntax.Perl)
+	if err != nil {
+		return false
+	}
+	return !hasAssertion(r)
+}
+
+func hasAssertion(r *syntax.Regexp) bool {
+	switch r.Op {
+	case syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText,
+		syntax.OpEndText, syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		return true
+	}
+	for _, sub := range r.Sub {
+		if hasAssertion(sub) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package gorsn
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// listingBackend is the local file system without the reading of the files.
+type listingBackend struct {
+	Backend
+}
+
+func TestValidate(t *testing.T) {
+	ignoreEvents := func(o *Options) {
+		o.Events().SetIgnoreCreate(true).SetIgnoreDelete(true).SetIgnoreModify(true).
+			SetIgnorePerm(true).SetIgnoreAttrib(true).SetIgnoreErrors(true)
+	}
+	tests := []struct {
+		name  string
+		setup func(*Options)
+		want  []string
+	}{
+		{"defaults", func(*Options) {}, nil},
+		{"include matching nothing", func(o *Options) {
+			o.Filters().SetIncludeRegex(regexp.MustCompile(`^[^\x00-\x{10FFFF}]`))
+		}, []string{"matches no path"}},
+		{"include matching some paths", func(o *Options) {
+			o.Filters().SetIncludeRegex(regexp.MustCompile(`\.go$|^[^\x00-\x{10FFFF}]`))
+		}, nil},
+		{"exclude matching every path", func(o *Options) {
+			o.Filters().SetExcludeRegex(regexp.MustCompile(`.*`))
+		}, []string{"matches every path"}},
+		{"anchored exclude", func(o *Options) {
+			o.Filters().SetExcludeRegex(regexp.MustCompile(`^.*$`))
+		}, nil},
+		{"all kinds of paths ignored", func(o *Options) {
+			o.Filters().SetIgnoreFiles(true).SetIgnoreFolders(true).SetIgnoreSymlinks(true).
+				SetIgnoreFifos(true).SetIgnoreSockets(true).SetIgnoreDevices(true)
+		}, []string{"all kinds of paths are ignored"}},
+		{"files only", func(o *Options) {
+			o.Filters().SetIgnoreFolders(true).SetIgnoreSymlinks(true).
+				SetIgnoreFifos(true).SetIgnoreSockets(true).SetIgnoreDevices(true)
+		}, nil},
+		{"all events ignored", ignoreEvents, []string{"all events are ignored"}},
+		{"only renames tracked", func(o *Options) {
+			ignoreEvents(o)
+			o.Events().SetTrackRenames(true)
+		}, nil},
+		{"only settled files reported", func(o *Options) {
+			ignoreEvents(o)
+			o.Events().SetSettleCycles(2)
+		}, nil},
+		{"only scan summaries reported", func(o *Options) {
+			ignoreEvents(o)
+			o.Events().SetScanSummary(true)
+		}, nil},
+		{"only size thresholds reported", func(o *Options) {
+			ignoreEvents(o)
+			o.Events().SetSizeThreshold(".", 1<<20)
+		}, nil},
+		{"removed size threshold", func(o *Options) {
+			ignoreEvents(o)
+			o.Events().SetSizeThreshold(".", 1<<20).SetSizeThreshold(".", 0)
+		}, []string{"all events are ignored"}},
+		{"only locked files reported", func(o *Options) {
+			ignoreEvents(o)
+			o.Events().SetDetectLocked(true)
+		}, nil},
+		{"only rotations reported", func(o *Options) {
+			ignoreEvents(o)
+			o.Events().SetDetectRotation(true)
+		}, nil},
+		{"only touches reported", func(o *Options) {
+			ignoreEvents(o)
+			o.Events().SetDetectTouch(true)
+		}, nil},
+		{"only stop on root lost", func(o *Options) {
+			ignoreEvents(o)
+			o.Events().SetStopOnRootLost(true)
+		}, nil},
+		{"negative queue size", func(o *Options) {
+			o.Delivery().SetQueueSize(-1)
+		}, nil},
+		{"checksum not supported by the backend", func(o *Options) {
+			o.Scan().SetBackend(listingBackend{osBackend{}}).SetChecksum(true)
+		}, []string{"checksum enabled but not supported by the backend"}},
+		{"checksum supported by the backend", func(o *Options) {
+			o.Scan().SetBackend(osBackend{}).SetChecksum(true)
+		}, nil},
+		{"many problems", func(o *Options) {
+			o.Filters().SetExcludeRegex(regexp.MustCompile(`.*`))
+			o.Filters().SetIgnoreFiles(true).SetIgnoreFolders(true).SetIgnoreSymlinks(true).
+				SetIgnoreFifos(true).SetIgnoreSockets(true).SetIgnoreDevices(true)
+		}, []string{"matches every path", "all kinds of paths are ignored"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := defaultOpts()
+			tt.setup(o)
+			err := o.Validate()
+			if _, nerr := New(t.TempDir(), o); (nerr == nil) != (tt.want == nil) {
+				t.Errorf("got error %v from New, want it to fail %v", nerr, tt.want != nil)
+			}
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("got error %v for valid options", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidOptions) {
+				t.Fatalf("got error %v, want ErrInvalidOptions", err)
+			}
+			for _, w := range tt.want {
+				if !strings.Contains(err.Error(), w) {
+					t.Errorf("got error %q, want it to report %q", err, w)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateNil(t *testing.T) {
+	var o *Options
+	if err := o.Validate(); err != nil {
+		t.Errorf("got error %v for nil options", err)
+	}
+}
@@ -0,0 +1,47 @@
+package gorsn
+
+// VanishedMode defines how a path listed during a scan but which
+// disappeared before it could be checked is reported.
+type VanishedMode uint32
+
+const (
+	// VANISHED_ERROR emits an `ERROR` event for the path.
+	VANISHED_ERROR VanishedMode = iota
+	// VANISHED_SUPPRESS ignores the path. A known path is reported
+	// as deleted at the end of the scan cycle.
+	VANISHED_SUPPRESS
+	// VANISHED_CREATE_DELETE emits a `CREATE` then a `DELETE` event
+	// for a path which was not known yet.
+	VANISHED_CREATE_DELETE
+	// VANISHED_TRANSIENT emits a `TRANSIENT` event for a path which
+	// was not known yet.
+	VANISHED_TRANSIENT
+)
+
+// vanished reports a path which disappeared before being checked.
+func (sn *snotifier) vanished(fse *fsEntry, err error) {
+	pt := getPathType(fse.d.Type())
+	mode := VanishedMode(sn.opts.events.vanished.Load())
+	sn.trace(traceRecord{Kind: traceVanished, Path: fse.path, Type: pt, Detail: err.Error()})
+	if mode == VANISHED_ERROR {
+		if !sn.opts.events.ignoreErrors.Load() {
+			sn.queueEvent(Event{Path: fse.path, Type: pt, Name: ERROR, Error: err})
+		}
+		return
+	}
+	if _, known := sn.paths.Load(fse.path); known {
+		// left to the deleted paths detection.
+		return
+	}
+	switch mode {
+	case VANISHED_CREATE_DELETE:
+		if !sn.opts.events.ignoreCreate.Load() {
+			sn.queueEvent(Event{Path: fse.path, Type: pt, Name: CREATE})
+		}
+		if !sn.opts.events.ignoreDelete.Load() {
+			sn.queueEvent(Event{Path: fse.path, Type: pt, Name: DELETE})
+		}
+	case VANISHED_TRANSIENT:
+		sn.queueEvent(Event{Path: fse.path, Type: pt, Name: TRANSIENT})
+	}
+}
@@ -0,0 +1,128 @@
+package gorsn
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// vanishingBackend is the local file system whose listed entries named
+// `vanish` disappear before their infos are read once armed.
+type vanishingBackend struct {
+	Backend
+	vanish map[string]bool
+	armed  atomic.Bool
+}
+
+func (b *vanishingBackend) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries, err := b.Backend.ReadDir(name)
+	if b.armed.Load() {
+		for i, d := range entries {
+			if b.vanish[d.Name()] {
+				entries[i] = vanishedEntry{d}
+			}
+		}
+	}
+	return entries, err
+}
+
+// vanishedEntry is a directory entry removed before its infos were read.
+type vanishedEntry struct {
+	fs.DirEntry
+}
+
+func (d vanishedEntry) Info() (fs.FileInfo, error) {
+	return nil, &fs.PathError{Op: "lstat", Path: d.Name(), Err: fs.ErrNotExist}
+}
+
+func TestVanished(t *testing.T) {
+	tests := []struct {
+		name         string
+		mode         VanishedMode
+		known, fresh []EventName
+	}{
+		{"error", VANISHED_ERROR, []EventName{ERROR, DELETE}, []EventName{ERROR}},
+		{"suppress", VANISHED_SUPPRESS, []EventName{DELETE}, nil},
+		{"create_delete", VANISHED_CREATE_DELETE, []EventName{DELETE}, []EventName{CREATE, DELETE}},
+		{"transient", VANISHED_TRANSIENT, []EventName{DELETE}, []EventName{TRANSIENT}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, "1", "known", "kept")
+			backend := &vanishingBackend{
+				Backend: osBackend{},
+				vanish:  map[string]bool{"known": true, "fresh": true},
+			}
+			var sn ScanNotifier
+			done := make(chan struct{})
+			opts := defaultOpts()
+			opts.Scan().SetInterval(5 * time.Millisecond).SetBackend(backend).
+				SetBeforeScan(func(cycle int) {
+					if cycle == 2 {
+						writeFiles(t, dir, "1", "fresh")
+						backend.armed.Store(true)
+					}
+				}).
+				SetAfterScan(func(cycle int, _ ScanSummary) {
+					if cycle == 2 {
+						sn.Pause()
+						close(done)
+					}
+				})
+			opts.Events().SetVanished(tt.mode)
+			sn, err := New(dir, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := sn.StartAsync(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			defer sn.Stop()
+
+			wait(t, done, "scan cycle")
+			var known, fresh []EventName
+			for {
+				var ev Event
+				select {
+				case ev = <-sn.Queue():
+				default:
+				}
+				if ev.Name == "" {
+					break
+				}
+				if ev.Name == ERROR && !errors.Is(ev.Error, fs.ErrNotExist) {
+					t.Errorf("got error %v, want a not exist one", ev.Error)
+				}
+				switch ev.Path {
+				case filepath.Join(dir, "known"):
+					known = append(known, ev.Name)
+				case filepath.Join(dir, "fresh"):
+					fresh = append(fresh, ev.Name)
+				case filepath.Join(dir, "kept"):
+					t.Errorf("got %s event of the untouched path", ev.Name)
+				}
+			}
+			if !reflect.DeepEqual(known, tt.known) {
+				t.Errorf("got events %v of the known path, want %v", known, tt.known)
+			}
+			if !reflect.DeepEqual(fresh, tt.fresh) {
+				t.Errorf("got events %v of the new path, want %v", fresh, tt.fresh)
+			}
+		})
+	}
+}
+
+func TestVanishedDefault(t *testing.T) {
+	if m := defaultOpts().Events().GetVanished(); m != VANISHED_ERROR {
+		t.Errorf("got default vanished mode %d, want VANISHED_ERROR", m)
+	}
+	if m := (&Options{}).Events().GetVanished(); m != VANISHED_ERROR {
+		t.Errorf("got zero vanished mode %d, want VANISHED_ERROR", m)
+	}
+}
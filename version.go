@@ -0,0 +1,65 @@
+package gorsn
+
+import (
+	"runtime/debug"
+)
+
+// SchemaVersion is the version of the serialized representation of the
+// events (e.g. audit log records) and of the exported states. It changes
+// only when these formats evolve in an incompatible way.
+const SchemaVersion = 2
+
+const modulePath = "github.com/jeamon/gorsn"
+
+// Build describes the library build running inside the current binary.
+type Build struct {
+	// Module is the module path of the library.
+	Module string
+	// Version is the module version (e.g. v1.2.0) or `(devel)` when
+	// it could not be determined such as for local builds.
+	Version string
+	// Sum is the checksum of the module content when known.
+	Sum string
+	// GoVersion is the version of the Go toolchain used to build the binary.
+	GoVersion string
+	// SchemaVersion is the version of the serialized events and states.
+	SchemaVersion int
+}
+
+// BuildInfo returns the build informations of the library as recorded
+// into the running binary, so operators could correlate the behavior of
+// their services with the library version.
+func BuildInfo() Build {
+	b := Build{Module: modulePath, Version: "(devel)", SchemaVersion: SchemaVersion}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return b
+	}
+	b.GoVersion = info.GoVersion
+	mod := &info.Main
+	if mod.Path != modulePath {
+		mod = nil
+		for _, dep := range info.Deps {
+			if dep.Path == modulePath {
+				mod = dep
+				break
+			}
+		}
+	}
+	if mod == nil {
+		return b
+	}
+	if mod.Replace != nil {
+		mod = mod.Replace
+	}
+	if mod.Version != "" {
+		b.Version = mod.Version
+	}
+	b.Sum = mod.Sum
+	return b
+}
+
+// Version returns the version of the library running inside the binary.
+func Version() string {
+	return BuildInfo().Version
+}
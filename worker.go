@@ -1,86 +1,286 @@
 package gorsn
 
 import (
+	"errors"
 	"io/fs"
-	"sync/atomic"
+	"sync"
 )
 
-func (sn *snotifier) workers(done *atomic.Bool) {
-	var i uint32
-	max := sn.opts.maxworkers.Load()
-	for i < max {
-		sn.wg.Add(1)
-		go sn.work(done)
-		i++
+// entries recycles the items sent to the workers so a scan cycle does not
+// allocate one per visited path. Events are plain values so they do not
+// need to be pooled.
+var entries = sync.Pool{New: func() any { return new(fsEntry) }}
+
+// resize grows or shrinks the pool of long-lived workers to `size`.
+// Extra workers are asked to exit by a nil entry on the internal queue.
+func (sn *snotifier) resize(size uint32) {
+	for sn.size < size {
+		sn.spawn()
+	}
+	for sn.size > size {
+		sn.size--
+		sn.iqueue <- nil
 	}
 }
 
-func (sn *snotifier) work(done *atomic.Bool) {
-	var fi fs.FileInfo
-	var pt pathType
-	var err error
-	for sn.running.Load() && !sn.stopping.Load() {
-		select {
-		case fse := <-sn.iqueue:
-			fi, err = fse.d.Info()
-			if err != nil {
-				// emit ERROR event earlier since no futuer check could be done.
-				if !sn.opts.event.ignoreErrors.Load() {
-					sn.queueEvent(Event{fse.path, getPathType(fi.Mode().Type()), ERROR, err})
-				}
-				continue
-			}
+// spawn starts one more worker into the pool.
+func (sn *snotifier) spawn() {
+	sn.size++
+	sn.wg.Add(1)
+	go sn.work()
+}
 
-			pt = getPathType(fse.d.Type())
-			// use check to support the dynamic nature of `sn.opts` value.
-			// pass nil since `fse.err` is used to build the event later.
-			if ignore, _ := sn.check(fse.path, pt, nil); ignore {
-				continue
-			}
-			sn.event(pt, fse, fi)
-		default:
-			// default ensures usage & non-blocking of select.
-			if len(sn.iqueue) == 0 && done.Load() {
-				sn.wg.Done()
-				return
-			}
+// work checks the paths received from the internal queue until it is
+// closed or until it receives a nil entry. Each entry is marked done
+// into the pending items so the scanner knows once a cycle completed,
+// then released so it must not be retained by `process`.
+func (sn *snotifier) work() {
+	defer sn.wg.Done()
+	for fse := range sn.iqueue {
+		if fse == nil {
+			return
 		}
+		sn.process(fse)
+		*fse = fsEntry{}
+		entries.Put(fse)
+		sn.items.Done()
 	}
-	sn.wg.Done()
+}
+
+// process builds and emits the event of a single entry.
+func (sn *snotifier) process(fse *fsEntry) {
+	if sn.stopping.Load() || sn.aborted.Load() {
+		// drop the entries of an abandoned cycle.
+		return
+	}
+	fi, err := fse.d.Info()
+	if errors.Is(err, fs.ErrNotExist) {
+		sn.vanished(fse, err)
+		return
+	}
+	if err != nil {
+		if sn.lockedFailure(fse.path, getPathType(fse.d.Type()), err) {
+			return
+		}
+		// emit ERROR event earlier since no futuer check could be done.
+		if !sn.opts.events.ignoreErrors.Load() {
+			sn.queueEvent(Event{Path: fse.path, Type: getPathType(fse.d.Type()), Name: ERROR, Error: err})
+		}
+		return
+	}
+
+	pt := getPathType(fse.d.Type())
+	// use check to support the dynamic nature of `sn.opts` value.
+	// pass nil since `fse.err` is used to build the event later.
+	if ignore, _ := sn.check(fse.path, pt, nil); ignore {
+		return
+	}
+	if sn.ownerFiltered(pt, sysStat(fi)) {
+		return
+	}
+	sn.event(pt, fse, fi)
 }
 
 // event processes the path based on its recent state and emit or
 // not an appropriate event to the external queue.
-func (sn *snotifier) event(pt pathType, fse *fsEntry, fi fs.FileInfo) {
+func (sn *snotifier) event(pt PathType, fse *fsEntry, fi fs.FileInfo) {
+	var pi *pathInfos
 	val, exists := sn.paths.Load(fse.path)
+	cold := false
+	if exists {
+		pi = val.(*pathInfos)
+	} else {
+		var err error
+		if pi, cold, err = sn.coldInfos(fse.path); err != nil {
+			// not reported as created nor deleted while the store fails.
+			sn.visit(fse.path)
+			if !sn.cfailed.Swap(true) {
+				sn.storeFailed(err)
+			}
+			return
+		}
+		if !cold {
+			sn.created(pt, fse, fi)
+			return
+		}
+	}
+	pi.visited = true
+	pi.seen.Store(sn.epoch.Load())
+	prev := pi.state()
+	replaced := sn.reappeared(pi, fi)
+	rotated := sn.rotated(pt, fi, pi)
+	change := sn.metaChanged(pt, fse, fi, pi)
 
-	if !exists {
-		sn.paths.Store(fse.path, &pathInfos{fi.ModTime(), fi.Mode().Type(), true})
-		if !sn.opts.event.ignoreCreate.Load() {
-			sn.queueEvent(Event{fse.path, pt, CREATE, fse.err})
+	modified := sn.contentChanged(pt, fi, pi, prev) || rotated
+	touched := modified && !replaced && pt == FILE && fi.Size() == pi.size && sn.opts.events.detectTouch.Load()
+	checksum := pt == FILE && sn.opts.scan.checksum.Load()
+	var held *Event
+	if touched {
+		// only the modification time changed. If enabled, the content digest
+		// tells whether the content changed with the same size.
+		change = true
+		pi.mu.Lock()
+		pi.modTime = fi.ModTime()
+		pi.mu.Unlock()
+		if !checksum {
+			sn.queueEvent(Event{Path: fse.path, Type: pt, Name: TOUCH, Error: fse.err})
+		}
+	} else if modified || replaced {
+		change = true
+		oldSize := pi.size
+		pi.mu.Lock()
+		pi.modTime = fi.ModTime()
+		pi.size = fi.Size()
+		pi.mu.Unlock()
+		sn.markChanging(pi, pt)
+		name := MODIFY
+		switch {
+		case rotated:
+			name = ROTATE
+		case replaced && !sn.opts.events.atomicSaves.Load():
+			name = REPLACE
+		}
+		if !sn.opts.events.ignoreModify.Load() {
+			ev := Event{Path: fse.path, Type: pt, Name: name, Error: fse.err}
+			sn.withSizes(&ev, oldSize, fi.Size())
+			if checksum && (sn.opts.events.includeChecksum.Load() || name == MODIFY && sn.opts.events.blockSize.Load() > 0) {
+				held = &ev
+			} else {
+				sn.queueEvent(ev)
+			}
 		}
+	} else {
+		sn.checkSettled(fse.path, pi, pt)
+	}
+
+	if cold {
+		// only cached again if changed or until hashed.
+		if change || checksum {
+			sn.track(fse.path, pi)
+		} else {
+			sn.visit(fse.path)
+		}
+	}
+
+	if checksum {
+		// the digest is compared by the hashing workers which emit
+		// the `MODIFY` event if only the content changed.
+		sn.hash(pt, fse, fi, pi, !(modified || replaced) || touched, touched, held)
+	}
+
+	if change {
+		sn.changed(fse.path)
+	}
+	if !change && !sn.opts.events.ignoreNoChange.Load() {
+		sn.queueEvent(Event{Path: fse.path, Type: pt, Name: NOCHANGE, Error: fse.err})
+	}
+}
+
+// created registers a new path and emits the appropriate event.
+func (sn *snotifier) created(pt PathType, fse *fsEntry, fi fs.FileInfo) {
+	if !sn.admit(fse.path, pt) {
 		return
 	}
-	pi := val.(*pathInfos)
-	pi.visited = true
+	pi := sn.newPathInfos(fi, true)
+	sn.markChanging(pi, pt)
+	sn.track(fse.path, pi)
+	sn.changed(fse.path)
+	ev := sn.creation(pt, fse, pi)
+	if pt == FILE && sn.opts.scan.checksum.Load() {
+		var held *Event
+		if sn.opts.events.includeChecksum.Load() {
+			// emitted by the hashing worker along with the digest.
+			held, ev = ev, nil
+		}
+		sn.hash(pt, fse, fi, pi, false, false, held)
+	}
+	if ev != nil {
+		sn.queueEvent(*ev)
+	}
+}
+
+// creation returns the event of a new path, nil if the event is ignored or
+// held until the deleted paths are known.
+func (sn *snotifier) creation(pt PathType, fse *fsEntry, pi *pathInfos) *Event {
+	if other, linked := sn.linkAdd(fse.path, pi.sys); linked {
+		sn.countLink(pi.sys.id(), 1)
+		return &Event{Path: fse.path, OldPath: other, Type: pt, Name: LINK, Error: fse.err}
+	}
+	ev := Event{Path: fse.path, Type: pt, Name: CREATE, Error: fse.err}
+	if sn.opts.events.trackRenames.Load() {
+		// hold until deleted paths are known.
+		sn.addPending(ev, pi.sys)
+		return nil
+	}
+	if sn.opts.events.ignoreCreate.Load() {
+		return nil
+	}
+	return &ev
+}
+
+// metaChanged compares the permissions, ownership, links and attributes
+// of a known path and emits an event for each change. It reports whether
+// any change was detected.
+func (sn *snotifier) metaChanged(pt PathType, fse *fsEntry, fi fs.FileInfo, pi *pathInfos) bool {
 	change := false
-	if fi.Mode().Type().Perm() != pi.mode.Perm() {
+	mode := fi.Mode()
+	if pi.modeUnknown {
+		// permissions were not recorded by the imported state.
+		pi.modeUnknown = false
+		pi.mu.Lock()
+		pi.mode = mode
+		pi.mu.Unlock()
+	}
+	if mode&permBits != pi.mode&permBits {
 		change = true
-		pi.mode = fi.Mode().Type()
-		if !sn.opts.event.ignorePerm.Load() {
-			sn.queueEvent(Event{fse.path, pt, PERM, fse.err})
+		if !sn.opts.events.ignorePerm.Load() {
+			sn.queueEvent(Event{Path: fse.path, Type: pt, Name: PERM, Error: fse.err, Mode: mode, OldMode: pi.mode})
 		}
 	}
+	pi.mu.Lock()
+	pi.mode = mode
+	pi.mu.Unlock()
 
-	if fi.ModTime() != pi.modTime {
+	sys := sysStat(fi)
+	if sn.opts.events.trackOwner.Load() && !sys.sameOwner(pi.sys) {
 		change = true
-		pi.modTime = fi.ModTime()
-		if !sn.opts.event.ignoreModify.Load() {
-			sn.queueEvent(Event{fse.path, pt, MODIFY, fse.err})
+		sn.queueEvent(Event{Path: fse.path, Type: pt, Name: OWNER, Error: fse.err})
+	}
+	if sn.opts.events.trackLinks.Load() && sys.valid && pi.sys.valid && sys.nlink != pi.sys.nlink {
+		change = true
+		name := LINK
+		if sys.nlink < pi.sys.nlink {
+			name = UNLINK
+		}
+		// dropped if only caused by a path linked or unlinked into the root.
+		sn.holdLink(Event{Path: fse.path, Type: pt, Name: name, Error: fse.err}, sys.id(), int(sys.nlink)-int(pi.sys.nlink))
+	}
+	if pi.sys.valid && pi.sys.id() != sys.id() {
+		// path now refers to another file.
+		sn.linkRemove(fse.path, pi.sys)
+	}
+	sn.linkAdd(fse.path, sys)
+
+	if !sys.sameAttributes(pi.sys) {
+		change = true
+		if !sn.opts.events.ignoreAttrib.Load() {
+			sn.queueEvent(Event{Path: fse.path, Type: pt, Name: ATTRIB, Error: fse.err})
 		}
 	}
+	pi.mu.Lock()
+	pi.sys = sys
+	pi.mu.Unlock()
+	return change
+}
 
-	if !change && !sn.opts.event.ignoreNoChange.Load() {
-		sn.queueEvent(Event{fse.path, pt, NOCHANGE, fse.err})
+// contentChanged reports whether the content of a known path changed based
+// on the configured comparator.
+func (sn *snotifier) contentChanged(pt PathType, fi fs.FileInfo, pi *pathInfos, prev PathState) bool {
+	if pt != FILE || !sn.opts.scan.checksum.Load() {
+		pi.mu.Lock()
+		pi.sum, pi.blocks = "", nil
+		pi.mu.Unlock()
 	}
+	cur := PathState{ModTime: fi.ModTime(), Mode: fi.Mode(), Size: fi.Size(), ChangeTime: nanoTime(pi.sys.ctime)}
+	return sn.opts.scan.comparator().Changed(prev, cur)
 }